@@ -0,0 +1,150 @@
+// Command contract-check verifies sample publisher messages against a running
+// notification service before they reach production.
+//
+// Each file argument holds one JSON payload, exactly as it would be published
+// to Redis. The payloads are sent to POST /internal/contract-check and every
+// violation is printed. The exit code is 1 if any sample is invalid, so the
+// tool can gate a CI pipeline:
+//
+//	contract-check -url https://notification.staging -channel project:p1:user:u1 samples/*.json
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"notification-srv/pkg/signing"
+)
+
+type checkRequest struct {
+	Channel string          `json:"channel,omitempty"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type checkResult struct {
+	Valid       bool            `json:"valid"`
+	ChannelType string          `json:"channel_type"`
+	MessageType string          `json:"message_type"`
+	Errors      []string        `json:"errors"`
+	Output      json.RawMessage `json:"output"`
+}
+
+type envelope struct {
+	ErrorCode int         `json:"error_code"`
+	Message   string      `json:"message"`
+	Data      checkResult `json:"data"`
+}
+
+func main() {
+	url := flag.String("url", "http://localhost:8080", "Base URL of the notification service")
+	key := flag.String("key", os.Getenv("INTERNAL_KEY"), "Internal service key (default $INTERNAL_KEY)")
+	keyID := flag.String("key-id", os.Getenv("INTERNAL_KEY_ID"), "Signing key ID; requests are signed instead of sending -key (default $INTERNAL_KEY_ID)")
+	secret := flag.String("secret", os.Getenv("INTERNAL_SIGNING_SECRET"), "Signing key secret (default $INTERNAL_SIGNING_SECRET)")
+	channel := flag.String("channel", "", "Redis channel the samples are published to (optional)")
+	verbose := flag.Bool("v", false, "Print the normalized output of valid samples")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] sample.json...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	auth := credentials{key: *key}
+	if *keyID != "" {
+		auth.signingKey = &signing.Key{ID: *keyID, Secret: []byte(*secret)}
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	failed := 0
+	for _, path := range flag.Args() {
+		result, err := check(client, *url, auth, *channel, path)
+		if err != nil {
+			fmt.Printf("ERROR %s: %v\n", path, err)
+			failed++
+			continue
+		}
+
+		if !result.Valid {
+			fmt.Printf("FAIL  %s (%s)\n", path, result.MessageType)
+			for _, e := range result.Errors {
+				fmt.Printf("      - %s\n", e)
+			}
+			failed++
+			continue
+		}
+
+		fmt.Printf("OK    %s (%s)\n", path, result.MessageType)
+		if *verbose {
+			fmt.Printf("      %s\n", result.Output)
+		}
+	}
+
+	fmt.Printf("\n%d checked, %d failed\n", flag.NArg(), failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// credentials authenticate the request: signed with signingKey when set,
+// otherwise with the shared internal key.
+type credentials struct {
+	key        string
+	signingKey *signing.Key
+}
+
+// check posts one sample file to the contract-check endpoint.
+func check(client *http.Client, baseURL string, auth credentials, channel, path string) (checkResult, error) {
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		return checkResult{}, err
+	}
+	if !json.Valid(payload) {
+		return checkResult{}, fmt.Errorf("file is not valid JSON")
+	}
+
+	body, err := json.Marshal(checkRequest{Channel: channel, Payload: payload})
+	if err != nil {
+		return checkResult{}, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, baseURL+"/internal/contract-check", bytes.NewReader(body))
+	if err != nil {
+		return checkResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if auth.signingKey != nil {
+		signing.Sign(req, *auth.signingKey, body, time.Now())
+	} else {
+		req.Header.Set("X-Internal-Key", auth.key)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return checkResult{}, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return checkResult{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return checkResult{}, fmt.Errorf("service returned %s: %s", resp.Status, raw)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return checkResult{}, fmt.Errorf("decode response: %w", err)
+	}
+	return env.Data, nil
+}
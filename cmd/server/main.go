@@ -2,17 +2,23 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"notification-srv/config"
 	"notification-srv/internal/httpserver"
+	"notification-srv/internal/metrics"
+	wsRedis "notification-srv/internal/websocket/delivery/redis"
+	"notification-srv/pkg/redisclient"
+	"notification-srv/pkg/subtoken"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/smap-hcmut/shared-libs/go/auth"
 	"github.com/smap-hcmut/shared-libs/go/discord"
 	"github.com/smap-hcmut/shared-libs/go/log"
-	"github.com/smap-hcmut/shared-libs/go/redis"
 )
 
 // @title       SMAP Notification Service API
@@ -30,12 +36,26 @@ import (
 // @in header
 // @name Authorization
 // @description Legacy Bearer token authentication (deprecated - use cookie authentication instead). Format: "Bearer {token}"
+//
+// @securityDefinitions.apikey InternalKey
+// @in header
+// @name X-Internal-Key
+// @description Shared service key for /internal/* while internal.allow_static_key is on. Signed requests send the X-Signature-* headers instead.
 func main() {
+	src := config.SourcesFromEnv()
+	flag.StringVar(&src.File, "config", src.File, "config file (env CONFIG_FILE; default: notification-config.* in ./config, . or /etc/smap/)")
+	flag.StringVar(&src.Profile, "profile", src.Profile, "profile merged over the config file, e.g. staging (env CONFIG_PROFILE)")
+	validateOnly := flag.Bool("validate-config", false, "print the effective configuration with secrets masked, validate it and exit")
+	flag.Parse()
+
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := config.LoadFrom(src)
+	if *validateOnly {
+		os.Exit(printConfig(err))
+	}
 	if err != nil {
-		fmt.Println("Failed to load config:", err)
-		return
+		fmt.Fprintln(os.Stderr, "Failed to load config:", err)
+		os.Exit(1)
 	}
 
 	// Initialize logger
@@ -51,21 +71,62 @@ func main() {
 	defer stop()
 
 	// Redis - Pub/Sub for real-time notifications
-	redisClient, err := redis.New(redis.RedisConfig{
+	redisClient, err := redisclient.ConnectWithRetry(ctx, redisclient.Config{
 		Host:     cfg.Redis.Host,
 		Port:     cfg.Redis.Port,
+		Username: cfg.Redis.Username,
 		Password: cfg.Redis.Password,
 		DB:       cfg.Redis.DB,
-	})
+		TLS:      redisTLS(cfg.Redis.TLS),
+	}, startupRetry(ctx, logger, cfg.Startup, "Redis"))
 	if err != nil {
 		logger.Errorf(ctx, "Failed to connect to Redis: %v", err)
 		return
 	}
 	defer redisClient.Close()
+	if err := metrics.InstrumentRedis(redisClient.GetClient(), cfg.Redis.Region, logger, cfg.Redis.SlowCommandThreshold); err != nil {
+		logger.Errorf(ctx, "Failed to instrument Redis: %v", err)
+		return
+	}
 	logger.Infof(ctx, "Redis client initialized")
 
-	// Scope/JWT Manager (verify tokens from HttpOnly cookie)
-	jwtManager := auth.NewManager(cfg.JWT.SecretKey)
+	// Redis - remote regions merged into the notification stream
+	remoteRegions := make([]wsRedis.Upstream, 0, len(cfg.Redis.Regions))
+	for _, region := range cfg.Redis.Regions {
+		client, err := redisclient.ConnectWithRetry(ctx, redisclient.Config{
+			Host:     region.Host,
+			Port:     region.Port,
+			Username: region.Username,
+			Password: region.Password,
+			DB:       region.DB,
+			TLS:      redisTLS(region.TLS),
+		}, startupRetry(ctx, logger, cfg.Startup, "Redis of region "+region.Name))
+		if err != nil {
+			logger.Errorf(ctx, "Failed to connect to Redis of region %s: %v", region.Name, err)
+			return
+		}
+		defer client.Close()
+		if err := metrics.InstrumentRedis(client.GetClient(), region.Name, logger, cfg.Redis.SlowCommandThreshold); err != nil {
+			logger.Errorf(ctx, "Failed to instrument Redis of region %s: %v", region.Name, err)
+			return
+		}
+
+		remoteRegions = append(remoteRegions, wsRedis.Upstream{
+			Region:        region.Name,
+			Redis:         client,
+			ChannelPrefix: region.ChannelPrefix,
+		})
+		logger.Infof(ctx, "Redis client initialized for region %s", region.Name)
+	}
+
+	// Scope/JWT Manager (verify tokens from HttpOnly cookie). Subscription
+	// tokens share its key, so it refuses their audience as user tokens.
+	baseJWTManager := auth.NewManager(cfg.JWT.SecretKey)
+	jwtManager := subtoken.Guard(baseJWTManager, cfg.JWT.SubscriptionAudience)
+	var subTokens *subtoken.Verifier
+	if cfg.JWT.SubscriptionEnabled {
+		subTokens = subtoken.NewVerifier(baseJWTManager, cfg.JWT.SubscriptionAudience, cfg.JWT.SubscriptionMaxTTL)
+	}
 	logger.Infof(ctx, "Scope/JWT Manager initialized")
 
 	// Discord - Monitoring & Notification
@@ -84,17 +145,62 @@ func main() {
 		Mode:        cfg.Server.Mode,
 		Environment: cfg.Environment.Name,
 
+		// Zero-downtime restart
+		ReusePort:     cfg.Server.ReusePort,
+		HandoffSocket: cfg.Server.HandoffSocket,
+
+		// Route prefix
+		BasePath: cfg.Server.BasePath,
+		WSPath:   cfg.Server.WSPath,
+
+		// Admin listener
+		AdminListener: cfg.Server.Admin,
+
 		// WebSocket configuration
 		WSConfig: cfg.WebSocket,
 
+		// Transform, backfill & client telemetry configuration
+		TransformConfig:   cfg.Transform,
+		BackfillConfig:    cfg.Backfill,
+		LatestStateConfig: cfg.LatestState,
+		TelemetryConfig:   cfg.Telemetry,
+		SinksConfig:       cfg.Sinks,
+		MQTTConfig:        cfg.MQTT,
+		GraphQLConfig:     cfg.GraphQL,
+		PresenceConfig:    cfg.Presence,
+		SegmentsConfig:    cfg.Segments,
+		BansConfig:        cfg.Bans,
+		DelegationConfig:  cfg.Delegation,
+		DeliveryConfig:    cfg.Delivery,
+		UserTraceConfig:   cfg.UserTrace,
+
+		// Experimental WebTransport (HTTP/3) listener
+		WebTransportConfig: cfg.WebTransport,
+
 		// Auth & security
-		JWTManager:  jwtManager,
-		Cookie:      cfg.Cookie,
-		InternalKey: cfg.InternalConfig.InternalKey,
+		JWTManager:     jwtManager,
+		SubTokens:      subTokens,
+		Cookie:         cfg.Cookie,
+		InternalConfig: cfg.InternalConfig,
 
 		// External services
 		Redis:   redisClient,
 		Discord: discordClient,
+
+		// Multi-region Redis
+		Region:        cfg.Redis.Region,
+		ChannelPrefix: cfg.Redis.ChannelPrefix,
+		RemoteRegions: remoteRegions,
+
+		// Background loop supervision
+		SupervisorConfig: cfg.Supervisor,
+		BreakerConfig:    cfg.Breaker,
+		LeaderConfig:     cfg.Leader,
+		WatchdogConfig:   cfg.Watchdog,
+		ProbeConfig:      cfg.Probe,
+		CanaryConfig:     cfg.Canary,
+
+		RateLimitConfig: cfg.RateLimit,
 	})
 	if err != nil {
 		logger.Error(ctx, "Failed to initialize HTTP server: ", err)
@@ -108,3 +214,46 @@ func main() {
 
 	logger.Info(ctx, "API server stopped gracefully")
 }
+
+func redisTLS(cfg config.RedisTLSConfig) redisclient.TLSConfig {
+	return redisclient.TLSConfig{
+		Enabled:            cfg.Enabled,
+		CAFile:             cfg.CAFile,
+		CertFile:           cfg.CertFile,
+		KeyFile:            cfg.KeyFile,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+}
+
+// startupRetry is the retry policy of a dependency startup waits for, logging
+// every failed attempt.
+func startupRetry(ctx context.Context, logger log.Logger, cfg config.StartupConfig, name string) redisclient.Retry {
+	return redisclient.Retry{
+		MaxWait:        cfg.MaxWait,
+		InitialBackoff: cfg.InitialBackoff,
+		MaxBackoff:     cfg.MaxBackoff,
+		OnRetry: func(attempt int, wait time.Duration, err error) {
+			logger.Warnf(ctx, "%s not reachable yet (attempt %d), retrying in %s: %v", name, attempt, wait, err)
+		},
+	}
+}
+
+// printConfig prints the effective configuration and the result of loading
+// it, and returns the exit code for --validate-config.
+func printConfig(loadErr error) int {
+	out, err := json.MarshalIndent(config.Effective(), "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to print config:", err)
+		return 1
+	}
+	fmt.Println(string(out))
+	fmt.Fprintln(os.Stderr, "Config files:", config.Files())
+
+	if loadErr != nil {
+		fmt.Fprintln(os.Stderr, "Failed to load config:", loadErr)
+		return 1
+	}
+	fmt.Fprintln(os.Stderr, "Configuration is valid")
+	return 0
+}
@@ -2,6 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -23,13 +28,69 @@ type Config struct {
 	// WebSocket Configuration
 	WebSocket WebSocketConfig
 
+	// Transform Configuration
+	Transform TransformConfig
+
+	// Project State Backfill Configuration
+	Backfill BackfillConfig
+
+	// Latest Project Topic State Configuration
+	LatestState LatestStateConfig
+
+	// Client Telemetry Configuration
+	Telemetry TelemetryConfig
+
+	// GraphQL Gateway Configuration
+	GraphQL GraphQLConfig
+
+	// WebTransport (HTTP/3) Listener Configuration
+	WebTransport WebTransportConfig
+
+	// User Presence Configuration
+	Presence PresenceConfig
+
+	// Broadcast Segment Configuration
+	Segments SegmentsConfig
+
+	// Deny-list Configuration
+	Bans BansConfig
+
+	// Delegated Access Configuration
+	Delegation DelegationConfig
+
+	// Delivery Guarantees Configuration
+	Delivery DeliveryConfig
+
+	// Targeted Tracing Configuration
+	UserTrace UserTraceConfig
+
+	// Outbound Sinks Configuration
+	Sinks SinksConfig
+
+	// MQTT Bridge Configuration
+	MQTT MQTTConfig
+
 	// Authentication & Security Configuration
 	JWT            JWTConfig
 	Cookie         CookieConfig
 	InternalConfig InternalConfig
 
 	// Monitoring & Notification Configuration
-	Discord DiscordConfig
+	Discord    DiscordConfig
+	Supervisor SupervisorConfig
+	Watchdog   WatchdogConfig
+	Probe      ProbeConfig
+	Canary     CanaryConfig
+	Breaker    BreakerConfig
+
+	// Inbound Rate Limit Configuration
+	RateLimit RateLimitConfig
+
+	// Startup Dependency Wait Configuration
+	Startup StartupConfig
+
+	// Leader Election Configuration
+	Leader LeaderConfig
 }
 
 // EnvironmentConfig is the configuration for the deployment environment.
@@ -41,14 +102,73 @@ type EnvironmentConfig struct {
 type ServerConfig struct {
 	Port int
 	Mode string
+
+	// Zero-downtime restart: the new process binds the port alongside the old one
+	// (SO_REUSEPORT) and asks it to drain over a unix socket
+	ReusePort     bool
+	HandoffSocket string // Empty disables the handoff
+
+	// Path-routed ingress: every route is mounted under BasePath (e.g.
+	// "/notifications"), the WebSocket endpoint at BasePath + WSPath
+	BasePath string
+	WSPath   string
+
+	// Where the admin routes, and the internal ones unless on the mTLS listener, are served
+	Admin AdminListenerConfig
+}
+
+// AdminListenerConfig takes the admin and internal routes off the public port.
+type AdminListenerConfig struct {
+	Mode string // public (on server.port), separate (on Addr) or disabled (admin routes not served)
+	Addr string // host:port, or unix:<path> for a unix socket
+	Auth string // Admin auth on the separate listener: jwt (ADMIN token) or none (the listener is the boundary)
 }
 
 // RedisConfig is the configuration for Redis
 type RedisConfig struct {
 	Host     string
 	Port     int
+	Username string // ACL user; empty authenticates as the default user
 	Password string
 	DB       int
+	TLS      RedisTLSConfig
+
+	// Multi-region: the local Redis above is the primary region. Remote
+	// regions are subscribed as well and merged into one notification stream.
+	Region        string              // Name of the local region, used as a tag in frames and metrics
+	ChannelPrefix string              // Prefix of every channel on the local Redis (e.g. "ap:")
+	Regions       []RedisRegionConfig // Remote regions
+
+	SlowCommandThreshold time.Duration // Commands slower than this are logged and counted (0 disables)
+}
+
+// RedisRegionConfig is a remote region's Redis endpoint.
+// PublicProjectConfig is a project open to anonymous viewers. Its messages are
+// taken from the project channel of OwnerUserID.
+type PublicProjectConfig struct {
+	ProjectID   string `mapstructure:"project_id"`
+	OwnerUserID string `mapstructure:"owner_user_id"`
+}
+
+type RedisRegionConfig struct {
+	Name          string         `mapstructure:"name"`
+	Host          string         `mapstructure:"host"`
+	Port          int            `mapstructure:"port"`
+	Username      string         `mapstructure:"username"`
+	Password      string         `mapstructure:"password"`
+	DB            int            `mapstructure:"db"`
+	TLS           RedisTLSConfig `mapstructure:"tls"`
+	ChannelPrefix string         `mapstructure:"channel_prefix"`
+}
+
+// RedisTLSConfig is the TLS configuration of a Redis connection.
+type RedisTLSConfig struct {
+	Enabled            bool   `mapstructure:"enabled"`
+	CAFile             string `mapstructure:"ca_file"`   // CA bundle replacing the system roots
+	CertFile           string `mapstructure:"cert_file"` // Client certificate, with KeyFile
+	KeyFile            string `mapstructure:"key_file"`
+	ServerName         string `mapstructure:"server_name"` // Defaults to the host
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
 }
 
 // WebSocketConfig is the configuration for WebSocket connections
@@ -60,11 +180,361 @@ type WebSocketConfig struct {
 	ReadBufferSize  int
 	WriteBufferSize int
 	MaxConnections  int
+	Heartbeat       bool // Send a heartbeat frame with server time and RTT on every ping
+	Hello           bool // Send a hello frame advertising the protocol capabilities first
+
+	// WebSocket library: gorilla, coder (github.com/coder/websocket) or netpoll
+	// (github.com/gobwas/ws with epoll-driven reads on Linux)
+	Transport string
+
+	// Consecutive malformed client frames tolerated before closing (0 = never close)
+	MaxProtocolViolations int
+
+	// Compression (permessage-deflate)
+	EnableCompression     bool
+	CompressionUADenylist []string // User-Agent substrings for which compression is disabled
+
+	// Client labels (?client_label=) kept as metric labels; others are counted as "other"
+	ClientLabels []string
+
+	// Minimum client version per client label ("*" = any other label); older clients get 426
+	MinClientVersions    map[string]string
+	UpgradeURL           string // Sent to outdated clients
+	CloseOutdatedClients bool   // Accept the handshake and close with 4260 instead of answering 426
+
+	// Budgets of the upgrade path stages (0 = unbounded); an overrun answers 504
+	AuthTimeout     time.Duration // Deny-list, token and quota checks
+	UpgradeTimeout  time.Duration // WebSocket handshake
+	RegisterTimeout time.Duration // Backfill and Hub registration
+
+	// Per-topic replay buffer for sequence gap recovery
+	ReplayBufferSize int // Frames kept per (topic, user)
+	ReplayMaxTopics  int // (topic, user) pairs kept, least recently used evicted first
+
+	// Rolling window of the per-channel statistics served to publisher teams
+	StatsWindow time.Duration
+
+	// Hub stats sampled into an in-memory history (interval 0 = disabled)
+	StatsHistoryInterval  time.Duration
+	StatsHistoryRetention time.Duration
+
+	// Delay between project_completed/project_failed and the project's topic cleanup (0 = never clean up)
+	TopicGCGrace time.Duration
+
+	// Frames per collapse key between full snapshots for delta-encoding clients (1 = never send deltas)
+	DeltaSnapshotEvery int
+
+	// Coalescing of collapse_key frames, stretched from min to max interval for slow connections
+	CoalesceEnabled     bool
+	CoalesceMinInterval time.Duration
+	CoalesceMaxInterval time.Duration
+
+	// Connection churn detection: alerts (and optional bans) per fixed window
+	ChurnEnabled         bool
+	ChurnWindow          time.Duration
+	ChurnMaxUserConnects int           // Connects of one user per window
+	ChurnMaxIPUsers      int           // Distinct users connecting from one IP per window
+	ChurnBanTTL          time.Duration // Temporary ban of the offender (0 = alert only)
+
+	// Decoy project IDs (path.Match patterns) that are never issued; subscribing
+	// to one is alerted and optionally banned
+	DecoyPatterns []string
+	DecoyBanTTL   time.Duration // Temporary ban of the subscriber (0 = alert only)
+	DecoyBanIP    bool          // Ban the subscriber's IP as well as the user
+
+	// Projects anonymous viewers may follow on {path}/public, e.g. for a
+	// status page
+	PublicEnabled        bool
+	PublicProjects       []PublicProjectConfig
+	PublicMaxConnections int     // Anonymous connections per replica (0 = unlimited)
+	PublicMaxPerIP       int     // Anonymous connections per IP (0 = unlimited)
+	PublicConnectRate    float64 // Anonymous upgrades per second and IP
+	PublicConnectBurst   int
+
+	// Request headers in which the TLS-terminating edge forwards client
+	// fingerprints, kept on the connection (empty = not captured)
+	TLSFingerprintHeader   string // e.g. a JA3 or JA4 hash
+	HTTP2FingerprintHeader string // HTTP/2 SETTINGS/priority fingerprint of the edge connection
+
+	// Backpressure signals on backpressure:{channel} for channels whose frames
+	// connections keep dropping, counted per fixed window (0 = off)
+	BackpressureWindow      time.Duration
+	BackpressureMinDropRate float64 // Share of the channel's frames dropped (0-1)
+	BackpressureMinFrames   int     // Frames routed in the window before a channel may be signalled
+
+	// Per-user connection quota: by the value of a token claim, else the default
+	QuotaClaim          string         // Claim holding the user's tier, e.g. "plan"
+	QuotaByClaim        map[string]int // Quota per claim value, e.g. pro: 25
+	QuotaDefaultPerUser int            // Quota of everyone else (0 = unlimited)
+	QuotaPolicy         string         // At the quota: reject the new connection, or kick_oldest
+
+	// Per-connection send queue: frames buffered for the write pump
+	SendQueueSize       int            // Frames per connection
+	SendQueuePolicy     string         // When full: drop_newest (drop the frame) or drop_oldest (evict the oldest queued frame)
+	SendQueueClassSizes map[string]int // Size per client label, e.g. dashboard: 1024
+}
+
+// TransformConfig is the configuration for the message transform layer
+type TransformConfig struct {
+	// Validation mode: strict (reject unknown fields and rule violations),
+	// lenient (ignore unknown fields), or log-only (record violations but deliver).
+	Validation string
+
+	// Shadow-run a candidate transformer version on a percentage of messages
+	// and record field-level diffs against the delivered output.
+	ShadowVersion string
+	ShadowPercent int
+
+	// Job status transition validation: off, flag (log + metric, deliver) or
+	// suppress (drop). Allowed replaces the built-in next statuses of the
+	// statuses it lists, e.g. data_onboarding: {failed: [pending, processing]}.
+	TransitionsMode      string
+	TransitionsMaxTopics int                            // Jobs whose last status is remembered
+	TransitionsAllowed   map[string]map[string][]string // Message type -> status -> allowed next statuses
+
+	// Terminal job updates repeating the job's last one within the window are
+	// dropped unless the payload has "force": true (window 0 = off)
+	TerminalDedupeWindow    time.Duration
+	TerminalDedupeMaxTopics int // Jobs whose last terminal update is remembered
+
+	// Free-text fields (names, messages, sample mentions) are made valid UTF-8
+	// and stripped of control characters. Fields replaces the built-in length
+	// limit and HTML escaping of a field, e.g. sample_mentions: {max_length: 280}.
+	SanitizeEnabled bool
+	SanitizeFields  map[string]SanitizeFieldConfig // JSON field name -> rule
+
+	// Message types that may be switched off while clients cannot show them;
+	// a disabled type is dropped on arrival
+	SecurityAlertEnabled     bool
+	BillingEventEnabled      bool
+	SystemMaintenanceEnabled bool
+}
+
+// SanitizeFieldConfig is how one free-text payload field is cut and escaped
+type SanitizeFieldConfig struct {
+	MaxLength  int  `mapstructure:"max_length"`  // Characters kept, the trailing ellipsis included (0 = no limit)
+	EscapeHTML bool `mapstructure:"escape_html"` // Escape for clients that render the text as HTML
+}
+
+// SupervisorConfig is the restart policy for background loops (Hub, Redis subscriber)
+type SupervisorConfig struct {
+	MaxRestartsPerMinute int           // Restarts per minute before a Discord alert is sent (0 = never alert)
+	RestartBackoff       time.Duration // Pause before restarting a crashed loop
+}
+
+// WatchdogConfig is the configuration for detecting silent Redis subscriber stalls
+type WatchdogConfig struct {
+	Enabled          bool
+	StallWindow      time.Duration // Silence tolerated while traffic is expected
+	CheckInterval    time.Duration
+	HeartbeatChannel string // Optional collector heartbeat channel carrying {"active_jobs": N}
+}
+
+// ProbeConfig is the configuration for the synthetic Redis pub/sub loopback probe
+type ProbeConfig struct {
+	Enabled    bool
+	Interval   time.Duration
+	InstanceID string // Defaults to the hostname
+}
+
+// CanaryConfig is the configuration for the synthetic canary user probed end to
+// end: Redis, subscriber, transform, Hub and a loopback connection. It shares
+// probe.instance_id.
+type CanaryConfig struct {
+	Enabled          bool
+	UserID           string        // Reserved user the probes are addressed to
+	ProjectID        string        // Project of the probes' channel
+	Interval         time.Duration // Between probes
+	Timeout          time.Duration // A probe not received within this is lost
+	LatencyThreshold time.Duration // A probe received later than this is slow
+	FailuresToAlert  int           // Failed probes in a row before the Discord alert
+}
+
+// RateLimitConfig caps the inbound message rate of each Redis channel
+type RateLimitConfig struct {
+	Rate        float64                      // Messages per second per channel (0 = unlimited)
+	Burst       int                          // Messages allowed at once after a quiet period
+	Prefixes    map[string]ChannelRateConfig // By channel prefix, e.g. "alert:"; the longest match wins
+	Mode        string                       // drop, or coalesce (hold the channel's latest message)
+	MaxChannels int                          // Channel buckets kept in memory
+
+	// Optional Redis list receiving a sample of the dropped messages
+	DLQKey        string
+	DLQSampleRate float64 // Share of dropped messages pushed (0-1)
+	DLQMaxLen     int     // The list is trimmed to this many entries
+}
+
+// ChannelRateConfig is the rate limit of the channels under one prefix
+type ChannelRateConfig struct {
+	Rate  float64 `mapstructure:"rate"`
+	Burst int     `mapstructure:"burst"`
+}
+
+// BreakerConfig is the circuit breaker policy of outbound dependencies (Discord, sinks)
+type BreakerConfig struct {
+	Enabled        bool
+	FailureRatio   float64       // Share of failed calls within Interval that opens a breaker
+	MinRequests    int           // Calls within Interval before the ratio is considered
+	Interval       time.Duration // Counting window while closed
+	OpenTimeout    time.Duration // Time open before probing again
+	HalfOpenProbes int           // Calls let through while half-open
+}
+
+// StartupConfig is how long startup waits for its dependencies (Redis) to
+// come up before giving up
+type StartupConfig struct {
+	MaxWait        time.Duration // Total wait per dependency; 0 = a single attempt
+	InitialBackoff time.Duration // Wait after the first failed attempt, doubled per attempt
+	MaxBackoff     time.Duration // Cap of the wait between attempts
+}
+
+// LeaderConfig is the configuration of the Redis leases electing the replica
+// that runs each singleton task
+type LeaderConfig struct {
+	KeyPrefix     string        // Lease key is KeyPrefix + task name
+	Identity      string        // Defaults to the hostname
+	TTL           time.Duration // Time a crashed leader blocks the others
+	RenewInterval time.Duration // Lease renewal and takeover attempts
+}
+
+// SinksConfig is the configuration for outbound copies of delivered notifications
+type SinksConfig struct {
+	Kafka      KafkaSinkConfig
+	ClickHouse ClickHouseSinkConfig
+}
+
+// MQTTConfig republishes terminal project notifications to an MQTT broker
+type MQTTConfig struct {
+	Enabled      bool
+	BrokerURL    string
+	ClientID     string
+	Username     string
+	Password     string
+	TopicPattern string // {project_id} is substituted
+	QoS          int
+	Retain       bool
+	QueueSize    int
+	Timeout      time.Duration
+}
+
+// ClickHouseSinkConfig exports delivery events to ClickHouse over its HTTP interface
+type ClickHouseSinkConfig struct {
+	Enabled       bool
+	URL           string
+	Database      string
+	Table         string
+	Username      string
+	Password      string
+	BatchSize     int
+	FlushInterval time.Duration
+	QueueSize     int
+	Timeout       time.Duration
+	MaxRetries    int
+}
+
+// KafkaSinkConfig mirrors delivered notifications to a Kafka topic, produced to the brokers directly
+type KafkaSinkConfig struct {
+	Enabled       bool
+	Brokers       []string // Seed brokers, produced to directly
+	Topic         string
+	MessageTypes  []string // Empty mirrors every message type
+	BatchSize     int
+	FlushInterval time.Duration
+	QueueSize     int
+	Timeout       time.Duration
+	MaxRetries    int
+}
+
+// BackfillConfig is the configuration for the project state snapshot sent on connect
+type BackfillConfig struct {
+	Enabled         bool
+	StateKeyPattern string        // Collector state key, {project_id} and {user_id} (optional) are substituted
+	Timeout         time.Duration // Upper bound on the state lookup
+}
+
+// LatestStateConfig is the configuration for the latest frame of each project
+// topic, written to Redis after delivery
+type LatestStateConfig struct {
+	Enabled    bool
+	KeyPattern string        // {project_id} and {user_id} are substituted
+	TTL        time.Duration // Refreshed by every frame of the topic
+}
+
+// PresenceConfig is the configuration for the per-user presence key in Redis
+type PresenceConfig struct {
+	Enabled    bool
+	KeyPattern string        // {user_id} is substituted
+	TTL        time.Duration // Refreshed on every pong, so it must exceed the 54s ping period
+}
+
+// SegmentsConfig is the configuration for the Redis sets broadcast segments are resolved from
+type SegmentsConfig struct {
+	PlanKeyPattern string // Set of user IDs on a plan, {plan} is substituted
+	OrgKeyPattern  string // Set of project IDs of an org, {org_id} is substituted
+}
+
+// BansConfig is the configuration for the deny-list of users and IPs in Redis
+type BansConfig struct {
+	KeyPattern string // {kind} (user, ip) and {value} are substituted
+}
+
+// DelegationConfig is the configuration for subscribing to another user's
+// stream with /ws?asUser=
+type DelegationConfig struct {
+	Enabled    bool
+	KeyPattern string // Set of user IDs allowed to act as a user, {user_id} is substituted
+}
+
+// DeliveryConfig is the configuration for at-least-once delivery: acked
+// frames, retries and the per-user offline queue
+type DeliveryConfig struct {
+	Enabled           bool
+	Policies          map[string]string // Message type -> at_most_once | at_least_once, replacing the built-in policy
+	AckTimeout        time.Duration     // Resend a frame not acked within this long
+	MaxRetries        int               // Resends before an unacked frame is given up
+	OfflineKeyPattern string            // Offline queue, {user_id} is substituted
+	OfflineMaxFrames  int               // Newest frames kept per user
+	OfflineTTL        time.Duration     // Expiry of a queue after its last frame
+}
+
+// UserTraceConfig is the configuration for the per-user tracing toggle of
+// /admin/traces
+type UserTraceConfig struct {
+	MaxTTL   time.Duration // Longest trace an operator may start
+	MaxUsers int           // Users traced at once on a replica
+}
+
+// GraphQLConfig is the configuration for the GraphQL subscription gateway
+type GraphQLConfig struct {
+	Enabled     bool
+	InitTimeout time.Duration // Time allowed between upgrade and connection_init
+}
+
+// WebTransportConfig is the configuration for the experimental WebTransport
+// listener, serving the /ws stream over HTTP/3 on its own UDP port
+type WebTransportConfig struct {
+	Enabled  bool
+	Port     int    // UDP port
+	CertFile string // Server certificate; HTTP/3 has no cleartext mode
+	KeyFile  string // Server private key
+}
+
+// TelemetryConfig is the configuration for client-reported delivery latency
+type TelemetryConfig struct {
+	EmitTTL          time.Duration
+	MaxEmitRecords   int
+	MaxReportSamples int
 }
 
 // JWTConfig is the configuration for the JWT
 type JWTConfig struct {
 	SecretKey string
+
+	// Project subscription tokens minted by smap-api (/ws?subToken=), signed
+	// with SecretKey. User tokens with this audience are always refused.
+	SubscriptionEnabled  bool
+	SubscriptionAudience string
+	SubscriptionMaxTTL   time.Duration // Longest exp - iat accepted
 }
 
 // CookieConfig is the configuration for HttpOnly cookie authentication
@@ -90,19 +560,43 @@ type DiscordConfig struct {
 }
 
 // InternalConfig is the configuration for internal service authentication.
+// Callers sign requests with a per-caller key; the shared InternalKey is
+// accepted on unsigned requests only while AllowStaticKey is on.
 type InternalConfig struct {
-	InternalKey string
+	InternalKey    string
+	AllowStaticKey bool                // Accept the X-Internal-Key header on unsigned requests
+	MaxSkew        time.Duration       // Clock difference tolerated on signed requests
+	Keys           []InternalKeyConfig // Signing keys; several per caller during rotation
+	MTLS           InternalMTLSConfig
 }
 
-// Load loads configuration using Viper
+// InternalMTLSConfig moves the internal endpoints to a separate listener that
+// requires a client certificate whose SPIFFE ID (URI SAN) is allow-listed.
+type InternalMTLSConfig struct {
+	Enabled          bool
+	Port             int
+	CertFile         string   // Server certificate, reloaded when the file changes
+	KeyFile          string   // Server private key
+	ClientCAFile     string   // CA bundle client certificates must chain to
+	AllowedSPIFFEIDs []string // Exact IDs, or "spiffe://trust-domain/prefix/*"
+}
+
+// InternalKeyConfig is a caller's request signing key.
+type InternalKeyConfig struct {
+	ID     string `mapstructure:"id"`
+	Caller string `mapstructure:"caller"`
+	Secret string `mapstructure:"secret"`
+}
+
+// Load loads configuration using Viper, from the files named by CONFIG_FILE
+// and CONFIG_PROFILE.
 func Load() (*Config, error) {
-	// Set config file name and paths
-	viper.SetConfigName("notification-config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath("./config")
-	viper.AddConfigPath(".")
-	viper.AddConfigPath("/etc/smap/")
+	return LoadFrom(SourcesFromEnv())
+}
 
+// LoadFrom loads the configuration from the given files and the environment,
+// in the order documented in sources.go, and validates it.
+func LoadFrom(src Sources) (*Config, error) {
 	// Enable environment variable override
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
@@ -113,12 +607,9 @@ func Load() (*Config, error) {
 	// Set defaults
 	setDefaults()
 
-	// Read config file (optional - will use env vars if file not found)
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return nil, fmt.Errorf("error reading config file: %w", err)
-		}
-		// Config file not found; using environment variables
+	// Read the config files (optional - will use env vars if not found)
+	if err := readFiles(src); err != nil {
+		return nil, err
 	}
 
 	cfg := &Config{}
@@ -129,6 +620,13 @@ func Load() (*Config, error) {
 	// Server
 	cfg.Server.Port = viper.GetInt("server.port")
 	cfg.Server.Mode = viper.GetString("server.mode")
+	cfg.Server.ReusePort = viper.GetBool("server.reuse_port")
+	cfg.Server.HandoffSocket = viper.GetString("server.handoff_socket")
+	cfg.Server.BasePath = viper.GetString("server.base_path")
+	cfg.Server.WSPath = viper.GetString("server.ws_path")
+	cfg.Server.Admin.Mode = viper.GetString("server.admin.mode")
+	cfg.Server.Admin.Addr = viper.GetString("server.admin.addr")
+	cfg.Server.Admin.Auth = viper.GetString("server.admin.auth")
 
 	// Logger
 	cfg.Logger.Level = viper.GetString("logger.level")
@@ -139,8 +637,21 @@ func Load() (*Config, error) {
 	// Redis
 	cfg.Redis.Host = viper.GetString("redis.host")
 	cfg.Redis.Port = viper.GetInt("redis.port")
+	cfg.Redis.Username = viper.GetString("redis.username")
 	cfg.Redis.Password = viper.GetString("redis.password")
 	cfg.Redis.DB = viper.GetInt("redis.db")
+	cfg.Redis.Region = viper.GetString("redis.region")
+	cfg.Redis.ChannelPrefix = viper.GetString("redis.channel_prefix")
+	cfg.Redis.TLS.Enabled = viper.GetBool("redis.tls.enabled")
+	cfg.Redis.TLS.CAFile = viper.GetString("redis.tls.ca_file")
+	cfg.Redis.TLS.CertFile = viper.GetString("redis.tls.cert_file")
+	cfg.Redis.TLS.KeyFile = viper.GetString("redis.tls.key_file")
+	cfg.Redis.TLS.ServerName = viper.GetString("redis.tls.server_name")
+	cfg.Redis.TLS.InsecureSkipVerify = viper.GetBool("redis.tls.insecure_skip_verify")
+	cfg.Redis.SlowCommandThreshold = viper.GetDuration("redis.slow_command_threshold")
+	if err := viper.UnmarshalKey("redis.regions", &cfg.Redis.Regions); err != nil {
+		return nil, fmt.Errorf("invalid redis.regions: %w", err)
+	}
 
 	// WebSocket
 	cfg.WebSocket.PingInterval = viper.GetDuration("websocket.ping_interval")
@@ -150,9 +661,144 @@ func Load() (*Config, error) {
 	cfg.WebSocket.ReadBufferSize = viper.GetInt("websocket.read_buffer_size")
 	cfg.WebSocket.WriteBufferSize = viper.GetInt("websocket.write_buffer_size")
 	cfg.WebSocket.MaxConnections = viper.GetInt("websocket.max_connections")
+	cfg.WebSocket.Transport = viper.GetString("websocket.transport")
+	cfg.WebSocket.Heartbeat = viper.GetBool("websocket.heartbeat")
+	cfg.WebSocket.Hello = viper.GetBool("websocket.hello")
+	cfg.WebSocket.MaxProtocolViolations = viper.GetInt("websocket.max_protocol_violations")
+	cfg.WebSocket.EnableCompression = viper.GetBool("websocket.enable_compression")
+	cfg.WebSocket.CompressionUADenylist = viper.GetStringSlice("websocket.compression_ua_denylist")
+	cfg.WebSocket.ClientLabels = viper.GetStringSlice("websocket.client_labels")
+	if err := viper.UnmarshalKey("websocket.min_client_versions", &cfg.WebSocket.MinClientVersions); err != nil {
+		return nil, fmt.Errorf("invalid websocket.min_client_versions: %w", err)
+	}
+	cfg.WebSocket.UpgradeURL = viper.GetString("websocket.upgrade_url")
+	cfg.WebSocket.CloseOutdatedClients = viper.GetBool("websocket.close_outdated_clients")
+	cfg.WebSocket.AuthTimeout = viper.GetDuration("websocket.timeouts.auth")
+	cfg.WebSocket.UpgradeTimeout = viper.GetDuration("websocket.timeouts.upgrade")
+	cfg.WebSocket.RegisterTimeout = viper.GetDuration("websocket.timeouts.register")
+	cfg.WebSocket.ReplayBufferSize = viper.GetInt("websocket.replay_buffer_size")
+	cfg.WebSocket.ReplayMaxTopics = viper.GetInt("websocket.replay_max_topics")
+	cfg.WebSocket.StatsWindow = viper.GetDuration("websocket.stats_window")
+	cfg.WebSocket.StatsHistoryInterval = viper.GetDuration("websocket.stats_history.interval")
+	cfg.WebSocket.StatsHistoryRetention = viper.GetDuration("websocket.stats_history.retention")
+	cfg.WebSocket.TopicGCGrace = viper.GetDuration("websocket.topic_gc_grace")
+	cfg.WebSocket.DeltaSnapshotEvery = viper.GetInt("websocket.delta_snapshot_every")
+	cfg.WebSocket.CoalesceEnabled = viper.GetBool("websocket.coalesce.enabled")
+	cfg.WebSocket.CoalesceMinInterval = viper.GetDuration("websocket.coalesce.min_interval")
+	cfg.WebSocket.CoalesceMaxInterval = viper.GetDuration("websocket.coalesce.max_interval")
+	cfg.WebSocket.ChurnEnabled = viper.GetBool("websocket.churn.enabled")
+	cfg.WebSocket.ChurnWindow = viper.GetDuration("websocket.churn.window")
+	cfg.WebSocket.ChurnMaxUserConnects = viper.GetInt("websocket.churn.max_user_connects")
+	cfg.WebSocket.ChurnMaxIPUsers = viper.GetInt("websocket.churn.max_ip_users")
+	cfg.WebSocket.ChurnBanTTL = viper.GetDuration("websocket.churn.ban_ttl")
+	cfg.WebSocket.DecoyPatterns = viper.GetStringSlice("websocket.decoy.patterns")
+	cfg.WebSocket.DecoyBanTTL = viper.GetDuration("websocket.decoy.ban_ttl")
+	cfg.WebSocket.DecoyBanIP = viper.GetBool("websocket.decoy.ban_ip")
+	cfg.WebSocket.PublicEnabled = viper.GetBool("websocket.public.enabled")
+	if err := viper.UnmarshalKey("websocket.public.projects", &cfg.WebSocket.PublicProjects); err != nil {
+		return nil, fmt.Errorf("invalid websocket.public.projects: %w", err)
+	}
+	cfg.WebSocket.PublicMaxConnections = viper.GetInt("websocket.public.max_connections")
+	cfg.WebSocket.PublicMaxPerIP = viper.GetInt("websocket.public.max_per_ip")
+	cfg.WebSocket.PublicConnectRate = viper.GetFloat64("websocket.public.connect_rate")
+	cfg.WebSocket.PublicConnectBurst = viper.GetInt("websocket.public.connect_burst")
+	cfg.WebSocket.TLSFingerprintHeader = viper.GetString("websocket.fingerprint_headers.tls")
+	cfg.WebSocket.HTTP2FingerprintHeader = viper.GetString("websocket.fingerprint_headers.http2")
+	cfg.WebSocket.BackpressureWindow = viper.GetDuration("websocket.backpressure.window")
+	cfg.WebSocket.BackpressureMinDropRate = viper.GetFloat64("websocket.backpressure.min_drop_rate")
+	cfg.WebSocket.BackpressureMinFrames = viper.GetInt("websocket.backpressure.min_frames")
+	cfg.WebSocket.QuotaClaim = viper.GetString("websocket.quota.claim")
+	if err := viper.UnmarshalKey("websocket.quota.by_claim", &cfg.WebSocket.QuotaByClaim); err != nil {
+		return nil, fmt.Errorf("invalid websocket.quota.by_claim: %w", err)
+	}
+	cfg.WebSocket.QuotaDefaultPerUser = viper.GetInt("websocket.quota.default_per_user")
+	cfg.WebSocket.QuotaPolicy = viper.GetString("websocket.quota.policy")
+	cfg.WebSocket.SendQueueSize = viper.GetInt("websocket.send_queue.size")
+	cfg.WebSocket.SendQueuePolicy = viper.GetString("websocket.send_queue.policy")
+	if err := viper.UnmarshalKey("websocket.send_queue.class_sizes", &cfg.WebSocket.SendQueueClassSizes); err != nil {
+		return nil, fmt.Errorf("invalid websocket.send_queue.class_sizes: %w", err)
+	}
+
+	// Transform
+	cfg.Transform.Validation = viper.GetString("transform.validation")
+	cfg.Transform.ShadowVersion = viper.GetString("transform.shadow.version")
+	cfg.Transform.ShadowPercent = viper.GetInt("transform.shadow.percent")
+	cfg.Transform.TransitionsMode = viper.GetString("transform.transitions.mode")
+	cfg.Transform.TransitionsMaxTopics = viper.GetInt("transform.transitions.max_topics")
+	if err := viper.UnmarshalKey("transform.transitions.allowed", &cfg.Transform.TransitionsAllowed); err != nil {
+		return nil, fmt.Errorf("invalid transform.transitions.allowed: %w", err)
+	}
+	cfg.Transform.TerminalDedupeWindow = viper.GetDuration("transform.terminal_dedupe.window")
+	cfg.Transform.TerminalDedupeMaxTopics = viper.GetInt("transform.terminal_dedupe.max_topics")
+	cfg.Transform.SanitizeEnabled = viper.GetBool("transform.sanitize.enabled")
+	cfg.Transform.SecurityAlertEnabled = viper.GetBool("transform.message_types.security_alert")
+	cfg.Transform.BillingEventEnabled = viper.GetBool("transform.message_types.billing_event")
+	cfg.Transform.SystemMaintenanceEnabled = viper.GetBool("transform.message_types.system_maintenance")
+	if err := viper.UnmarshalKey("transform.sanitize.fields", &cfg.Transform.SanitizeFields); err != nil {
+		return nil, fmt.Errorf("invalid transform.sanitize.fields: %w", err)
+	}
+
+	// Backfill
+	cfg.Backfill.Enabled = viper.GetBool("backfill.enabled")
+	cfg.Backfill.StateKeyPattern = viper.GetString("backfill.state_key_pattern")
+	cfg.Backfill.Timeout = viper.GetDuration("backfill.timeout")
+
+	// Latest state
+	cfg.LatestState.Enabled = viper.GetBool("latest_state.enabled")
+	cfg.LatestState.KeyPattern = viper.GetString("latest_state.key_pattern")
+	cfg.LatestState.TTL = viper.GetDuration("latest_state.ttl")
+
+	// Presence
+	cfg.Presence.Enabled = viper.GetBool("presence.enabled")
+	cfg.Presence.KeyPattern = viper.GetString("presence.key_pattern")
+	cfg.Presence.TTL = viper.GetDuration("presence.ttl")
+
+	// Segments
+	cfg.Segments.PlanKeyPattern = viper.GetString("segments.plan_key_pattern")
+	cfg.Segments.OrgKeyPattern = viper.GetString("segments.org_key_pattern")
+
+	// Bans
+	cfg.Bans.KeyPattern = viper.GetString("bans.key_pattern")
+
+	// Delegation
+	cfg.Delegation.Enabled = viper.GetBool("delegation.enabled")
+	cfg.Delegation.KeyPattern = viper.GetString("delegation.key_pattern")
+
+	// Delivery
+	cfg.Delivery.Enabled = viper.GetBool("delivery.enabled")
+	if err := viper.UnmarshalKey("delivery.policies", &cfg.Delivery.Policies); err != nil {
+		return nil, fmt.Errorf("invalid delivery.policies: %w", err)
+	}
+	cfg.Delivery.AckTimeout = viper.GetDuration("delivery.ack_timeout")
+	cfg.Delivery.MaxRetries = viper.GetInt("delivery.max_retries")
+	cfg.Delivery.OfflineKeyPattern = viper.GetString("delivery.offline.key_pattern")
+	cfg.Delivery.OfflineMaxFrames = viper.GetInt("delivery.offline.max_frames")
+	cfg.Delivery.OfflineTTL = viper.GetDuration("delivery.offline.ttl")
+
+	// User Trace
+	cfg.UserTrace.MaxTTL = viper.GetDuration("user_trace.max_ttl")
+	cfg.UserTrace.MaxUsers = viper.GetInt("user_trace.max_users")
+
+	// GraphQL
+	cfg.GraphQL.Enabled = viper.GetBool("graphql.enabled")
+	cfg.GraphQL.InitTimeout = viper.GetDuration("graphql.init_timeout")
+
+	// WebTransport
+	cfg.WebTransport.Enabled = viper.GetBool("webtransport.enabled")
+	cfg.WebTransport.Port = viper.GetInt("webtransport.port")
+	cfg.WebTransport.CertFile = viper.GetString("webtransport.cert_file")
+	cfg.WebTransport.KeyFile = viper.GetString("webtransport.key_file")
+
+	// Telemetry
+	cfg.Telemetry.EmitTTL = viper.GetDuration("telemetry.emit_ttl")
+	cfg.Telemetry.MaxEmitRecords = viper.GetInt("telemetry.max_emit_records")
+	cfg.Telemetry.MaxReportSamples = viper.GetInt("telemetry.max_report_samples")
 
 	// JWT
 	cfg.JWT.SecretKey = viper.GetString("jwt.secret_key")
+	cfg.JWT.SubscriptionEnabled = viper.GetBool("jwt.subscription.enabled")
+	cfg.JWT.SubscriptionAudience = viper.GetString("jwt.subscription.audience")
+	cfg.JWT.SubscriptionMaxTTL = viper.GetDuration("jwt.subscription.max_ttl")
 
 	// Cookie
 	cfg.Cookie.Name = viper.GetString("cookie.name")
@@ -161,10 +807,116 @@ func Load() (*Config, error) {
 
 	// Internal auth
 	cfg.InternalConfig.InternalKey = viper.GetString("internal.internal_key")
+	cfg.InternalConfig.AllowStaticKey = viper.GetBool("internal.allow_static_key")
+	cfg.InternalConfig.MaxSkew = viper.GetDuration("internal.max_skew")
+	if err := viper.UnmarshalKey("internal.keys", &cfg.InternalConfig.Keys); err != nil {
+		return nil, fmt.Errorf("invalid internal.keys: %w", err)
+	}
+	cfg.InternalConfig.MTLS.Enabled = viper.GetBool("internal.mtls.enabled")
+	cfg.InternalConfig.MTLS.Port = viper.GetInt("internal.mtls.port")
+	cfg.InternalConfig.MTLS.CertFile = viper.GetString("internal.mtls.cert_file")
+	cfg.InternalConfig.MTLS.KeyFile = viper.GetString("internal.mtls.key_file")
+	cfg.InternalConfig.MTLS.ClientCAFile = viper.GetString("internal.mtls.client_ca_file")
+	cfg.InternalConfig.MTLS.AllowedSPIFFEIDs = viper.GetStringSlice("internal.mtls.allowed_spiffe_ids")
 
 	// Discord
 	cfg.Discord.WebhookURL = viper.GetString("discord.webhook_url")
 
+	// Supervisor
+	cfg.Supervisor.MaxRestartsPerMinute = viper.GetInt("supervisor.max_restarts_per_minute")
+	cfg.Supervisor.RestartBackoff = viper.GetDuration("supervisor.restart_backoff")
+
+	// Watchdog
+	cfg.Watchdog.Enabled = viper.GetBool("watchdog.enabled")
+	cfg.Watchdog.StallWindow = viper.GetDuration("watchdog.stall_window")
+	cfg.Watchdog.CheckInterval = viper.GetDuration("watchdog.check_interval")
+	cfg.Watchdog.HeartbeatChannel = viper.GetString("watchdog.heartbeat_channel")
+
+	// Probe
+	cfg.Probe.Enabled = viper.GetBool("probe.enabled")
+	cfg.Probe.Interval = viper.GetDuration("probe.interval")
+	cfg.Probe.InstanceID = viper.GetString("probe.instance_id")
+
+	// Canary
+	cfg.Canary.Enabled = viper.GetBool("canary.enabled")
+	cfg.Canary.UserID = viper.GetString("canary.user_id")
+	cfg.Canary.ProjectID = viper.GetString("canary.project_id")
+	cfg.Canary.Interval = viper.GetDuration("canary.interval")
+	cfg.Canary.Timeout = viper.GetDuration("canary.timeout")
+	cfg.Canary.LatencyThreshold = viper.GetDuration("canary.latency_threshold")
+	cfg.Canary.FailuresToAlert = viper.GetInt("canary.failures_to_alert")
+
+	// Breaker
+	cfg.Breaker.Enabled = viper.GetBool("breaker.enabled")
+	cfg.Breaker.FailureRatio = viper.GetFloat64("breaker.failure_ratio")
+	cfg.Breaker.MinRequests = viper.GetInt("breaker.min_requests")
+	cfg.Breaker.Interval = viper.GetDuration("breaker.interval")
+	cfg.Breaker.OpenTimeout = viper.GetDuration("breaker.open_timeout")
+	cfg.Breaker.HalfOpenProbes = viper.GetInt("breaker.half_open_probes")
+	if cfg.Probe.InstanceID == "" {
+		cfg.Probe.InstanceID, _ = os.Hostname()
+	}
+
+	// Inbound Rate Limit
+	cfg.RateLimit.Rate = viper.GetFloat64("rate_limit.rate")
+	cfg.RateLimit.Burst = viper.GetInt("rate_limit.burst")
+	if err := viper.UnmarshalKey("rate_limit.prefixes", &cfg.RateLimit.Prefixes); err != nil {
+		return nil, fmt.Errorf("invalid rate_limit.prefixes: %w", err)
+	}
+	cfg.RateLimit.Mode = viper.GetString("rate_limit.mode")
+	cfg.RateLimit.MaxChannels = viper.GetInt("rate_limit.max_channels")
+	cfg.RateLimit.DLQKey = viper.GetString("rate_limit.dlq.key")
+	cfg.RateLimit.DLQSampleRate = viper.GetFloat64("rate_limit.dlq.sample_rate")
+	cfg.RateLimit.DLQMaxLen = viper.GetInt("rate_limit.dlq.max_len")
+
+	// Startup
+	cfg.Startup.MaxWait = viper.GetDuration("startup.max_wait")
+	cfg.Startup.InitialBackoff = viper.GetDuration("startup.initial_backoff")
+	cfg.Startup.MaxBackoff = viper.GetDuration("startup.max_backoff")
+
+	// Leader
+	cfg.Leader.KeyPrefix = viper.GetString("leader.key_prefix")
+	cfg.Leader.Identity = viper.GetString("leader.identity")
+	cfg.Leader.TTL = viper.GetDuration("leader.ttl")
+	cfg.Leader.RenewInterval = viper.GetDuration("leader.renew_interval")
+	if cfg.Leader.Identity == "" {
+		cfg.Leader.Identity, _ = os.Hostname()
+	}
+
+	// Sinks
+	cfg.Sinks.Kafka.Enabled = viper.GetBool("sinks.kafka.enabled")
+	cfg.Sinks.Kafka.Brokers = viper.GetStringSlice("sinks.kafka.brokers")
+	cfg.Sinks.Kafka.Topic = viper.GetString("sinks.kafka.topic")
+	cfg.Sinks.Kafka.MessageTypes = viper.GetStringSlice("sinks.kafka.message_types")
+	cfg.Sinks.Kafka.BatchSize = viper.GetInt("sinks.kafka.batch_size")
+	cfg.Sinks.Kafka.FlushInterval = viper.GetDuration("sinks.kafka.flush_interval")
+	cfg.Sinks.Kafka.QueueSize = viper.GetInt("sinks.kafka.queue_size")
+	cfg.Sinks.Kafka.Timeout = viper.GetDuration("sinks.kafka.timeout")
+	cfg.Sinks.Kafka.MaxRetries = viper.GetInt("sinks.kafka.max_retries")
+	cfg.Sinks.ClickHouse.Enabled = viper.GetBool("sinks.clickhouse.enabled")
+	cfg.Sinks.ClickHouse.URL = viper.GetString("sinks.clickhouse.url")
+	cfg.Sinks.ClickHouse.Database = viper.GetString("sinks.clickhouse.database")
+	cfg.Sinks.ClickHouse.Table = viper.GetString("sinks.clickhouse.table")
+	cfg.Sinks.ClickHouse.Username = viper.GetString("sinks.clickhouse.username")
+	cfg.Sinks.ClickHouse.Password = viper.GetString("sinks.clickhouse.password")
+	cfg.Sinks.ClickHouse.BatchSize = viper.GetInt("sinks.clickhouse.batch_size")
+	cfg.Sinks.ClickHouse.FlushInterval = viper.GetDuration("sinks.clickhouse.flush_interval")
+	cfg.Sinks.ClickHouse.QueueSize = viper.GetInt("sinks.clickhouse.queue_size")
+	cfg.Sinks.ClickHouse.Timeout = viper.GetDuration("sinks.clickhouse.timeout")
+	cfg.Sinks.ClickHouse.MaxRetries = viper.GetInt("sinks.clickhouse.max_retries")
+
+	// MQTT
+	cfg.MQTT.Enabled = viper.GetBool("mqtt.enabled")
+	cfg.MQTT.BrokerURL = viper.GetString("mqtt.broker_url")
+	cfg.MQTT.ClientID = viper.GetString("mqtt.client_id")
+	cfg.MQTT.Username = viper.GetString("mqtt.username")
+	cfg.MQTT.Password = viper.GetString("mqtt.password")
+	cfg.MQTT.TopicPattern = viper.GetString("mqtt.topic_pattern")
+	cfg.MQTT.QoS = viper.GetInt("mqtt.qos")
+	cfg.MQTT.Retain = viper.GetBool("mqtt.retain")
+	cfg.MQTT.QueueSize = viper.GetInt("mqtt.queue_size")
+	cfg.MQTT.Timeout = viper.GetDuration("mqtt.timeout")
+
 	// Validate required fields
 	if err := validate(cfg); err != nil {
 		return nil, err
@@ -180,6 +932,13 @@ func setDefaults() {
 	// Server
 	viper.SetDefault("server.port", 8081)
 	viper.SetDefault("server.mode", "release")
+	viper.SetDefault("server.reuse_port", false)
+	viper.SetDefault("server.handoff_socket", "")
+	viper.SetDefault("server.base_path", "")
+	viper.SetDefault("server.ws_path", "/ws")
+	viper.SetDefault("server.admin.mode", "public")
+	viper.SetDefault("server.admin.addr", "")
+	viper.SetDefault("server.admin.auth", "jwt")
 
 	// Logger
 	viper.SetDefault("logger.level", "info")
@@ -190,8 +949,18 @@ func setDefaults() {
 	// Redis
 	viper.SetDefault("redis.host", "localhost")
 	viper.SetDefault("redis.port", 6379)
+	viper.SetDefault("redis.username", "")
 	viper.SetDefault("redis.password", "")
 	viper.SetDefault("redis.db", 0)
+	viper.SetDefault("redis.region", "local")
+	viper.SetDefault("redis.channel_prefix", "")
+	viper.SetDefault("redis.tls.enabled", false)
+	viper.SetDefault("redis.tls.ca_file", "")
+	viper.SetDefault("redis.tls.cert_file", "")
+	viper.SetDefault("redis.tls.key_file", "")
+	viper.SetDefault("redis.tls.server_name", "")
+	viper.SetDefault("redis.tls.insecure_skip_verify", false)
+	viper.SetDefault("redis.slow_command_threshold", 100*time.Millisecond)
 
 	// WebSocket
 	viper.SetDefault("websocket.ping_interval", 30*time.Second)
@@ -201,6 +970,128 @@ func setDefaults() {
 	viper.SetDefault("websocket.read_buffer_size", 1024)
 	viper.SetDefault("websocket.write_buffer_size", 1024)
 	viper.SetDefault("websocket.max_connections", 10000)
+	viper.SetDefault("websocket.transport", "gorilla")
+	viper.SetDefault("websocket.heartbeat", false)
+	viper.SetDefault("websocket.hello", false)
+	viper.SetDefault("websocket.max_protocol_violations", 5)
+	viper.SetDefault("websocket.enable_compression", false)
+	viper.SetDefault("websocket.compression_ua_denylist", []string{})
+	viper.SetDefault("websocket.client_labels", []string{})
+	viper.SetDefault("websocket.min_client_versions", map[string]string{})
+	viper.SetDefault("websocket.upgrade_url", "")
+	viper.SetDefault("websocket.close_outdated_clients", false)
+	viper.SetDefault("websocket.timeouts.auth", 3*time.Second)
+	viper.SetDefault("websocket.timeouts.upgrade", 10*time.Second)
+	viper.SetDefault("websocket.timeouts.register", 5*time.Second)
+	viper.SetDefault("websocket.replay_buffer_size", 100)
+	viper.SetDefault("websocket.replay_max_topics", 50000)
+	viper.SetDefault("websocket.stats_window", 15*time.Minute)
+	viper.SetDefault("websocket.stats_history.interval", 10*time.Second)
+	viper.SetDefault("websocket.stats_history.retention", 24*time.Hour)
+	viper.SetDefault("websocket.topic_gc_grace", 10*time.Minute)
+	viper.SetDefault("websocket.delta_snapshot_every", 20)
+	viper.SetDefault("websocket.coalesce.enabled", false)
+	viper.SetDefault("websocket.coalesce.min_interval", 250*time.Millisecond)
+	viper.SetDefault("websocket.coalesce.max_interval", 2*time.Second)
+	viper.SetDefault("websocket.churn.enabled", false)
+	viper.SetDefault("websocket.churn.window", time.Minute)
+	viper.SetDefault("websocket.churn.max_user_connects", 100)
+	viper.SetDefault("websocket.churn.max_ip_users", 20)
+	viper.SetDefault("websocket.churn.ban_ttl", 0)
+	viper.SetDefault("websocket.decoy.patterns", []string{})
+	viper.SetDefault("websocket.decoy.ban_ttl", 0)
+	viper.SetDefault("websocket.decoy.ban_ip", false)
+	viper.SetDefault("websocket.public.enabled", false)
+	viper.SetDefault("websocket.public.max_connections", 1000)
+	viper.SetDefault("websocket.public.max_per_ip", 3)
+	viper.SetDefault("websocket.public.connect_rate", 0.1)
+	viper.SetDefault("websocket.public.connect_burst", 5)
+	viper.SetDefault("websocket.fingerprint_headers.tls", "")
+	viper.SetDefault("websocket.fingerprint_headers.http2", "")
+	viper.SetDefault("websocket.backpressure.window", 0)
+	viper.SetDefault("websocket.backpressure.min_drop_rate", 0.2)
+	viper.SetDefault("websocket.backpressure.min_frames", 100)
+	viper.SetDefault("websocket.quota.claim", "plan")
+	viper.SetDefault("websocket.quota.by_claim", map[string]int{})
+	viper.SetDefault("websocket.quota.default_per_user", 0)
+	viper.SetDefault("websocket.quota.policy", "reject")
+	viper.SetDefault("websocket.send_queue.size", 256)
+	viper.SetDefault("websocket.send_queue.policy", "drop_newest")
+	viper.SetDefault("websocket.send_queue.class_sizes", map[string]int{})
+
+	// Transform
+	viper.SetDefault("transform.validation", "lenient")
+	viper.SetDefault("transform.shadow.version", "")
+	viper.SetDefault("transform.shadow.percent", 0)
+	viper.SetDefault("transform.transitions.mode", "off")
+	viper.SetDefault("transform.transitions.max_topics", 100000)
+	viper.SetDefault("transform.transitions.allowed", map[string]map[string][]string{})
+	viper.SetDefault("transform.terminal_dedupe.window", 5*time.Minute)
+	viper.SetDefault("transform.terminal_dedupe.max_topics", 100000)
+	viper.SetDefault("transform.sanitize.enabled", true)
+	viper.SetDefault("transform.sanitize.fields", map[string]any{})
+	viper.SetDefault("transform.message_types.security_alert", true)
+	viper.SetDefault("transform.message_types.billing_event", true)
+	viper.SetDefault("transform.message_types.system_maintenance", true)
+
+	// Backfill
+	viper.SetDefault("backfill.enabled", false)
+	viper.SetDefault("backfill.state_key_pattern", "project_state:{project_id}")
+	viper.SetDefault("backfill.timeout", 500*time.Millisecond)
+
+	// Latest state
+	viper.SetDefault("latest_state.enabled", false)
+	viper.SetDefault("latest_state.key_pattern", "state:project:{project_id}:{user_id}")
+	viper.SetDefault("latest_state.ttl", 24*time.Hour)
+
+	// Presence
+	viper.SetDefault("presence.enabled", false)
+	viper.SetDefault("presence.key_pattern", "presence:{user_id}")
+	viper.SetDefault("presence.ttl", 90*time.Second)
+
+	// Segments
+	viper.SetDefault("segments.plan_key_pattern", "segment:plan:{plan}")
+	viper.SetDefault("segments.org_key_pattern", "segment:org:{org_id}")
+
+	// Bans
+	viper.SetDefault("bans.key_pattern", "ban:{kind}:{value}")
+
+	// Delegation
+	viper.SetDefault("delegation.enabled", false)
+	viper.SetDefault("delegation.key_pattern", "delegates:{user_id}")
+
+	// Delivery
+	viper.SetDefault("delivery.enabled", false)
+	viper.SetDefault("delivery.policies", map[string]string{})
+	viper.SetDefault("delivery.ack_timeout", 10*time.Second)
+	viper.SetDefault("delivery.max_retries", 3)
+	viper.SetDefault("delivery.offline.key_pattern", "offline:{user_id}")
+	viper.SetDefault("delivery.offline.max_frames", 100)
+	viper.SetDefault("delivery.offline.ttl", 72*time.Hour)
+
+	// User Trace
+	viper.SetDefault("user_trace.max_ttl", time.Hour)
+	viper.SetDefault("user_trace.max_users", 10)
+
+	// GraphQL
+	viper.SetDefault("graphql.enabled", false)
+	viper.SetDefault("graphql.init_timeout", 10*time.Second)
+
+	// WebTransport
+	viper.SetDefault("webtransport.enabled", false)
+	viper.SetDefault("webtransport.port", 4433)
+	viper.SetDefault("webtransport.cert_file", "")
+	viper.SetDefault("webtransport.key_file", "")
+
+	// Telemetry
+	viper.SetDefault("telemetry.emit_ttl", 2*time.Minute)
+	viper.SetDefault("telemetry.max_emit_records", 100000)
+	viper.SetDefault("telemetry.max_report_samples", 500)
+
+	// JWT
+	viper.SetDefault("jwt.subscription.enabled", false)
+	viper.SetDefault("jwt.subscription.audience", "notification-subscription")
+	viper.SetDefault("jwt.subscription.max_ttl", time.Hour)
 
 	// Cookie
 	viper.SetDefault("cookie.name", "smap_auth_token")
@@ -209,41 +1100,704 @@ func setDefaults() {
 
 	// Internal auth
 	viper.SetDefault("internal.internal_key", "")
+	viper.SetDefault("internal.allow_static_key", true)
+	viper.SetDefault("internal.max_skew", 5*time.Minute)
+	viper.SetDefault("internal.mtls.enabled", false)
+	viper.SetDefault("internal.mtls.port", 8443)
+	viper.SetDefault("internal.mtls.cert_file", "")
+	viper.SetDefault("internal.mtls.key_file", "")
+	viper.SetDefault("internal.mtls.client_ca_file", "")
+	viper.SetDefault("internal.mtls.allowed_spiffe_ids", []string{})
 
 	// Discord (optional)
 	viper.SetDefault("discord.webhook_url", "")
+
+	// Supervisor
+	viper.SetDefault("supervisor.max_restarts_per_minute", 5)
+	viper.SetDefault("supervisor.restart_backoff", time.Second)
+
+	// Watchdog
+	viper.SetDefault("watchdog.enabled", false)
+	viper.SetDefault("watchdog.stall_window", 10*time.Minute)
+	viper.SetDefault("watchdog.check_interval", 30*time.Second)
+	viper.SetDefault("watchdog.heartbeat_channel", "")
+
+	// Breaker
+	viper.SetDefault("breaker.enabled", true)
+	viper.SetDefault("breaker.failure_ratio", 0.5)
+	viper.SetDefault("breaker.min_requests", 5)
+	viper.SetDefault("breaker.interval", time.Minute)
+	viper.SetDefault("breaker.open_timeout", 30*time.Second)
+	viper.SetDefault("breaker.half_open_probes", 1)
+
+	// Startup
+	viper.SetDefault("startup.max_wait", 2*time.Minute)
+	viper.SetDefault("startup.initial_backoff", 500*time.Millisecond)
+	viper.SetDefault("startup.max_backoff", 10*time.Second)
+
+	// Leader
+	viper.SetDefault("leader.key_prefix", "notification:leader:")
+	viper.SetDefault("leader.identity", "")
+	viper.SetDefault("leader.ttl", 15*time.Second)
+	viper.SetDefault("leader.renew_interval", 5*time.Second)
+
+	// Sinks
+	viper.SetDefault("sinks.kafka.enabled", false)
+	viper.SetDefault("sinks.kafka.brokers", []string{})
+	viper.SetDefault("sinks.kafka.topic", "notifications.delivered")
+	viper.SetDefault("sinks.kafka.message_types", []string{})
+	viper.SetDefault("sinks.kafka.batch_size", 100)
+	viper.SetDefault("sinks.kafka.flush_interval", time.Second)
+	viper.SetDefault("sinks.kafka.queue_size", 10000)
+	viper.SetDefault("sinks.kafka.timeout", 5*time.Second)
+	viper.SetDefault("sinks.kafka.max_retries", 3)
+	viper.SetDefault("sinks.clickhouse.enabled", false)
+	viper.SetDefault("sinks.clickhouse.url", "")
+	viper.SetDefault("sinks.clickhouse.database", "notification")
+	viper.SetDefault("sinks.clickhouse.table", "delivery_events")
+	viper.SetDefault("sinks.clickhouse.username", "")
+	viper.SetDefault("sinks.clickhouse.password", "")
+	viper.SetDefault("sinks.clickhouse.batch_size", 1000)
+	viper.SetDefault("sinks.clickhouse.flush_interval", 5*time.Second)
+	viper.SetDefault("sinks.clickhouse.queue_size", 50000)
+	viper.SetDefault("sinks.clickhouse.timeout", 10*time.Second)
+	viper.SetDefault("sinks.clickhouse.max_retries", 3)
+
+	// MQTT
+	viper.SetDefault("mqtt.enabled", false)
+	viper.SetDefault("mqtt.broker_url", "")
+	viper.SetDefault("mqtt.client_id", "notification-srv")
+	viper.SetDefault("mqtt.username", "")
+	viper.SetDefault("mqtt.password", "")
+	viper.SetDefault("mqtt.topic_pattern", "smap/project/{project_id}/status")
+	viper.SetDefault("mqtt.qos", 1)
+	viper.SetDefault("mqtt.retain", true)
+	viper.SetDefault("mqtt.queue_size", 1000)
+	viper.SetDefault("mqtt.timeout", 5*time.Second)
+
+	// Probe
+	viper.SetDefault("probe.enabled", true)
+	viper.SetDefault("probe.interval", 10*time.Second)
+	viper.SetDefault("probe.instance_id", "")
+
+	// Canary
+	viper.SetDefault("canary.enabled", false)
+	viper.SetDefault("canary.user_id", "notification-canary")
+	viper.SetDefault("canary.project_id", "notification-canary")
+	viper.SetDefault("canary.interval", 10*time.Second)
+	viper.SetDefault("canary.timeout", 5*time.Second)
+	viper.SetDefault("canary.latency_threshold", time.Second)
+	viper.SetDefault("canary.failures_to_alert", 3)
+
+	// Inbound Rate Limit
+	viper.SetDefault("rate_limit.rate", 0)
+	viper.SetDefault("rate_limit.burst", 50)
+	viper.SetDefault("rate_limit.prefixes", map[string]any{})
+	viper.SetDefault("rate_limit.mode", "drop")
+	viper.SetDefault("rate_limit.max_channels", 100000)
+	viper.SetDefault("rate_limit.dlq.key", "")
+	viper.SetDefault("rate_limit.dlq.sample_rate", 0.01)
+	viper.SetDefault("rate_limit.dlq.max_len", 1000)
 }
 
+// versionPattern matches the client versions accepted in websocket.min_client_versions.
+var versionPattern = regexp.MustCompile(`^v?\d+(\.\d+){0,2}([-+].*)?$`)
+
+// maxStatsHistorySamples bounds the memory of the stats history.
+const maxStatsHistorySamples = 100000
+
+// validate checks the whole configuration and reports every problem found,
+// not only the first.
 func validate(cfg *Config) error {
+	var problems []string
+	fail := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
 	// Validate JWT
 	if cfg.JWT.SecretKey == "" {
-		return fmt.Errorf("jwt.secret_key is required")
+		fail("jwt.secret_key is required")
+	} else if len(cfg.JWT.SecretKey) < 32 {
+		fail("jwt.secret_key must be at least 32 characters for security")
 	}
-	if len(cfg.JWT.SecretKey) < 32 {
-		return fmt.Errorf("jwt.secret_key must be at least 32 characters for security")
+	if cfg.JWT.SubscriptionAudience == "" {
+		fail("jwt.subscription.audience is required")
+	}
+	if cfg.JWT.SubscriptionEnabled && cfg.JWT.SubscriptionMaxTTL <= 0 {
+		fail("jwt.subscription.max_ttl must be positive")
 	}
 
 	// Validate Server
 	if cfg.Server.Port <= 0 || cfg.Server.Port > 65535 {
-		return fmt.Errorf("server.port is invalid")
+		fail("server.port is invalid")
+	}
+	if cfg.Server.HandoffSocket != "" && !cfg.Server.ReusePort {
+		fail("server.handoff_socket requires server.reuse_port")
+	}
+	if p := cfg.Server.BasePath; p != "" && (!strings.HasPrefix(p, "/") || strings.HasSuffix(p, "/")) {
+		fail("server.base_path must start and not end with /, e.g. /notifications")
+	}
+	if p := cfg.Server.WSPath; !strings.HasPrefix(p, "/") || len(p) < 2 {
+		fail("server.ws_path must start with / and name a route, e.g. /ws")
+	}
+	switch cfg.Server.Admin.Mode {
+	case "public", "disabled":
+	case "separate":
+		if err := validateAdminAddr(cfg.Server.Admin.Addr, cfg.Server.Port); err != nil {
+			fail("%v", err)
+		}
+	default:
+		fail("server.admin.mode must be one of public, separate, disabled")
+	}
+	switch cfg.Server.Admin.Auth {
+	case "jwt", "none":
+	default:
+		fail("server.admin.auth must be jwt or none")
 	}
 
 	// Validate Redis
 	if cfg.Redis.Host == "" {
-		return fmt.Errorf("redis.host is required")
+		fail("redis.host is required")
 	}
 	if cfg.Redis.Port == 0 {
-		return fmt.Errorf("redis.port is required")
+		fail("redis.port is required")
+	}
+	if (cfg.Redis.TLS.CertFile == "") != (cfg.Redis.TLS.KeyFile == "") {
+		fail("redis.tls.cert_file and redis.tls.key_file must be set together")
+	}
+	regions := map[string]bool{cfg.Redis.Region: true}
+	for i, r := range cfg.Redis.Regions {
+		if r.Name == "" || r.Host == "" || r.Port == 0 {
+			fail("redis.regions[%d]: name, host and port are required", i)
+		}
+		if (r.TLS.CertFile == "") != (r.TLS.KeyFile == "") {
+			fail("redis.regions[%d]: tls.cert_file and tls.key_file must be set together", i)
+		}
+		if regions[r.Name] {
+			fail("redis.regions[%d]: duplicate region %q", i, r.Name)
+		}
+		regions[r.Name] = true
+	}
+	if cfg.Redis.SlowCommandThreshold < 0 {
+		fail("redis.slow_command_threshold must not be negative")
+	}
+
+	// Validate WebSocket connections
+	if ws := cfg.WebSocket; ws.PongWait <= 0 || ws.PingInterval <= 0 || ws.PingInterval >= ws.PongWait {
+		fail("websocket.ping_interval and websocket.pong_wait must be positive, with ping_interval < pong_wait")
+	}
+	if cfg.WebSocket.WriteWait <= 0 {
+		fail("websocket.write_wait must be positive")
+	}
+	if cfg.WebSocket.MaxMessageSize <= 0 || cfg.WebSocket.ReadBufferSize <= 0 || cfg.WebSocket.WriteBufferSize <= 0 {
+		fail("websocket.max_message_size, read_buffer_size and write_buffer_size must be positive")
+	}
+	if cfg.WebSocket.MaxConnections <= 0 {
+		fail("websocket.max_connections must be positive")
+	}
+
+	// Validate WebSocket replay buffer
+	if cfg.WebSocket.ReplayBufferSize < 0 {
+		fail("websocket.replay_buffer_size must not be negative")
+	}
+	if cfg.WebSocket.ReplayMaxTopics < 0 {
+		fail("websocket.replay_max_topics must not be negative")
+	}
+	if cfg.WebSocket.StatsWindow < time.Minute {
+		fail("websocket.stats_window must be at least 1m")
+	}
+	if i, r := cfg.WebSocket.StatsHistoryInterval, cfg.WebSocket.StatsHistoryRetention; i < 0 {
+		fail("websocket.stats_history.interval must not be negative")
+	} else if i > 0 && (i < time.Second || r < i || r/i > maxStatsHistorySamples) {
+		fail("websocket.stats_history.interval must be at least 1s and retention between one and %d intervals", maxStatsHistorySamples)
+	}
+	if cfg.WebSocket.TopicGCGrace < 0 {
+		fail("websocket.topic_gc_grace must not be negative")
+	}
+	if cfg.WebSocket.DeltaSnapshotEvery < 1 {
+		fail("websocket.delta_snapshot_every must be at least 1")
+	}
+	if cfg.WebSocket.CoalesceEnabled {
+		if cfg.WebSocket.CoalesceMinInterval <= 0 {
+			fail("websocket.coalesce.min_interval must be positive")
+		}
+		if cfg.WebSocket.CoalesceMaxInterval < cfg.WebSocket.CoalesceMinInterval {
+			fail("websocket.coalesce.max_interval must not be less than min_interval")
+		}
+	}
+	if cfg.WebSocket.ChurnEnabled {
+		if cfg.WebSocket.ChurnWindow <= 0 {
+			fail("websocket.churn.window must be positive")
+		}
+		if cfg.WebSocket.ChurnMaxUserConnects < 1 || cfg.WebSocket.ChurnMaxIPUsers < 1 {
+			fail("websocket.churn.max_user_connects and max_ip_users must be at least 1")
+		}
+		if cfg.WebSocket.ChurnBanTTL < 0 {
+			fail("websocket.churn.ban_ttl must not be negative")
+		}
+	}
+	for _, pattern := range cfg.WebSocket.DecoyPatterns {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" || pattern == "*" {
+			fail("websocket.decoy.patterns: invalid pattern %q", pattern)
+		}
+	}
+	if cfg.WebSocket.DecoyBanTTL < 0 {
+		fail("websocket.decoy.ban_ttl must not be negative")
+	}
+	if cfg.WebSocket.PublicEnabled {
+		publicProjects := make(map[string]bool)
+		for i, p := range cfg.WebSocket.PublicProjects {
+			if p.ProjectID == "" || p.OwnerUserID == "" {
+				fail("websocket.public.projects[%d]: project_id and owner_user_id are required", i)
+			}
+			if publicProjects[p.ProjectID] {
+				fail("websocket.public.projects[%d]: duplicate project %q", i, p.ProjectID)
+			}
+			publicProjects[p.ProjectID] = true
+		}
+		if cfg.WebSocket.PublicMaxConnections < 0 {
+			fail("websocket.public.max_connections must not be negative")
+		}
+		if cfg.WebSocket.PublicMaxPerIP < 0 {
+			fail("websocket.public.max_per_ip must not be negative")
+		}
+		if cfg.WebSocket.PublicConnectRate <= 0 {
+			fail("websocket.public.connect_rate must be positive")
+		}
+		if cfg.WebSocket.PublicConnectBurst < 1 {
+			fail("websocket.public.connect_burst must be at least 1")
+		}
+	}
+	if cfg.WebSocket.BackpressureWindow < 0 {
+		fail("websocket.backpressure.window must not be negative")
+	}
+	if cfg.WebSocket.BackpressureWindow > 0 {
+		if cfg.WebSocket.BackpressureMinDropRate <= 0 || cfg.WebSocket.BackpressureMinDropRate > 1 {
+			fail("websocket.backpressure.min_drop_rate must be above 0 and at most 1")
+		}
+		if cfg.WebSocket.BackpressureMinFrames < 1 {
+			fail("websocket.backpressure.min_frames must be at least 1")
+		}
+	}
+	for label, v := range cfg.WebSocket.MinClientVersions {
+		if !versionPattern.MatchString(v) {
+			fail("websocket.min_client_versions[%s]: %q is not a version like 1.2.3", label, v)
+		}
+	}
+	if cfg.WebSocket.AuthTimeout < 0 || cfg.WebSocket.UpgradeTimeout < 0 || cfg.WebSocket.RegisterTimeout < 0 {
+		fail("websocket.timeouts must not be negative")
+	}
+	switch cfg.WebSocket.QuotaPolicy {
+	case "reject", "kick_oldest":
+	default:
+		fail("websocket.quota.policy must be reject or kick_oldest")
+	}
+	if cfg.WebSocket.QuotaDefaultPerUser < 0 {
+		fail("websocket.quota.default_per_user must not be negative")
+	}
+	for value, limit := range cfg.WebSocket.QuotaByClaim {
+		if limit < 0 {
+			fail("websocket.quota.by_claim[%s] must not be negative", value)
+		}
+	}
+	if cfg.WebSocket.SendQueueSize < 1 {
+		fail("websocket.send_queue.size must be at least 1")
+	}
+	switch cfg.WebSocket.Transport {
+	case "gorilla", "coder":
+	case "netpoll":
+		if cfg.WebSocket.EnableCompression {
+			fail("websocket.enable_compression is not supported with websocket.transport netpoll")
+		}
+	default:
+		fail("websocket.transport must be gorilla, coder or netpoll")
+	}
+	switch cfg.WebSocket.SendQueuePolicy {
+	case "drop_newest", "drop_oldest":
+	default:
+		fail("websocket.send_queue.policy must be drop_newest or drop_oldest")
+	}
+	for label, size := range cfg.WebSocket.SendQueueClassSizes {
+		if size < 1 {
+			fail("websocket.send_queue.class_sizes[%s] must be at least 1", label)
+		}
+	}
+
+	// Validate Transform
+	switch cfg.Transform.Validation {
+	case "strict", "lenient", "log-only":
+	default:
+		fail("transform.validation must be one of strict, lenient, log-only")
+	}
+	if cfg.Transform.ShadowPercent < 0 || cfg.Transform.ShadowPercent > 100 {
+		fail("transform.shadow.percent must be between 0 and 100")
+	}
+	switch cfg.Transform.TransitionsMode {
+	case "off", "flag", "suppress":
+	default:
+		fail("transform.transitions.mode must be one of off, flag, suppress")
+	}
+	if cfg.Transform.TransitionsMode != "off" && cfg.Transform.TransitionsMaxTopics < 1 {
+		fail("transform.transitions.max_topics must be at least 1")
+	}
+	for msgType := range cfg.Transform.TransitionsAllowed {
+		switch msgType {
+		case "data_onboarding", "campaign_event":
+		default:
+			fail("transform.transitions.allowed[%s]: only data_onboarding and campaign_event have a status", msgType)
+		}
+	}
+	if cfg.Transform.TerminalDedupeWindow < 0 {
+		fail("transform.terminal_dedupe.window must not be negative")
+	}
+	if cfg.Transform.TerminalDedupeWindow > 0 && cfg.Transform.TerminalDedupeMaxTopics < 1 {
+		fail("transform.terminal_dedupe.max_topics must be at least 1")
+	}
+	for field, rule := range cfg.Transform.SanitizeFields {
+		switch field {
+		case "source_name", "message", "current_phase", "project_name", "affected_aspects",
+			"sample_mentions", "time_window", "action_required", "campaign_name", "resource_name",
+			"location", "user_agent", "title":
+		default:
+			fail("transform.sanitize.fields[%s]: not a free-text payload field", field)
+		}
+		if rule.MaxLength < 0 {
+			fail("transform.sanitize.fields[%s].max_length must not be negative", field)
+		}
+	}
+
+	// Validate Backfill
+	if cfg.Backfill.Enabled && (!strings.Contains(cfg.Backfill.StateKeyPattern, "{project_id}") || cfg.Backfill.Timeout <= 0) {
+		fail("backfill.state_key_pattern must contain {project_id} and backfill.timeout must be positive")
+	}
+
+	// Validate Latest State
+	if cfg.LatestState.Enabled {
+		if !strings.Contains(cfg.LatestState.KeyPattern, "{project_id}") || !strings.Contains(cfg.LatestState.KeyPattern, "{user_id}") {
+			fail("latest_state.key_pattern must contain {project_id} and {user_id}")
+		}
+		if cfg.LatestState.TTL <= 0 {
+			fail("latest_state.ttl must be positive")
+		}
+	}
+
+	// Validate Presence
+	if cfg.Presence.Enabled {
+		if !strings.Contains(cfg.Presence.KeyPattern, "{user_id}") {
+			fail("presence.key_pattern must contain {user_id}")
+		}
+		if cfg.Presence.TTL < time.Minute {
+			fail("presence.ttl must be at least 1m (the key is refreshed on each pong, every 54s)")
+		}
+	}
+
+	// Validate Segments
+	if !strings.Contains(cfg.Segments.PlanKeyPattern, "{plan}") {
+		fail("segments.plan_key_pattern must contain {plan}")
+	}
+	if !strings.Contains(cfg.Segments.OrgKeyPattern, "{org_id}") {
+		fail("segments.org_key_pattern must contain {org_id}")
+	}
+
+	// Validate Bans
+	if !strings.Contains(cfg.Bans.KeyPattern, "{kind}") || !strings.Contains(cfg.Bans.KeyPattern, "{value}") {
+		fail("bans.key_pattern must contain {kind} and {value}")
+	}
+
+	// Validate Delegation
+	if cfg.Delegation.Enabled && !strings.Contains(cfg.Delegation.KeyPattern, "{user_id}") {
+		fail("delegation.key_pattern must contain {user_id}")
+	}
+
+	// Validate Delivery
+	if cfg.Delivery.Enabled {
+		for msgType, policy := range cfg.Delivery.Policies {
+			switch msgType {
+			case "data_onboarding", "analytics_pipeline", "campaign_event", "crisis_alert", "security_alert", "billing_event":
+			default:
+				fail("delivery.policies[%s]: only message types addressed to a user have a policy", msgType)
+			}
+			switch policy {
+			case "at_most_once", "at_least_once":
+			default:
+				fail("delivery.policies[%s] must be one of at_most_once, at_least_once", msgType)
+			}
+		}
+		if cfg.Delivery.AckTimeout < time.Second {
+			fail("delivery.ack_timeout must be at least 1s")
+		}
+		if cfg.Delivery.MaxRetries < 0 {
+			fail("delivery.max_retries must not be negative")
+		}
+		if !strings.Contains(cfg.Delivery.OfflineKeyPattern, "{user_id}") {
+			fail("delivery.offline.key_pattern must contain {user_id}")
+		}
+		if cfg.Delivery.OfflineMaxFrames < 1 || cfg.Delivery.OfflineTTL <= 0 {
+			fail("delivery.offline.max_frames must be at least 1 and delivery.offline.ttl must be positive")
+		}
+	}
+
+	// Validate User Trace
+	if cfg.UserTrace.MaxTTL <= 0 {
+		fail("user_trace.max_ttl must be positive")
+	}
+	if cfg.UserTrace.MaxUsers < 0 {
+		fail("user_trace.max_users must not be negative")
+	}
+
+	// Validate GraphQL
+	if cfg.GraphQL.Enabled && cfg.GraphQL.InitTimeout <= 0 {
+		fail("graphql.init_timeout must be positive")
+	}
+
+	// Validate WebTransport
+	if wt := cfg.WebTransport; wt.Enabled {
+		if wt.Port <= 0 || wt.Port > 65535 {
+			fail("webtransport.port is invalid")
+		}
+		if wt.CertFile == "" || wt.KeyFile == "" {
+			fail("webtransport.cert_file and key_file are required when WebTransport is enabled")
+		}
+	}
+
+	// Validate Watchdog
+	if cfg.Watchdog.Enabled && (cfg.Watchdog.StallWindow <= 0 || cfg.Watchdog.CheckInterval <= 0) {
+		fail("watchdog.stall_window and watchdog.check_interval must be positive")
+	}
+
+	// Validate Probe
+	if cfg.Probe.Enabled && (cfg.Probe.Interval <= 0 || cfg.Probe.InstanceID == "") {
+		fail("probe.interval must be positive and probe.instance_id must be set")
+	}
+
+	// Validate Canary
+	if cfg.Canary.Enabled {
+		if cfg.Canary.UserID == "" || cfg.Canary.ProjectID == "" ||
+			strings.Contains(cfg.Canary.UserID, ":") || strings.Contains(cfg.Canary.ProjectID, ":") {
+			fail("canary.user_id and canary.project_id must be set and must not contain ':'")
+		}
+		if cfg.Canary.Interval <= 0 || cfg.Canary.Timeout <= 0 {
+			fail("canary.interval and canary.timeout must be positive")
+		}
+		if cfg.Canary.LatencyThreshold <= 0 || cfg.Canary.LatencyThreshold >= cfg.Canary.Timeout {
+			fail("canary.latency_threshold must be positive and below canary.timeout")
+		}
+		if cfg.Canary.FailuresToAlert < 1 {
+			fail("canary.failures_to_alert must be at least 1")
+		}
+		if cfg.Probe.InstanceID == "" {
+			fail("probe.instance_id must be set for the canary")
+		}
+	}
+
+	// Validate Inbound Rate Limit
+	rates := map[string]ChannelRateConfig{"": {Rate: cfg.RateLimit.Rate, Burst: cfg.RateLimit.Burst}}
+	for prefix, r := range cfg.RateLimit.Prefixes {
+		rates[prefix] = r
+	}
+	for prefix, r := range rates {
+		if r.Rate < 0 {
+			fail("rate_limit rate of prefix %q must not be negative", prefix)
+		}
+		if r.Rate > 0 && r.Burst < 1 {
+			fail("rate_limit burst of prefix %q must be at least 1", prefix)
+		}
+	}
+	switch cfg.RateLimit.Mode {
+	case "drop", "coalesce":
+	default:
+		fail("rate_limit.mode must be one of drop, coalesce")
+	}
+	if cfg.RateLimit.MaxChannels < 1 {
+		fail("rate_limit.max_channels must be at least 1")
+	}
+	if cfg.RateLimit.DLQKey != "" {
+		if cfg.RateLimit.DLQSampleRate < 0 || cfg.RateLimit.DLQSampleRate > 1 {
+			fail("rate_limit.dlq.sample_rate must be between 0 and 1")
+		}
+		if cfg.RateLimit.DLQMaxLen < 1 {
+			fail("rate_limit.dlq.max_len must be at least 1")
+		}
+	}
+
+	// Validate Breaker
+	if b := cfg.Breaker; b.Enabled {
+		if b.FailureRatio <= 0 || b.FailureRatio > 1 {
+			fail("breaker.failure_ratio must be in (0, 1]")
+		}
+		if b.MinRequests < 1 || b.HalfOpenProbes < 1 {
+			fail("breaker.min_requests and breaker.half_open_probes must be at least 1")
+		}
+		if b.Interval <= 0 || b.OpenTimeout <= 0 {
+			fail("breaker.interval and breaker.open_timeout must be positive")
+		}
+	}
+
+	// Validate Startup
+	if s := cfg.Startup; s.MaxWait < 0 {
+		fail("startup.max_wait must not be negative")
+	} else if s.MaxWait > 0 && (s.InitialBackoff <= 0 || s.MaxBackoff < s.InitialBackoff) {
+		fail("startup.initial_backoff must be positive and at most startup.max_backoff")
+	}
+
+	// Validate Leader
+	if l := cfg.Leader; l.KeyPrefix == "" || l.Identity == "" {
+		fail("leader.key_prefix and leader.identity must be set")
+	}
+	if l := cfg.Leader; l.RenewInterval <= 0 || l.TTL <= l.RenewInterval {
+		fail("leader.renew_interval must be positive and below leader.ttl")
+	}
+
+	// Validate Sinks
+	if k := cfg.Sinks.Kafka; k.Enabled {
+		if len(k.Brokers) == 0 || k.Topic == "" {
+			fail("sinks.kafka.brokers and sinks.kafka.topic are required when the Kafka sink is enabled")
+		}
+		if k.BatchSize <= 0 || k.FlushInterval <= 0 || k.QueueSize <= 0 || k.Timeout <= 0 || k.MaxRetries < 0 {
+			fail("sinks.kafka batch_size, flush_interval, queue_size and timeout must be positive")
+		}
+	}
+	if ch := cfg.Sinks.ClickHouse; ch.Enabled {
+		if ch.URL == "" || ch.Table == "" {
+			fail("sinks.clickhouse.url and sinks.clickhouse.table are required when the ClickHouse sink is enabled")
+		}
+		if ch.BatchSize <= 0 || ch.FlushInterval <= 0 || ch.QueueSize <= 0 || ch.Timeout <= 0 || ch.MaxRetries < 0 {
+			fail("sinks.clickhouse batch_size, flush_interval, queue_size and timeout must be positive")
+		}
+	}
+
+	// Validate MQTT
+	if m := cfg.MQTT; m.Enabled {
+		if m.BrokerURL == "" || !strings.Contains(m.TopicPattern, "{project_id}") {
+			fail("mqtt.broker_url is required and mqtt.topic_pattern must contain {project_id} when the MQTT bridge is enabled")
+		}
+		if m.QoS < 0 || m.QoS > 2 {
+			fail("mqtt.qos must be 0, 1 or 2")
+		}
+		if m.QueueSize <= 0 || m.Timeout <= 0 {
+			fail("mqtt.queue_size and mqtt.timeout must be positive")
+		}
+	}
+
+	// Validate internal signing keys
+	if cfg.InternalConfig.MaxSkew <= 0 {
+		fail("internal.max_skew must be positive")
+	}
+	keyIDs := make(map[string]bool, len(cfg.InternalConfig.Keys))
+	for i, k := range cfg.InternalConfig.Keys {
+		if k.ID == "" || k.Caller == "" {
+			fail("internal.keys[%d]: id and caller are required", i)
+		}
+		if len(k.Secret) < 32 {
+			fail("internal.keys[%d]: secret must be at least 32 characters", i)
+		}
+		if keyIDs[k.ID] {
+			fail("internal.keys[%d]: duplicate key id %q", i, k.ID)
+		}
+		keyIDs[k.ID] = true
+	}
+	if m := cfg.InternalConfig.MTLS; m.Enabled {
+		if m.Port <= 0 || m.Port > 65535 || m.Port == cfg.Server.Port {
+			fail("internal.mtls.port is invalid or equal to server.port")
+		}
+		if m.CertFile == "" || m.KeyFile == "" || m.ClientCAFile == "" {
+			fail("internal.mtls.cert_file, key_file and client_ca_file are required when mTLS is enabled")
+		}
+		if len(m.AllowedSPIFFEIDs) == 0 {
+			fail("internal.mtls.allowed_spiffe_ids must not be empty when mTLS is enabled")
+		}
+		for i, id := range m.AllowedSPIFFEIDs {
+			if !strings.HasPrefix(id, "spiffe://") {
+				fail("internal.mtls.allowed_spiffe_ids[%d]: %q is not a spiffe:// ID", i, id)
+			}
+		}
 	}
 
 	// Validate Cookie
 	if cfg.Cookie.Name == "" {
-		return fmt.Errorf("cookie.name is required")
+		fail("cookie.name is required")
 	}
 
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
 	return nil
 }
 
+// ValidationError lists every problem found by Load in a configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration (%d problems):\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// validateAdminAddr checks the address of a separate admin listener: a unix
+// socket path, or a host:port other than the public port.
+func validateAdminAddr(addr string, publicPort int) error {
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		if path == "" {
+			return fmt.Errorf("server.admin.addr needs a socket path after unix:")
+		}
+		return nil
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("server.admin.addr must be host:port or unix:<path> when server.admin.mode is separate")
+	}
+	if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 || n == publicPort {
+		return fmt.Errorf("server.admin.addr port is invalid or equal to server.port")
+	}
+	return nil
+}
+
+// secretSettings are the setting names whose values Effective masks.
+var secretSettings = map[string]bool{
+	"secret_key":   true, // jwt
+	"password":     true, // redis, redis.regions, sinks.clickhouse, mqtt
+	"internal_key": true,
+	"secret":       true, // internal.keys
+	"webhook_url":  true, // discord, carries the webhook token
+}
+
+// masked replaces a set secret.
+const masked = "********"
+
+// Effective returns the settings read by Load, defaults and environment
+// overrides included, keyed like the config file. Secrets are masked and
+// durations formatted like in the file.
+func Effective() map[string]any {
+	return redact(viper.AllSettings())
+}
+
+func redact(settings map[string]any) map[string]any {
+	out := make(map[string]any, len(settings))
+	for k, v := range settings {
+		out[k] = redactValue(k, v)
+	}
+	return out
+}
+
+func redactValue(key string, v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		return redact(v)
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = redactValue(key, item)
+		}
+		return items
+	case time.Duration:
+		return v.String()
+	}
+	if secretSettings[key] && fmt.Sprint(v) != "" {
+		return masked
+	}
+	return v
+}
+
 func bindEnv() error {
 	// Support both canonical env var names (SERVER_PORT, WEBSOCKET_*, ...)
 	// and legacy names used in some manifests (WS_*, ENV).
@@ -253,31 +1807,225 @@ func bindEnv() error {
 		"server.port": {"SERVER_PORT", "WS_PORT"},
 		"server.mode": {"SERVER_MODE", "WS_MODE"},
 
+		"server.reuse_port":     {"SERVER_REUSE_PORT"},
+		"server.handoff_socket": {"SERVER_HANDOFF_SOCKET"},
+		"server.base_path":      {"SERVER_BASE_PATH"},
+		"server.ws_path":        {"SERVER_WS_PATH"},
+		"server.admin.mode":     {"SERVER_ADMIN_MODE"},
+		"server.admin.addr":     {"SERVER_ADMIN_ADDR"},
+		"server.admin.auth":     {"SERVER_ADMIN_AUTH"},
+
 		"logger.level":         {"LOGGER_LEVEL"},
 		"logger.mode":          {"LOGGER_MODE"},
 		"logger.encoding":      {"LOGGER_ENCODING"},
 		"logger.color_enabled": {"LOGGER_COLOR_ENABLED"},
 
-		"redis.host":     {"REDIS_HOST"},
-		"redis.port":     {"REDIS_PORT"},
-		"redis.password": {"REDIS_PASSWORD"},
-		"redis.db":       {"REDIS_DB"},
+		"redis.host":                     {"REDIS_HOST"},
+		"redis.port":                     {"REDIS_PORT"},
+		"redis.username":                 {"REDIS_USERNAME"},
+		"redis.password":                 {"REDIS_PASSWORD"},
+		"redis.db":                       {"REDIS_DB"},
+		"redis.region":                   {"REDIS_REGION"},
+		"redis.channel_prefix":           {"REDIS_CHANNEL_PREFIX"},
+		"redis.slow_command_threshold":   {"REDIS_SLOW_COMMAND_THRESHOLD"},
+		"redis.tls.enabled":              {"REDIS_TLS_ENABLED"},
+		"redis.tls.ca_file":              {"REDIS_TLS_CA_FILE"},
+		"redis.tls.cert_file":            {"REDIS_TLS_CERT_FILE"},
+		"redis.tls.key_file":             {"REDIS_TLS_KEY_FILE"},
+		"redis.tls.server_name":          {"REDIS_TLS_SERVER_NAME"},
+		"redis.tls.insecure_skip_verify": {"REDIS_TLS_INSECURE_SKIP_VERIFY"},
+
+		"websocket.ping_interval":           {"WEBSOCKET_PING_INTERVAL", "WS_PING_INTERVAL"},
+		"websocket.pong_wait":               {"WEBSOCKET_PONG_WAIT", "WS_PONG_WAIT"},
+		"websocket.write_wait":              {"WEBSOCKET_WRITE_WAIT", "WS_WRITE_WAIT"},
+		"websocket.max_message_size":        {"WEBSOCKET_MAX_MESSAGE_SIZE", "WS_MAX_MESSAGE_SIZE"},
+		"websocket.read_buffer_size":        {"WEBSOCKET_READ_BUFFER_SIZE", "WS_READ_BUFFER_SIZE"},
+		"websocket.write_buffer_size":       {"WEBSOCKET_WRITE_BUFFER_SIZE", "WS_WRITE_BUFFER_SIZE"},
+		"websocket.max_connections":         {"WEBSOCKET_MAX_CONNECTIONS", "WS_MAX_CONNECTIONS"},
+		"websocket.transport":               {"WEBSOCKET_TRANSPORT"},
+		"websocket.heartbeat":               {"WEBSOCKET_HEARTBEAT"},
+		"websocket.hello":                   {"WEBSOCKET_HELLO"},
+		"websocket.max_protocol_violations": {"WEBSOCKET_MAX_PROTOCOL_VIOLATIONS"},
+		"websocket.enable_compression":      {"WEBSOCKET_ENABLE_COMPRESSION"},
+		"websocket.compression_ua_denylist": {"WEBSOCKET_COMPRESSION_UA_DENYLIST"},
+		"websocket.client_labels":           {"WEBSOCKET_CLIENT_LABELS"},
+		"websocket.upgrade_url":             {"WEBSOCKET_UPGRADE_URL"},
+		"websocket.close_outdated_clients":  {"WEBSOCKET_CLOSE_OUTDATED_CLIENTS"},
+		"websocket.timeouts.auth":           {"WEBSOCKET_TIMEOUTS_AUTH"},
+		"websocket.timeouts.upgrade":        {"WEBSOCKET_TIMEOUTS_UPGRADE"},
+		"websocket.timeouts.register":       {"WEBSOCKET_TIMEOUTS_REGISTER"},
+		"websocket.replay_buffer_size":      {"WEBSOCKET_REPLAY_BUFFER_SIZE"},
+		"websocket.replay_max_topics":       {"WEBSOCKET_REPLAY_MAX_TOPICS"},
+		"websocket.stats_window":            {"WEBSOCKET_STATS_WINDOW"},
+		"websocket.stats_history.interval":  {"WEBSOCKET_STATS_HISTORY_INTERVAL"},
+		"websocket.stats_history.retention": {"WEBSOCKET_STATS_HISTORY_RETENTION"},
+		"websocket.topic_gc_grace":          {"WEBSOCKET_TOPIC_GC_GRACE"},
+		"websocket.delta_snapshot_every":    {"WEBSOCKET_DELTA_SNAPSHOT_EVERY"},
+		"websocket.coalesce.enabled":        {"WEBSOCKET_COALESCE_ENABLED"},
+		"websocket.coalesce.min_interval":   {"WEBSOCKET_COALESCE_MIN_INTERVAL"},
+		"websocket.coalesce.max_interval":   {"WEBSOCKET_COALESCE_MAX_INTERVAL"},
+		"websocket.churn.enabled":           {"WEBSOCKET_CHURN_ENABLED"},
+		"websocket.churn.window":            {"WEBSOCKET_CHURN_WINDOW"},
+		"websocket.churn.max_user_connects": {"WEBSOCKET_CHURN_MAX_USER_CONNECTS"},
+		"websocket.churn.max_ip_users":      {"WEBSOCKET_CHURN_MAX_IP_USERS"},
+		"websocket.churn.ban_ttl":           {"WEBSOCKET_CHURN_BAN_TTL"},
+		"websocket.quota.claim":             {"WEBSOCKET_QUOTA_CLAIM"},
+		"websocket.quota.default_per_user":  {"WEBSOCKET_QUOTA_DEFAULT_PER_USER"},
+		"websocket.quota.policy":            {"WEBSOCKET_QUOTA_POLICY"},
+		"websocket.send_queue.size":         {"WEBSOCKET_SEND_QUEUE_SIZE"},
+		"websocket.send_queue.policy":       {"WEBSOCKET_SEND_QUEUE_POLICY"},
+
+		"websocket.backpressure.window":        {"WEBSOCKET_BACKPRESSURE_WINDOW"},
+		"websocket.backpressure.min_drop_rate": {"WEBSOCKET_BACKPRESSURE_MIN_DROP_RATE"},
+		"websocket.backpressure.min_frames":    {"WEBSOCKET_BACKPRESSURE_MIN_FRAMES"},
+
+		"websocket.fingerprint_headers.tls":   {"WEBSOCKET_FINGERPRINT_HEADERS_TLS"},
+		"websocket.fingerprint_headers.http2": {"WEBSOCKET_FINGERPRINT_HEADERS_HTTP2"},
+
+		"websocket.decoy.patterns": {"WEBSOCKET_DECOY_PATTERNS"},
+		"websocket.decoy.ban_ttl":  {"WEBSOCKET_DECOY_BAN_TTL"},
+		"websocket.decoy.ban_ip":   {"WEBSOCKET_DECOY_BAN_IP"},
+
+		"websocket.public.enabled":         {"WEBSOCKET_PUBLIC_ENABLED"},
+		"websocket.public.max_connections": {"WEBSOCKET_PUBLIC_MAX_CONNECTIONS"},
+		"websocket.public.max_per_ip":      {"WEBSOCKET_PUBLIC_MAX_PER_IP"},
+		"websocket.public.connect_rate":    {"WEBSOCKET_PUBLIC_CONNECT_RATE"},
+		"websocket.public.connect_burst":   {"WEBSOCKET_PUBLIC_CONNECT_BURST"},
 
-		"websocket.ping_interval":     {"WEBSOCKET_PING_INTERVAL", "WS_PING_INTERVAL"},
-		"websocket.pong_wait":         {"WEBSOCKET_PONG_WAIT", "WS_PONG_WAIT"},
-		"websocket.write_wait":        {"WEBSOCKET_WRITE_WAIT", "WS_WRITE_WAIT"},
-		"websocket.max_message_size":  {"WEBSOCKET_MAX_MESSAGE_SIZE", "WS_MAX_MESSAGE_SIZE"},
-		"websocket.read_buffer_size":  {"WEBSOCKET_READ_BUFFER_SIZE", "WS_READ_BUFFER_SIZE"},
-		"websocket.write_buffer_size": {"WEBSOCKET_WRITE_BUFFER_SIZE", "WS_WRITE_BUFFER_SIZE"},
-		"websocket.max_connections":   {"WEBSOCKET_MAX_CONNECTIONS", "WS_MAX_CONNECTIONS"},
+		"transform.validation":                 {"TRANSFORM_VALIDATION"},
+		"transform.shadow.version":             {"TRANSFORM_SHADOW_VERSION"},
+		"transform.shadow.percent":             {"TRANSFORM_SHADOW_PERCENT"},
+		"transform.transitions.mode":           {"TRANSFORM_TRANSITIONS_MODE"},
+		"transform.transitions.max_topics":     {"TRANSFORM_TRANSITIONS_MAX_TOPICS"},
+		"transform.terminal_dedupe.window":     {"TRANSFORM_TERMINAL_DEDUPE_WINDOW"},
+		"transform.terminal_dedupe.max_topics": {"TRANSFORM_TERMINAL_DEDUPE_MAX_TOPICS"},
+		"transform.sanitize.enabled":           {"TRANSFORM_SANITIZE_ENABLED"},
 
-		"jwt.secret_key": {"JWT_SECRET_KEY"},
+		"transform.message_types.security_alert":     {"TRANSFORM_MESSAGE_TYPES_SECURITY_ALERT"},
+		"transform.message_types.billing_event":      {"TRANSFORM_MESSAGE_TYPES_BILLING_EVENT"},
+		"transform.message_types.system_maintenance": {"TRANSFORM_MESSAGE_TYPES_SYSTEM_MAINTENANCE"},
+
+		"backfill.enabled":           {"BACKFILL_ENABLED"},
+		"backfill.state_key_pattern": {"BACKFILL_STATE_KEY_PATTERN"},
+		"backfill.timeout":           {"BACKFILL_TIMEOUT"},
+
+		"latest_state.enabled":     {"LATEST_STATE_ENABLED"},
+		"latest_state.key_pattern": {"LATEST_STATE_KEY_PATTERN"},
+		"latest_state.ttl":         {"LATEST_STATE_TTL"},
+
+		"presence.enabled":     {"PRESENCE_ENABLED"},
+		"presence.key_pattern": {"PRESENCE_KEY_PATTERN"},
+		"presence.ttl":         {"PRESENCE_TTL"},
+
+		"segments.plan_key_pattern": {"SEGMENTS_PLAN_KEY_PATTERN"},
+		"segments.org_key_pattern":  {"SEGMENTS_ORG_KEY_PATTERN"},
+
+		"bans.key_pattern": {"BANS_KEY_PATTERN"},
+
+		"delegation.enabled":     {"DELEGATION_ENABLED"},
+		"delegation.key_pattern": {"DELEGATION_KEY_PATTERN"},
+
+		"delivery.enabled":             {"DELIVERY_ENABLED"},
+		"delivery.ack_timeout":         {"DELIVERY_ACK_TIMEOUT"},
+		"delivery.max_retries":         {"DELIVERY_MAX_RETRIES"},
+		"delivery.offline.key_pattern": {"DELIVERY_OFFLINE_KEY_PATTERN"},
+		"delivery.offline.max_frames":  {"DELIVERY_OFFLINE_MAX_FRAMES"},
+		"delivery.offline.ttl":         {"DELIVERY_OFFLINE_TTL"},
+
+		"user_trace.max_ttl":   {"USER_TRACE_MAX_TTL"},
+		"user_trace.max_users": {"USER_TRACE_MAX_USERS"},
+
+		"graphql.enabled":      {"GRAPHQL_ENABLED"},
+		"graphql.init_timeout": {"GRAPHQL_INIT_TIMEOUT"},
+
+		"webtransport.enabled":   {"WEBTRANSPORT_ENABLED"},
+		"webtransport.port":      {"WEBTRANSPORT_PORT"},
+		"webtransport.cert_file": {"WEBTRANSPORT_CERT_FILE"},
+		"webtransport.key_file":  {"WEBTRANSPORT_KEY_FILE"},
+
+		"telemetry.emit_ttl":           {"TELEMETRY_EMIT_TTL"},
+		"telemetry.max_emit_records":   {"TELEMETRY_MAX_EMIT_RECORDS"},
+		"telemetry.max_report_samples": {"TELEMETRY_MAX_REPORT_SAMPLES"},
+
+		"jwt.secret_key":            {"JWT_SECRET_KEY"},
+		"jwt.subscription.enabled":  {"JWT_SUBSCRIPTION_ENABLED"},
+		"jwt.subscription.audience": {"JWT_SUBSCRIPTION_AUDIENCE"},
+		"jwt.subscription.max_ttl":  {"JWT_SUBSCRIPTION_MAX_TTL"},
 
 		"cookie.name":    {"COOKIE_NAME"},
 		"cookie.max_age": {"COOKIE_MAX_AGE"},
 		"cookie.domain":  {"COOKIE_DOMAIN"},
 
+		"internal.allow_static_key": {"INTERNAL_ALLOW_STATIC_KEY"},
+		"internal.max_skew":         {"INTERNAL_MAX_SKEW"},
+
+		"internal.mtls.enabled":            {"INTERNAL_MTLS_ENABLED"},
+		"internal.mtls.port":               {"INTERNAL_MTLS_PORT"},
+		"internal.mtls.cert_file":          {"INTERNAL_MTLS_CERT_FILE"},
+		"internal.mtls.key_file":           {"INTERNAL_MTLS_KEY_FILE"},
+		"internal.mtls.client_ca_file":     {"INTERNAL_MTLS_CLIENT_CA_FILE"},
+		"internal.mtls.allowed_spiffe_ids": {"INTERNAL_MTLS_ALLOWED_SPIFFE_IDS"},
+
 		"discord.webhook_url": {"DISCORD_WEBHOOK_URL"},
+
+		"supervisor.max_restarts_per_minute": {"SUPERVISOR_MAX_RESTARTS_PER_MINUTE"},
+		"supervisor.restart_backoff":         {"SUPERVISOR_RESTART_BACKOFF"},
+
+		"watchdog.enabled":           {"WATCHDOG_ENABLED"},
+		"watchdog.stall_window":      {"WATCHDOG_STALL_WINDOW"},
+		"watchdog.check_interval":    {"WATCHDOG_CHECK_INTERVAL"},
+		"watchdog.heartbeat_channel": {"WATCHDOG_HEARTBEAT_CHANNEL"},
+
+		"breaker.enabled":          {"BREAKER_ENABLED"},
+		"breaker.failure_ratio":    {"BREAKER_FAILURE_RATIO"},
+		"breaker.min_requests":     {"BREAKER_MIN_REQUESTS"},
+		"breaker.interval":         {"BREAKER_INTERVAL"},
+		"breaker.open_timeout":     {"BREAKER_OPEN_TIMEOUT"},
+		"breaker.half_open_probes": {"BREAKER_HALF_OPEN_PROBES"},
+
+		"startup.max_wait":        {"STARTUP_MAX_WAIT"},
+		"startup.initial_backoff": {"STARTUP_INITIAL_BACKOFF"},
+		"startup.max_backoff":     {"STARTUP_MAX_BACKOFF"},
+
+		"leader.key_prefix":     {"LEADER_KEY_PREFIX"},
+		"leader.identity":       {"LEADER_IDENTITY", "HOSTNAME"},
+		"leader.ttl":            {"LEADER_TTL"},
+		"leader.renew_interval": {"LEADER_RENEW_INTERVAL"},
+
+		"sinks.kafka.enabled":       {"SINKS_KAFKA_ENABLED"},
+		"sinks.kafka.brokers":       {"SINKS_KAFKA_BROKERS"},
+		"sinks.kafka.topic":         {"SINKS_KAFKA_TOPIC"},
+		"sinks.kafka.message_types": {"SINKS_KAFKA_MESSAGE_TYPES"},
+		"sinks.clickhouse.enabled":  {"SINKS_CLICKHOUSE_ENABLED"},
+		"sinks.clickhouse.url":      {"SINKS_CLICKHOUSE_URL"},
+		"sinks.clickhouse.username": {"SINKS_CLICKHOUSE_USERNAME"},
+		"sinks.clickhouse.password": {"SINKS_CLICKHOUSE_PASSWORD"},
+
+		"mqtt.enabled":    {"MQTT_ENABLED"},
+		"mqtt.broker_url": {"MQTT_BROKER_URL"},
+		"mqtt.client_id":  {"MQTT_CLIENT_ID"},
+		"mqtt.username":   {"MQTT_USERNAME"},
+		"mqtt.password":   {"MQTT_PASSWORD"},
+
+		"probe.enabled":     {"PROBE_ENABLED"},
+		"probe.interval":    {"PROBE_INTERVAL"},
+		"probe.instance_id": {"PROBE_INSTANCE_ID", "HOSTNAME"},
+
+		"canary.enabled":           {"CANARY_ENABLED"},
+		"canary.user_id":           {"CANARY_USER_ID"},
+		"canary.project_id":        {"CANARY_PROJECT_ID"},
+		"canary.interval":          {"CANARY_INTERVAL"},
+		"canary.timeout":           {"CANARY_TIMEOUT"},
+		"canary.latency_threshold": {"CANARY_LATENCY_THRESHOLD"},
+		"canary.failures_to_alert": {"CANARY_FAILURES_TO_ALERT"},
+
+		"rate_limit.rate":            {"RATE_LIMIT_RATE"},
+		"rate_limit.burst":           {"RATE_LIMIT_BURST"},
+		"rate_limit.mode":            {"RATE_LIMIT_MODE"},
+		"rate_limit.max_channels":    {"RATE_LIMIT_MAX_CHANNELS"},
+		"rate_limit.dlq.key":         {"RATE_LIMIT_DLQ_KEY"},
+		"rate_limit.dlq.sample_rate": {"RATE_LIMIT_DLQ_SAMPLE_RATE"},
+		"rate_limit.dlq.max_len":     {"RATE_LIMIT_DLQ_MAX_LEN"},
 	}
 
 	for key, envs := range binds {
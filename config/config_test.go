@@ -0,0 +1,111 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"notification-srv/config"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// writeConfig writes a config file into dir and returns its path.
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// load runs LoadFrom on a fresh viper, as Load keeps its settings in the global one.
+func load(t *testing.T, src config.Sources) (*config.Config, error) {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	return config.LoadFrom(src)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "notification-config.yaml", `
+jwt:
+  secret_key: `+testSecret+`
+server:
+  port: 9000
+  mode: release
+redis:
+  host: base-redis
+websocket:
+  max_connections: 500
+`)
+	writeConfig(t, dir, "notification-config.staging.yaml", `
+server:
+  port: 9100
+redis:
+  host: staging-redis
+`)
+	t.Setenv("REDIS_HOST", "env-redis")
+
+	cfg, err := load(t, config.Sources{Paths: []string{dir}, Profile: "staging"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 9100, cfg.Server.Port, "profile overrides the base file")
+	assert.Equal(t, "release", cfg.Server.Mode, "base file kept where the profile is silent")
+	assert.Equal(t, "env-redis", cfg.Redis.Host, "environment overrides both files")
+	assert.Equal(t, 500, cfg.WebSocket.MaxConnections)
+	assert.Equal(t, 60*time.Second, cfg.WebSocket.PongWait, "defaults fill the rest")
+	assert.Equal(t, []string{
+		filepath.Join(dir, "notification-config.yaml"),
+		filepath.Join(dir, "notification-config.staging.yaml"),
+	}, config.Files())
+}
+
+func TestLoadTOMLFile(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, dir, "service.toml", `
+[jwt]
+secret_key = "`+testSecret+`"
+
+[websocket]
+pong_wait = "90s"
+`)
+	writeConfig(t, dir, "service.prod.toml", `
+[websocket]
+max_connections = 2000
+`)
+
+	cfg, err := load(t, config.Sources{File: path, Profile: "prod"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 90*time.Second, cfg.WebSocket.PongWait)
+	assert.Equal(t, 2000, cfg.WebSocket.MaxConnections)
+}
+
+func TestLoadMissingProfile(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "notification-config.yaml", "jwt:\n  secret_key: "+testSecret+"\n")
+
+	_, err := load(t, config.Sources{Paths: []string{dir}, Profile: "prod"})
+	assert.ErrorContains(t, err, `config profile "prod"`)
+}
+
+func TestLoadReportsAllProblems(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "notification-config.yaml", `
+jwt:
+  secret_key: short
+websocket:
+  pong_wait: 0s
+`)
+
+	_, err := load(t, config.Sources{Paths: []string{dir}})
+	var verr *config.ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Len(t, verr.Problems, 2)
+}
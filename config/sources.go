@@ -0,0 +1,94 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Settings are resolved in this order, each source overriding the previous:
+//
+//  1. defaults (setDefaults)
+//  2. the base file: Sources.File, or the first notification-config.<ext> in
+//     Sources.Paths, where <ext> is any format viper reads (yaml, yml, toml,
+//     json, ...). Optional.
+//  3. the profile file next to it, notification-config.<profile>.<ext>, when
+//     a profile is set. Required once a profile is asked for.
+//  4. environment variables: the names in bindEnv, then the key upper-cased
+//     with dots as underscores (websocket.pong_wait → WEBSOCKET_PONG_WAIT).
+
+// configName is the base name of the config files.
+const configName = "notification-config"
+
+// defaultPaths are searched for the base file when Sources.File is empty.
+var defaultPaths = []string{"./config", ".", "/etc/smap/"}
+
+// Sources selects the config files Load reads.
+type Sources struct {
+	File    string   // Base file; empty searches Paths
+	Paths   []string // Directories searched for the base file; empty uses ./config, . and /etc/smap/
+	Profile string   // dev, staging, prod, ...: merges notification-config.<profile>.<ext> over the base file
+}
+
+// SourcesFromEnv reads the sources from CONFIG_FILE and CONFIG_PROFILE.
+func SourcesFromEnv() Sources {
+	return Sources{File: os.Getenv("CONFIG_FILE"), Profile: os.Getenv("CONFIG_PROFILE")}
+}
+
+// filesRead are the config files of the last Load, base file first.
+var filesRead []string
+
+// Files returns the config files the last Load read, base file first.
+func Files() []string {
+	return filesRead
+}
+
+// readFiles reads the base and profile files into viper.
+func readFiles(src Sources) error {
+	filesRead = nil
+
+	if src.File != "" {
+		viper.SetConfigFile(src.File)
+	} else {
+		viper.SetConfigName(configName)
+		paths := src.Paths
+		if len(paths) == 0 {
+			paths = defaultPaths
+		}
+		for _, p := range paths {
+			viper.AddConfigPath(p)
+		}
+	}
+
+	base := ""
+	if err := viper.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return fmt.Errorf("error reading config file: %w", err)
+		}
+		// No base file; defaults, the profile and environment variables only
+	} else {
+		base = viper.ConfigFileUsed()
+		filesRead = append(filesRead, base)
+	}
+
+	if src.Profile == "" {
+		return nil
+	}
+	if base != "" {
+		// Next to the base file, in its format
+		ext := filepath.Ext(base)
+		viper.SetConfigFile(strings.TrimSuffix(base, ext) + "." + src.Profile + ext)
+	} else {
+		viper.SetConfigName(configName + "." + src.Profile)
+	}
+	if err := viper.MergeInConfig(); err != nil {
+		return fmt.Errorf("error reading config profile %q: %w", src.Profile, err)
+	}
+	filesRead = append(filesRead, viper.ConfigFileUsed())
+	return nil
+}
@@ -0,0 +1,2004 @@
+// Package docs Code generated by swaggo/swag. DO NOT EDIT
+package docs
+
+import "github.com/swaggo/swag"
+
+const docTemplate = `{
+    "schemes": {{ marshal .Schemes }},
+    "swagger": "2.0",
+    "info": {
+        "description": "{{escape .Description}}",
+        "title": "{{.Title}}",
+        "contact": {},
+        "version": "{{.Version}}"
+    },
+    "host": "{{.Host}}",
+    "basePath": "{{.BasePath}}",
+    "paths": {
+        "/admin/bans": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Bans a user ID (kind user) or an IP, or CIDR range (kind ip) for ttl_seconds on every replica. Banned clients get 403 on /ws before their token is checked, and their open connections are closed with code 4030. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Ban a user or IP range",
+                "parameters": [
+                    {
+                        "description": "Ban",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_websocket_delivery_http.banReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_websocket_delivery_http.banResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Removes a user ID or IP range from the deny-list. The value must be the banned user ID or range (a single IP matches its /32 or /128 ban). Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Lift a ban",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user or ip",
+                        "name": "kind",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID, IP or CIDR range",
+                        "name": "value",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Resp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/broadcast": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Sends a SYSTEM notification (system_event broadcast, title, message) to the users of a segment on every replica: all connected users (kind all), listed user IDs (kind users), users on a plan (kind plan, value = plan name), or users with a connection filtered to a project of an org (kind org, value = org ID). Plan and org segments are resolved from Redis sets. The response reports what the segment resolved to; delivery is asynchronous. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Broadcast to a segment",
+                "parameters": [
+                    {
+                        "description": "Segment and message",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_websocket_delivery_http.broadcastReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_websocket_delivery_http.broadcastResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/connections": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "List live WebSocket connections with per-connection details such as negotiated compression. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List connections",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by user ID",
+                        "name": "user_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_websocket_delivery_http.listConnectionsResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/maintenance": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns whether maintenance mode is on for this replica, with its reason and start time. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get maintenance mode",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_websocket_delivery_http.maintenanceResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "While maintenance mode is on, only critical and terminal messages (crisis alerts, system notices, finished or failed onboardings, completed pipelines, finished campaigns) are delivered; everything else is dropped. Clients get a SYSTEM banner when the mode changes. The change is applied locally and published on control:maintenance:{on|off} for the other replicas. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Set maintenance mode",
+                "parameters": [
+                    {
+                        "description": "Maintenance toggle",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_websocket_delivery_http.setMaintenanceReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_websocket_delivery_http.maintenanceResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/stats/history": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Hub stats of this replica sampled every websocket.stats_history.interval and kept in memory for websocket.stats_history.retention: connections and unique users at each sample, and messages received, rejected, delivered and dropped since the previous one. Shows trends around an incident even when metrics scraping was broken. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Stats history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only samples within this duration, e.g. 1h; default the whole history",
+                        "name": "window",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_websocket_delivery_http.getStatsHistoryResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/traces": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns the users under targeted tracing on this replica, with the expiry of their trace. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List traced users",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_websocket_delivery_http.listUserTracesResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Logs every hop of the user's messages on every replica for ttl_seconds: one line per message with the time each step ended, the payload as published and the frame as routed, then a line per socket write and per ack. Latency exemplars of the user's messages carry their user_id. The TTL and the number of traced users are capped. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Trace a user",
+                "parameters": [
+                    {
+                        "description": "User to trace",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_websocket_delivery_http.traceUserReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_websocket_delivery_http.userTraceResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many users traced",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Stops tracing the user on every replica before the trace expires. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Stop tracing a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Traced user ID",
+                        "name": "user_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Resp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/projects/{id}/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns the frames of the caller's project topic with a sequence number greater than after_seq, oldest first. If the epoch differs from the one the client saw, or truncated is true, the client must resync instead of relying on the frames.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "WebSocket"
+                ],
+                "summary": "Recover missed project notifications",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Last sequence number the client received",
+                        "name": "after_seq",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_websocket_delivery_http.listProjectNotificationsResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/telemetry/latency": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Accepts message-ID receive timestamps from the browser client. The server joins them with emit times to compute end-to-end delivery latency histograms.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Telemetry"
+                ],
+                "summary": "Report delivery latency",
+                "parameters": [
+                    {
+                        "description": "Receive timestamps",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_telemetry_delivery_http.reportLatencyReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_telemetry_delivery_http.reportLatencyResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
+            "get": {
+                "description": "WebSocket endpoint speaking the graphql-transport-ws protocol (Apollo graphql-ws). Offers the projectProgress(projectId) subscription. Authenticates like /ws: token query, auth cookie, or {\"token\": ...} in the connection_init payload.",
+                "tags": [
+                    "GraphQL"
+                ],
+                "summary": "GraphQL subscriptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Connection quota exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Executes a GraphQL query or operation over HTTP. Subscriptions need the WebSocket endpoint.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "GraphQL"
+                ],
+                "summary": "GraphQL query",
+                "responses": {
+                    "200": {
+                        "description": "GraphQL response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Check if the WebSocket service is healthy",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Health Check",
+                "responses": {
+                    "200": {
+                        "description": "Service is healthy",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/contract-check": {
+            "post": {
+                "security": [
+                    {
+                        "InternalKey": []
+                    }
+                ],
+                "description": "Runs channel parsing, type detection, strict validation and transformation on a sample payload and returns every violation plus the normalized frame clients would receive. Used by publisher CI (see cmd/contract-check).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Internal"
+                ],
+                "summary": "Check message contract",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Internal service key, for unsigned requests",
+                        "name": "X-Internal-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signing key ID (see pkg/signing)",
+                        "name": "X-Signature-Key-Id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unix seconds the request was signed at",
+                        "name": "X-Signature-Timestamp",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Hex HMAC-SHA256 request signature",
+                        "name": "X-Signature",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Sample message",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_websocket_delivery_http.checkContractReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_websocket_delivery_http.checkContractResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/stats/channels": {
+            "get": {
+                "security": [
+                    {
+                        "InternalKey": []
+                    }
+                ],
+                "description": "Messages received, transform errors, frames delivered and dropped, and messages without recipients, per channel pattern (IDs replaced by *) over the rolling window (websocket.stats_window) of this replica. Lets publisher teams check their integration is flowing.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Internal"
+                ],
+                "summary": "Channel statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Internal service key, for unsigned requests",
+                        "name": "X-Internal-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signing key ID (see pkg/signing)",
+                        "name": "X-Signature-Key-Id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unix seconds the request was signed at",
+                        "name": "X-Signature-Timestamp",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Hex HMAC-SHA256 request signature",
+                        "name": "X-Signature",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "default": "*",
+                        "description": "Glob over channel patterns, e.g. project:*",
+                        "name": "pattern",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_websocket_delivery_http.getChannelStatsResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/live": {
+            "get": {
+                "description": "Check if the WebSocket service is alive",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Liveness Check",
+                "responses": {
+                    "200": {
+                        "description": "Service is alive",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/protocol": {
+            "get": {
+                "description": "Returns the version of the client protocol and the features this server has on: encodings a client may negotiate (permessage-deflate, the smap.delta.v1 subprotocol), whether frames may carry ack_required, whether frames carry seq so missed ones can be fetched again, the largest client frame, the ping interval and whether HEARTBEAT frames follow pings, and the client frame actions. With websocket.hello on, connections receive the same document first as a hello frame.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notification"
+                ],
+                "summary": "Get the client protocol",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_websocket_delivery_http.protocolResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/ready": {
+            "get": {
+                "description": "Check if the WebSocket service is ready to serve traffic",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Readiness Check",
+                "responses": {
+                    "200": {
+                        "description": "Service is ready",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Service is not ready",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/schemas/{message_type}/{version}": {
+            "get": {
+                "description": "Returns the JSON Schema (draft 2020-12) generated from the Go payload type of a message type and contract version.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schemas"
+                ],
+                "summary": "Get message contract schema",
+                "parameters": [
+                    {
+                        "enum": [
+                            "DATA_ONBOARDING",
+                            "ANALYTICS_PIPELINE",
+                            "CRISIS_ALERT",
+                            "CAMPAIGN_EVENT",
+                            "SYSTEM"
+                        ],
+                        "type": "string",
+                        "description": "Message type",
+                        "name": "message_type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "v1",
+                        "description": "Contract version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JSON Schema document",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/ws": {
+            "get": {
+                "description": "Upgrade HTTP to WebSocket for real-time notifications. Requires valid JWT token in query 'token' or cookie.",
+                "tags": [
+                    "Notification"
+                ],
+                "summary": "Connect to WebSocket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project subscription token minted by smap-api; replaces token and receives only its project",
+                        "name": "subToken",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project ID Filter",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated message types to receive, e.g. project_progress,crisis_alert",
+                        "name": "types",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Least important messages to receive: low, normal, high or critical",
+                        "name": "min_importance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated payload fields to keep, e.g. status,progress",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Frontend build, e.g. dashboard-v2 (letters, digits, . _ -; max 64)",
+                        "name": "client_label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Subscribe to this user's stream instead; requires delegated access",
+                        "name": "asUser",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client version, checked against the minimum of its label",
+                        "name": "X-Client-Version",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client version, for browsers that cannot set X-Client-Version",
+                        "name": "client_version",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Banned, or not allowed to act as asUser",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "426": {
+                        "description": "Client upgrade required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_websocket_delivery_http.upgradeRequiredResp"
+                        }
+                    },
+                    "429": {
+                        "description": "Connection quota exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Connection setup timed out",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/ws/public": {
+            "get": {
+                "description": "Upgrade HTTP to WebSocket without authentication to follow a project listed in websocket.public.projects, e.g. from a status page. Only that project's messages and broadcasts are delivered.",
+                "tags": [
+                    "Notification"
+                ],
+                "summary": "Connect to a public project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Public project ID",
+                        "name": "project_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated message types to receive, e.g. project_progress,crisis_alert",
+                        "name": "types",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Least important messages to receive: low, normal, high or critical",
+                        "name": "min_importance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated payload fields to keep, e.g. status,progress",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Frontend build, e.g. status-page (letters, digits, . _ -; max 64)",
+                        "name": "client_label",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Banned",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Project is not public",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Anonymous connection limit reached",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Maximum connections reached",
+                        "schema": {
+                            "$ref": "#/definitions/notification-srv_pkg_errcode.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "internal_telemetry_delivery_http.latencySampleReq": {
+            "type": "object",
+            "properties": {
+                "message_id": {
+                    "type": "string"
+                },
+                "received_at": {
+                    "description": "Unix milliseconds on the client clock",
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_telemetry_delivery_http.reportLatencyReq": {
+            "type": "object",
+            "properties": {
+                "reports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_telemetry_delivery_http.latencySampleReq"
+                    }
+                }
+            }
+        },
+        "internal_telemetry_delivery_http.reportLatencyResp": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "integer"
+                },
+                "dropped": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_websocket_delivery_http.banReq": {
+            "type": "object",
+            "properties": {
+                "kind": {
+                    "description": "user or ip",
+                    "type": "string"
+                },
+                "ttl_seconds": {
+                    "description": "Ban duration",
+                    "type": "integer"
+                },
+                "value": {
+                    "description": "User ID, IP or CIDR range",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_websocket_delivery_http.banResp": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "value": {
+                    "description": "IPs are returned as CIDR ranges",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_websocket_delivery_http.broadcastReq": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "segment": {
+                    "$ref": "#/definitions/internal_websocket_delivery_http.segmentReq"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_websocket_delivery_http.broadcastResp": {
+            "type": "object",
+            "properties": {
+                "all": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "projects": {
+                    "description": "Projects the segment resolved to",
+                    "type": "integer"
+                },
+                "users": {
+                    "description": "Users the segment resolved to",
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_websocket_delivery_http.channelStatsResp": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string"
+                },
+                "delivered": {
+                    "type": "integer"
+                },
+                "dropped": {
+                    "type": "integer"
+                },
+                "no_recipients": {
+                    "type": "integer"
+                },
+                "received": {
+                    "type": "integer"
+                },
+                "transform_errors": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_websocket_delivery_http.checkContractReq": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "description": "Optional, e.g. project:{project_id}:user:{user_id}",
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "internal_websocket_delivery_http.checkContractResp": {
+            "type": "object",
+            "properties": {
+                "channel_type": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "message_type": {
+                    "type": "string"
+                },
+                "output": {
+                    "$ref": "#/definitions/notification-srv_internal_websocket.NotificationOutput"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "internal_websocket_delivery_http.connectionResp": {
+            "type": "object",
+            "properties": {
+                "client_label": {
+                    "type": "string"
+                },
+                "compression": {
+                    "type": "boolean"
+                },
+                "connected_at": {
+                    "type": "string"
+                },
+                "delegated_by": {
+                    "type": "string"
+                },
+                "delta": {
+                    "type": "boolean"
+                },
+                "drops": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "fields": {
+                    "description": "Empty sends full payloads",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "focus": {
+                    "type": "string"
+                },
+                "http2_fingerprint": {
+                    "type": "string"
+                },
+                "min_importance": {
+                    "description": "Empty receives all",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/notification-srv_internal_websocket.Importance"
+                        }
+                    ]
+                },
+                "project_id": {
+                    "type": "string"
+                },
+                "project_only": {
+                    "type": "boolean"
+                },
+                "public": {
+                    "type": "boolean"
+                },
+                "remote_ip": {
+                    "type": "string"
+                },
+                "rtt_ms": {
+                    "type": "number"
+                },
+                "send_queue_cap": {
+                    "type": "integer"
+                },
+                "send_queue_len": {
+                    "type": "integer"
+                },
+                "tls_fingerprint": {
+                    "type": "string"
+                },
+                "top_drop_reason": {
+                    "type": "string"
+                },
+                "types": {
+                    "description": "Empty receives all types",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/notification-srv_internal_websocket.MessageType"
+                    }
+                },
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_websocket_delivery_http.getChannelStatsResp": {
+            "type": "object",
+            "properties": {
+                "channels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_websocket_delivery_http.channelStatsResp"
+                    }
+                },
+                "window_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_websocket_delivery_http.getStatsHistoryResp": {
+            "type": "object",
+            "properties": {
+                "interval_seconds": {
+                    "type": "integer"
+                },
+                "samples": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_websocket_delivery_http.statsSampleResp"
+                    }
+                }
+            }
+        },
+        "internal_websocket_delivery_http.listConnectionsResp": {
+            "type": "object",
+            "properties": {
+                "connections": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_websocket_delivery_http.connectionResp"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_websocket_delivery_http.listProjectNotificationsResp": {
+            "type": "object",
+            "properties": {
+                "epoch": {
+                    "type": "string"
+                },
+                "frames": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "last_seq": {
+                    "type": "integer"
+                },
+                "truncated": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "internal_websocket_delivery_http.listUserTracesResp": {
+            "type": "object",
+            "properties": {
+                "traces": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_websocket_delivery_http.userTraceResp"
+                    }
+                }
+            }
+        },
+        "internal_websocket_delivery_http.maintenanceResp": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "since": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_websocket_delivery_http.protocolResp": {
+            "type": "object",
+            "properties": {
+                "ack": {
+                    "description": "Frames may carry ack_required",
+                    "type": "boolean"
+                },
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "encodings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "heartbeat_frames": {
+                    "type": "boolean"
+                },
+                "heartbeat_interval_ms": {
+                    "description": "Between pings",
+                    "type": "integer"
+                },
+                "max_message_size": {
+                    "description": "Largest client frame, in bytes",
+                    "type": "integer"
+                },
+                "resume": {
+                    "description": "Frames carry seq; fetch missed ones with after_seq",
+                    "type": "boolean"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_websocket_delivery_http.segmentReq": {
+            "type": "object",
+            "properties": {
+                "kind": {
+                    "description": "all, users, plan, org",
+                    "type": "string"
+                },
+                "user_ids": {
+                    "description": "Only for kind users",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "value": {
+                    "description": "Plan name or org ID",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_websocket_delivery_http.setMaintenanceReq": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "reason": {
+                    "description": "Shown to clients in the banner",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_websocket_delivery_http.statsSampleResp": {
+            "type": "object",
+            "properties": {
+                "active_connections": {
+                    "type": "integer"
+                },
+                "delivered": {
+                    "type": "integer"
+                },
+                "dropped": {
+                    "type": "integer"
+                },
+                "received": {
+                    "type": "integer"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "transform_errors": {
+                    "type": "integer"
+                },
+                "unique_users": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_websocket_delivery_http.traceUserReq": {
+            "type": "object",
+            "properties": {
+                "ttl_seconds": {
+                    "description": "Trace duration",
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_websocket_delivery_http.upgradeRequiredResp": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "$ref": "#/definitions/notification-srv_pkg_errcode.Category"
+                },
+                "client_version": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "correlation_id": {
+                    "description": "The X-Trace-Id of the request",
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "instance": {
+                    "description": "The request path",
+                    "type": "string"
+                },
+                "min_version": {
+                    "type": "string"
+                },
+                "retryable": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "description": "The HTTP status",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "The public message",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "TypePrefix + Code, stable per code",
+                    "type": "string"
+                },
+                "upgrade_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_websocket_delivery_http.userTraceResp": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "notification-srv_internal_websocket.Importance": {
+            "type": "string",
+            "enum": [
+                "low",
+                "normal",
+                "high",
+                "critical"
+            ],
+            "x-enum-comments": {
+                "ImportanceCritical": "Critical crisis alerts",
+                "ImportanceHigh": "Failures, warnings, system notices",
+                "ImportanceLow": "Progress ticks",
+                "ImportanceNormal": "Finished work, informational alerts"
+            },
+            "x-enum-descriptions": [
+                "Progress ticks",
+                "Finished work, informational alerts",
+                "Failures, warnings, system notices",
+                "Critical crisis alerts"
+            ],
+            "x-enum-varnames": [
+                "ImportanceLow",
+                "ImportanceNormal",
+                "ImportanceHigh",
+                "ImportanceCritical"
+            ]
+        },
+        "notification-srv_internal_websocket.MessageType": {
+            "type": "string",
+            "enum": [
+                "DATA_ONBOARDING",
+                "ANALYTICS_PIPELINE",
+                "CRISIS_ALERT",
+                "CAMPAIGN_EVENT",
+                "SYSTEM",
+                "HEARTBEAT",
+                "PROJECT_PROGRESS",
+                "SERVICE_ANNOUNCEMENT",
+                "SECURITY_ALERT",
+                "BILLING_EVENT",
+                "SYSTEM_MAINTENANCE"
+            ],
+            "x-enum-comments": {
+                "MessageTypeBillingEvent": "Invoice, payment or plan change",
+                "MessageTypeProjectProgress": "Snapshot pushed when a project-filtered connection opens",
+                "MessageTypeSecurityAlert": "Account security event, e.g. a new login",
+                "MessageTypeServiceAnnouncement": "Operator banner from control:announcement",
+                "MessageTypeSystemMaintenance": "Scheduled maintenance window"
+            },
+            "x-enum-descriptions": [
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "Snapshot pushed when a project-filtered connection opens",
+                "Operator banner from control:announcement",
+                "Account security event, e.g. a new login",
+                "Invoice, payment or plan change",
+                "Scheduled maintenance window"
+            ],
+            "x-enum-varnames": [
+                "MessageTypeDataOnboarding",
+                "MessageTypeAnalyticsPipeline",
+                "MessageTypeCrisisAlert",
+                "MessageTypeCampaignEvent",
+                "MessageTypeSystem",
+                "MessageTypeHeartbeat",
+                "MessageTypeProjectProgress",
+                "MessageTypeServiceAnnouncement",
+                "MessageTypeSecurityAlert",
+                "MessageTypeBillingEvent",
+                "MessageTypeSystemMaintenance"
+            ]
+        },
+        "notification-srv_internal_websocket.NotificationOutput": {
+            "type": "object",
+            "properties": {
+                "ack_required": {
+                    "description": "Set on at-least-once frames: the client must answer {\"action\": \"ack\", \"id\": ...}",
+                    "type": "boolean"
+                },
+                "collapse_key": {
+                    "type": "string"
+                },
+                "epoch": {
+                    "description": "Sequence epoch; sequences restart when it changes",
+                    "type": "string"
+                },
+                "group_key": {
+                    "description": "Notifications with the same group key belong together (e.g. project:{project_id});\none with the same collapse key replaces the previous one instead of stacking.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique message ID, echoed back by clients in latency reports",
+                    "type": "string"
+                },
+                "importance": {
+                    "description": "Importance of a message from a publisher, set by the transform from its type and status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/notification-srv_internal_websocket.Importance"
+                        }
+                    ]
+                },
+                "payload": {},
+                "region": {
+                    "description": "Upstream region the message came from (multi-region deployments)",
+                    "type": "string"
+                },
+                "seq": {
+                    "description": "Per-topic sequence number, contiguous for the receiving user",
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "topic": {
+                    "description": "Sequence topic, e.g. project:{project_id} (project/campaign channels only)",
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/notification-srv_internal_websocket.MessageType"
+                }
+            }
+        },
+        "notification-srv_pkg_errcode.Category": {
+            "type": "string",
+            "enum": [
+                "invalid",
+                "unauthenticated",
+                "forbidden",
+                "not_found",
+                "rate_limited",
+                "timeout",
+                "unavailable",
+                "storage",
+                "not_implemented",
+                "upgrade_required",
+                "internal"
+            ],
+            "x-enum-comments": {
+                "Forbidden": "Authenticated but not allowed",
+                "Invalid": "The request or message is malformed",
+                "NotImplemented": "The feature is not configured",
+                "RateLimited": "A quota or limit was reached",
+                "Storage": "Redis or another store failed",
+                "Timeout": "A deadline expired",
+                "Unauthenticated": "Missing or invalid credentials",
+                "Unavailable": "A dependency or capacity is exhausted",
+                "UpgradeRequired": "The client build is too old"
+            },
+            "x-enum-descriptions": [
+                "The request or message is malformed",
+                "Missing or invalid credentials",
+                "Authenticated but not allowed",
+                "",
+                "A quota or limit was reached",
+                "A deadline expired",
+                "A dependency or capacity is exhausted",
+                "Redis or another store failed",
+                "The feature is not configured",
+                "The client build is too old",
+                ""
+            ],
+            "x-enum-varnames": [
+                "Invalid",
+                "Unauthenticated",
+                "Forbidden",
+                "NotFound",
+                "RateLimited",
+                "Timeout",
+                "Unavailable",
+                "Storage",
+                "NotImplemented",
+                "UpgradeRequired",
+                "Internal"
+            ]
+        },
+        "notification-srv_pkg_errcode.Problem": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "$ref": "#/definitions/notification-srv_pkg_errcode.Category"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "correlation_id": {
+                    "description": "The X-Trace-Id of the request",
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "instance": {
+                    "description": "The request path",
+                    "type": "string"
+                },
+                "retryable": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "description": "The HTTP status",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "The public message",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "TypePrefix + Code, stable per code",
+                    "type": "string"
+                }
+            }
+        },
+        "response.Resp": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "error_code": {
+                    "type": "integer"
+                },
+                "errors": {},
+                "message": {
+                    "type": "string"
+                },
+                "trace_id": {
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "Bearer": {
+            "description": "Legacy Bearer token authentication (deprecated - use cookie authentication instead). Format: \"Bearer {token}\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        },
+        "CookieAuth": {
+            "description": "Authentication token stored in HttpOnly cookie. Set automatically by /login endpoint.",
+            "type": "apiKey",
+            "name": "smap_auth_token",
+            "in": "cookie"
+        },
+        "InternalKey": {
+            "description": "Shared service key for /internal/* while internal.allow_static_key is on. Signed requests send the X-Signature-* headers instead.",
+            "type": "apiKey",
+            "name": "X-Internal-Key",
+            "in": "header"
+        }
+    }
+}`
+
+// SwaggerInfo holds exported Swagger Info so clients can modify it
+var SwaggerInfo = &swag.Spec{
+	Version:          "1",
+	Host:             "localhost:8080",
+	BasePath:         "",
+	Schemes:          []string{"http"},
+	Title:            "SMAP Notification Service API",
+	Description:      "SMAP Notification Service API documentation.",
+	InfoInstanceName: "swagger",
+	SwaggerTemplate:  docTemplate,
+}
+
+func init() {
+	swag.Register(SwaggerInfo.InstanceName(), SwaggerInfo)
+}
@@ -1,8 +1,8 @@
 package alert
 
-import "errors"
+import "notification-srv/pkg/errcode"
 
 var (
-	ErrDispatchFailed = errors.New("failed to dispatch alert")
-	ErrInvalidInput   = errors.New("invalid alert input")
+	ErrDispatchFailed = errcode.NewRetryable("alert.dispatch_failed", errcode.Unavailable, "failed to dispatch alert", "Alert dispatch failed")
+	ErrInvalidInput   = errcode.New("alert.invalid_input", errcode.Invalid, "invalid alert input", "Invalid alert input")
 )
@@ -12,4 +12,19 @@ type UseCase interface {
 
 	// DispatchCampaignEvent sends updates about campaign lifecycle events.
 	DispatchCampaignEvent(ctx context.Context, input CampaignEventInput) error
+
+	// DispatchComponentRestart reports a background component that keeps crashing.
+	DispatchComponentRestart(ctx context.Context, input ComponentRestartInput) error
+
+	// DispatchSubscriberStall reports a Redis subscriber that has gone silent.
+	DispatchSubscriberStall(ctx context.Context, input SubscriberStallInput) error
+
+	// DispatchConnectionChurn reports a user or IP churning WebSocket connections.
+	DispatchConnectionChurn(ctx context.Context, input ConnectionChurnInput) error
+
+	// DispatchDecoyProject reports a subscription to a decoy project ID.
+	DispatchDecoyProject(ctx context.Context, input DecoyProjectInput) error
+
+	// DispatchCanaryFailure reports a canary whose end-to-end probes keep failing.
+	DispatchCanaryFailure(ctx context.Context, input CanaryFailureInput) error
 }
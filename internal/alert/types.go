@@ -42,3 +42,50 @@ type CampaignEventInput struct {
 	Message      string
 	Timestamp    time.Time
 }
+
+// ComponentRestartInput reports a background component stuck in a crash loop.
+type ComponentRestartInput struct {
+	Component string // e.g. "websocket-hub", "redis-subscriber"
+	Restarts  int    // Restarts within Window
+	Window    time.Duration
+	LastPanic string
+}
+
+// SubscriberStallInput reports a Redis subscriber that stopped receiving messages.
+type SubscriberStallInput struct {
+	Region        string // Upstream region; empty for single-region deployments
+	SilentFor     time.Duration
+	LastMessageAt time.Time // Zero if nothing was received since start
+	ActiveJobs    int64     // As last reported by the collector heartbeat
+	Reconnected   bool
+}
+
+// ConnectionChurnInput reports a user or IP opening WebSocket connections at an abusive rate.
+type ConnectionChurnInput struct {
+	Kind      string // "user" (connects of one user) or "ip" (distinct users from one IP)
+	Subject   string // User ID or IP
+	Count     int    // Observed within Window
+	Threshold int
+	Window    time.Duration
+	BannedFor time.Duration // Zero if the subject was not banned
+}
+
+// DecoyProjectInput reports a subscription to a never-issued project ID, a sign of project enumeration.
+type DecoyProjectInput struct {
+	ProjectID string
+	Pattern   string // Decoy pattern the project ID matched
+	UserID    string
+	RemoteIP  string
+	Transport string        // e.g. websocket or graphql
+	BannedFor time.Duration // Zero if the subscriber was not banned
+}
+
+// CanaryFailureInput reports end-to-end probes of the canary user that keep failing.
+type CanaryFailureInput struct {
+	InstanceID    string
+	Result        string        // Last probe: "slow", "lost" or "publish_failed"
+	Failures      int           // Consecutive failed probes
+	Latency       time.Duration // Of the last probe if it was slow; zero otherwise
+	Threshold     time.Duration
+	LastSuccessAt time.Time // Zero if no probe succeeded since start
+}
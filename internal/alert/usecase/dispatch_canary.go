@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"notification-srv/internal/alert"
+	"time"
+
+	"github.com/smap-hcmut/shared-libs/go/discord"
+)
+
+func (uc *implUseCase) DispatchCanaryFailure(ctx context.Context, input alert.CanaryFailureInput) error {
+	if uc.discord == nil {
+		return alert.ErrDispatchFailed
+	}
+
+	lastSuccess := "never"
+	if !input.LastSuccessAt.IsZero() {
+		lastSuccess = input.LastSuccessAt.Format(time.RFC3339)
+	}
+
+	fields := []discord.EmbedField{
+		buildField("Instance", input.InstanceID, true),
+		buildField("Last Probe", input.Result, true),
+		buildField("Failed Probes", fmt.Sprintf("%d in a row", input.Failures), true),
+		buildField("Last Success", lastSuccess, true),
+	}
+	if input.Latency > 0 {
+		fields = append(fields, buildField("Latency", fmt.Sprintf("%s (threshold %s)", input.Latency.Round(time.Millisecond), input.Threshold), true))
+	}
+
+	opts := discord.MessageOptions{
+		Type:        discord.MessageTypeError,
+		Title:       "Canary Probes Failing",
+		Description: "Probe messages published for the canary user are not reaching its loopback connection in time. Real users' notifications are likely delayed or lost.",
+		Fields:      fields,
+		Timestamp:   time.Now(),
+		Footer: &discord.EmbedFooter{
+			Text: "Notification Service • Canary",
+		},
+	}
+
+	return uc.discord.SendEmbed(ctx, opts)
+}
@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"notification-srv/internal/alert"
+	"time"
+
+	"github.com/smap-hcmut/shared-libs/go/discord"
+)
+
+func (uc *implUseCase) DispatchConnectionChurn(ctx context.Context, input alert.ConnectionChurnInput) error {
+	if uc.discord == nil {
+		return alert.ErrDispatchFailed
+	}
+
+	subject := "User"
+	observed := "Connects"
+	if input.Kind == "ip" {
+		subject = "IP"
+		observed = "Distinct Users"
+	}
+	ban := "none"
+	if input.BannedFor > 0 {
+		ban = input.BannedFor.String()
+	}
+
+	fields := []discord.EmbedField{
+		buildField(subject, input.Subject, true),
+		buildField(observed, fmt.Sprintf("%d in %s (threshold %d)", input.Count, input.Window, input.Threshold), true),
+		buildField("Ban", ban, true),
+	}
+
+	opts := discord.MessageOptions{
+		Type:        discord.MessageTypeWarning,
+		Title:       fmt.Sprintf("Connection Churn: %s %s", subject, input.Subject),
+		Description: "WebSocket connections are being opened at an abusive rate.",
+		Fields:      fields,
+		Timestamp:   time.Now(),
+		Footer: &discord.EmbedFooter{
+			Text: "Notification Service • Abuse Detection",
+		},
+	}
+
+	return uc.discord.SendEmbed(ctx, opts)
+}
@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"notification-srv/internal/alert"
+	"time"
+
+	"github.com/smap-hcmut/shared-libs/go/discord"
+)
+
+func (uc *implUseCase) DispatchComponentRestart(ctx context.Context, input alert.ComponentRestartInput) error {
+	if uc.discord == nil {
+		return alert.ErrDispatchFailed
+	}
+
+	fields := []discord.EmbedField{
+		buildField("Component", input.Component, true),
+		buildField("Restarts", fmt.Sprintf("%d in %s", input.Restarts, input.Window), true),
+		buildField("Last Panic", input.LastPanic, false),
+	}
+
+	opts := discord.MessageOptions{
+		Type:        discord.MessageTypeError,
+		Title:       fmt.Sprintf("Component Crash Loop: %s", input.Component),
+		Description: fmt.Sprintf("**%s** keeps panicking and being restarted. Notifications may be delayed or lost.", input.Component),
+		Fields:      fields,
+		Timestamp:   time.Now(),
+		Footer: &discord.EmbedFooter{
+			Text: "Notification Service • Supervisor",
+		},
+	}
+
+	return uc.discord.SendEmbed(ctx, opts)
+}
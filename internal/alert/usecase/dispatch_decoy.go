@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"notification-srv/internal/alert"
+	"time"
+
+	"github.com/smap-hcmut/shared-libs/go/discord"
+)
+
+func (uc *implUseCase) DispatchDecoyProject(ctx context.Context, input alert.DecoyProjectInput) error {
+	if uc.discord == nil {
+		return alert.ErrDispatchFailed
+	}
+
+	ban := "none"
+	if input.BannedFor > 0 {
+		ban = input.BannedFor.String()
+	}
+	remoteIP := input.RemoteIP
+	if remoteIP == "" {
+		remoteIP = "unknown"
+	}
+
+	fields := []discord.EmbedField{
+		buildField("User", input.UserID, true),
+		buildField("IP", remoteIP, true),
+		buildField("Transport", input.Transport, true),
+		buildField("Pattern", input.Pattern, true),
+		buildField("Ban", ban, true),
+	}
+
+	opts := discord.MessageOptions{
+		Type:        discord.MessageTypeError,
+		Title:       fmt.Sprintf("Decoy Project Subscribed: %s", input.ProjectID),
+		Description: "A never-issued project ID was subscribed to, which suggests project enumeration.",
+		Fields:      fields,
+		Timestamp:   time.Now(),
+		Footer: &discord.EmbedFooter{
+			Text: "Notification Service • Abuse Detection",
+		},
+	}
+
+	return uc.discord.SendEmbed(ctx, opts)
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"notification-srv/internal/alert"
+	"time"
+
+	"github.com/smap-hcmut/shared-libs/go/discord"
+)
+
+func (uc *implUseCase) DispatchSubscriberStall(ctx context.Context, input alert.SubscriberStallInput) error {
+	if uc.discord == nil {
+		return alert.ErrDispatchFailed
+	}
+
+	lastMessage := "never"
+	if !input.LastMessageAt.IsZero() {
+		lastMessage = input.LastMessageAt.Format(time.RFC3339)
+	}
+	reconnect := "failed"
+	if input.Reconnected {
+		reconnect = "succeeded"
+	}
+
+	fields := []discord.EmbedField{
+		buildField("Silent For", input.SilentFor.Round(time.Second).String(), true),
+		buildField("Last Message", lastMessage, true),
+		buildField("Active Jobs", fmt.Sprintf("%d", input.ActiveJobs), true),
+		buildField("Reconnect", reconnect, true),
+	}
+	if input.Region != "" {
+		fields = append([]discord.EmbedField{buildField("Region", input.Region, true)}, fields...)
+	}
+
+	opts := discord.MessageOptions{
+		Type:        discord.MessageTypeWarning,
+		Title:       "Redis Subscriber Stalled",
+		Description: "No Redis messages have arrived while traffic was expected. A pubsub reconnect was attempted.",
+		Fields:      fields,
+		Timestamp:   time.Now(),
+		Footer: &discord.EmbedFooter{
+			Text: "Notification Service • Watchdog",
+		},
+	}
+
+	return uc.discord.SendEmbed(ctx, opts)
+}
@@ -0,0 +1,32 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strings"
+)
+
+// Admin listener modes and auth (server.admin.*)
+const (
+	adminModeSeparate = "separate"
+	adminModeDisabled = "disabled"
+	adminAuthNone     = "none"
+)
+
+// adminUnixPrefix marks server.admin.addr as a unix socket path.
+const adminUnixPrefix = "unix:"
+
+// listenAdmin binds the admin listener: a unix socket, replacing one left
+// behind by a previous process, or a TCP address bound like the public port.
+func (srv *HTTPServer) listenAdmin(ctx context.Context) (net.Listener, error) {
+	path, ok := strings.CutPrefix(srv.adminListener.Addr, adminUnixPrefix)
+	if !ok {
+		return srv.listen(ctx, srv.adminListener.Addr)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,104 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+
+	"notification-srv/internal/metrics"
+	"notification-srv/pkg/breaker"
+
+	"github.com/smap-hcmut/shared-libs/go/discord"
+)
+
+// newBreaker creates the circuit breaker of an outbound dependency, or nil
+// (allowing every call) when breakers are disabled.
+func (srv *HTTPServer) newBreaker(dependency string) *breaker.Breaker {
+	if !srv.breakerConfig.Enabled {
+		return nil
+	}
+
+	metrics.BreakerState.WithLabelValues(dependency).Set(float64(breaker.StateClosed))
+	return breaker.New(breaker.Config{
+		Name:           dependency,
+		FailureRatio:   srv.breakerConfig.FailureRatio,
+		MinRequests:    srv.breakerConfig.MinRequests,
+		Interval:       srv.breakerConfig.Interval,
+		OpenTimeout:    srv.breakerConfig.OpenTimeout,
+		HalfOpenProbes: srv.breakerConfig.HalfOpenProbes,
+		OnStateChange: func(name string, from, to breaker.State) {
+			metrics.BreakerState.WithLabelValues(name).Set(float64(to))
+			metrics.BreakerTransitions.WithLabelValues(name, to.String()).Inc()
+			if to == breaker.StateOpen {
+				srv.logger.Warnf(context.Background(), "circuit breaker %s: %s -> %s, calls fail fast", name, from, to)
+			} else {
+				srv.logger.Infof(context.Background(), "circuit breaker %s: %s -> %s", name, from, to)
+			}
+		},
+	})
+}
+
+// breakerDiscord fails Discord calls fast while Discord keeps failing, so
+// alerts and panic reports don't each wait out the webhook's retries.
+type breakerDiscord struct {
+	discord.IDiscord
+	breaker *breaker.Breaker
+}
+
+func newBreakerDiscord(d discord.IDiscord, b *breaker.Breaker) discord.IDiscord {
+	if d == nil || b == nil {
+		return d
+	}
+	return &breakerDiscord{IDiscord: d, breaker: b}
+}
+
+// discordFailure reports whether err is Discord's fault rather than a message
+// rejected before it was sent.
+func discordFailure(err error) bool {
+	return !errors.Is(err, discord.ErrMessageTooLong) &&
+		!errors.Is(err, discord.ErrEmbedTooLong) &&
+		!errors.Is(err, discord.ErrTooManyFields)
+}
+
+func (d *breakerDiscord) do(fn func() error) error {
+	err := d.breaker.Do(fn, discordFailure)
+	if errors.Is(err, breaker.ErrOpen) {
+		metrics.BreakerRejected.WithLabelValues("discord").Inc()
+	}
+	return err
+}
+
+func (d *breakerDiscord) SendMessage(ctx context.Context, content string) error {
+	return d.do(func() error { return d.IDiscord.SendMessage(ctx, content) })
+}
+
+func (d *breakerDiscord) SendEmbed(ctx context.Context, options discord.MessageOptions) error {
+	return d.do(func() error { return d.IDiscord.SendEmbed(ctx, options) })
+}
+
+func (d *breakerDiscord) SendError(ctx context.Context, title, description string, err error) error {
+	return d.do(func() error { return d.IDiscord.SendError(ctx, title, description, err) })
+}
+
+func (d *breakerDiscord) SendSuccess(ctx context.Context, title, description string) error {
+	return d.do(func() error { return d.IDiscord.SendSuccess(ctx, title, description) })
+}
+
+func (d *breakerDiscord) SendWarning(ctx context.Context, title, description string) error {
+	return d.do(func() error { return d.IDiscord.SendWarning(ctx, title, description) })
+}
+
+func (d *breakerDiscord) SendInfo(ctx context.Context, title, description string) error {
+	return d.do(func() error { return d.IDiscord.SendInfo(ctx, title, description) })
+}
+
+func (d *breakerDiscord) ReportBug(ctx context.Context, message string) error {
+	return d.do(func() error { return d.IDiscord.ReportBug(ctx, message) })
+}
+
+func (d *breakerDiscord) SendNotification(ctx context.Context, title, description string, fields map[string]string) error {
+	return d.do(func() error { return d.IDiscord.SendNotification(ctx, title, description, fields) })
+}
+
+func (d *breakerDiscord) SendActivityLog(ctx context.Context, action, user, details string) error {
+	return d.do(func() error { return d.IDiscord.SendActivityLog(ctx, action, user, details) })
+}
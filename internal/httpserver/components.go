@@ -0,0 +1,163 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"notification-srv/internal/lifecycle"
+)
+
+// registerComponents registers the background services in start order.
+// Stop runs in reverse, so the HTTP server stops accepting connections before
+// the subscriber and Hub behind it go away. New subsystems only need a
+// Register call here.
+func (srv *HTTPServer) registerComponents() {
+	srv.lifecycle.Register(lifecycle.Component{
+		Name:   "redis",
+		Health: srv.redis.Ping,
+	})
+
+	// Sinks start before and stop after the Hub so the last deliveries are flushed
+	for _, s := range srv.sinks {
+		srv.lifecycle.Register(lifecycle.Component{
+			Name:  "sink-" + s.Name(),
+			Start: s.Start,
+			Stop:  s.Close,
+		})
+	}
+
+	srv.lifecycle.Register(lifecycle.Component{
+		Name: "websocket-hub",
+		Start: func(ctx context.Context) error {
+			go srv.supervisor.Run(context.Background(), "websocket-hub", srv.wsUC.Run)
+			return nil
+		},
+		Stop: srv.wsUC.Shutdown,
+	})
+
+	var stopSampler context.CancelFunc
+	srv.lifecycle.Register(lifecycle.Component{
+		Name: "stats-sampler",
+		Start: func(ctx context.Context) error {
+			var sampleCtx context.Context
+			sampleCtx, stopSampler = context.WithCancel(context.Background())
+			go srv.supervisor.Run(sampleCtx, "stats-sampler", func() { srv.wsUC.SampleStats(sampleCtx) })
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			stopSampler()
+			return nil
+		},
+	})
+
+	srv.lifecycle.Register(lifecycle.Component{
+		Name: "redis-subscriber",
+		Start: func(ctx context.Context) error {
+			return srv.wsSubscriber.Start()
+		},
+		Stop: srv.wsSubscriber.Shutdown,
+	})
+
+	httpSrv := &http.Server{
+		Addr:    fmt.Sprintf(":%d", srv.port),
+		Handler: srv.gin,
+	}
+	srv.lifecycle.Register(lifecycle.Component{
+		Name: "http",
+		Start: func(ctx context.Context) error {
+			// Listen synchronously so a busy port fails startup instead of a background log line
+			ln, err := srv.listen(ctx, httpSrv.Addr)
+			if err != nil {
+				return err
+			}
+			go func() {
+				if err := httpSrv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					srv.logger.Errorf(ctx, "HTTP server error: %v", err)
+				}
+			}()
+			srv.logger.Infof(ctx, "HTTP server started on port: %d", srv.port)
+			return nil
+		},
+		Stop: httpSrv.Shutdown,
+	})
+
+	if srv.internalGin != nil {
+		mtls := srv.internalConfig.MTLS
+		internalSrv := &http.Server{
+			Addr:    fmt.Sprintf(":%d", mtls.Port),
+			Handler: srv.internalGin,
+		}
+		srv.lifecycle.Register(lifecycle.Component{
+			Name: "internal-mtls",
+			Start: func(ctx context.Context) error {
+				certs, err := newCertReloader(srv.logger, mtls.CertFile, mtls.KeyFile, mtls.ClientCAFile)
+				if err != nil {
+					return err
+				}
+				ln, err := srv.listen(ctx, internalSrv.Addr)
+				if err != nil {
+					return err
+				}
+				go func() {
+					if err := internalSrv.Serve(tls.NewListener(ln, certs.tlsConfig())); err != nil && !errors.Is(err, http.ErrServerClosed) {
+						srv.logger.Errorf(ctx, "internal mTLS server error: %v", err)
+					}
+				}()
+				srv.logger.Infof(ctx, "internal mTLS server started on port: %d", mtls.Port)
+				return nil
+			},
+			Stop: internalSrv.Shutdown,
+		})
+	}
+
+	if srv.adminGin != nil {
+		adminSrv := &http.Server{Handler: srv.adminGin}
+		srv.lifecycle.Register(lifecycle.Component{
+			Name: "admin-http",
+			Start: func(ctx context.Context) error {
+				ln, err := srv.listenAdmin(ctx)
+				if err != nil {
+					return err
+				}
+				go func() {
+					if err := adminSrv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+						srv.logger.Errorf(ctx, "admin HTTP server error: %v", err)
+					}
+				}()
+				srv.logger.Infof(ctx, "admin HTTP server started on: %s", srv.adminListener.Addr)
+				return nil
+			},
+			Stop: adminSrv.Shutdown,
+		})
+	}
+
+	if srv.webTransport != nil {
+		srv.lifecycle.Register(lifecycle.Component{
+			Name:  "webtransport",
+			Start: srv.startWebTransport,
+			Stop:  srv.stopWebTransport,
+		})
+	}
+
+	// Last to start: the previous process drains only once this one accepts
+	if srv.handoffSocket != "" {
+		var handoffLn net.Listener
+		srv.lifecycle.Register(lifecycle.Component{
+			Name: "handoff",
+			Start: func(ctx context.Context) error {
+				ln, err := srv.startHandoff(ctx)
+				handoffLn = ln
+				return err
+			},
+			Stop: func(ctx context.Context) error {
+				// Already closed if a newer process took over
+				handoffLn.Close()
+				return nil
+			},
+		})
+	}
+}
@@ -2,13 +2,34 @@ package httpserver
 
 import (
 	"context"
+	"notification-srv/config"
+	"notification-srv/docs"
+	"notification-srv/internal/alert"
 	alertUC "notification-srv/internal/alert/usecase"
+	"notification-srv/internal/lifecycle"
+	"notification-srv/internal/metrics"
 	"notification-srv/internal/model"
+	schemaHTTP "notification-srv/internal/schema/delivery/http"
+	schemaUC "notification-srv/internal/schema/usecase"
+	telemetryHTTP "notification-srv/internal/telemetry/delivery/http"
+	telemetryUC "notification-srv/internal/telemetry/usecase"
+	ws "notification-srv/internal/websocket"
+	wsGraphQL "notification-srv/internal/websocket/delivery/graphql"
 	wsHTTP "notification-srv/internal/websocket/delivery/http"
 	wsRedis "notification-srv/internal/websocket/delivery/redis"
+	wsRepo "notification-srv/internal/websocket/repository/redis"
+	"notification-srv/internal/websocket/transport"
+	"notification-srv/internal/websocket/transport/coder"
+	"notification-srv/internal/websocket/transport/gorilla"
+	"notification-srv/internal/websocket/transport/netpoll"
 	wsUC "notification-srv/internal/websocket/usecase"
+	"strings"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/smap-hcmut/shared-libs/go/middleware"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
 // mapHandlers initializes and maps all HTTP routes
@@ -18,7 +39,7 @@ func (srv *HTTPServer) mapHandlers() error {
 		JWTManager:       srv.jwtMgr,
 		CookieName:       srv.cookieCfg.Name,
 		ProductionDomain: srv.cookieCfg.Domain,
-		InternalKey:      srv.internalKey,
+		InternalKey:      srv.internalConfig.InternalKey,
 		IsProduction:     srv.environment == string(model.EnvironmentProduction),
 	})
 
@@ -33,24 +54,234 @@ func (srv *HTTPServer) mapHandlers() error {
 	// 1. Alert (Reference Domain)
 	alertUseCase := alertUC.New(srv.logger, srv.discord)
 
-	// 2. WebSocket Domain
+	// Supervision of background loops; crash loops are reported through the alert domain
+	srv.supervisor = lifecycle.NewSupervisor(srv.logger, lifecycle.SupervisorConfig{
+		MaxRestartsPerMinute: srv.supervisorConfig.MaxRestartsPerMinute,
+		RestartBackoff:       srv.supervisorConfig.RestartBackoff,
+		OnCrashLoop: func(ctx context.Context, component string, restarts int, lastPanic string) {
+			if err := alertUseCase.DispatchComponentRestart(ctx, alert.ComponentRestartInput{
+				Component: component,
+				Restarts:  restarts,
+				Window:    time.Minute,
+				LastPanic: lastPanic,
+			}); err != nil {
+				srv.logger.Warnf(ctx, "crash loop alert dispatch failed: %v", err)
+			}
+		},
+	})
+
+	// 2. Telemetry Domain
+	telemetryUseCase := telemetryUC.New(srv.logger, telemetryUC.Config{
+		EmitTTL:          srv.telemetryConfig.EmitTTL,
+		MaxEmitRecords:   srv.telemetryConfig.MaxEmitRecords,
+		MaxReportSamples: srv.telemetryConfig.MaxReportSamples,
+	})
+	telemetryHandler := telemetryHTTP.New(srv.logger, telemetryUseCase)
+
+	// Outbound sinks
+	if err := srv.initSinks(); err != nil {
+		return err
+	}
+
+	// 3. WebSocket Domain
+	// Repository
+	wsRepoConfig := wsRepo.Config{
+		StateKeyPattern:    srv.backfillConfig.StateKeyPattern,
+		LatestKeyPattern:   srv.latestStateConfig.KeyPattern,
+		PresenceKeyPattern: srv.presenceConfig.KeyPattern,
+		OfflineKeyPattern:  srv.deliveryConfig.OfflineKeyPattern,
+		ChannelPrefix:      srv.channelPrefix,
+
+		PlanKeyPattern: srv.segmentsConfig.PlanKeyPattern,
+		OrgKeyPattern:  srv.segmentsConfig.OrgKeyPattern,
+		BanKeyPattern:  srv.bansConfig.KeyPattern,
+
+		DelegateKeyPattern: srv.delegationConfig.KeyPattern,
+	}
+	wsRepository := wsRepo.New(srv.redis, wsRepoConfig)
+
 	// UseCase
-	srv.wsUC = wsUC.New(srv.logger, srv.wsConfig.MaxConnections, alertUseCase)
+	srv.wsUC = wsUC.New(srv.logger, wsUC.Config{
+		MaxConnections: srv.wsConfig.MaxConnections,
+		Heartbeat:      srv.wsConfig.Heartbeat,
+		Protocol: wsUC.ProtocolConfig{
+			Hello:       srv.wsConfig.Hello,
+			Compression: srv.wsConfig.EnableCompression,
+		},
+
+		MaxProtocolViolations: srv.wsConfig.MaxProtocolViolations,
+		Validation:            wsUC.ValidationMode(srv.transformConfig.Validation),
+		DisabledTypes:         disabledTypes(srv.transformConfig),
+		Transitions: wsUC.TransitionConfig{
+			Mode:      wsUC.TransitionMode(srv.transformConfig.TransitionsMode),
+			MaxTopics: srv.transformConfig.TransitionsMaxTopics,
+			Allowed:   transitionGraphs(srv.transformConfig.TransitionsAllowed),
+		},
+		TerminalDedupe: wsUC.TerminalDedupeConfig{
+			Window:    srv.transformConfig.TerminalDedupeWindow,
+			MaxTopics: srv.transformConfig.TerminalDedupeMaxTopics,
+		},
+		Sanitize: wsUC.SanitizeConfig{
+			Enabled: srv.transformConfig.SanitizeEnabled,
+			Fields:  sanitizeRules(srv.transformConfig.SanitizeFields),
+		},
+		Shadow: wsUC.ShadowConfig{
+			Version: srv.transformConfig.ShadowVersion,
+			Percent: srv.transformConfig.ShadowPercent,
+		},
+		Backfill: wsUC.BackfillConfig{
+			Enabled:    srv.backfillConfig.Enabled,
+			UserScoped: strings.Contains(srv.backfillConfig.StateKeyPattern, "{user_id}"),
+			Timeout:    srv.backfillConfig.Timeout,
+		},
+		Sequence: wsUC.SequenceConfig{
+			BufferSize: srv.wsConfig.ReplayBufferSize,
+			MaxTopics:  srv.wsConfig.ReplayMaxTopics,
+		},
+		StatsWindow:        srv.wsConfig.StatsWindow,
+		TopicGCGrace:       srv.wsConfig.TopicGCGrace,
+		DeltaSnapshotEvery: srv.wsConfig.DeltaSnapshotEvery,
+		Sinks:              srv.sinks,
+		StatsHistory: wsUC.StatsHistoryConfig{
+			Interval:  srv.wsConfig.StatsHistoryInterval,
+			Retention: srv.wsConfig.StatsHistoryRetention,
+		},
+		LatestState: wsUC.LatestStateConfig{
+			Enabled: srv.latestStateConfig.Enabled,
+			TTL:     srv.latestStateConfig.TTL,
+		},
+		Delivery: deliveryConfig(srv.deliveryConfig),
+		UserTrace: wsUC.UserTraceConfig{
+			MaxTTL:   srv.userTraceConfig.MaxTTL,
+			MaxUsers: srv.userTraceConfig.MaxUsers,
+		},
+		Canary: wsUC.CanaryConfig{
+			Enabled:         srv.canaryConfig.Enabled,
+			UserID:          srv.canaryConfig.UserID,
+			ProjectID:       srv.canaryConfig.ProjectID,
+			InstanceID:      srv.probeConfig.InstanceID,
+			Interval:        srv.canaryConfig.Interval,
+			Timeout:         srv.canaryConfig.Timeout,
+			Threshold:       srv.canaryConfig.LatencyThreshold,
+			FailuresToAlert: srv.canaryConfig.FailuresToAlert,
+		},
+		Presence: wsUC.PresenceConfig{
+			Enabled: srv.presenceConfig.Enabled,
+			TTL:     srv.presenceConfig.TTL,
+		},
+		Coalesce: wsUC.CoalesceConfig{
+			Enabled:     srv.wsConfig.CoalesceEnabled,
+			MinInterval: srv.wsConfig.CoalesceMinInterval,
+			MaxInterval: srv.wsConfig.CoalesceMaxInterval,
+		},
+		Churn: wsUC.ChurnConfig{
+			Enabled:         srv.wsConfig.ChurnEnabled,
+			Window:          srv.wsConfig.ChurnWindow,
+			MaxUserConnects: srv.wsConfig.ChurnMaxUserConnects,
+			MaxIPUsers:      srv.wsConfig.ChurnMaxIPUsers,
+			BanTTL:          srv.wsConfig.ChurnBanTTL,
+		},
+		Decoy: wsUC.DecoyConfig{
+			Patterns: srv.wsConfig.DecoyPatterns,
+			BanTTL:   srv.wsConfig.DecoyBanTTL,
+			BanIP:    srv.wsConfig.DecoyBanIP,
+		},
+		Public: publicConfig(srv.wsConfig),
+		Backpressure: wsUC.BackpressureConfig{
+			Window:      srv.wsConfig.BackpressureWindow,
+			MinDropRate: srv.wsConfig.BackpressureMinDropRate,
+			MinFrames:   srv.wsConfig.BackpressureMinFrames,
+		},
+		Segments:   wsRepo.NewSegmentResolver(srv.redis, wsRepoConfig),
+		Delegation: srv.delegationAuthorizer(wsRepoConfig),
+		Quota: wsUC.QuotaConfig{
+			Provider:       wsUC.NewClaimQuotas(srv.wsConfig.QuotaClaim, srv.wsConfig.QuotaByClaim),
+			DefaultPerUser: srv.wsConfig.QuotaDefaultPerUser,
+			Policy:         wsUC.QuotaPolicy(srv.wsConfig.QuotaPolicy),
+		},
+		SendQueue: wsUC.SendQueueConfig{
+			Size:       srv.wsConfig.SendQueueSize,
+			Policy:     wsUC.SendQueuePolicy(srv.wsConfig.SendQueuePolicy),
+			ClassSizes: srv.wsConfig.SendQueueClassSizes,
+		},
+		ClientLabels: srv.wsConfig.ClientLabels,
+	}, alertUseCase, telemetryUseCase, wsRepository)
 
-	// Delivery: Redis Subscriber
-	srv.wsSubscriber = wsRedis.New(srv.redis, srv.wsUC, srv.logger)
+	// 4. Schema Domain (message contracts)
+	schemaHandler := schemaHTTP.New(srv.logger, schemaUC.New(srv.logger))
+
+	// Delivery: Redis Subscriber, merging the local region with any remote ones
+	upstreams := append([]wsRedis.Upstream{{
+		Region:        srv.region,
+		Redis:         srv.redis,
+		ChannelPrefix: srv.channelPrefix,
+	}}, srv.remoteRegions...)
+	srv.wsSubscriber = wsRedis.NewMultiRegion(upstreams, srv.wsUC, srv.logger, srv.supervisor, wsRedis.Config{
+		Watchdog: wsRedis.WatchdogConfig{
+			Enabled:          srv.watchdogConfig.Enabled,
+			StallWindow:      srv.watchdogConfig.StallWindow,
+			CheckInterval:    srv.watchdogConfig.CheckInterval,
+			HeartbeatChannel: srv.watchdogConfig.HeartbeatChannel,
+			OnStall: func(ctx context.Context, info wsRedis.StallInfo) {
+				if err := alertUseCase.DispatchSubscriberStall(ctx, alert.SubscriberStallInput{
+					Region:        info.Region,
+					SilentFor:     info.SilentFor,
+					LastMessageAt: info.LastMessageAt,
+					ActiveJobs:    info.ActiveJobs,
+					Reconnected:   info.Reconnected,
+				}); err != nil {
+					srv.logger.Warnf(ctx, "stall alert dispatch failed: %v", err)
+				}
+			},
+		},
+		Probe: wsRedis.ProbeConfig{
+			Enabled:    srv.probeConfig.Enabled,
+			Interval:   srv.probeConfig.Interval,
+			InstanceID: srv.probeConfig.InstanceID,
+		},
+		RateLimit: wsRedis.RateLimitConfig{
+			ChannelRate: wsRedis.ChannelRate{Rate: srv.rateLimitConfig.Rate, Burst: srv.rateLimitConfig.Burst},
+			Prefixes:    channelRates(srv.rateLimitConfig.Prefixes),
+			Mode:        wsRedis.RateLimitMode(srv.rateLimitConfig.Mode),
+			MaxChannels: srv.rateLimitConfig.MaxChannels,
+
+			DLQKey:        srv.rateLimitConfig.DLQKey,
+			DLQSampleRate: srv.rateLimitConfig.DLQSampleRate,
+			DLQMaxLen:     int64(srv.rateLimitConfig.DLQMaxLen),
+		},
+	})
 	// Subscriber start is handled in Run()
 
 	// Delivery: HTTP Handler
+	upgrader := newUpgrader(srv.wsConfig.Transport)
 	wsHandler := wsHTTP.New(
 		srv.wsUC,
 		srv.jwtMgr, // No assertion needed, srv.jwtMgr is auth.Manager
 		srv.logger,
 		wsHTTP.WSConfig{
+			Path:            srv.wsPath,
 			MaxConnections:  srv.wsConfig.MaxConnections,
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
 			AllowedOrigins:  []string{"*"},
+			Transport:       upgrader,
+
+			EnableCompression:   srv.wsConfig.EnableCompression,
+			CompressionDenylist: srv.wsConfig.CompressionUADenylist,
+
+			SubTokens:    srv.subTokens,
+			WebTransport: srv.webTransport,
+
+			TLSFingerprintHeader:   srv.wsConfig.TLSFingerprintHeader,
+			HTTP2FingerprintHeader: srv.wsConfig.HTTP2FingerprintHeader,
+
+			MinClientVersions: srv.wsConfig.MinClientVersions,
+			UpgradeURL:        srv.wsConfig.UpgradeURL,
+			CloseOutdated:     srv.wsConfig.CloseOutdatedClients,
+
+			AuthTimeout:     srv.wsConfig.AuthTimeout,
+			UpgradeTimeout:  srv.wsConfig.UpgradeTimeout,
+			RegisterTimeout: srv.wsConfig.RegisterTimeout,
 		},
 		wsHTTP.CookieConfig{
 			Name:     srv.cookieCfg.Name,
@@ -64,18 +295,61 @@ func (srv *HTTPServer) mapHandlers() error {
 	)
 
 	// Register Routes
-	// WebSocket is registered at root level (not under api/v1) because
-	// Traefik strips /notification prefix → client calls /notification/ws → service receives /ws
-	wsHandler.RegisterRoutes(srv.gin.Group(""), mw)
+	// Routes are mounted under server.base_path, empty by default: behind an
+	// ingress that strips the prefix (Traefik: /notification/ws → /ws) none is
+	// needed, while a path-routed ingress without rewrites forwards it as is.
+	// The admin and mTLS listeners are not behind the ingress.
+	wsHandler.RegisterRoutes(srv.gin.Group(srv.basePath), mw)
+	wsHandler.RegisterAPIRoutes(srv.gin.Group(srv.basePath), mw)
+	switch srv.adminListener.Mode {
+	case adminModeSeparate:
+		var auth []gin.HandlerFunc
+		if srv.adminListener.Auth != adminAuthNone {
+			auth = []gin.HandlerFunc{mw.Auth(), mw.AdminOnly()}
+		}
+		wsHandler.RegisterAdminRoutes(srv.adminGin.Group(""), auth...)
+	case adminModeDisabled:
+	default:
+		wsHandler.RegisterAdminRoutes(srv.gin.Group(srv.basePath), mw.Auth(), mw.AdminOnly())
+	}
+	switch {
+	case srv.internalGin != nil:
+		wsHandler.RegisterInternalRoutes(srv.internalGin.Group(""), srv.mtlsAuth())
+	case srv.adminGin != nil:
+		wsHandler.RegisterInternalRoutes(srv.adminGin.Group(""), srv.internalAuth(mw))
+	default:
+		wsHandler.RegisterInternalRoutes(srv.gin.Group(srv.basePath), srv.internalAuth(mw))
+	}
+	telemetryHandler.RegisterRoutes(srv.gin.Group(srv.basePath), mw)
+	schemaHandler.RegisterRoutes(srv.gin.Group(srv.basePath))
+
+	// GraphQL gateway over the same Hub registration and authorizer
+	if srv.graphqlConfig.Enabled {
+		gqlHandler, err := wsGraphQL.New(
+			srv.wsUC,
+			srv.jwtMgr,
+			srv.logger,
+			wsGraphQL.Config{InitTimeout: srv.graphqlConfig.InitTimeout, Transport: upgrader},
+			wsGraphQL.CookieConfig{Name: srv.cookieCfg.Name},
+		)
+		if err != nil {
+			return err
+		}
+		gqlHandler.RegisterRoutes(srv.gin.Group(srv.basePath), mw)
+	}
+
+	// WebTransport over the same Hub registration and upgrade auth; the HTTP/3
+	// listener is reached directly, not through the ingress
+	if srv.wtGin != nil {
+		wsHandler.RegisterWebTransportRoutes(srv.wtGin.Group(""))
+	}
 
 	return nil
 }
 
-// registerMiddlewares registers global middlewares
+// registerMiddlewares registers the middlewares of the public routes on top
+// of the stack installed by useMiddleware.
 func (srv *HTTPServer) registerMiddlewares() {
-	srv.gin.Use(middleware.Tracing())
-	srv.gin.Use(middleware.Recovery(srv.logger, srv.discord))
-
 	// CORS configuration based on environment
 	corsConfig := middleware.DefaultCORSConfig(srv.environment)
 	srv.gin.Use(middleware.CORS(corsConfig))
@@ -89,9 +363,122 @@ func (srv *HTTPServer) registerMiddlewares() {
 	}
 }
 
-// registerSystemRoutes registers health check and monitoring routes
+// registerSystemRoutes registers health check and monitoring routes. Probes
+// and scrapers reach the pod directly, so they stay at the root; the health
+// checks are also mounted under the base path for the ingress.
 func (srv *HTTPServer) registerSystemRoutes() {
 	srv.gin.GET("/health", srv.healthCheck)
 	srv.gin.GET("/ready", srv.readyCheck)
 	srv.gin.GET("/live", srv.liveCheck)
+	srv.gin.GET("/metrics", metrics.Handler())
+
+	if srv.basePath != "" {
+		base := srv.gin.Group(srv.basePath)
+		base.GET("/health", srv.healthCheck)
+		base.GET("/ready", srv.readyCheck)
+		base.GET("/live", srv.liveCheck)
+	}
+
+	// API docs generated from the handler annotations by `make swagger`
+	if srv.environment != string(model.EnvironmentProduction) {
+		docs.SwaggerInfo.BasePath = srv.basePath
+		srv.gin.Group(srv.basePath).GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	}
+}
+
+// transitionGraphs keys the configured status transitions by message type.
+func transitionGraphs(allowed map[string]map[string][]string) map[ws.MessageType]map[string][]string {
+	graphs := make(map[ws.MessageType]map[string][]string, len(allowed))
+	for msgType, graph := range allowed {
+		graphs[ws.MessageType(strings.ToUpper(msgType))] = graph
+	}
+	return graphs
+}
+
+// newUpgrader returns the WebSocket library selected by websocket.transport.
+func newUpgrader(library string) transport.Upgrader {
+	switch library {
+	case transport.LibraryCoder:
+		return coder.New()
+	case transport.LibraryNetpoll:
+		return netpoll.New()
+	}
+	return gorilla.New()
+}
+
+// channelRates converts the configured rate limits by channel prefix.
+func channelRates(prefixes map[string]config.ChannelRateConfig) map[string]wsRedis.ChannelRate {
+	rates := make(map[string]wsRedis.ChannelRate, len(prefixes))
+	for prefix, r := range prefixes {
+		rates[prefix] = wsRedis.ChannelRate{Rate: r.Rate, Burst: r.Burst}
+	}
+	return rates
+}
+
+// sanitizeRules converts the configured sanitization rules by field name.
+func sanitizeRules(fields map[string]config.SanitizeFieldConfig) map[string]wsUC.SanitizeRule {
+	rules := make(map[string]wsUC.SanitizeRule, len(fields))
+	for field, f := range fields {
+		rules[field] = wsUC.SanitizeRule{MaxLength: f.MaxLength, EscapeHTML: f.EscapeHTML}
+	}
+	return rules
+}
+
+// publicConfig converts the public projects, none unless they are enabled.
+func publicConfig(cfg config.WebSocketConfig) wsUC.PublicConfig {
+	if !cfg.PublicEnabled {
+		return wsUC.PublicConfig{}
+	}
+	projects := make(map[string]string, len(cfg.PublicProjects))
+	for _, p := range cfg.PublicProjects {
+		projects[p.ProjectID] = p.OwnerUserID
+	}
+	return wsUC.PublicConfig{
+		Projects:       projects,
+		MaxConnections: cfg.PublicMaxConnections,
+		MaxPerIP:       cfg.PublicMaxPerIP,
+		ConnectRate:    cfg.PublicConnectRate,
+		ConnectBurst:   cfg.PublicConnectBurst,
+	}
+}
+
+// delegationAuthorizer returns the Redis delegate sets, or nil unless
+// delegated access is enabled.
+func (srv *HTTPServer) delegationAuthorizer(repoCfg wsRepo.Config) ws.DelegationAuthorizer {
+	if !srv.delegationConfig.Enabled {
+		return nil
+	}
+	return wsRepo.NewDelegationAuthorizer(srv.redis, repoCfg)
+}
+
+// deliveryConfig converts the delivery guarantees, keying the policies by
+// message type.
+func deliveryConfig(cfg config.DeliveryConfig) wsUC.DeliveryConfig {
+	policies := make(map[ws.MessageType]ws.DeliveryPolicy, len(cfg.Policies))
+	for msgType, policy := range cfg.Policies {
+		policies[ws.MessageType(strings.ToUpper(msgType))] = ws.DeliveryPolicy(policy)
+	}
+	return wsUC.DeliveryConfig{
+		Enabled:          cfg.Enabled,
+		Policies:         policies,
+		AckTimeout:       cfg.AckTimeout,
+		MaxRetries:       cfg.MaxRetries,
+		OfflineMaxFrames: cfg.OfflineMaxFrames,
+		OfflineTTL:       cfg.OfflineTTL,
+	}
+}
+
+// disabledTypes lists the message types switched off in the transform config.
+func disabledTypes(cfg config.TransformConfig) map[ws.MessageType]bool {
+	disabled := make(map[ws.MessageType]bool)
+	for msgType, enabled := range map[ws.MessageType]bool{
+		ws.MessageTypeSecurityAlert:     cfg.SecurityAlertEnabled,
+		ws.MessageTypeBillingEvent:      cfg.BillingEventEnabled,
+		ws.MessageTypeSystemMaintenance: cfg.SystemMaintenanceEnabled,
+	} {
+		if !enabled {
+			disabled[msgType] = true
+		}
+	}
+	return disabled
 }
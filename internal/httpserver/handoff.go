@@ -0,0 +1,100 @@
+package httpserver
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// Handoff protocol, one line each way on the unix socket: the new process sends
+// "takeover", the old one closes its socket, answers "ok" and starts draining.
+const (
+	handoffRequest = "takeover"
+	handoffAck     = "ok"
+
+	handoffTimeout = 5 * time.Second
+)
+
+// listen binds the HTTP port, with SO_REUSEPORT when zero-downtime restarts
+// are enabled.
+func (srv *HTTPServer) listen(ctx context.Context, addr string) (net.Listener, error) {
+	lc := net.ListenConfig{}
+	if srv.reusePort {
+		lc.Control = reusePortControl
+	}
+	return lc.Listen(ctx, "tcp", addr)
+}
+
+// startHandoff asks the process currently holding the handoff socket (if any)
+// to drain, then takes the socket over. Called once this process accepts on the
+// HTTP port, so no upgrade is refused in between.
+func (srv *HTTPServer) startHandoff(ctx context.Context) (net.Listener, error) {
+	if err := requestTakeover(srv.handoffSocket); err != nil {
+		srv.logger.Warnf(ctx, "handoff: no previous process drained: %v", err)
+	} else {
+		srv.logger.Infof(ctx, "handoff: previous process is draining")
+	}
+
+	// The socket file is left behind by the previous process (or a crashed one)
+	if err := os.Remove(srv.handoffSocket); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", srv.handoffSocket)
+	if err != nil {
+		return nil, err
+	}
+	go srv.serveHandoff(ctx, ln)
+	return ln, nil
+}
+
+// requestTakeover sends the takeover request and waits for the acknowledgement.
+func requestTakeover(path string) error {
+	conn, err := net.DialTimeout("unix", path, handoffTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(handoffTimeout))
+
+	if _, err := conn.Write([]byte(handoffRequest + "\n")); err != nil {
+		return err
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(line) != handoffAck {
+		return errors.New("unexpected handoff reply: " + line)
+	}
+	return nil
+}
+
+// serveHandoff waits for a newer process and then signals Run to shut down.
+func (srv *HTTPServer) serveHandoff(ctx context.Context, ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return // Listener closed on shutdown
+		}
+
+		conn.SetDeadline(time.Now().Add(handoffTimeout))
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil || strings.TrimSpace(line) != handoffRequest {
+			conn.Close()
+			continue
+		}
+
+		// Close first so the new process can bind the socket path right after the ack
+		ln.Close()
+		conn.Write([]byte(handoffAck + "\n"))
+		conn.Close()
+
+		srv.logger.Infof(ctx, "handoff: newer process took over, draining")
+		close(srv.takeover)
+		return
+	}
+}
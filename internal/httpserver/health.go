@@ -2,12 +2,19 @@ package httpserver
 
 import (
 	"notification-srv/internal/websocket"
+	"notification-srv/pkg/errcode"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/smap-hcmut/shared-libs/go/errors"
 	"github.com/smap-hcmut/shared-libs/go/response"
 )
 
+var (
+	errComponentUnhealthy = errcode.New("health.component_failed", errcode.Internal, "component health check failed", "Component health check failed")
+	errRedisUnavailable   = errcode.New("health.redis_unavailable", errcode.Internal, "redis connection not available", "Redis connection not available")
+	errProbeFailing       = errcode.New("health.probe_failing", errcode.Internal, "redis subscription probe failing", "Redis subscription probe failing")
+)
+
 // healthCheck handles health check requests
 // @Summary Health Check
 // @Description Check if the WebSocket service is healthy
@@ -19,9 +26,12 @@ import (
 func (srv *HTTPServer) healthCheck(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	// Check Redis connection
-	if err := srv.redis.Ping(ctx); err != nil {
-		response.Error(c, errors.NewInternalServerError("Redis connection failed"))
+	// Check every registered component (Redis, subscriber, ...)
+	if failures := srv.lifecycle.Health(ctx); len(failures) > 0 {
+		for name, err := range failures {
+			srv.logger.Warnf(ctx, "health check failed: component=%s err=%v", name, err)
+		}
+		errcode.Respond(c, errComponentUnhealthy)
 		return
 	}
 
@@ -41,9 +51,27 @@ func (srv *HTTPServer) healthCheck(c *gin.Context) {
 		"active_connections": hubStats.ActiveConnections,
 		"total_unique_users": hubStats.TotalUniqueUsers,
 		"redis":              "connected",
+		"components":         srv.lifecycle.Names(),
+		"last_message_at":    srv.wsSubscriber.HealthInfo().LastMessageAt,
+		"regions":            srv.regionHealth(),
 	})
 }
 
+// regionHealth summarizes each Redis upstream for /health, primary region first.
+func (srv *HTTPServer) regionHealth() []gin.H {
+	upstreams := srv.wsSubscriber.Upstreams()
+	regions := make([]gin.H, len(upstreams))
+	for i, info := range upstreams {
+		regions[i] = gin.H{
+			"region":           info.Region,
+			"last_message_at":  info.LastMessageAt,
+			"probe_healthy":    info.ProbeHealthy,
+			"probe_latency_ms": float64(info.ProbeLatency) / float64(time.Millisecond),
+		}
+	}
+	return regions
+}
+
 // readyCheck handles readiness check requests
 // @Summary Readiness Check
 // @Description Check if the WebSocket service is ready to serve traffic
@@ -51,23 +79,32 @@ func (srv *HTTPServer) healthCheck(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Success 200 {object} map[string]interface{} "Service is ready"
-// @Failure 503 {object} map[string]interface{} "Service is not ready"
+// @Failure 500 {object} errcode.Problem "Service is not ready"
 // @Router /ready [get]
 func (srv *HTTPServer) readyCheck(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	// Check if Redis is ready
 	if err := srv.redis.Ping(ctx); err != nil {
-		response.Error(c, errors.NewInternalServerError("Redis connection not available"))
+		errcode.Respond(c, errRedisUnavailable)
+		return
+	}
+
+	// Check the subscription itself: a live connection can still have a broken pubsub
+	info := srv.wsSubscriber.HealthInfo()
+	if !info.ProbeHealthy {
+		errcode.Respond(c, errProbeFailing)
 		return
 	}
 
 	response.OK(c, gin.H{
-		"status":  "ready",
-		"message": "From SMAP Notification Service With Love",
-		"version": "1.0.0",
-		"service": "notification-srv",
-		"redis":   "connected",
+		"status":           "ready",
+		"message":          "From SMAP Notification Service With Love",
+		"version":          "1.0.0",
+		"service":          "notification-srv",
+		"redis":            "connected",
+		"probe_latency_ms": float64(info.ProbeLatency) / float64(time.Millisecond),
+		"last_probe_at":    info.LastProbeAt,
 	})
 }
 
@@ -2,18 +2,21 @@ package httpserver
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
+// shutdownTimeout bounds how long components get to stop after a shutdown signal.
+const shutdownTimeout = 30 * time.Second
+
 // Run starts the HTTP server and all background services, then blocks until shutdown signal.
 // This method manages the complete lifecycle of the WebSocket service:
 //  1. Map HTTP handlers and routes (Initialize wiring)
-//  2. Start WebSocket UseCase (Hub)
-//  3. Start HTTP server
-//  4. Wait for shutdown signal
+//  2. Start components in order (Hub, Redis Subscriber, HTTP server)
+//  3. Wait for shutdown signal (or a handoff to a newer process)
+//  4. Stop components in reverse order
 func (srv *HTTPServer) Run() error {
 	ctx := context.Background()
 
@@ -23,38 +26,28 @@ func (srv *HTTPServer) Run() error {
 		return err
 	}
 
-	// 2. Start WebSocket background services
-	// Start UseCase (Hub)
-	go srv.wsUC.Run()
-	srv.logger.Info(ctx, "WebSocket UseCase background service started")
-
-	// Start Redis Subscriber
-	if err := srv.wsSubscriber.Start(); err != nil {
-		srv.logger.Fatalf(ctx, "Failed to start Redis subscriber: %v", err)
+	// 2. Start components
+	srv.registerComponents()
+	if err := srv.lifecycle.Start(ctx); err != nil {
+		srv.logger.Errorf(ctx, "Failed to start components: %v", err)
 		return err
 	}
 
-	// 3. Start HTTP server in background
-	go func() {
-		if err := srv.gin.Run(fmt.Sprintf(":%d", srv.port)); err != nil {
-			srv.logger.Errorf(ctx, "HTTP server error: %v", err)
-		}
-	}()
-
-	srv.logger.Infof(ctx, "HTTP server started on port: %d", srv.port)
-
-	// 4. Wait for shutdown signal
+	// 3. Wait for shutdown signal, or a newer process taking over
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
-	srv.logger.Info(ctx, <-ch)
+	select {
+	case sig := <-ch:
+		srv.logger.Info(ctx, sig)
+	case <-srv.takeover:
+	}
 	srv.logger.Info(ctx, "Stopping WebSocket service...")
 
-	// Graceful shutdown
-	if err := srv.wsUC.Shutdown(ctx); err != nil {
-		srv.logger.Errorf(ctx, "WebSocket UseCase shutdown error: %v", err)
-	}
-	if err := srv.wsSubscriber.Shutdown(ctx); err != nil {
-		srv.logger.Errorf(ctx, "Redis Subscriber shutdown error: %v", err)
+	// 4. Graceful shutdown
+	stopCtx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+	defer cancel()
+	if err := srv.lifecycle.Stop(stopCtx); err != nil {
+		srv.logger.Errorf(ctx, "Shutdown error: %v", err)
 	}
 
 	return nil
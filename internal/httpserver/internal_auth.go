@@ -0,0 +1,81 @@
+package httpserver
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"notification-srv/internal/metrics"
+	"notification-srv/pkg/errcode"
+	"notification-srv/pkg/signing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smap-hcmut/shared-libs/go/middleware"
+)
+
+// maxSignedBody bounds the body read into memory to verify its signature.
+const maxSignedBody = 1 << 20
+
+// errInternalUnauthorized answers /internal/* requests that fail authentication.
+var errInternalUnauthorized = errcode.New("auth.internal_unauthorized", errcode.Unauthenticated, "internal request not authenticated", "Unauthorized")
+
+// internalAuth authenticates /internal/* requests. Signed requests are
+// verified against the per-caller keys; unsigned ones fall back to the shared
+// X-Internal-Key while internal.allow_static_key is on.
+func (srv *HTTPServer) internalAuth(mw *middleware.Middleware) gin.HandlerFunc {
+	keys := make([]signing.Key, 0, len(srv.internalConfig.Keys))
+	for _, k := range srv.internalConfig.Keys {
+		keys = append(keys, signing.Key{ID: k.ID, Caller: k.Caller, Secret: []byte(k.Secret)})
+	}
+	verifier := signing.NewVerifier(keys, srv.internalConfig.MaxSkew)
+	staticKey := mw.InternalAuth()
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		if !signing.Signed(c.Request) {
+			if !srv.internalConfig.AllowStaticKey {
+				metrics.InternalRequests.WithLabelValues("", "rejected").Inc()
+				srv.logger.Warnf(ctx, "internal request rejected: unsigned method=%s path=%s", c.Request.Method, c.Request.URL.Path)
+				errcode.Respond(c, errInternalUnauthorized)
+				c.Abort()
+				return
+			}
+			staticKey(c)
+			if !c.IsAborted() {
+				metrics.InternalRequests.WithLabelValues("", "static_key").Inc()
+			}
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxSignedBody+1))
+		if err == nil && len(body) > maxSignedBody {
+			err = io.ErrShortBuffer
+		}
+		if err != nil {
+			metrics.InternalRequests.WithLabelValues("", "rejected").Inc()
+			srv.logger.Warnf(ctx, "internal request rejected: read body: %v", err)
+			errcode.Respond(c, errInternalUnauthorized)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		key, err := verifier.Verify(c.Request, body, time.Now())
+		if err != nil {
+			metrics.InternalRequests.WithLabelValues("", "rejected").Inc()
+			srv.logger.Warnf(ctx, "internal request rejected: key_id=%q method=%s path=%s: %v",
+				c.GetHeader(signing.HeaderKeyID), c.Request.Method, c.Request.URL.Path, err)
+			errcode.Respond(c, errInternalUnauthorized)
+			c.Abort()
+			return
+		}
+
+		metrics.InternalRequests.WithLabelValues(key.Caller, "signed").Inc()
+		srv.logger.Infof(ctx, "internal request: caller=%s key_id=%s method=%s path=%s",
+			key.Caller, key.ID, c.Request.Method, c.Request.URL.Path)
+		c.Set("auth_type", "internal")
+		c.Set("internal_caller", key.Caller)
+		c.Next()
+	}
+}
@@ -0,0 +1,65 @@
+package httpserver
+
+import (
+	"context"
+
+	"notification-srv/internal/lifecycle"
+	"notification-srv/internal/metrics"
+	"notification-srv/pkg/leader"
+)
+
+// registerSingleton registers a task that runs on one replica only: the one
+// holding the task's lease in Redis. run is started under the supervisor when
+// this replica becomes leader, and its ctx is cancelled when leadership is
+// lost or the server stops. Tasks such as schedulers and reapers register
+// here from registerComponents.
+func (srv *HTTPServer) registerSingleton(task string, run func(ctx context.Context)) {
+	metrics.LeaderStatus.WithLabelValues(task).Set(0)
+	elector := leader.New(srv.redis.GetClient(), leader.Config{
+		Key:           srv.leaderConfig.KeyPrefix + task,
+		Identity:      srv.leaderConfig.Identity,
+		TTL:           srv.leaderConfig.TTL,
+		RenewInterval: srv.leaderConfig.RenewInterval,
+		OnAcquire: func(ctx context.Context) {
+			metrics.LeaderStatus.WithLabelValues(task).Set(1)
+			metrics.LeaderChanges.WithLabelValues(task, "acquired").Inc()
+			srv.logger.Infof(ctx, "leader of %s: running it on this replica", task)
+			go srv.supervisor.Run(ctx, task, func() { run(ctx) })
+		},
+		OnLose: func() {
+			metrics.LeaderStatus.WithLabelValues(task).Set(0)
+			metrics.LeaderChanges.WithLabelValues(task, "lost").Inc()
+			srv.logger.Warnf(context.Background(), "lost leadership of %s: stopped it on this replica", task)
+		},
+		OnError: func(err error) {
+			srv.logger.Warnf(context.Background(), "leader election of %s: %v", task, err)
+		},
+	})
+
+	var (
+		cancel context.CancelFunc
+		done   = make(chan struct{})
+	)
+	srv.lifecycle.Register(lifecycle.Component{
+		Name: "leader-" + task,
+		Start: func(ctx context.Context) error {
+			var runCtx context.Context
+			runCtx, cancel = context.WithCancel(context.Background())
+			go func() {
+				defer close(done)
+				elector.Run(runCtx)
+			}()
+			return nil
+		},
+		// Waits for the lease to be released so another replica takes over at once
+		Stop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+}
@@ -0,0 +1,130 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"notification-srv/internal/metrics"
+	"notification-srv/internal/websocket/transport"
+	"notification-srv/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smap-hcmut/shared-libs/go/middleware"
+)
+
+// useMiddleware installs the stack shared by the public and the internal
+// engine: trace IDs first so everything after logs them, then access logs and
+// route metrics, which see the 500 written by the recovery below them.
+func (srv *HTTPServer) useMiddleware(e *gin.Engine) {
+	e.Use(middleware.Tracing())
+	e.Use(srv.accessLog())
+	e.Use(routeMetrics())
+	e.Use(srv.recovery())
+}
+
+// unmatchedRoute labels requests no route matched, keeping 404 scans out of
+// the route label.
+const unmatchedRoute = "unmatched"
+
+func routeOf(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return unmatchedRoute
+}
+
+// quietRoute reports whether requests to route are too frequent to log:
+// probes and scrapes, also when mounted under the base path.
+func quietRoute(route string) bool {
+	for _, suffix := range []string{"/health", "/ready", "/live", "/metrics"} {
+		if strings.HasSuffix(route, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// accessLog logs every request with its route template and trace ID. The
+// query string is left out as it may carry a token.
+func (srv *HTTPServer) accessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := routeOf(c)
+		if quietRoute(route) {
+			return
+		}
+
+		ctx := c.Request.Context()
+		status := c.Writer.Status()
+		msg := "http request: method=%s route=%s path=%s status=%d latency=%s ip=%s"
+		args := []any{c.Request.Method, route, c.Request.URL.Path, status, time.Since(start), c.ClientIP()}
+		switch {
+		case status >= 500:
+			srv.logger.Errorf(ctx, msg, args...)
+		case status >= 400:
+			srv.logger.Warnf(ctx, msg, args...)
+		default:
+			srv.logger.Infof(ctx, msg, args...)
+		}
+	}
+}
+
+// routeMetrics counts requests and observes their latency by route template.
+// WebSocket upgrades are counted as 101 once hijacked, and not observed: the
+// handler only returns when the connection is registered.
+func routeMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		upgrade := transport.IsUpgrade(c.Request)
+		c.Next()
+
+		route := routeOf(c)
+		status := c.Writer.Status()
+		if upgrade && status == http.StatusOK {
+			status = http.StatusSwitchingProtocols // Hijacked by the upgrader, which wrote the status itself
+		}
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(status)).Inc()
+		if !upgrade {
+			metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+		}
+	}
+}
+
+// recovery turns a panic into a 500 problem document and reports it to
+// Discord with its stack. Nothing is written if the response has started,
+// e.g. on a hijacked WebSocket connection.
+func (srv *HTTPServer) recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			if r == http.ErrAbortHandler {
+				panic(r) // The handler asked net/http to drop the connection
+			}
+
+			ctx := c.Request.Context()
+			stack := debug.Stack()
+			srv.logger.Errorf(ctx, "panic recovered: method=%s path=%s: %v\n%s", c.Request.Method, c.Request.URL.Path, r, stack)
+			if srv.discord != nil {
+				report := fmt.Sprintf("Panic in %s %s: %v\n%s", c.Request.Method, routeOf(c), r, stack)
+				if err := srv.discord.ReportBug(ctx, report); err != nil {
+					srv.logger.Warnf(ctx, "panic report to Discord failed: %v", err)
+				}
+			}
+
+			if !c.Writer.Written() {
+				errcode.Respond(c, errcode.Unclassified)
+			}
+			c.Abort()
+		}()
+		c.Next()
+	}
+}
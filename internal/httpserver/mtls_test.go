@@ -0,0 +1,181 @@
+package httpserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"notification-srv/config"
+	"notification-srv/pkg/notificationtest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA issues the server and client certificates of a test.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a leaf certificate for usage, with uri as its URI SAN if set.
+func (ca *testCA) issue(t *testing.T, usage x509.ExtKeyUsage, uri string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "test leaf"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if uri != "" {
+		u, err := url.Parse(uri)
+		require.NoError(t, err)
+		tmpl.URIs = []*url.URL{u}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// writeServerFiles writes the server certificate, its key and the client CA the
+// way they are deployed, and returns their paths.
+func writeServerFiles(t *testing.T, ca *testCA, server tls.Certificate) (certFile, keyFile, caFile string) {
+	t.Helper()
+	dir := t.TempDir()
+	keyDER, err := x509.MarshalPKCS8PrivateKey(server.PrivateKey)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, "tls.crt")
+	keyFile = filepath.Join(dir, "tls.key")
+	caFile = filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate[0]}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600))
+	require.NoError(t, os.WriteFile(caFile, ca.pem, 0o600))
+	return certFile, keyFile, caFile
+}
+
+// newMTLSServer serves an internal endpoint behind mtlsAuth on a listener
+// configured by certReloader, and returns its URL and the CA that issued
+// its certificates.
+func newMTLSServer(t *testing.T, allowed []string) (string, *testCA) {
+	t.Helper()
+	ca := newTestCA(t)
+	certFile, keyFile, caFile := writeServerFiles(t, ca, ca.issue(t, x509.ExtKeyUsageServerAuth, ""))
+	certs, err := newCertReloader(notificationtest.Logger{}, certFile, keyFile, caFile)
+	require.NoError(t, err)
+
+	srv := &HTTPServer{
+		logger:         notificationtest.Logger{},
+		internalConfig: config.InternalConfig{MTLS: config.InternalMTLSConfig{Enabled: true, AllowedSPIFFEIDs: allowed}},
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/internal/stats", srv.mtlsAuth(), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("internal_caller"))
+	})
+
+	server := httptest.NewUnstartedServer(r)
+	server.TLS = certs.tlsConfig()
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server.URL, ca
+}
+
+// getInternal calls the internal endpoint presenting client, if any.
+func getInternal(t *testing.T, serverURL string, ca *testCA, client *tls.Certificate) (*http.Response, error) {
+	t.Helper()
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	cfg := &tls.Config{RootCAs: roots}
+	if client != nil {
+		cfg.Certificates = []tls.Certificate{*client}
+	}
+	c := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}, Timeout: 5 * time.Second}
+	return c.Get(serverURL + "/internal/stats")
+}
+
+func TestMTLSAllowList(t *testing.T) {
+	serverURL, ca := newMTLSServer(t, []string{
+		"spiffe://smap.local/ns/ingest/sa/collector",
+		"spiffe://smap.local/ns/analysis/*",
+	})
+
+	tests := []struct {
+		name string
+		id   string
+		want int
+	}{
+		{name: "exact match", id: "spiffe://smap.local/ns/ingest/sa/collector", want: http.StatusOK},
+		{name: "prefix match", id: "spiffe://smap.local/ns/analysis/sa/worker", want: http.StatusOK},
+		{name: "not listed", id: "spiffe://smap.local/ns/ingest/sa/other", want: http.StatusUnauthorized},
+		{name: "prefix without separator", id: "spiffe://smap.local/ns/analysis-evil/sa/worker", want: http.StatusUnauthorized},
+		{name: "other trust domain", id: "spiffe://evil.local/ns/ingest/sa/collector", want: http.StatusUnauthorized},
+		{name: "not a spiffe uri", id: "https://smap.local/ns/ingest/sa/collector", want: http.StatusUnauthorized},
+		{name: "no uri san", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := ca.issue(t, x509.ExtKeyUsageClientAuth, tt.id)
+			resp, err := getInternal(t, serverURL, ca, &client)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.want, resp.StatusCode)
+		})
+	}
+}
+
+func TestMTLSRequiresClientCertificate(t *testing.T) {
+	serverURL, ca := newMTLSServer(t, []string{"spiffe://smap.local/ns/ingest/sa/collector"})
+
+	t.Run("no certificate", func(t *testing.T) {
+		_, err := getInternal(t, serverURL, ca, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("certificate from another CA", func(t *testing.T) {
+		client := newTestCA(t).issue(t, x509.ExtKeyUsageClientAuth, "spiffe://smap.local/ns/ingest/sa/collector")
+		_, err := getInternal(t, serverURL, ca, &client)
+		assert.Error(t, err)
+	})
+}
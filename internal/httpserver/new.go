@@ -3,14 +3,17 @@ package httpserver
 import (
 	"errors"
 	"notification-srv/config"
+	"notification-srv/internal/lifecycle"
+	"notification-srv/internal/sink"
 	"notification-srv/internal/websocket"
 	"notification-srv/internal/websocket/delivery/redis"
+	"notification-srv/pkg/subtoken"
 
 	"github.com/gin-gonic/gin"
+	"github.com/quic-go/webtransport-go"
 	"github.com/smap-hcmut/shared-libs/go/auth"
 	"github.com/smap-hcmut/shared-libs/go/discord"
 	"github.com/smap-hcmut/shared-libs/go/log"
-	"github.com/smap-hcmut/shared-libs/go/middleware"
 	pkgRedis "github.com/smap-hcmut/shared-libs/go/redis"
 )
 
@@ -20,23 +23,94 @@ import (
 type HTTPServer struct {
 	// Server configuration
 	gin         *gin.Engine
+	internalGin *gin.Engine // Internal endpoints when they are served over mTLS, nil otherwise
+	adminGin    *gin.Engine // Admin (and non-mTLS internal) endpoints on their own listener, nil otherwise
+	wtGin       *gin.Engine // WebTransport sessions on the HTTP/3 listener, nil otherwise
 	logger      log.Logger
 	port        int
 	environment string
 
+	// Zero-downtime restart
+	reusePort     bool
+	handoffSocket string
+	takeover      chan struct{} // Closed when a newer process asks this one to drain
+
+	// Route prefix
+	basePath string
+	wsPath   string
+
+	// Admin listener
+	adminListener config.AdminListenerConfig
+
+	// Ordered start/stop and health of background components,
+	// and panic supervision of their long-running loops
+	lifecycle        *lifecycle.Registry
+	supervisor       *lifecycle.Supervisor
+	supervisorConfig config.SupervisorConfig
+	watchdogConfig   config.WatchdogConfig
+	probeConfig      config.ProbeConfig
+	canaryConfig     config.CanaryConfig
+	rateLimitConfig  config.RateLimitConfig
+
 	// WebSocket core (New Domain)
 	wsUC         websocket.UseCase
 	wsSubscriber redis.Subscriber
 	wsConfig     config.WebSocketConfig
 
+	// Transform, backfill & client telemetry
+	transformConfig config.TransformConfig
+	backfillConfig  config.BackfillConfig
+	telemetryConfig config.TelemetryConfig
+
+	// GraphQL subscription gateway
+	graphqlConfig config.GraphQLConfig
+
+	// Experimental WebTransport listener and its sessions
+	webTransportConfig config.WebTransportConfig
+	webTransport       *webtransport.Server
+
+	// Latest project topic state key
+	latestStateConfig config.LatestStateConfig
+
+	// User presence key
+	presenceConfig config.PresenceConfig
+
+	// At-least-once delivery
+	deliveryConfig config.DeliveryConfig
+
+	// Per-user tracing bounds
+	userTraceConfig config.UserTraceConfig
+
+	// Broadcast segment sets, deny-list and delegated access
+	segmentsConfig   config.SegmentsConfig
+	bansConfig       config.BansConfig
+	delegationConfig config.DelegationConfig
+
+	// Outbound sinks (mirrors of delivered notifications)
+	sinksConfig config.SinksConfig
+	mqttConfig  config.MQTTConfig
+	sinks       []sink.Sink
+
+	// Circuit breakers of outbound dependencies
+	breakerConfig config.BreakerConfig
+
+	// Leases of tasks that run on one replica only
+	leaderConfig config.LeaderConfig
+
 	// Auth & security
-	jwtMgr      auth.Manager
-	cookieCfg   config.CookieConfig
-	internalKey string
+	jwtMgr         auth.Manager
+	subTokens      *subtoken.Verifier
+	cookieCfg      config.CookieConfig
+	internalConfig config.InternalConfig
 
 	// External services
 	redis   pkgRedis.IRedis
 	discord discord.IDiscord
+
+	// Multi-region Redis: the local region plus remote upstreams
+	region        string
+	channelPrefix string
+	remoteRegions []redis.Upstream
 }
 
 // Config is the constructor input for HTTPServer.
@@ -47,17 +121,81 @@ type Config struct {
 	Mode        string
 	Environment string
 
+	// Zero-downtime restart
+	ReusePort     bool
+	HandoffSocket string
+
+	// Route prefix
+	BasePath string // Mounts every route under it, e.g. "/notifications"
+	WSPath   string // WebSocket endpoint under BasePath
+
+	// Admin listener
+	AdminListener config.AdminListenerConfig
+
 	// WebSocket configuration
 	WSConfig config.WebSocketConfig
 
+	// Transform, backfill & client telemetry configuration
+	TransformConfig config.TransformConfig
+	BackfillConfig  config.BackfillConfig
+	TelemetryConfig config.TelemetryConfig
+
+	// GraphQL gateway configuration
+	GraphQLConfig config.GraphQLConfig
+
+	// WebTransport listener configuration
+	WebTransportConfig config.WebTransportConfig
+
+	// Latest project topic state configuration
+	LatestStateConfig config.LatestStateConfig
+
+	// User presence configuration
+	PresenceConfig config.PresenceConfig
+
+	// At-least-once delivery configuration
+	DeliveryConfig config.DeliveryConfig
+
+	// Per-user tracing configuration
+	UserTraceConfig config.UserTraceConfig
+
+	// Broadcast segment, deny-list and delegated access configuration
+	SegmentsConfig   config.SegmentsConfig
+	BansConfig       config.BansConfig
+	DelegationConfig config.DelegationConfig
+
+	// Outbound sinks configuration
+	SinksConfig config.SinksConfig
+	MQTTConfig  config.MQTTConfig
+
+	// Circuit breakers of outbound dependencies
+	BreakerConfig config.BreakerConfig
+
+	// Leases of tasks that run on one replica only
+	LeaderConfig config.LeaderConfig
+
 	// Auth & security
-	JWTManager  auth.Manager
-	Cookie      config.CookieConfig
-	InternalKey string
+	JWTManager     auth.Manager
+	SubTokens      *subtoken.Verifier // Verifies /ws?subToken=; nil = not accepted
+	Cookie         config.CookieConfig
+	InternalConfig config.InternalConfig
 
 	// External services
 	Redis   pkgRedis.IRedis
 	Discord discord.IDiscord
+
+	// Multi-region Redis
+	Region        string           // Name of the local region (Redis above)
+	ChannelPrefix string           // Channel prefix on the local Redis
+	RemoteRegions []redis.Upstream // Optional remote regions
+
+	// Background loop supervision
+	SupervisorConfig config.SupervisorConfig
+	WatchdogConfig   config.WatchdogConfig
+	ProbeConfig      config.ProbeConfig
+	CanaryConfig     config.CanaryConfig
+
+	// Inbound rate limit of the Redis subscriber
+	RateLimitConfig config.RateLimitConfig
 }
 
 // New creates a new HTTPServer instance with the provided configuration.
@@ -73,23 +211,79 @@ func New(logger log.Logger, cfg Config) (*HTTPServer, error) {
 		logger:      logger,
 		port:        cfg.Port,
 		environment: cfg.Environment,
+		lifecycle:   lifecycle.New(logger),
+
+		reusePort:     cfg.ReusePort,
+		handoffSocket: cfg.HandoffSocket,
+		takeover:      make(chan struct{}),
+
+		basePath: cfg.BasePath,
+		wsPath:   cfg.WSPath,
+
+		adminListener: cfg.AdminListener,
+
+		supervisorConfig: cfg.SupervisorConfig,
+		watchdogConfig:   cfg.WatchdogConfig,
+		probeConfig:      cfg.ProbeConfig,
+		canaryConfig:     cfg.CanaryConfig,
+		rateLimitConfig:  cfg.RateLimitConfig,
 
 		// WebSocket config
 		wsConfig: cfg.WSConfig,
 
+		// Transform, backfill & client telemetry config
+		transformConfig:   cfg.TransformConfig,
+		backfillConfig:    cfg.BackfillConfig,
+		latestStateConfig: cfg.LatestStateConfig,
+		telemetryConfig:   cfg.TelemetryConfig,
+		sinksConfig:       cfg.SinksConfig,
+		mqttConfig:        cfg.MQTTConfig,
+		graphqlConfig:     cfg.GraphQLConfig,
+
+		webTransportConfig: cfg.WebTransportConfig,
+		presenceConfig:     cfg.PresenceConfig,
+		segmentsConfig:     cfg.SegmentsConfig,
+		bansConfig:         cfg.BansConfig,
+		delegationConfig:   cfg.DelegationConfig,
+		deliveryConfig:     cfg.DeliveryConfig,
+		userTraceConfig:    cfg.UserTraceConfig,
+		breakerConfig:      cfg.BreakerConfig,
+		leaderConfig:       cfg.LeaderConfig,
+
 		// Auth & security
-		jwtMgr:      cfg.JWTManager,
-		cookieCfg:   cfg.Cookie,
-		internalKey: cfg.InternalKey,
+		jwtMgr:         cfg.JWTManager,
+		subTokens:      cfg.SubTokens,
+		cookieCfg:      cfg.Cookie,
+		internalConfig: cfg.InternalConfig,
 
 		// External services
 		redis:   cfg.Redis,
 		discord: cfg.Discord,
+
+		// Multi-region Redis
+		region:        cfg.Region,
+		channelPrefix: cfg.ChannelPrefix,
+		remoteRegions: cfg.RemoteRegions,
 	}
 
+	srv.discord = newBreakerDiscord(cfg.Discord, srv.newBreaker("discord"))
+
 	// Add middlewares
-	srv.gin.Use(middleware.Logger(srv.logger, srv.environment))
-	srv.gin.Use(gin.Recovery())
+	srv.useMiddleware(srv.gin)
+
+	if cfg.InternalConfig.MTLS.Enabled {
+		srv.internalGin = gin.New()
+		srv.useMiddleware(srv.internalGin)
+	}
+	if cfg.AdminListener.Mode == adminModeSeparate {
+		srv.adminGin = gin.New()
+		srv.useMiddleware(srv.adminGin)
+	}
+	if cfg.WebTransportConfig.Enabled {
+		srv.wtGin = gin.New()
+		srv.useMiddleware(srv.wtGin)
+		srv.webTransport = newWebTransportServer(cfg.WebTransportConfig.Port, srv.wtGin)
+	}
 
 	if err := srv.validate(); err != nil {
 		return nil, err
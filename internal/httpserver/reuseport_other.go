@@ -0,0 +1,12 @@
+//go:build !linux
+
+package httpserver
+
+import (
+	"errors"
+	"syscall"
+)
+
+func reusePortControl(network, address string, c syscall.RawConn) error {
+	return errors.New("server.reuse_port is only supported on Linux")
+}
@@ -0,0 +1,69 @@
+package httpserver
+
+import (
+	"fmt"
+
+	clickhouseSink "notification-srv/internal/sink/clickhouse"
+	kafkaSink "notification-srv/internal/sink/kafka"
+	mqttSink "notification-srv/internal/sink/mqtt"
+)
+
+// initSinks creates the enabled outbound sinks.
+func (srv *HTTPServer) initSinks() error {
+	if k := srv.sinksConfig.Kafka; k.Enabled {
+		s, err := kafkaSink.New(srv.logger, kafkaSink.Config{
+			Brokers:       k.Brokers,
+			Topic:         k.Topic,
+			MessageTypes:  k.MessageTypes,
+			BatchSize:     k.BatchSize,
+			FlushInterval: k.FlushInterval,
+			QueueSize:     k.QueueSize,
+			Timeout:       k.Timeout,
+			MaxRetries:    k.MaxRetries,
+			Breaker:       srv.newBreaker("kafka"),
+		})
+		if err != nil {
+			return fmt.Errorf("init kafka sink: %w", err)
+		}
+		srv.sinks = append(srv.sinks, s)
+	}
+
+	if ch := srv.sinksConfig.ClickHouse; ch.Enabled {
+		s, err := clickhouseSink.New(srv.logger, clickhouseSink.Config{
+			URL:           ch.URL,
+			Database:      ch.Database,
+			Table:         ch.Table,
+			Username:      ch.Username,
+			Password:      ch.Password,
+			BatchSize:     ch.BatchSize,
+			FlushInterval: ch.FlushInterval,
+			QueueSize:     ch.QueueSize,
+			Timeout:       ch.Timeout,
+			MaxRetries:    ch.MaxRetries,
+			Breaker:       srv.newBreaker("clickhouse"),
+		})
+		if err != nil {
+			return fmt.Errorf("init clickhouse sink: %w", err)
+		}
+		srv.sinks = append(srv.sinks, s)
+	}
+
+	if m := srv.mqttConfig; m.Enabled {
+		s, err := mqttSink.New(srv.logger, mqttSink.Config{
+			BrokerURL:    m.BrokerURL,
+			ClientID:     m.ClientID,
+			Username:     m.Username,
+			Password:     m.Password,
+			TopicPattern: m.TopicPattern,
+			QoS:          byte(m.QoS),
+			Retain:       m.Retain,
+			QueueSize:    m.QueueSize,
+			Timeout:      m.Timeout,
+		})
+		if err != nil {
+			return fmt.Errorf("init mqtt bridge: %w", err)
+		}
+		srv.sinks = append(srv.sinks, s)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/quic-go/webtransport-go"
+)
+
+// newWebTransportServer returns the HTTP/3 server accepting WebTransport
+// sessions on port, with handler serving their CONNECT requests.
+func newWebTransportServer(port int, handler *gin.Engine) *webtransport.Server {
+	return &webtransport.Server{
+		H3: http3.Server{
+			Addr:    fmt.Sprintf(":%d", port),
+			Handler: handler,
+		},
+		// Sessions authenticate by token like /ws, which accepts any origin
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+}
+
+// startWebTransport listens on the UDP port synchronously, so a busy port
+// fails startup, and serves WebTransport sessions in the background.
+func (srv *HTTPServer) startWebTransport(ctx context.Context) error {
+	wt := srv.webTransportConfig
+	cert, err := tls.LoadX509KeyPair(wt.CertFile, wt.KeyFile)
+	if err != nil {
+		return fmt.Errorf("webtransport: load key pair: %w", err)
+	}
+	srv.webTransport.H3.TLSConfig = &tls.Config{
+		MinVersion:   tls.VersionTLS13,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	conn, err := net.ListenPacket("udp", srv.webTransport.H3.Addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := srv.webTransport.Serve(conn); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			srv.logger.Errorf(ctx, "WebTransport server error: %v", err)
+		}
+	}()
+	srv.logger.Infof(ctx, "WebTransport (HTTP/3) server started on UDP port: %d", wt.Port)
+	return nil
+}
+
+// stopWebTransport closes the sessions, which ends their Hub streams.
+func (srv *HTTPServer) stopWebTransport(ctx context.Context) error {
+	return srv.webTransport.Close()
+}
@@ -0,0 +1,133 @@
+// Package lifecycle starts, stops and health-checks the long-running components
+// of the service in a fixed order.
+//
+// Components are registered once during wiring. Start runs them in registration
+// order and, if one fails, stops those already started in reverse order. Stop
+// always runs in reverse order. Every hook runs under panic recovery, so one
+// misbehaving component cannot take the others down with it.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/smap-hcmut/shared-libs/go/log"
+)
+
+// Hook is a Start, Stop or Health callback. Start hooks must not block:
+// long-running loops are started in their own goroutine.
+type Hook func(ctx context.Context) error
+
+// Component is a unit managed by the Registry. Any hook may be nil.
+type Component struct {
+	Name   string
+	Start  Hook
+	Stop   Hook
+	Health Hook
+}
+
+// Registry owns the ordered list of components.
+type Registry struct {
+	logger log.Logger
+
+	mu         sync.Mutex
+	components []Component
+	started    int // Number of components (from the front) that started successfully
+}
+
+// New creates an empty Registry.
+func New(logger log.Logger) *Registry {
+	return &Registry{logger: logger}
+}
+
+// Register appends a component. Components start in the order they are registered.
+func (r *Registry) Register(c Component) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.components = append(r.components, c)
+}
+
+// Start starts every component in order. On the first failure the components
+// already started are stopped in reverse order and the failure is returned.
+func (r *Registry) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, c := range r.components {
+		if err := r.call(ctx, c.Name, "start", c.Start); err != nil {
+			r.started = i
+			r.stopLocked(ctx)
+			return fmt.Errorf("start %s: %w", c.Name, err)
+		}
+		r.logger.Infof(ctx, "lifecycle: %s started", c.Name)
+	}
+	r.started = len(r.components)
+	return nil
+}
+
+// Stop stops every started component in reverse order. All components are
+// given a chance to stop; their errors are joined.
+func (r *Registry) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stopLocked(ctx)
+}
+
+func (r *Registry) stopLocked(ctx context.Context) error {
+	var errs []error
+	for i := r.started - 1; i >= 0; i-- {
+		c := r.components[i]
+		if err := r.call(ctx, c.Name, "stop", c.Stop); err != nil {
+			r.logger.Errorf(ctx, "lifecycle: %s stop failed: %v", c.Name, err)
+			errs = append(errs, fmt.Errorf("stop %s: %w", c.Name, err))
+			continue
+		}
+		r.logger.Infof(ctx, "lifecycle: %s stopped", c.Name)
+	}
+	r.started = 0
+	return errors.Join(errs...)
+}
+
+// Health runs every component's health hook and returns the failures keyed by
+// component name. An empty map means every component is healthy.
+func (r *Registry) Health(ctx context.Context) map[string]error {
+	r.mu.Lock()
+	components := append([]Component(nil), r.components...)
+	r.mu.Unlock()
+
+	failures := make(map[string]error)
+	for _, c := range components {
+		if err := r.call(ctx, c.Name, "health", c.Health); err != nil {
+			failures[c.Name] = err
+		}
+	}
+	return failures
+}
+
+// Names returns the registered component names in start order.
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, len(r.components))
+	for i, c := range r.components {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// call runs a hook, converting a panic into an error.
+func (r *Registry) call(ctx context.Context, name, phase string, hook Hook) (err error) {
+	if hook == nil {
+		return nil
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			r.logger.Errorf(ctx, "lifecycle: %s %s panicked: %v", name, phase, p)
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return hook(ctx)
+}
@@ -0,0 +1,102 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"notification-srv/internal/metrics"
+
+	"github.com/smap-hcmut/shared-libs/go/log"
+)
+
+// crashWindow is the sliding window restarts are counted over.
+const crashWindow = time.Minute
+
+// CrashLoopFunc is called when a component restarts more than the allowed
+// number of times within a minute.
+type CrashLoopFunc func(ctx context.Context, component string, restarts int, lastPanic string)
+
+// SupervisorConfig holds the restart policy.
+type SupervisorConfig struct {
+	MaxRestartsPerMinute int           // Restarts tolerated per minute before OnCrashLoop fires (0 = never fire)
+	RestartBackoff       time.Duration // Pause before restarting a crashed loop
+	OnCrashLoop          CrashLoopFunc // Optional
+}
+
+// Supervisor keeps long-running goroutines alive: a loop that panics is logged,
+// counted and restarted instead of silently disappearing.
+type Supervisor struct {
+	logger log.Logger
+	cfg    SupervisorConfig
+
+	mu       sync.Mutex
+	restarts map[string][]time.Time // Restart times within crashWindow, per component
+}
+
+// NewSupervisor creates a Supervisor with the given restart policy.
+func NewSupervisor(logger log.Logger, cfg SupervisorConfig) *Supervisor {
+	return &Supervisor{
+		logger:   logger,
+		cfg:      cfg,
+		restarts: make(map[string][]time.Time),
+	}
+}
+
+// Run calls fn and restarts it whenever it panics. It returns when fn returns
+// normally or ctx is done.
+func (s *Supervisor) Run(ctx context.Context, component string, fn func()) {
+	for {
+		p, stack := runRecovered(fn)
+		if p == nil {
+			return
+		}
+
+		metrics.ComponentRestarts.WithLabelValues(component).Inc()
+		s.logger.Errorf(ctx, "supervisor: %s panicked, restarting: %v\n%s", component, p, stack)
+		s.recordRestart(ctx, component, fmt.Sprint(p))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.cfg.RestartBackoff):
+		}
+	}
+}
+
+// recordRestart tracks restarts in the sliding window and fires OnCrashLoop
+// once when the limit is first exceeded.
+func (s *Supervisor) recordRestart(ctx context.Context, component, lastPanic string) {
+	now := time.Now()
+
+	s.mu.Lock()
+	recent := s.restarts[component][:0]
+	for _, t := range s.restarts[component] {
+		if now.Sub(t) < crashWindow {
+			recent = append(recent, t)
+		}
+	}
+	recent = append(recent, now)
+	s.restarts[component] = recent
+	count := len(recent)
+	s.mu.Unlock()
+
+	if s.cfg.OnCrashLoop == nil || s.cfg.MaxRestartsPerMinute <= 0 || count != s.cfg.MaxRestartsPerMinute+1 {
+		return
+	}
+	s.logger.Errorf(ctx, "supervisor: %s restarted %d times in the last minute", component, count)
+	s.cfg.OnCrashLoop(ctx, component, count, lastPanic)
+}
+
+// runRecovered calls fn and returns the recovered panic value (nil if fn returned normally).
+func runRecovered(fn func()) (p any, stack []byte) {
+	defer func() {
+		if p = recover(); p != nil {
+			stack = debug.Stack()
+		}
+	}()
+	fn()
+	return nil, nil
+}
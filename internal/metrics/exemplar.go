@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smap-hcmut/shared-libs/go/tracing"
+)
+
+var tracer = tracing.NewTraceContext()
+
+// ObserveWithTrace observes v and, when ctx carries a trace ID, attaches it as
+// a trace_id exemplar, so a latency spike on a dashboard links to the logs and
+// trace of one of the messages behind it.
+func ObserveWithTrace(ctx context.Context, o prometheus.Observer, v float64) {
+	traceID := tracer.GetTraceID(ctx)
+	if eo, ok := o.(prometheus.ExemplarObserver); ok && traceID != "" {
+		eo.ObserveWithExemplar(v, prometheus.Labels{"trace_id": traceID})
+		return
+	}
+	o.Observe(v)
+}
+
+// ObserveWithUser is ObserveWithTrace for a message of a user under targeted
+// tracing: the exemplar also carries user_id, so the user's messages stand out
+// on a dashboard. A user ID too long for an exemplar is left out.
+func ObserveWithUser(ctx context.Context, o prometheus.Observer, v float64, userID string) {
+	eo, ok := o.(prometheus.ExemplarObserver)
+	if !ok {
+		o.Observe(v)
+		return
+	}
+	labels := prometheus.Labels{}
+	runes := 0
+	if traceID := tracer.GetTraceID(ctx); traceID != "" {
+		labels["trace_id"] = traceID
+		runes = len("trace_id") + utf8.RuneCountInString(traceID)
+	}
+	if runes+len("user_id")+utf8.RuneCountInString(userID) <= prometheus.ExemplarMaxRunes {
+		labels["user_id"] = userID
+	}
+	eo.ObserveWithExemplar(v, labels)
+}
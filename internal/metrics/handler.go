@@ -0,0 +1,17 @@
+package metrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler returns a gin handler exposing all registered collectors in the
+// Prometheus text format, or in OpenMetrics with exemplars to scrapers that
+// ask for it.
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+	))
+}
@@ -0,0 +1,559 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "notification"
+
+// Delivery metrics
+var (
+	// DeliveryLatency is the end-to-end latency between the server emitting a
+	// message and the client reporting it as received.
+	DeliveryLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "delivery",
+		Name:      "e2e_latency_seconds",
+		Help:      "End-to-end latency from server emit to client receive, as reported by clients.",
+		Buckets:   []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"type"})
+
+	// LatencyReportsDropped counts client reports that could not be joined with an emit record.
+	LatencyReportsDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "delivery",
+		Name:      "latency_reports_dropped_total",
+		Help:      "Client latency reports discarded, by reason.",
+	}, []string{"reason"})
+
+	// The stages of the delivery path carry trace_id exemplars (ObserveWithTrace).
+
+	// TransformDuration is the time to validate and transform a message.
+	TransformDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "delivery",
+		Name:      "transform_duration_seconds",
+		Help:      "Time to validate and transform a message into its client envelope, by message type.",
+		Buckets:   stageBuckets,
+	}, []string{"type"})
+
+	// EnqueueDuration is the time to queue a frame to its recipients.
+	EnqueueDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "delivery",
+		Name:      "enqueue_duration_seconds",
+		Help:      "Time for the Hub to queue a frame to the send queues of its recipients.",
+		Buckets:   stageBuckets,
+	})
+
+	// WriteDuration is the time from setting a write deadline to flushing
+	// the frames written under it.
+	WriteDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "delivery",
+		Name:      "write_duration_seconds",
+		Help:      "Time to write and flush a batch of frames to a connection, by client label.",
+		Buckets:   stageBuckets,
+	}, []string{"client_label"})
+)
+
+// stageBuckets covers the sub-millisecond to second latencies of one stage of
+// the delivery path.
+var stageBuckets = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 1}
+
+// Connection metrics
+var (
+	// ActiveConnections is the number of open connections, by client label
+	// (the configured labels, "other" or "none").
+	ActiveConnections = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "active_connections",
+		Help:      "Open connections, by client label.",
+	}, []string{"client_label"})
+
+	// Disconnects counts closed WebSocket connections, by client label and kind
+	// (normal, unexpected).
+	Disconnects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "disconnects_total",
+		Help:      "Closed WebSocket connections, by client label and kind (normal, unexpected).",
+	}, []string{"client_label", "kind"})
+
+	// ConnectionRTT is the ping/pong round-trip time of WebSocket connections.
+	ConnectionRTT = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "rtt_seconds",
+		Help:      "Ping/pong round-trip time of WebSocket connections.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	})
+
+	// DeltaFrames counts frames written to delta-encoding connections, by kind (delta, full).
+	DeltaFrames = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "delta_frames_total",
+		Help:      "Frames written to delta-encoding connections, by kind.",
+	}, []string{"kind"})
+
+	// CoalescedFrames counts collapse_key frames replaced by a newer one before being written.
+	CoalescedFrames = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "coalesced_frames_total",
+		Help:      "Frames replaced by a newer frame with the same collapse key before being written.",
+	})
+
+	// CoalesceAdjustments counts coalescing interval changes, by direction (up, down).
+	CoalesceAdjustments = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "coalesce_adjustments_total",
+		Help:      "Per-connection coalescing interval changes, by direction.",
+	}, []string{"direction"})
+
+	// CoalesceStretched is the number of connections coalescing above the minimum interval.
+	CoalesceStretched = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "coalesce_stretched_connections",
+		Help:      "Connections whose coalescing interval is above the minimum.",
+	})
+
+	// ChurnDetected counts connection churn detections, by kind (user, ip).
+	ChurnDetected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "churn_detected_total",
+		Help:      "Users or IPs flagged for opening connections at an abusive rate, by kind.",
+	}, []string{"kind"})
+
+	// DelegatedSubscriptions counts subscriptions to another user's stream, by result.
+	DelegatedSubscriptions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "delegated_subscriptions_total",
+		Help:      "Subscriptions to another user's stream, by result: granted, denied, disabled or error.",
+	}, []string{"result"})
+
+	// LatestStateWrites counts writes of the latest state key of project topics, by result.
+	LatestStateWrites = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "latest_state_writes_total",
+		Help:      "Writes of the latest frame of project topics to Redis, by result: ok or error.",
+	}, []string{"result"})
+
+	// CanaryProbes counts the canary's end-to-end probes, by result.
+	CanaryProbes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "canary",
+		Name:      "probes_total",
+		Help:      "End-to-end probes of the canary user, by result: ok, slow (over the latency threshold), lost (not received in time) or publish_failed.",
+	}, []string{"result"})
+
+	// CanaryLatency is the publish-to-loopback latency of the canary's probes.
+	CanaryLatency = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "canary",
+		Name:      "latency_seconds",
+		Help:      "Latency from publishing a canary probe to Redis to its frame reaching the canary's loopback connection.",
+		Buckets:   []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	})
+
+	// CanaryHealthy is 1 while the canary's probes succeed, 0 once they keep failing.
+	CanaryHealthy = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "canary",
+		Name:      "healthy",
+		Help:      "1 if the canary's end-to-end probes succeed, 0 after canary.failures_to_alert failures in a row.",
+	})
+
+	// ReliableDeliveries counts the outcomes of at-least-once frames.
+	ReliableDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "reliable_deliveries_total",
+		Help:      "At-least-once frame outcomes: acked, retried, expired (out of retries), offline (queued for the user), elsewhere (user on another replica), drained (sent from the offline queue on connect) or lost.",
+	}, []string{"result"})
+
+	// DecoySubscriptions counts subscriptions to decoy project IDs, by transport.
+	DecoySubscriptions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "decoy_subscriptions_total",
+		Help:      "Subscriptions to never-issued decoy project IDs, by transport.",
+	}, []string{"transport"})
+
+	// PublicConnects counts anonymous upgrades to public projects, by result
+	// (accepted, not_public, rate_limited, ip_limit, max_connections).
+	PublicConnects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "public_connects_total",
+		Help:      "Anonymous upgrades to public projects, by result.",
+	}, []string{"result"})
+
+	// PublicActiveConnections is the number of open anonymous connections.
+	PublicActiveConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "public_active_connections",
+		Help:      "Open anonymous connections to public projects.",
+	})
+
+	// PublicFramesSent counts frames queued to anonymous viewers.
+	PublicFramesSent = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "public_frames_sent_total",
+		Help:      "Frames queued to anonymous viewers of public projects.",
+	})
+
+	// BackpressureSignals counts backpressure signals published to slow down a
+	// channel's publisher, by channel pattern.
+	BackpressureSignals = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "backpressure_signals_total",
+		Help:      "Backpressure signals published for channels whose frames connections kept dropping, by channel pattern.",
+	}, []string{"pattern"})
+
+	// BannedRejected counts connections refused because the user or IP is on the deny-list.
+	BannedRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "banned_rejected_total",
+		Help:      "Connections refused by the deny-list, by ban kind.",
+	}, []string{"kind"})
+
+	// QuotaRejected counts connections refused because the user reached their quota.
+	QuotaRejected = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "quota_rejected_total",
+		Help:      "Connections refused because the user already held their connection quota.",
+	})
+
+	// OutdatedClients counts connections refused because the client is below
+	// the minimum version, by the label whose minimum applied ("*" for the default).
+	OutdatedClients = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "outdated_clients_total",
+		Help:      "Connections refused with 426 or close code 4260 for an outdated client version, by client label policy.",
+	}, []string{"client_label"})
+
+	// QuotaKicked counts connections closed to make room for a newer one of the same user.
+	QuotaKicked = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "quota_kicked_total",
+		Help:      "Oldest connections closed because the user connected again at their quota (policy kick_oldest).",
+	})
+
+	// SendQueueEvicted counts frames evicted from full send queues (policy drop_oldest).
+	SendQueueEvicted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "send_queue_evicted_total",
+		Help:      "Oldest queued frames evicted for a newer one because a connection's send queue was full.",
+	})
+
+	// FramesDropped counts frames that did not reach a connection.
+	FramesDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "frames_dropped_total",
+		Help:      "Frames that did not reach a connection, by reason (buffer_full, filtered, closed) and client label.",
+	}, []string{"reason", "client_label"})
+
+	// StageTimeouts counts upgrades abandoned because a stage overran its budget.
+	StageTimeouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "stage_timeouts_total",
+		Help:      "Connection setups abandoned because a stage overran websocket.timeouts, by stage (auth, upgrade, register).",
+	}, []string{"stage"})
+)
+
+// Transform metrics
+var (
+	// DisabledTypeDropped counts messages of a disabled message type, by message type.
+	DisabledTypeDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "transform",
+		Name:      "disabled_type_dropped_total",
+		Help:      "Messages dropped on arrival because their message type is disabled, by message type.",
+	}, []string{"type"})
+
+	// ValidationFailures counts payloads that failed validation, by message type and validation mode.
+	ValidationFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "transform",
+		Name:      "validation_failures_total",
+		Help:      "Payloads that failed validation, by message type and validation mode.",
+	}, []string{"type", "mode"})
+
+	// InvalidTransitions counts job status updates whose status may not follow
+	// the previous one, by message type, transition and action (flagged, suppressed).
+	InvalidTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "transform",
+		Name:      "invalid_transitions_total",
+		Help:      "Job status updates with a status that may not follow the previous one, by message type, transition and action.",
+	}, []string{"type", "from", "to", "action"})
+
+	// DuplicateTerminals counts terminal job updates that repeat the job's last
+	// one within the dedupe window, by message type and action (suppressed, forced).
+	DuplicateTerminals = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "transform",
+		Name:      "duplicate_terminals_total",
+		Help:      "Terminal job updates repeating the job's last one within the dedupe window, by message type and action.",
+	}, []string{"type", "action"})
+
+	// SanitizedFields counts free-text payload fields changed by sanitization,
+	// by message type, field and action (invalid_utf8, control_chars, truncated).
+	SanitizedFields = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "transform",
+		Name:      "sanitized_fields_total",
+		Help:      "Free-text payload fields changed by sanitization, by message type, field and action.",
+	}, []string{"type", "field", "action"})
+
+	// ProcessErrors counts subscriber messages that failed processing, by error code and category.
+	ProcessErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "transform",
+		Name:      "process_errors_total",
+		Help:      "Subscriber messages that failed processing, by error code and category.",
+	}, []string{"code", "category"})
+
+	// ShadowRuns counts shadow transform comparisons, by message type and result
+	// (match, diff, error_mismatch, error).
+	ShadowRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "transform",
+		Name:      "shadow_runs_total",
+		Help:      "Shadow transform comparisons, by message type and result.",
+	}, []string{"type", "result"})
+
+	// ShadowFieldDiffs counts fields whose value differed between the primary and shadow transformer.
+	ShadowFieldDiffs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "transform",
+		Name:      "shadow_field_diffs_total",
+		Help:      "Fields that differed between primary and shadow transform output.",
+	}, []string{"type", "field"})
+)
+
+// Runtime metrics
+var (
+	// ComponentRestarts counts supervised goroutines restarted after a panic.
+	ComponentRestarts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "runtime",
+		Name:      "component_restarts_total",
+		Help:      "Supervised background loops restarted after a panic, by component.",
+	}, []string{"component"})
+)
+
+// Redis probe metrics
+var (
+	// ProbeLatency is the publish-to-receive loopback latency of the synthetic Redis probe.
+	ProbeLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "redis",
+		Name:      "probe_latency_seconds",
+		Help:      "Loopback latency of the synthetic Redis pub/sub probe, by upstream region.",
+		Buckets:   []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+	}, []string{"region"})
+
+	// ProbeFailures counts probe publishes that failed.
+	ProbeFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "redis",
+		Name:      "probe_publish_failures_total",
+		Help:      "Synthetic Redis probe publishes that failed, by upstream region.",
+	}, []string{"region"})
+
+	// ProbeHealthy is 1 while probe loopbacks arrive in time, 0 otherwise.
+	ProbeHealthy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "redis",
+		Name:      "probe_healthy",
+		Help:      "1 if the Redis subscription loopback probe of an upstream region is healthy, 0 otherwise.",
+	}, []string{"region"})
+
+	// InboundPayloadBytes is the size of inbound messages, by upstream region and
+	// channel type. Its count and sum are the messages and bytes each kind of
+	// publisher sends.
+	InboundPayloadBytes = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "redis",
+		Name:      "inbound_payload_bytes",
+		Help:      "Size of inbound Redis messages, by upstream region and channel type.",
+		Buckets:   prometheus.ExponentialBuckets(256, 4, 8), // 256B to 4MiB
+	}, []string{"region", "channel_type"})
+
+	// InboundRateLimited counts inbound messages over their channel's rate, by
+	// channel type and action (dropped, delayed, coalesced).
+	InboundRateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "redis",
+		Name:      "inbound_rate_limited_total",
+		Help:      "Inbound messages over their channel's rate limit, by channel type and action.",
+	}, []string{"channel_type", "action"})
+
+	// RedisSlowCommands counts Redis commands slower than redis.slow_command_threshold.
+	RedisSlowCommands = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "redis",
+		Name:      "slow_commands_total",
+		Help:      "Redis commands slower than the slow command threshold, by region and command.",
+	}, []string{"region", "command"})
+)
+
+// Outbound sink metrics
+var (
+	// SinkRecords counts notifications mirrored to outbound sinks, by outcome
+	// (sent, failed, dropped).
+	SinkRecords = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "sink",
+		Name:      "records_total",
+		Help:      "Notifications mirrored to outbound sinks, by sink and result (sent, failed, dropped).",
+	}, []string{"sink", "result"})
+
+	// SinkBatchDuration is the time spent producing one batch, retries included.
+	SinkBatchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "sink",
+		Name:      "batch_duration_seconds",
+		Help:      "Time to produce one batch to an outbound sink, retries included.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"sink"})
+)
+
+// Hub event bus metrics
+var (
+	// HubEventsDropped counts Hub events not handed to a subscriber that fell behind.
+	HubEventsDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "hub_events",
+		Name:      "dropped_total",
+		Help:      "Hub events dropped because a subscriber's queue was full, by event type.",
+	}, []string{"type"})
+)
+
+// Maintenance mode metrics
+var (
+	// MaintenanceSuppressed counts messages not delivered because maintenance mode is on.
+	MaintenanceSuppressed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "maintenance",
+		Name:      "suppressed_total",
+		Help:      "Non-critical messages dropped while maintenance mode is on, by message type.",
+	}, []string{"type"})
+
+	// MaintenanceEnabled is 1 while maintenance mode is on.
+	MaintenanceEnabled = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "maintenance",
+		Name:      "enabled",
+		Help:      "1 while maintenance mode is on, 0 otherwise.",
+	})
+)
+
+// Operator broadcast metrics
+var (
+	// Broadcasts counts operator broadcasts accepted by this replica.
+	Broadcasts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "admin",
+		Name:      "broadcasts_total",
+		Help:      "Operator broadcasts accepted by POST /admin/broadcast, by segment kind.",
+	}, []string{"segment"})
+)
+
+// HTTP metrics
+var (
+	// HTTPRequests counts HTTP requests by method, route template ("unmatched"
+	// when no route matched) and status.
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests, by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration is the latency of HTTP requests other than WebSocket upgrades.
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests other than WebSocket upgrades, by method and route.",
+		Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	}, []string{"method", "route"})
+)
+
+// Internal API metrics
+var (
+	// InternalRequests counts authentication outcomes of /internal/* requests.
+	InternalRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "internal",
+		Name:      "requests_total",
+		Help:      "Authentication of /internal/* requests, by caller and result (signed, static_key, rejected).",
+	}, []string{"caller", "result"})
+)
+
+// Circuit breaker metrics
+var (
+	// BreakerState is the state of each outbound dependency's circuit breaker.
+	BreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "breaker",
+		Name:      "state",
+		Help:      "Circuit breaker state by dependency: 0 closed, 1 half-open, 2 open.",
+	}, []string{"dependency"})
+
+	// BreakerTransitions counts circuit breaker state changes.
+	BreakerTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "breaker",
+		Name:      "transitions_total",
+		Help:      "Circuit breaker state changes, by dependency and new state.",
+	}, []string{"dependency", "state"})
+
+	// BreakerRejected counts calls failed fast by an open circuit breaker.
+	BreakerRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "breaker",
+		Name:      "rejected_total",
+		Help:      "Calls not made because the dependency's circuit breaker was open.",
+	}, []string{"dependency"})
+)
+
+// Leader election metrics
+var (
+	// LeaderStatus is 1 for the singleton tasks this replica currently leads.
+	LeaderStatus = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "leader",
+		Name:      "status",
+		Help:      "Whether this replica holds the lease of a singleton task: 1 leader, 0 follower.",
+	}, []string{"task"})
+
+	// LeaderChanges counts leadership changes of this replica.
+	LeaderChanges = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "leader",
+		Name:      "changes_total",
+		Help:      "Leadership changes of this replica, by task and event (acquired, lost).",
+	}, []string{"task", "event"})
+)
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/smap-hcmut/shared-libs/go/log"
+)
+
+// slowCommandHook logs and counts Redis commands slower than threshold.
+// Blocking commands (SUBSCRIBE, BLPOP, ...) are expected to be slow and only
+// show up here if they are issued through Process.
+type slowCommandHook struct {
+	region    string
+	threshold time.Duration
+	logger    log.Logger
+}
+
+// InstrumentRedis exports the pool statistics of client under region and, if
+// slowThreshold is positive, logs commands slower than it.
+func InstrumentRedis(client *goredis.Client, region string, logger log.Logger, slowThreshold time.Duration) error {
+	if err := RegisterRedisPool(region, client.PoolStats); err != nil {
+		return err
+	}
+	if slowThreshold > 0 {
+		client.AddHook(&slowCommandHook{region: region, threshold: slowThreshold, logger: logger})
+	}
+	return nil
+}
+
+func (h *slowCommandHook) DialHook(next goredis.DialHook) goredis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h *slowCommandHook) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook {
+	return func(ctx context.Context, cmd goredis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		if d := time.Since(start); d >= h.threshold {
+			h.slow(ctx, cmd.Name(), cmd.Name(), d, err)
+		}
+		return err
+	}
+}
+
+func (h *slowCommandHook) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []goredis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		if d := time.Since(start); d >= h.threshold {
+			names := make([]string, 0, len(cmds))
+			for _, cmd := range cmds {
+				names = append(names, cmd.Name())
+			}
+			h.slow(ctx, "pipeline["+strings.Join(names, ",")+"]", "pipeline", d, err)
+		}
+		return err
+	}
+}
+
+// slow logs a slow command under name and counts it under the metric label
+// command, which stays low-cardinality for pipelines.
+func (h *slowCommandHook) slow(ctx context.Context, name, command string, d time.Duration, err error) {
+	RedisSlowCommands.WithLabelValues(h.region, command).Inc()
+	if err != nil && err != goredis.Nil {
+		h.logger.Warnf(ctx, "redis slow command: region=%s command=%s duration=%s err=%v", h.region, name, d, err)
+		return
+	}
+	h.logger.Warnf(ctx, "redis slow command: region=%s command=%s duration=%s", h.region, name, d)
+}
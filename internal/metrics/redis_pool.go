@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// redisPoolCollector exports the pool statistics of one Redis client at scrape time.
+type redisPoolCollector struct {
+	stats func() *goredis.PoolStats
+
+	hits, misses, timeouts *prometheus.Desc
+	total, idle, stale     *prometheus.Desc
+}
+
+// RegisterRedisPool exports the connection pool statistics of a Redis client,
+// labelled with its region. stats is usually (*goredis.Client).PoolStats.
+func RegisterRedisPool(region string, stats func() *goredis.PoolStats) error {
+	labels := prometheus.Labels{"region": region}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "redis_pool", name), help, nil, labels)
+	}
+
+	return prometheus.Register(&redisPoolCollector{
+		stats:    stats,
+		hits:     desc("hits_total", "Times a free connection was found in the Redis pool."),
+		misses:   desc("misses_total", "Times a free connection was not found in the Redis pool."),
+		timeouts: desc("timeouts_total", "Times waiting for a Redis pool connection timed out."),
+		total:    desc("connections", "Connections in the Redis pool."),
+		idle:     desc("idle_connections", "Idle connections in the Redis pool."),
+		stale:    desc("stale_connections_total", "Stale connections removed from the Redis pool."),
+	})
+}
+
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.total
+	ch <- c.idle
+	ch <- c.stale
+}
+
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(s.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(s.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(s.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.stale, prometheus.CounterValue, float64(s.StaleConns))
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// TokenClaims returns the claims of a JWT, including those auth.Payload does
+// not carry (e.g. plan). It does not verify the token: call it only on a token
+// the JWT manager has accepted. Returns nil if the token cannot be decoded.
+func TokenClaims(token string) map[string]any {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return nil
+	}
+	return claims
+}
@@ -1,5 +1,7 @@
 package model
 
+import "github.com/smap-hcmut/shared-libs/go/auth"
+
 const (
 	RoleAdmin   = "ADMIN"
 	RoleAnalyst = "ANALYST"
@@ -27,3 +29,13 @@ func (s Scope) IsAnalyst() bool {
 func (s Scope) IsViewer() bool {
 	return s.Role == RoleViewer
 }
+
+// ToScope converts the auth scope set by the JWT middleware into the service scope.
+func ToScope(s auth.Scope) Scope {
+	return Scope{
+		UserID:   s.UserID,
+		Username: s.Username,
+		Role:     s.Role,
+		JTI:      s.JTI,
+	}
+}
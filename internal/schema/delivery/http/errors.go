@@ -0,0 +1,19 @@
+package http
+
+import (
+	"errors"
+
+	"notification-srv/internal/schema"
+)
+
+// mapError passes through the domain errors this handler answers; Respond
+// derives their status and body from the errcode taxonomy.
+func (h *handler) mapError(err error) error {
+	switch {
+	case errors.Is(err, schema.ErrUnknownMessageType),
+		errors.Is(err, schema.ErrUnknownVersion):
+		return err
+	default:
+		panic(err)
+	}
+}
@@ -0,0 +1,39 @@
+package http
+
+import (
+	"net/http"
+
+	"notification-srv/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSchema serves the JSON Schema of a message payload.
+// The document is returned as-is (not wrapped in the standard response envelope)
+// so validators can consume the URL directly.
+// @Summary Get message contract schema
+// @Description Returns the JSON Schema (draft 2020-12) generated from the Go payload type of a message type and contract version.
+// @Tags Schemas
+// @Produce json
+// @Param message_type path string true "Message type" Enums(DATA_ONBOARDING, ANALYTICS_PIPELINE, CRISIS_ALERT, CAMPAIGN_EVENT, SYSTEM)
+// @Param version path string true "Contract version" example(v1)
+// @Success 200 {object} map[string]interface{} "JSON Schema document"
+// @Failure 400 {object} errcode.Problem "Bad Request"
+// @Failure 404 {object} errcode.Problem "Not Found"
+// @Router /schemas/{message_type}/{version} [GET]
+func (h *handler) GetSchema(c *gin.Context) {
+	req, err := h.processGetSchemaRequest(c)
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	output, err := h.uc.GetSchema(c.Request.Context(), req.toInput())
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	c.Header("Content-Type", "application/schema+json")
+	c.JSON(http.StatusOK, output.Schema)
+}
@@ -0,0 +1,25 @@
+package http
+
+import (
+	"notification-srv/internal/schema"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smap-hcmut/shared-libs/go/log"
+)
+
+// Handler defines the HTTP handler interface for message contract schemas.
+type Handler interface {
+	RegisterRoutes(r *gin.RouterGroup)
+}
+
+type handler struct {
+	uc     schema.UseCase
+	logger log.Logger
+}
+
+func New(logger log.Logger, uc schema.UseCase) Handler {
+	return &handler{
+		uc:     uc,
+		logger: logger,
+	}
+}
@@ -0,0 +1,31 @@
+package http
+
+import (
+	"strings"
+
+	"notification-srv/internal/schema"
+)
+
+// --- Request DTOs ---
+
+type getSchemaReq struct {
+	MessageType string
+	Version     string
+}
+
+func (r getSchemaReq) validate() error {
+	if r.MessageType == "" {
+		return schema.ErrUnknownMessageType
+	}
+	if r.Version == "" {
+		return schema.ErrUnknownVersion
+	}
+	return nil
+}
+
+func (r getSchemaReq) toInput() schema.GetSchemaInput {
+	return schema.GetSchemaInput{
+		MessageType: strings.ToUpper(r.MessageType),
+		Version:     strings.ToLower(r.Version),
+	}
+}
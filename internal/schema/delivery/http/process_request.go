@@ -0,0 +1,16 @@
+package http
+
+import "github.com/gin-gonic/gin"
+
+// processGetSchemaRequest binds and validates the path parameters.
+func (h *handler) processGetSchemaRequest(c *gin.Context) (getSchemaReq, error) {
+	req := getSchemaReq{
+		MessageType: c.Param("message_type"),
+		Version:     c.Param("version"),
+	}
+
+	if err := req.validate(); err != nil {
+		return getSchemaReq{}, err
+	}
+	return req, nil
+}
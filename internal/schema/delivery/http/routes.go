@@ -0,0 +1,9 @@
+package http
+
+import "github.com/gin-gonic/gin"
+
+// RegisterRoutes registers the schema routes. Contracts are public so publisher
+// teams and CI pipelines can fetch them without a user token.
+func (h *handler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/schemas/:message_type/:version", h.GetSchema)
+}
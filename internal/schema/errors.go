@@ -0,0 +1,8 @@
+package schema
+
+import "notification-srv/pkg/errcode"
+
+var (
+	ErrUnknownMessageType = errcode.New("schema.unknown_type", errcode.NotFound, "unknown message type", "Unknown message type")
+	ErrUnknownVersion     = errcode.New("schema.unknown_version", errcode.NotFound, "unknown schema version", "Unknown schema version")
+)
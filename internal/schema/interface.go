@@ -0,0 +1,8 @@
+package schema
+
+import "context"
+
+// UseCase defines the logic for publishing message contracts as JSON Schema.
+type UseCase interface {
+	GetSchema(ctx context.Context, input GetSchemaInput) (GetSchemaOutput, error)
+}
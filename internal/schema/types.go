@@ -0,0 +1,12 @@
+package schema
+
+// GetSchemaInput identifies a message contract.
+type GetSchemaInput struct {
+	MessageType string // e.g. DATA_ONBOARDING
+	Version     string // e.g. v1
+}
+
+// GetSchemaOutput holds a JSON Schema (draft 2020-12) document.
+type GetSchemaOutput struct {
+	Schema map[string]any
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+// document builds the top-level JSON Schema for a message payload type.
+func document(version, msgType string, t reflect.Type) map[string]any {
+	doc := map[string]any{"type": "object"}
+	if t != nil {
+		doc = typeSchema(t)
+	}
+
+	doc["$schema"] = draft
+	doc["$id"] = fmt.Sprintf("/schemas/%s/%s", msgType, version)
+	doc["title"] = fmt.Sprintf("%s payload (%s)", msgType, version)
+	return doc
+}
+
+// typeSchema maps a Go type to a JSON Schema fragment, following encoding/json rules.
+func typeSchema(t reflect.Type) map[string]any {
+	if t == reflect.TypeFor[time.Time]() {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": []string{"array", "null"}, "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		// interface{} and anything else: any JSON value
+		return map[string]any{}
+	}
+}
+
+// structSchema lists exported fields under their JSON names. Payloads are
+// decoded leniently (missing fields take zero values), so nothing is required.
+func structSchema(t reflect.Type) map[string]any {
+	props := make(map[string]any)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		props[name] = typeSchema(f.Type)
+	}
+
+	return map[string]any{
+		"type":       "object",
+		"properties": props,
+	}
+}
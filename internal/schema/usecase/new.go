@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"notification-srv/internal/schema"
+
+	"github.com/smap-hcmut/shared-libs/go/log"
+)
+
+// implUseCase implements schema.UseCase.
+type implUseCase struct {
+	logger log.Logger
+
+	// Generated documents, keyed by version then message type. Contracts are
+	// compiled into the binary, so they are generated once at startup.
+	schemas map[string]map[string]map[string]any
+}
+
+// New creates a new Schema UseCase.
+func New(logger log.Logger) schema.UseCase {
+	schemas := make(map[string]map[string]map[string]any, len(contracts))
+	for version, types := range contracts {
+		schemas[version] = make(map[string]map[string]any, len(types))
+		for msgType, t := range types {
+			schemas[version][string(msgType)] = document(version, string(msgType), t)
+		}
+	}
+
+	return &implUseCase{
+		logger:  logger,
+		schemas: schemas,
+	}
+}
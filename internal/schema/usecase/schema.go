@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"context"
+
+	"notification-srv/internal/schema"
+)
+
+func (uc *implUseCase) GetSchema(ctx context.Context, input schema.GetSchemaInput) (schema.GetSchemaOutput, error) {
+	byType, ok := uc.schemas[input.Version]
+	if !ok {
+		return schema.GetSchemaOutput{}, schema.ErrUnknownVersion
+	}
+
+	doc, ok := byType[input.MessageType]
+	if !ok {
+		return schema.GetSchemaOutput{}, schema.ErrUnknownMessageType
+	}
+
+	return schema.GetSchemaOutput{Schema: doc}, nil
+}
@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"reflect"
+
+	ws "notification-srv/internal/websocket"
+)
+
+// contracts maps each schema version to the payload type of every message type.
+// A nil type means the payload is free-form. Add a new version here (instead of
+// changing an existing one) when a payload changes incompatibly.
+var contracts = map[string]map[ws.MessageType]reflect.Type{
+	"v1": {
+		ws.MessageTypeDataOnboarding:    reflect.TypeFor[ws.DataOnboardingPayload](),
+		ws.MessageTypeAnalyticsPipeline: reflect.TypeFor[ws.AnalyticsPipelinePayload](),
+		ws.MessageTypeCrisisAlert:       reflect.TypeFor[ws.CrisisAlertPayload](),
+		ws.MessageTypeCampaignEvent:     reflect.TypeFor[ws.CampaignEventPayload](),
+		ws.MessageTypeSystem:            nil,
+		ws.MessageTypeProjectProgress:   reflect.TypeFor[ws.ProjectProgressPayload](),
+
+		ws.MessageTypeServiceAnnouncement: reflect.TypeFor[ws.ServiceAnnouncementPayload](),
+
+		ws.MessageTypeSecurityAlert:     reflect.TypeFor[ws.SecurityAlertPayload](),
+		ws.MessageTypeBillingEvent:      reflect.TypeFor[ws.BillingEventPayload](),
+		ws.MessageTypeSystemMaintenance: reflect.TypeFor[ws.SystemMaintenancePayload](),
+	},
+}
@@ -0,0 +1,93 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// NewBatcher creates a Batcher. Call Start to begin flushing.
+func NewBatcher(cfg BatchConfig, flush FlushFunc) *Batcher {
+	return &Batcher{
+		cfg:   cfg,
+		flush: flush,
+		queue: make(chan Notification, cfg.QueueSize),
+		quit:  make(chan struct{}),
+	}
+}
+
+// Enqueue queues n without blocking. It reports false when the queue is full.
+func (b *Batcher) Enqueue(n Notification) bool {
+	select {
+	case b.queue <- n:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *Batcher) Start() {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.run(context.Background())
+	}()
+}
+
+// Close stops batching and flushes what is still queued, within ctx.
+func (b *Batcher) Close(ctx context.Context) error {
+	close(b.quit)
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("flush on close: %w", ctx.Err())
+	}
+}
+
+func (b *Batcher) run(ctx context.Context) {
+	ticker := time.NewTicker(b.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Notification, 0, b.cfg.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		b.flush(ctx, batch)
+		batch = batch[:0]
+	}
+	add := func(n Notification) {
+		batch = append(batch, n)
+		if len(batch) >= b.cfg.BatchSize {
+			flush()
+		}
+	}
+
+	for {
+		select {
+		case n := <-b.queue:
+			add(n)
+		case <-ticker.C:
+			flush()
+		case <-b.quit:
+			// Drain what was queued before shutdown
+			for {
+				select {
+				case n := <-b.queue:
+					add(n)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package clickhouse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"notification-srv/internal/metrics"
+	"notification-srv/internal/sink"
+	"notification-srv/pkg/breaker"
+)
+
+func (s *clickhouseSink) Name() string {
+	return sinkName
+}
+
+func (s *clickhouseSink) Publish(ctx context.Context, n sink.Notification) {
+	if !s.batcher.Enqueue(n) {
+		metrics.SinkRecords.WithLabelValues(sinkName, "dropped").Inc()
+	}
+}
+
+func (s *clickhouseSink) Start(ctx context.Context) error {
+	s.batcher.Start()
+	s.logger.Infof(ctx, "ClickHouse exporter started: table=%s", s.cfg.Table)
+	return nil
+}
+
+func (s *clickhouseSink) Close(ctx context.Context) error {
+	return s.batcher.Close(ctx)
+}
+
+func (s *clickhouseSink) flush(ctx context.Context, batch []sink.Notification) {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	rows := 0
+	for _, n := range batch {
+		if err := enc.Encode(toEvent(n)); err != nil {
+			metrics.SinkRecords.WithLabelValues(sinkName, "failed").Inc()
+			s.logger.Warnf(ctx, "clickhouse sink: encode event %s: %v", n.ID, err)
+			continue
+		}
+		rows++
+	}
+	if rows == 0 {
+		return
+	}
+
+	start := time.Now()
+	defer func() {
+		metrics.SinkBatchDuration.WithLabelValues(sinkName).Observe(time.Since(start).Seconds())
+	}()
+
+	for attempt := 0; ; attempt++ {
+		err := s.cfg.Breaker.Do(func() error { return s.insert(ctx, body.Bytes()) }, nil)
+		if errors.Is(err, breaker.ErrOpen) {
+			metrics.BreakerRejected.WithLabelValues(sinkName).Inc()
+			metrics.SinkRecords.WithLabelValues(sinkName, "dropped").Add(float64(rows))
+			return
+		}
+		if err == nil {
+			metrics.SinkRecords.WithLabelValues(sinkName, "sent").Add(float64(rows))
+			return
+		}
+
+		if attempt >= s.cfg.MaxRetries {
+			metrics.SinkRecords.WithLabelValues(sinkName, "failed").Add(float64(rows))
+			s.logger.Errorf(ctx, "clickhouse sink: dropping %d events after %d attempts: %v", rows, attempt+1, err)
+			return
+		}
+		time.Sleep(time.Duration(attempt+1) * 200 * time.Millisecond)
+	}
+}
+
+// insert posts newline-delimited rows to the ClickHouse HTTP interface.
+func (s *clickhouseSink) insert(ctx context.Context, rows []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.insertURL, bytes.NewReader(rows))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if s.cfg.Username != "" {
+		req.Header.Set("X-ClickHouse-User", s.cfg.Username)
+		req.Header.Set("X-ClickHouse-Key", s.cfg.Password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("clickhouse returned %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+func toEvent(n sink.Notification) deliveryEvent {
+	status := "delivered"
+	switch {
+	case n.Recipients < 0:
+		status = "broadcast"
+	case n.Recipients == 0 && n.Dropped > 0:
+		status = "dropped"
+	case n.Recipients == 0:
+		status = "no_recipient"
+	}
+
+	topic := n.ChannelType
+	if n.EntityID != "" {
+		topic += ":" + n.EntityID
+	}
+
+	return deliveryEvent{
+		EventTime:   n.DeliveredAt.UTC().Format("2006-01-02 15:04:05.000"),
+		MessageID:   n.ID,
+		MessageType: n.Type,
+		ChannelType: n.ChannelType,
+		Topic:       topic,
+		UserID:      n.UserID,
+		Region:      n.Region,
+		FrameBytes:  n.Size,
+		Recipients:  n.Recipients,
+		Dropped:     n.Dropped,
+		Status:      status,
+		LatencyMs:   n.DeliveredAt.Sub(n.ReceivedAt).Milliseconds(),
+	}
+}
@@ -0,0 +1,49 @@
+package clickhouse
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"notification-srv/internal/sink"
+
+	"github.com/smap-hcmut/shared-libs/go/log"
+)
+
+const sinkName = "clickhouse"
+
+type clickhouseSink struct {
+	logger    log.Logger
+	client    *http.Client
+	cfg       Config
+	insertURL string
+
+	batcher *sink.Batcher
+}
+
+// New creates the ClickHouse exporter. Call Start to begin exporting.
+func New(logger log.Logger, cfg Config) (sink.Sink, error) {
+	if cfg.URL == "" || cfg.Table == "" {
+		return nil, errors.New("clickhouse sink: url and table are required")
+	}
+
+	table := cfg.Table
+	if cfg.Database != "" {
+		table = cfg.Database + "." + table
+	}
+	query := url.Values{"query": {"INSERT INTO " + table + " FORMAT JSONEachRow"}}
+
+	s := &clickhouseSink{
+		logger:    logger,
+		client:    &http.Client{Timeout: cfg.Timeout},
+		cfg:       cfg,
+		insertURL: strings.TrimRight(cfg.URL, "/") + "/?" + query.Encode(),
+	}
+	s.batcher = sink.NewBatcher(sink.BatchConfig{
+		BatchSize:     cfg.BatchSize,
+		FlushInterval: cfg.FlushInterval,
+		QueueSize:     cfg.QueueSize,
+	}, s.flush)
+	return s, nil
+}
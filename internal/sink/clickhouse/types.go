@@ -0,0 +1,43 @@
+package clickhouse
+
+import (
+	"time"
+
+	"notification-srv/pkg/breaker"
+)
+
+// Config controls the ClickHouse delivery event exporter. Rows are inserted
+// over the ClickHouse HTTP interface as JSONEachRow, batched and asynchronously.
+type Config struct {
+	URL      string // e.g. http://clickhouse:8123
+	Database string
+	Table    string
+	Username string
+	Password string
+
+	BatchSize     int
+	FlushInterval time.Duration
+	QueueSize     int // Events buffered before new ones are dropped
+	Timeout       time.Duration
+	MaxRetries    int // Retries of a failed insert before the batch is dropped
+
+	// Breaker, if set, drops batches without calling ClickHouse while it keeps failing
+	Breaker *breaker.Breaker
+}
+
+// deliveryEvent is one row of the delivery events table
+// (see scripts/clickhouse/delivery_events.sql).
+type deliveryEvent struct {
+	EventTime   string `json:"event_time"` // DateTime64(3), UTC
+	MessageID   string `json:"message_id"`
+	MessageType string `json:"message_type"`
+	ChannelType string `json:"channel_type"`
+	Topic       string `json:"topic"`
+	UserID      string `json:"user_id"`
+	Region      string `json:"region"`
+	FrameBytes  int    `json:"frame_bytes"`
+	Recipients  int    `json:"recipients"`
+	Dropped     int    `json:"dropped"`
+	Status      string `json:"status"`     // delivered, dropped, no_recipient, broadcast
+	LatencyMs   int64  `json:"latency_ms"` // From Redis receipt to Hub queueing
+}
@@ -0,0 +1,21 @@
+package sink
+
+import (
+	"context"
+)
+
+// Sink receives a copy of every notification routed to WebSocket clients,
+// with its delivery outcome, e.g. to feed an analytics pipeline.
+type Sink interface {
+	// Name identifies the sink in logs, metrics and lifecycle components.
+	Name() string
+
+	// Publish queues a routed notification; Recipients is 0 when it reached no
+	// connection. It must not block the delivery path; sinks drop (and count)
+	// what they cannot keep up with.
+	Publish(ctx context.Context, n Notification)
+
+	// Start and Close bound the sink's background work. Close flushes what is queued.
+	Start(ctx context.Context) error
+	Close(ctx context.Context) error
+}
@@ -0,0 +1,67 @@
+package kafka
+
+import (
+	"errors"
+	"time"
+
+	"notification-srv/internal/sink"
+
+	"github.com/smap-hcmut/shared-libs/go/log"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+const sinkName = "kafka"
+
+type kafkaSink struct {
+	logger log.Logger
+	client *kgo.Client
+	cfg    Config
+	types  map[string]bool // nil mirrors every type
+
+	batcher *sink.Batcher
+}
+
+// New creates the Kafka sink. Call Start to begin producing.
+func New(logger log.Logger, cfg Config) (sink.Sink, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, errors.New("kafka sink: brokers are required")
+	}
+	if cfg.Topic == "" {
+		return nil, errors.New("kafka sink: topic is required")
+	}
+
+	var types map[string]bool
+	if len(cfg.MessageTypes) > 0 {
+		types = make(map[string]bool, len(cfg.MessageTypes))
+		for _, t := range cfg.MessageTypes {
+			types[t] = true
+		}
+	}
+
+	// The client connects lazily, so the brokers may come up after the service
+	client, err := kgo.NewClient(
+		kgo.SeedBrokers(cfg.Brokers...),
+		kgo.DefaultProduceTopic(cfg.Topic),
+		kgo.ProduceRequestTimeout(cfg.Timeout),
+		kgo.RecordRetries(cfg.MaxRetries),
+		// Bounds retries the broker cannot count, such as failed dials; the
+		// client allows no less than a second
+		kgo.RecordDeliveryTimeout(max(time.Duration(cfg.MaxRetries+1)*cfg.Timeout, time.Second)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &kafkaSink{
+		logger: logger,
+		client: client,
+		cfg:    cfg,
+		types:  types,
+	}
+	s.batcher = sink.NewBatcher(sink.BatchConfig{
+		BatchSize:     cfg.BatchSize,
+		FlushInterval: cfg.FlushInterval,
+		QueueSize:     cfg.QueueSize,
+	}, s.flush)
+	return s, nil
+}
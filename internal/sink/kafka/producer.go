@@ -0,0 +1,121 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"notification-srv/internal/metrics"
+	"notification-srv/internal/sink"
+	"notification-srv/pkg/breaker"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func (s *kafkaSink) Name() string {
+	return sinkName
+}
+
+// Publish queues notifications that reached at least one connection.
+func (s *kafkaSink) Publish(ctx context.Context, n sink.Notification) {
+	if n.Recipients == 0 {
+		return
+	}
+	if s.types != nil && !s.types[n.Type] {
+		return
+	}
+
+	if !s.batcher.Enqueue(n) {
+		metrics.SinkRecords.WithLabelValues(sinkName, "dropped").Inc()
+	}
+}
+
+func (s *kafkaSink) Start(ctx context.Context) error {
+	s.batcher.Start()
+
+	pingCtx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	if err := s.client.Ping(pingCtx); err != nil {
+		s.logger.Warnf(ctx, "Kafka sink: brokers not reachable yet, producing will retry: %v", err)
+	}
+
+	s.logger.Infof(ctx, "Kafka sink started: brokers=%v topic=%s", s.cfg.Brokers, s.cfg.Topic)
+	return nil
+}
+
+// Close flushes what is still queued and waits for the records in flight, within ctx.
+func (s *kafkaSink) Close(ctx context.Context) error {
+	err := s.batcher.Close(ctx)
+	if ferr := s.client.Flush(ctx); err == nil {
+		err = ferr
+	}
+	s.client.Close()
+	return err
+}
+
+// flush hands a batch to the producer without waiting for the brokers; the
+// records' results are counted as they arrive.
+func (s *kafkaSink) flush(ctx context.Context, batch []sink.Notification) {
+	records := make([]*kgo.Record, 0, len(batch))
+	for _, n := range batch {
+		value, err := json.Marshal(n)
+		if err != nil {
+			metrics.SinkRecords.WithLabelValues(sinkName, "failed").Inc()
+			s.logger.Warnf(ctx, "kafka sink: marshal notification %s: %v", n.ID, err)
+			continue
+		}
+		// Keyed by user so one user's notifications stay ordered within a partition
+		records = append(records, &kgo.Record{Key: []byte(n.UserID), Value: value})
+	}
+	if len(records) == 0 {
+		return
+	}
+
+	done, err := s.cfg.Breaker.Allow()
+	if errors.Is(err, breaker.ErrOpen) {
+		metrics.BreakerRejected.WithLabelValues(sinkName).Inc()
+		metrics.SinkRecords.WithLabelValues(sinkName, "dropped").Add(float64(len(records)))
+		return
+	}
+
+	result := &batchResult{start: time.Now(), size: len(records), done: done, pending: len(records)}
+	for _, r := range records {
+		// Blocks while the producer's buffer is full, which backs up the batcher's queue
+		s.client.Produce(ctx, r, func(_ *kgo.Record, err error) {
+			s.settle(result, err)
+		})
+	}
+}
+
+// settle counts one record of a batch. The last one records the batch
+// duration and tells the breaker, which only counts a batch that failed
+// entirely as a failure.
+func (s *kafkaSink) settle(b *batchResult, err error) {
+	if err != nil {
+		metrics.SinkRecords.WithLabelValues(sinkName, "failed").Inc()
+	} else {
+		metrics.SinkRecords.WithLabelValues(sinkName, "sent").Inc()
+	}
+
+	b.mu.Lock()
+	if err != nil {
+		b.failed++
+		b.err = err
+	}
+	b.pending--
+	last := b.pending == 0
+	b.mu.Unlock()
+	if !last {
+		return
+	}
+
+	metrics.SinkBatchDuration.WithLabelValues(sinkName).Observe(time.Since(b.start).Seconds())
+	b.done(b.failed == b.size)
+	switch {
+	case b.failed == b.size:
+		s.logger.Errorf(context.Background(), "kafka sink: dropping %d records: %v", b.size, b.err)
+	case b.failed > 0:
+		s.logger.Warnf(context.Background(), "kafka sink: %d of %d records to topic %s failed: %v", b.failed, b.size, s.cfg.Topic, b.err)
+	}
+}
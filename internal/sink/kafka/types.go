@@ -0,0 +1,37 @@
+package kafka
+
+import (
+	"sync"
+	"time"
+
+	"notification-srv/pkg/breaker"
+)
+
+// Config controls the Kafka sink. Records are produced straight to the
+// brokers, batched and sent asynchronously.
+type Config struct {
+	Brokers       []string // Seed brokers, e.g. kafka-1:9092
+	Topic         string   // Target topic
+	MessageTypes  []string // Message types to mirror; empty mirrors every type
+	BatchSize     int      // Records handed to the producer at once
+	FlushInterval time.Duration
+	QueueSize     int           // Notifications buffered before new ones are dropped
+	Timeout       time.Duration // Per produce request
+	MaxRetries    int           // Retries of a failed record before it is dropped
+
+	// Breaker, if set, drops batches without producing while the brokers keep failing
+	Breaker *breaker.Breaker
+}
+
+// batchResult gathers the produce results of one batch, which arrive on the
+// producer's goroutines, and reports the batch once every record is settled.
+type batchResult struct {
+	start time.Time
+	size  int
+	done  func(failed bool) // Breaker report
+
+	mu      sync.Mutex
+	pending int
+	failed  int
+	err     error // Last produce error
+}
@@ -0,0 +1,128 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"notification-srv/internal/metrics"
+	"notification-srv/internal/sink"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	typeDataOnboarding    = "DATA_ONBOARDING"
+	typeAnalyticsPipeline = "ANALYTICS_PIPELINE"
+
+	statusCompleted = "COMPLETED"
+	statusFailed    = "FAILED"
+)
+
+func (s *mqttSink) Name() string {
+	return sinkName
+}
+
+// Publish queues project progress notifications; terminal ones are
+// republished whether or not a WebSocket client received them.
+func (s *mqttSink) Publish(ctx context.Context, n sink.Notification) {
+	if n.Type != typeDataOnboarding && n.Type != typeAnalyticsPipeline {
+		return
+	}
+
+	if !s.batcher.Enqueue(n) {
+		metrics.SinkRecords.WithLabelValues(sinkName, "dropped").Inc()
+	}
+}
+
+func (s *mqttSink) Start(ctx context.Context) error {
+	s.batcher.Start()
+
+	// With connect retry the client keeps trying in the background; publishes
+	// made meanwhile wait in its queue up to the timeout
+	token := s.client.Connect()
+	if !token.WaitTimeout(s.cfg.Timeout) {
+		s.logger.Warnf(ctx, "MQTT bridge: broker %s not reachable yet, retrying in background", s.cfg.BrokerURL)
+	} else if err := token.Error(); err != nil {
+		s.logger.Warnf(ctx, "MQTT bridge: connect to %s: %v", s.cfg.BrokerURL, err)
+	}
+
+	s.logger.Infof(ctx, "MQTT bridge started: broker=%s topic=%s", s.cfg.BrokerURL, s.cfg.TopicPattern)
+	return nil
+}
+
+func (s *mqttSink) Close(ctx context.Context) error {
+	err := s.batcher.Close(ctx)
+	s.client.Disconnect(uint(s.cfg.Timeout.Milliseconds()))
+	return err
+}
+
+func (s *mqttSink) flush(ctx context.Context, batch []sink.Notification) {
+	for _, n := range batch {
+		msg, ok := terminalStatus(n)
+		if !ok {
+			continue
+		}
+
+		body, err := json.Marshal(msg)
+		if err != nil {
+			metrics.SinkRecords.WithLabelValues(sinkName, "failed").Inc()
+			continue
+		}
+
+		topic := strings.ReplaceAll(s.cfg.TopicPattern, "{project_id}", msg.ProjectID)
+		token := s.client.Publish(topic, s.cfg.QoS, s.cfg.Retain, body)
+		if !token.WaitTimeout(s.cfg.Timeout) || token.Error() != nil {
+			metrics.SinkRecords.WithLabelValues(sinkName, "failed").Inc()
+			s.logger.Warnf(ctx, "MQTT bridge: publish %s to %s failed: %v", msg.Status, topic, token.Error())
+			continue
+		}
+		metrics.SinkRecords.WithLabelValues(sinkName, "sent").Inc()
+	}
+}
+
+func (s *mqttSink) onConnectionLost(_ paho.Client, err error) {
+	s.logger.Warnf(context.Background(), "MQTT bridge: connection lost: %v", err)
+}
+
+// terminalStatus reports whether a notification ends a project phase: an
+// onboarding that completed or failed, or a pipeline that reached 100%.
+func terminalStatus(n sink.Notification) (statusMessage, bool) {
+	var f frame
+	if err := json.Unmarshal(n.Frame, &f); err != nil {
+		return statusMessage{}, false
+	}
+
+	msg := statusMessage{
+		ProjectID: f.Payload.ProjectID,
+		Type:      n.Type,
+		Source:    f.Payload.SourceName,
+		Message:   f.Payload.Message,
+		Timestamp: f.Timestamp,
+	}
+	if msg.ProjectID == "" {
+		msg.ProjectID = n.EntityID
+	}
+	if msg.ProjectID == "" {
+		return statusMessage{}, false
+	}
+
+	switch n.Type {
+	case typeDataOnboarding:
+		status := strings.ToUpper(f.Payload.Status)
+		if status != statusCompleted && status != statusFailed {
+			return statusMessage{}, false
+		}
+		msg.Status = status
+
+	case typeAnalyticsPipeline:
+		if f.Payload.Progress < 100 {
+			return statusMessage{}, false
+		}
+		msg.Status = statusCompleted
+
+	default:
+		return statusMessage{}, false
+	}
+	return msg, true
+}
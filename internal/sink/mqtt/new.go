@@ -0,0 +1,59 @@
+package mqtt
+
+import (
+	"errors"
+	"strings"
+
+	"notification-srv/internal/sink"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/smap-hcmut/shared-libs/go/log"
+)
+
+const sinkName = "mqtt"
+
+type mqttSink struct {
+	logger log.Logger
+	client paho.Client
+	cfg    Config
+
+	batcher *sink.Batcher
+}
+
+// New creates the MQTT bridge. Call Start to connect to the broker.
+func New(logger log.Logger, cfg Config) (sink.Sink, error) {
+	if cfg.BrokerURL == "" {
+		return nil, errors.New("mqtt sink: broker url is required")
+	}
+	if !strings.Contains(cfg.TopicPattern, "{project_id}") {
+		return nil, errors.New("mqtt sink: topic pattern must contain {project_id}")
+	}
+	if cfg.QoS > 2 {
+		return nil, errors.New("mqtt sink: qos must be 0, 1 or 2")
+	}
+
+	opts := paho.NewClientOptions().
+		AddBroker(cfg.BrokerURL).
+		SetClientID(cfg.ClientID).
+		SetUsername(cfg.Username).
+		SetPassword(cfg.Password).
+		SetConnectTimeout(cfg.Timeout).
+		SetWriteTimeout(cfg.Timeout).
+		SetAutoReconnect(true).
+		SetConnectRetry(true) // The broker may come up after the service
+
+	s := &mqttSink{
+		logger: logger,
+		cfg:    cfg,
+	}
+	opts.SetConnectionLostHandler(s.onConnectionLost)
+	s.client = paho.NewClient(opts)
+
+	// Publishes wait for the broker ack, so keep them off the delivery path
+	s.batcher = sink.NewBatcher(sink.BatchConfig{
+		BatchSize:     1,
+		FlushInterval: cfg.Timeout,
+		QueueSize:     cfg.QueueSize,
+	}, s.flush)
+	return s, nil
+}
@@ -0,0 +1,45 @@
+package mqtt
+
+import (
+	"time"
+)
+
+// Config controls the MQTT bridge, which republishes terminal project
+// notifications (onboarding finished or failed, pipeline done) to a broker.
+type Config struct {
+	BrokerURL    string // e.g. tcp://mosquitto:1883 or ssl://broker:8883
+	ClientID     string
+	Username     string
+	Password     string
+	TopicPattern string // {project_id} is substituted, e.g. smap/project/{project_id}/status
+	QoS          byte
+	Retain       bool // Retained so a lamp that reconnects shows the last status
+	QueueSize    int  // Notifications buffered before new ones are dropped
+	Timeout      time.Duration
+}
+
+// statusMessage is the MQTT payload published for a terminal notification.
+type statusMessage struct {
+	ProjectID string    `json:"project_id"`
+	Type      string    `json:"type"`
+	Status    string    `json:"status"` // COMPLETED | FAILED
+	Source    string    `json:"source,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// frame is the part of a delivered frame the bridge reads.
+type frame struct {
+	Type      string       `json:"type"`
+	Timestamp time.Time    `json:"timestamp"`
+	Payload   framePayload `json:"payload"`
+}
+
+// framePayload covers DATA_ONBOARDING and ANALYTICS_PIPELINE payloads.
+type framePayload struct {
+	ProjectID  string `json:"project_id"`
+	SourceName string `json:"source_name"`
+	Status     string `json:"status"`
+	Progress   int    `json:"progress"`
+	Message    string `json:"message"`
+}
@@ -0,0 +1,45 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Notification is a notification as delivered to clients, with routing metadata.
+type Notification struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	Importance  string          `json:"importance,omitempty"` // low, normal, high or critical; push and email consumers should act on high and up only
+	ChannelType string          `json:"channel_type"`
+	EntityID    string          `json:"entity_id,omitempty"` // Project or campaign ID
+	UserID      string          `json:"user_id,omitempty"`   // Empty for broadcasts
+	Region      string          `json:"region,omitempty"`
+	Recipients  int             `json:"recipients"`  // Connections the frame was queued to; -1 for broadcasts
+	Dropped     int             `json:"dropped"`     // Connections skipped because their send buffer was full
+	Size        int             `json:"size"`        // Frame size in bytes
+	ReceivedAt  time.Time       `json:"received_at"` // When the message was read from Redis
+	DeliveredAt time.Time       `json:"delivered_at"`
+	Frame       json.RawMessage `json:"frame"` // The frame exactly as sent to clients
+}
+
+// BatchConfig controls a Batcher.
+type BatchConfig struct {
+	BatchSize     int // Notifications per flush
+	FlushInterval time.Duration
+	QueueSize     int // Notifications buffered before Enqueue starts rejecting
+}
+
+// FlushFunc writes one batch. The batch slice is reused after it returns.
+type FlushFunc func(ctx context.Context, batch []Notification)
+
+// Batcher queues notifications and flushes them in batches from a single
+// goroutine, when a batch is full or the flush interval elapses.
+type Batcher struct {
+	cfg   BatchConfig
+	flush FlushFunc
+	queue chan Notification
+	quit  chan struct{}
+	wg    sync.WaitGroup
+}
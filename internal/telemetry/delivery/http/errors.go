@@ -0,0 +1,20 @@
+package http
+
+import (
+	"errors"
+
+	"notification-srv/internal/telemetry"
+)
+
+// mapError passes through the domain errors this handler answers; Respond
+// derives their status and body from the errcode taxonomy.
+func (h *handler) mapError(err error) error {
+	switch {
+	case errors.Is(err, telemetry.ErrEmptyReport),
+		errors.Is(err, telemetry.ErrTooManySamples),
+		errors.Is(err, telemetry.ErrInvalidSample):
+		return err
+	default:
+		panic(err)
+	}
+}
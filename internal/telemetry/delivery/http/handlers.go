@@ -0,0 +1,37 @@
+package http
+
+import (
+	"notification-srv/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smap-hcmut/shared-libs/go/response"
+)
+
+// ReportLatency ingests client-side receive timestamps for delivered messages.
+// @Summary Report delivery latency
+// @Description Accepts message-ID receive timestamps from the browser client. The server joins them with emit times to compute end-to-end delivery latency histograms.
+// @Tags Telemetry
+// @Accept json
+// @Produce json
+// @Param body body reportLatencyReq true "Receive timestamps"
+// @Success 200 {object} reportLatencyResp
+// @Failure 400 {object} errcode.Problem "Bad Request"
+// @Failure 401 {object} errcode.Problem "Unauthorized"
+// @Security CookieAuth
+// @Security Bearer
+// @Router /api/telemetry/latency [POST]
+func (h *handler) ReportLatency(c *gin.Context) {
+	req, sc, err := h.processReportLatencyRequest(c)
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	output, err := h.uc.ReportLatency(c.Request.Context(), sc, req.toInput())
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	response.OK(c, h.newReportLatencyResp(output))
+}
@@ -0,0 +1,26 @@
+package http
+
+import (
+	"notification-srv/internal/telemetry"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smap-hcmut/shared-libs/go/log"
+	"github.com/smap-hcmut/shared-libs/go/middleware"
+)
+
+// Handler defines the HTTP handler interface for client telemetry.
+type Handler interface {
+	RegisterRoutes(r *gin.RouterGroup, mw *middleware.Middleware)
+}
+
+type handler struct {
+	uc     telemetry.UseCase
+	logger log.Logger
+}
+
+func New(logger log.Logger, uc telemetry.UseCase) Handler {
+	return &handler{
+		uc:     uc,
+		logger: logger,
+	}
+}
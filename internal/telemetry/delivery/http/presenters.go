@@ -0,0 +1,55 @@
+package http
+
+import (
+	"time"
+
+	"notification-srv/internal/telemetry"
+)
+
+// --- Request DTOs ---
+
+type latencySampleReq struct {
+	MessageID  string `json:"message_id"`
+	ReceivedAt int64  `json:"received_at"` // Unix milliseconds on the client clock
+}
+
+type reportLatencyReq struct {
+	Reports []latencySampleReq `json:"reports"`
+}
+
+func (r reportLatencyReq) validate() error {
+	if len(r.Reports) == 0 {
+		return telemetry.ErrEmptyReport
+	}
+	for _, s := range r.Reports {
+		if s.MessageID == "" || s.ReceivedAt <= 0 {
+			return telemetry.ErrInvalidSample
+		}
+	}
+	return nil
+}
+
+func (r reportLatencyReq) toInput() telemetry.ReportLatencyInput {
+	samples := make([]telemetry.LatencySample, len(r.Reports))
+	for i, s := range r.Reports {
+		samples[i] = telemetry.LatencySample{
+			MessageID:  s.MessageID,
+			ReceivedAt: time.UnixMilli(s.ReceivedAt),
+		}
+	}
+	return telemetry.ReportLatencyInput{Samples: samples}
+}
+
+// --- Response DTOs ---
+
+type reportLatencyResp struct {
+	Accepted int `json:"accepted"`
+	Dropped  int `json:"dropped"`
+}
+
+func (h *handler) newReportLatencyResp(output telemetry.ReportLatencyOutput) reportLatencyResp {
+	return reportLatencyResp{
+		Accepted: output.Accepted,
+		Dropped:  output.Dropped,
+	}
+}
@@ -0,0 +1,25 @@
+package http
+
+import (
+	"notification-srv/internal/model"
+	"notification-srv/internal/telemetry"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smap-hcmut/shared-libs/go/auth"
+)
+
+// processReportLatencyRequest binds and validates the latency report body and
+// extracts the caller scope set by the auth middleware.
+func (h *handler) processReportLatencyRequest(c *gin.Context) (reportLatencyReq, model.Scope, error) {
+	var req reportLatencyReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return reportLatencyReq{}, model.Scope{}, telemetry.ErrInvalidSample
+	}
+
+	if err := req.validate(); err != nil {
+		return reportLatencyReq{}, model.Scope{}, err
+	}
+
+	sc := model.ToScope(auth.GetScopeFromContext(c.Request.Context()))
+	return req, sc, nil
+}
@@ -0,0 +1,14 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/smap-hcmut/shared-libs/go/middleware"
+)
+
+// RegisterRoutes registers the telemetry routes.
+func (h *handler) RegisterRoutes(r *gin.RouterGroup, mw *middleware.Middleware) {
+	tel := r.Group("/api/telemetry", mw.Auth())
+	{
+		tel.POST("/latency", h.ReportLatency)
+	}
+}
@@ -0,0 +1,9 @@
+package telemetry
+
+import "notification-srv/pkg/errcode"
+
+var (
+	ErrEmptyReport    = errcode.New("telemetry.empty_report", errcode.Invalid, "latency report contains no samples", "Latency report contains no samples")
+	ErrTooManySamples = errcode.New("telemetry.too_many_samples", errcode.Invalid, "latency report exceeds maximum sample count", "Too many samples in latency report")
+	ErrInvalidSample  = errcode.New("telemetry.invalid_sample", errcode.Invalid, "invalid latency sample", "Invalid latency sample")
+)
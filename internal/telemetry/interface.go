@@ -0,0 +1,16 @@
+package telemetry
+
+import (
+	"context"
+
+	"notification-srv/internal/model"
+)
+
+// UseCase defines the logic for client-side delivery telemetry.
+type UseCase interface {
+	// Emit Tracking (called by the WebSocket domain when a frame leaves the server)
+	RecordEmit(ctx context.Context, input RecordEmitInput)
+
+	// Client Reports
+	ReportLatency(ctx context.Context, sc model.Scope, input ReportLatencyInput) (ReportLatencyOutput, error)
+}
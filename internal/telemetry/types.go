@@ -0,0 +1,28 @@
+package telemetry
+
+import "time"
+
+// RecordEmitInput describes a message frame that was handed to the Hub for delivery.
+type RecordEmitInput struct {
+	MessageID   string
+	MessageType string
+	UserID      string // Empty for broadcast messages
+	EmittedAt   time.Time
+}
+
+// LatencySample is a single client-side receive timestamp for a message.
+type LatencySample struct {
+	MessageID  string
+	ReceivedAt time.Time
+}
+
+// ReportLatencyInput is a batch of receive timestamps reported by a client.
+type ReportLatencyInput struct {
+	Samples []LatencySample
+}
+
+// ReportLatencyOutput summarizes how many samples were joined with an emit record.
+type ReportLatencyOutput struct {
+	Accepted int
+	Dropped  int
+}
@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"container/list"
+	"hash/fnv"
+	"time"
+)
+
+// shard returns the shard holding the record of messageID.
+func (uc *implUseCase) shard(messageID string) *emitShard {
+	h := fnv.New32a()
+	h.Write([]byte(messageID))
+	return uc.shards[h.Sum32()%emitShards]
+}
+
+// evict drops expired records and, if still at capacity, the oldest ones.
+// Caller must hold s.mu.
+func (s *emitShard) evict(now time.Time, ttl time.Duration, capacity int) {
+	for el := s.order.Back(); el != nil; el = s.order.Back() {
+		rec := el.Value.(*emitRecord)
+		expired := ttl > 0 && now.Sub(rec.emittedAt) > ttl
+		full := capacity > 0 && s.order.Len() >= capacity
+		if !expired && !full {
+			return
+		}
+		s.remove(el)
+	}
+}
+
+// remove forgets the record of el. Caller must hold s.mu.
+func (s *emitShard) remove(el *list.Element) {
+	s.order.Remove(el)
+	delete(s.emits, el.Value.(*emitRecord).messageID)
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+
+	"notification-srv/internal/metrics"
+	"notification-srv/internal/model"
+	"notification-srv/internal/telemetry"
+)
+
+// RecordEmit remembers when a message left the server so that a later client
+// report can be joined with it.
+func (uc *implUseCase) RecordEmit(ctx context.Context, input telemetry.RecordEmitInput) {
+	if input.MessageID == "" {
+		return
+	}
+
+	s := uc.shard(input.MessageID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evict(input.EmittedAt, uc.cfg.EmitTTL, uc.maxPerShard)
+	if _, ok := s.emits[input.MessageID]; ok {
+		return
+	}
+
+	s.emits[input.MessageID] = s.order.PushFront(&emitRecord{
+		messageID:   input.MessageID,
+		messageType: input.MessageType,
+		userID:      input.UserID,
+		emittedAt:   input.EmittedAt,
+	})
+}
+
+// ReportLatency joins client receive timestamps with emit records and observes
+// the resulting end-to-end latency. Samples that cannot be joined are dropped.
+func (uc *implUseCase) ReportLatency(ctx context.Context, sc model.Scope, input telemetry.ReportLatencyInput) (telemetry.ReportLatencyOutput, error) {
+	if len(input.Samples) == 0 {
+		return telemetry.ReportLatencyOutput{}, telemetry.ErrEmptyReport
+	}
+	if uc.cfg.MaxReportSamples > 0 && len(input.Samples) > uc.cfg.MaxReportSamples {
+		return telemetry.ReportLatencyOutput{}, telemetry.ErrTooManySamples
+	}
+
+	var out telemetry.ReportLatencyOutput
+	for _, sample := range input.Samples {
+		if uc.report(sc, sample) {
+			out.Accepted++
+		} else {
+			out.Dropped++
+		}
+	}
+	return out, nil
+}
+
+// report joins one sample with its emit record under the record's shard lock
+// and reports whether it was accepted.
+func (uc *implUseCase) report(sc model.Scope, sample telemetry.LatencySample) bool {
+	s := uc.shard(sample.MessageID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.emits[sample.MessageID]
+	if !ok {
+		metrics.LatencyReportsDropped.WithLabelValues("unknown_message").Inc()
+		return false
+	}
+	rec := el.Value.(*emitRecord)
+
+	// Broadcast records may be reported by any user; targeted ones only by their recipient.
+	if rec.userID != "" && rec.userID != sc.UserID {
+		metrics.LatencyReportsDropped.WithLabelValues("user_mismatch").Inc()
+		return false
+	}
+
+	latency := sample.ReceivedAt.Sub(rec.emittedAt)
+	if latency < 0 {
+		metrics.LatencyReportsDropped.WithLabelValues("clock_skew").Inc()
+		return false
+	}
+
+	metrics.DeliveryLatency.WithLabelValues(rec.messageType).Observe(latency.Seconds())
+
+	// Targeted messages are received once; keep broadcast records for other recipients.
+	if rec.userID != "" {
+		s.remove(el)
+	}
+	return true
+}
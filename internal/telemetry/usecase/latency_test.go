@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"notification-srv/internal/model"
+	"notification-srv/internal/telemetry"
+	"notification-srv/pkg/notificationtest"
+)
+
+// records counts the emit records held, checking each shard's index and order
+// agree.
+func records(t *testing.T, uc *implUseCase) int {
+	t.Helper()
+	n := 0
+	for i, s := range uc.shards {
+		s.mu.Lock()
+		if len(s.emits) != s.order.Len() {
+			t.Errorf("shard %d: %d indexed, %d in order", i, len(s.emits), s.order.Len())
+		}
+		n += len(s.emits)
+		s.mu.Unlock()
+	}
+	return n
+}
+
+func report(uc telemetry.UseCase, userID, messageID string, receivedAt time.Time) telemetry.ReportLatencyOutput {
+	out, _ := uc.ReportLatency(context.Background(), model.Scope{UserID: userID}, telemetry.ReportLatencyInput{
+		Samples: []telemetry.LatencySample{{MessageID: messageID, ReceivedAt: receivedAt}},
+	})
+	return out
+}
+
+func TestReportLatencyJoinsEmits(t *testing.T) {
+	uc := New(notificationtest.Logger{}, Config{EmitTTL: time.Minute}).(*implUseCase)
+	ctx := context.Background()
+	now := time.Now()
+
+	uc.RecordEmit(ctx, telemetry.RecordEmitInput{MessageID: "m1", MessageType: "SYSTEM", UserID: "u1", EmittedAt: now})
+	uc.RecordEmit(ctx, telemetry.RecordEmitInput{MessageID: "b1", MessageType: "SYSTEM", EmittedAt: now})
+
+	if out := report(uc, "u2", "m1", now.Add(time.Second)); out.Accepted != 0 {
+		t.Error("another user's report accepted")
+	}
+	if out := report(uc, "u1", "m1", now.Add(-time.Second)); out.Accepted != 0 {
+		t.Error("report before the emit accepted")
+	}
+	if out := report(uc, "u1", "m1", now.Add(time.Second)); out.Accepted != 1 {
+		t.Fatal("recipient's report dropped")
+	}
+
+	// A targeted record is consumed, a broadcast one kept for other recipients
+	if out := report(uc, "u1", "m1", now.Add(time.Second)); out.Accepted != 0 {
+		t.Error("targeted record reported twice")
+	}
+	for _, user := range []string{"u1", "u2"} {
+		if out := report(uc, user, "b1", now.Add(time.Second)); out.Accepted != 1 {
+			t.Errorf("broadcast report of %s dropped", user)
+		}
+	}
+	if got := records(t, uc); got != 1 {
+		t.Errorf("%d records held, want the broadcast one", got)
+	}
+}
+
+func TestRecordEmitEvicts(t *testing.T) {
+	uc := New(notificationtest.Logger{}, Config{EmitTTL: time.Minute, MaxEmitRecords: 64}).(*implUseCase)
+	ctx := context.Background()
+	start := time.Now()
+
+	for i := 0; i < 1000; i++ {
+		uc.RecordEmit(ctx, telemetry.RecordEmitInput{MessageID: fmt.Sprintf("m%d", i), EmittedAt: start})
+	}
+	if got, limit := records(t, uc), uc.maxPerShard*emitShards; got > limit {
+		t.Fatalf("%d records held, want at most %d", got, limit)
+	}
+	// The newest are kept
+	if out := report(uc, "", "m999", start); out.Accepted != 1 {
+		t.Error("newest record evicted")
+	}
+
+	// Expired records leave as their shard records again
+	later := start.Add(2 * time.Minute)
+	for i := 0; i < 1000; i++ {
+		uc.RecordEmit(ctx, telemetry.RecordEmitInput{MessageID: fmt.Sprintf("n%d", i), UserID: "u1", EmittedAt: later})
+	}
+	if out := report(uc, "", "m999", later); out.Accepted != 0 {
+		t.Error("expired record reported")
+	}
+	// Consumed records leave the emit order too
+	for i := 0; i < 1000; i++ {
+		report(uc, "u1", fmt.Sprintf("n%d", i), later)
+	}
+	if got := records(t, uc); got != 0 {
+		t.Errorf("%d records held after every one was consumed", got)
+	}
+}
+
+func TestRecordEmitConcurrent(t *testing.T) {
+	uc := New(notificationtest.Logger{}, Config{EmitTTL: time.Minute, MaxEmitRecords: 1000}).(*implUseCase)
+	ctx := context.Background()
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				id := fmt.Sprintf("w%d-%d", w, i)
+				uc.RecordEmit(ctx, telemetry.RecordEmitInput{MessageID: id, UserID: "u1", EmittedAt: now})
+				report(uc, "u1", id, now)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := records(t, uc); got != 0 {
+		t.Errorf("%d records held, want every one consumed", got)
+	}
+}
@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"container/list"
+
+	"notification-srv/internal/telemetry"
+
+	"github.com/smap-hcmut/shared-libs/go/log"
+)
+
+// implUseCase implements telemetry.UseCase.
+type implUseCase struct {
+	logger log.Logger
+	cfg    Config
+
+	// Emit records awaiting a client report, sharded by message ID. Each
+	// shard holds at most maxPerShard records (0 = unbounded).
+	shards      [emitShards]*emitShard
+	maxPerShard int
+}
+
+// New creates a new Telemetry UseCase.
+func New(logger log.Logger, cfg Config) telemetry.UseCase {
+	uc := &implUseCase{
+		logger: logger,
+		cfg:    cfg,
+	}
+	if cfg.MaxEmitRecords > 0 {
+		uc.maxPerShard = max((cfg.MaxEmitRecords+emitShards-1)/emitShards, 1)
+	}
+	for i := range uc.shards {
+		uc.shards[i] = &emitShard{
+			emits: make(map[string]*list.Element),
+			order: list.New(),
+		}
+	}
+	return uc
+}
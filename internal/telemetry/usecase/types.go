@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// emitShards is the number of independently locked parts of the emit
+// records, so concurrent deliveries rarely wait on each other.
+const emitShards = 16
+
+// emitRecord is the server-side half of a latency measurement.
+type emitRecord struct {
+	messageID   string
+	messageType string
+	userID      string
+	emittedAt   time.Time
+}
+
+// emitShard holds the emit records of the message IDs hashing to it, in emit
+// order.
+type emitShard struct {
+	mu    sync.Mutex
+	emits map[string]*list.Element // Value is *emitRecord
+	order *list.List               // Front = most recently emitted
+}
+
+// Config holds the tunables for emit tracking.
+type Config struct {
+	EmitTTL          time.Duration // How long an emit record waits for a client report
+	MaxEmitRecords   int           // Upper bound on tracked emit records
+	MaxReportSamples int           // Upper bound on samples accepted per report
+}
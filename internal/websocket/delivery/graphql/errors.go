@@ -0,0 +1,22 @@
+package graphql
+
+import (
+	"errors"
+
+	"notification-srv/internal/websocket"
+)
+
+// mapError passes through the domain errors this handler answers; Respond
+// derives their status and body from the errcode taxonomy.
+func (h *handler) mapError(err error) error {
+	switch {
+	case errors.Is(err, websocket.ErrInvalidToken),
+		errors.Is(err, websocket.ErrMissingToken),
+		errors.Is(err, websocket.ErrInvalidMessage),
+		errors.Is(err, websocket.ErrBanned),
+		errors.Is(err, websocket.ErrQuotaExceeded):
+		return err
+	default:
+		panic(err)
+	}
+}
@@ -0,0 +1,95 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+
+	"notification-srv/internal/websocket"
+	"notification-srv/internal/websocket/transport"
+	"notification-srv/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleSubscriptions upgrades to a graphql-transport-ws connection.
+// @Summary GraphQL subscriptions
+// @Description WebSocket endpoint speaking the graphql-transport-ws protocol (Apollo graphql-ws). Offers the projectProgress(projectId) subscription. Authenticates like /ws: token query, auth cookie, or {"token": ...} in the connection_init payload.
+// @Tags GraphQL
+// @Param token query string false "JWT Token"
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 401 {object} errcode.Problem "Unauthorized"
+// @Failure 429 {object} errcode.Problem "Connection quota exceeded"
+// @Router /graphql [GET]
+func (h *handler) HandleSubscriptions(c *gin.Context) {
+	// Banned addresses are refused before any token work, like /ws
+	if err := h.uc.CheckBan(c.Request.Context(), websocket.CheckBanInput{RemoteIP: c.ClientIP()}); err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	// A token on the upgrade request must be valid; without one, connection_init must carry it
+	var userID string
+	var claims map[string]any
+	if token := h.upgradeToken(c); token != "" {
+		var err error
+		if userID, claims, err = h.verify(c.Request.Context(), token); err != nil {
+			errcode.Respond(c, h.mapError(err))
+			return
+		}
+		if err := h.uc.CheckBan(c.Request.Context(), websocket.CheckBanInput{UserID: userID}); err != nil {
+			errcode.Respond(c, h.mapError(err))
+			return
+		}
+	}
+
+	conn, err := h.cfg.Transport.Upgrade(c.Writer, c.Request, transport.Options{
+		Subprotocols: []string{subprotocol},
+	})
+	if err != nil {
+		h.logger.Errorf(c.Request.Context(), "graphql upgrade failed: %v", err)
+		return
+	}
+	if conn.Subprotocol() != subprotocol {
+		conn.Close(transport.CloseProtocolError, "Subprotocol not acceptable")
+		return
+	}
+
+	s := &session{
+		h:         h,
+		conn:      conn,
+		userID:    userID,
+		claims:    claims,
+		userAgent: c.Request.UserAgent(),
+		remoteIP:  c.ClientIP(),
+		subs:      make(map[string]context.CancelFunc),
+	}
+	// The request context ends with the handler, so the session gets its own
+	go s.serve(context.Background())
+}
+
+// HandleQuery executes a GraphQL query over HTTP.
+// @Summary GraphQL query
+// @Description Executes a GraphQL query or operation over HTTP. Subscriptions need the WebSocket endpoint.
+// @Tags GraphQL
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "GraphQL response"
+// @Failure 400 {object} errcode.Problem "Bad Request"
+// @Failure 401 {object} errcode.Problem "Unauthorized"
+// @Security CookieAuth
+// @Security Bearer
+// @Router /graphql [POST]
+func (h *handler) HandleQuery(c *gin.Context) {
+	req, userID, err := h.processQueryRequest(c)
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), ctxKeyUserID, userID)
+	ctx = context.WithValue(ctx, ctxKeyUserAgent, c.Request.UserAgent())
+	ctx = context.WithValue(ctx, ctxKeyRemoteIP, c.ClientIP())
+
+	// GraphQL clients expect the spec response shape, not the service envelope
+	c.JSON(http.StatusOK, h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables))
+}
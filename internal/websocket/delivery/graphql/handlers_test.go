@@ -0,0 +1,133 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"notification-srv/internal/websocket"
+	wsGraphQL "notification-srv/internal/websocket/delivery/graphql"
+	"notification-srv/pkg/notificationtest"
+
+	"github.com/gin-gonic/gin"
+	gorilla "github.com/gorilla/websocket"
+	"github.com/smap-hcmut/shared-libs/go/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// banUseCase bans the users in banned; the handler calls nothing else before
+// the connection is acknowledged.
+type banUseCase struct {
+	websocket.UseCase
+	banned map[string]bool
+}
+
+func (uc banUseCase) CheckBan(ctx context.Context, input websocket.CheckBanInput) error {
+	if uc.banned[input.UserID] {
+		return websocket.ErrBanned
+	}
+	return nil
+}
+
+func newGraphQLServer(t *testing.T) string {
+	t.Helper()
+
+	tokens := notificationtest.NewTokenManager()
+	tokens.AddToken("banned_token", "banned_user")
+	tokens.AddToken("valid_token", "user_123")
+
+	h, err := wsGraphQL.New(banUseCase{banned: map[string]bool{"banned_user": true}}, tokens, notificationtest.Logger{},
+		wsGraphQL.Config{InitTimeout: 5 * time.Second}, wsGraphQL.CookieConfig{})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h.RegisterRoutes(r.Group(""), middleware.New(middleware.Config{JWTManager: tokens}))
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/graphql"
+}
+
+func dialGraphQL(url string) (*gorilla.Conn, *http.Response, error) {
+	dialer := gorilla.Dialer{Subprotocols: []string{"graphql-transport-ws"}}
+	return dialer.Dial(url, nil)
+}
+
+// initWith sends connection_init with payload and returns the server's answer:
+// the type of its reply, or the close code it closed with.
+func initWith(t *testing.T, conn *gorilla.Conn, payload string) (string, int) {
+	t.Helper()
+
+	require.NoError(t, conn.WriteMessage(gorilla.TextMessage, []byte(`{"type":"connection_init","payload":`+payload+`}`)))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, data, err := conn.ReadMessage()
+	if ce, ok := err.(*gorilla.CloseError); ok {
+		return "", ce.Code
+	}
+	require.NoError(t, err)
+	return string(data), 0
+}
+
+func TestSubscriptionsRefuseBannedUser(t *testing.T) {
+	url := newGraphQLServer(t)
+
+	t.Run("upgrade token", func(t *testing.T) {
+		_, resp, err := dialGraphQL(url + "?token=banned_token")
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("connection_init token", func(t *testing.T) {
+		conn, _, err := dialGraphQL(url)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, code := initWith(t, conn, `{"token":"banned_token"}`)
+		assert.Equal(t, 4403, code)
+	})
+
+	t.Run("connection_init bearer", func(t *testing.T) {
+		conn, _, err := dialGraphQL(url)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, code := initWith(t, conn, `{"authorization":"Bearer banned_token"}`)
+		assert.Equal(t, 4403, code)
+	})
+}
+
+func TestSubscriptionsAcceptUser(t *testing.T) {
+	url := newGraphQLServer(t)
+
+	t.Run("upgrade token", func(t *testing.T) {
+		conn, _, err := dialGraphQL(url + "?token=valid_token")
+		require.NoError(t, err)
+		defer conn.Close()
+
+		reply, _ := initWith(t, conn, `{}`)
+		assert.Contains(t, reply, `"connection_ack"`)
+	})
+
+	t.Run("connection_init token", func(t *testing.T) {
+		conn, _, err := dialGraphQL(url)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		reply, _ := initWith(t, conn, `{"token":"valid_token"}`)
+		assert.Contains(t, reply, `"connection_ack"`)
+	})
+
+	t.Run("connection_init invalid token", func(t *testing.T) {
+		conn, _, err := dialGraphQL(url)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, code := initWith(t, conn, `{"token":"unknown"}`)
+		assert.Equal(t, 4401, code)
+	})
+}
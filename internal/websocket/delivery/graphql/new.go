@@ -0,0 +1,48 @@
+package graphql
+
+import (
+	"fmt"
+
+	"notification-srv/internal/websocket"
+	"notification-srv/internal/websocket/transport/gorilla"
+
+	"github.com/gin-gonic/gin"
+	gql "github.com/graph-gophers/graphql-go"
+	"github.com/smap-hcmut/shared-libs/go/auth"
+	"github.com/smap-hcmut/shared-libs/go/log"
+	"github.com/smap-hcmut/shared-libs/go/middleware"
+)
+
+// Handler serves the GraphQL gateway in front of the WebSocket Hub.
+type Handler interface {
+	RegisterRoutes(r *gin.RouterGroup, mw *middleware.Middleware)
+}
+
+type handler struct {
+	uc        websocket.UseCase
+	jwtMgr    auth.Manager
+	logger    log.Logger
+	cfg       Config
+	cookieCfg CookieConfig
+	schema    *gql.Schema
+}
+
+func New(uc websocket.UseCase, jwtMgr auth.Manager, logger log.Logger, cfg Config, cookieCfg CookieConfig) (Handler, error) {
+	schema, err := gql.ParseSchema(schemaSDL, &rootResolver{uc: uc}, gql.MaxDepth(10))
+	if err != nil {
+		return nil, fmt.Errorf("parse graphql schema: %w", err)
+	}
+
+	if cfg.Transport == nil {
+		cfg.Transport = gorilla.New()
+	}
+
+	return &handler{
+		uc:        uc,
+		jwtMgr:    jwtMgr,
+		logger:    logger,
+		cfg:       cfg,
+		cookieCfg: cookieCfg,
+		schema:    schema,
+	}, nil
+}
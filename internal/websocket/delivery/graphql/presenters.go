@@ -0,0 +1,59 @@
+package graphql
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"notification-srv/internal/websocket/transport"
+)
+
+// --- Configuration DTOs ---
+
+// Config controls the GraphQL endpoint.
+type Config struct {
+	InitTimeout time.Duration      // Time allowed between upgrade and connection_init
+	Transport   transport.Upgrader // WebSocket library of the handshakes (nil = gorilla)
+}
+
+// CookieConfig names the auth cookie shared with the /ws endpoint.
+type CookieConfig struct {
+	Name string
+}
+
+// --- Protocol DTOs ---
+
+// wsMessage is the envelope of every protocol message.
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// initPayload carries the token when the upgrade request had none.
+type initPayload struct {
+	Token         string `json:"token"`
+	Authorization string `json:"authorization"` // "Bearer <token>"
+}
+
+// subscribePayload is a GraphQL operation request.
+type subscribePayload struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// graphQLRequest is a POST /graphql body.
+type graphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// token returns the token from the connection_init payload, if any.
+func (p initPayload) token() string {
+	if p.Token != "" {
+		return p.Token
+	}
+	return strings.TrimPrefix(p.Authorization, "Bearer ")
+}
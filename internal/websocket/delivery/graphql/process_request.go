@@ -0,0 +1,50 @@
+package graphql
+
+import (
+	"context"
+
+	"notification-srv/internal/model"
+	"notification-srv/internal/websocket"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smap-hcmut/shared-libs/go/auth"
+)
+
+// upgradeToken returns the token of the upgrade request, from the token query
+// parameter or the auth cookie, like /ws. It may be empty: Apollo clients can
+// send it in connection_init instead.
+func (h *handler) upgradeToken(c *gin.Context) string {
+	if token := c.Query("token"); token != "" {
+		return token
+	}
+	if cookie, err := c.Cookie(h.cookieCfg.Name); err == nil {
+		return cookie
+	}
+	return ""
+}
+
+// verify checks a token with the same JWT manager as /ws and returns the user
+// ID and the token's claims.
+func (h *handler) verify(ctx context.Context, token string) (string, map[string]any, error) {
+	if token == "" {
+		return "", nil, websocket.ErrMissingToken
+	}
+	payload, err := h.jwtMgr.Verify(token)
+	if err != nil {
+		h.logger.Warnf(ctx, "graphql token verification failed: %v", err)
+		return "", nil, websocket.ErrInvalidToken
+	}
+	return payload.UserID, model.TokenClaims(token), nil
+}
+
+// processQueryRequest binds a POST /graphql body and returns the caller's user ID
+// as set by the auth middleware.
+func (h *handler) processQueryRequest(c *gin.Context) (graphQLRequest, string, error) {
+	var req graphQLRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Query == "" {
+		return graphQLRequest{}, "", websocket.ErrInvalidMessage
+	}
+
+	sc := auth.GetScopeFromContext(c.Request.Context())
+	return req, sc.UserID, nil
+}
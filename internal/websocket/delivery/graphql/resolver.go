@@ -0,0 +1,167 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"notification-srv/internal/websocket"
+
+	gql "github.com/graph-gophers/graphql-go"
+)
+
+func (r *rootResolver) ServerTime() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+// ProjectProgress registers a Hub stream for the caller and the project, and
+// translates project frames into typed events.
+func (r *rootResolver) ProjectProgress(ctx context.Context, args struct{ ProjectID gql.ID }) (<-chan *projectProgressEvent, error) {
+	userID, _ := ctx.Value(ctxKeyUserID).(string)
+	userAgent, _ := ctx.Value(ctxKeyUserAgent).(string)
+	if userID == "" {
+		return nil, websocket.ErrMissingToken
+	}
+	projectID := string(args.ProjectID)
+	remoteIP, _ := ctx.Value(ctxKeyRemoteIP).(string)
+	if err := r.uc.CheckDecoy(ctx, websocket.CheckDecoyInput{
+		UserID:    userID,
+		ProjectID: projectID,
+		RemoteIP:  remoteIP,
+		Transport: "graphql",
+	}); err != nil {
+		return nil, err
+	}
+	// Each subscription holds a Hub connection, so it counts against the quota
+	claims, _ := ctx.Value(ctxKeyClaims).(map[string]any)
+	quota, err := r.uc.CheckQuota(ctx, websocket.QuotaInput{UserID: userID, Claims: claims})
+	if err != nil {
+		return nil, err
+	}
+
+	frames, err := r.uc.Subscribe(ctx, websocket.SubscribeInput{
+		UserID:    userID,
+		ProjectID: projectID,
+		UserAgent: userAgent,
+		Quota:     quota,
+		// Only the types toProjectProgressEvent maps, so alerts don't fill the buffer
+		Types: []websocket.MessageType{
+			websocket.MessageTypeProjectProgress,
+			websocket.MessageTypeDataOnboarding,
+			websocket.MessageTypeAnalyticsPipeline,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan *projectProgressEvent)
+	go func() {
+		defer close(events)
+		for data := range frames {
+			ev, ok := toProjectProgressEvent(data, projectID)
+			if !ok {
+				continue
+			}
+			select {
+			case events <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// toProjectProgressEvent maps a Hub frame to an event. Frames that are not
+// progress of projectID (e.g. crisis alerts) are skipped.
+func toProjectProgressEvent(data []byte, projectID string) (*projectProgressEvent, bool) {
+	var f frame
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, false
+	}
+
+	ev := &projectProgressEvent{
+		id:        gql.ID(f.ID),
+		timestamp: f.Timestamp,
+		projectID: gql.ID(projectID),
+	}
+	if f.Seq > 0 {
+		seq := strconv.FormatUint(f.Seq, 10)
+		ev.seq = &seq
+		ev.epoch = &f.Epoch
+	}
+
+	switch f.Type {
+	case websocket.MessageTypeProjectProgress:
+		var p websocket.ProjectProgressPayload
+		if err := json.Unmarshal(f.Payload, &p); err != nil || p.ProjectID != projectID {
+			return nil, false
+		}
+		ev.kind = "SNAPSHOT"
+		ev.snapshot = &projectSnapshot{state: string(p.State)}
+
+	case websocket.MessageTypeDataOnboarding:
+		var p websocket.DataOnboardingPayload
+		if err := json.Unmarshal(f.Payload, &p); err != nil || p.ProjectID != projectID {
+			return nil, false
+		}
+		phase := "onboarding"
+		progress := int32(p.Progress)
+		ev.kind = "ONBOARDING"
+		ev.phase, ev.status, ev.progress = &phase, &p.Status, &progress
+		ev.onboarding = &dataOnboarding{p: p}
+
+	case websocket.MessageTypeAnalyticsPipeline:
+		var p websocket.AnalyticsPipelinePayload
+		if err := json.Unmarshal(f.Payload, &p); err != nil || p.ProjectID != projectID {
+			return nil, false
+		}
+		progress := int32(p.Progress)
+		ev.kind = "PIPELINE"
+		ev.phase, ev.progress = &p.CurrentPhase, &progress
+		ev.pipeline = &analyticsPipeline{p: p}
+
+	default:
+		return nil, false
+	}
+	return ev, true
+}
+
+// --- ProjectProgressEvent ---
+
+func (e *projectProgressEvent) ID() gql.ID                   { return e.id }
+func (e *projectProgressEvent) Kind() string                 { return e.kind }
+func (e *projectProgressEvent) Timestamp() string            { return e.timestamp.Format(time.RFC3339Nano) }
+func (e *projectProgressEvent) ProjectID() gql.ID            { return e.projectID }
+func (e *projectProgressEvent) Phase() *string               { return e.phase }
+func (e *projectProgressEvent) Status() *string              { return e.status }
+func (e *projectProgressEvent) Progress() *int32             { return e.progress }
+func (e *projectProgressEvent) Seq() *string                 { return e.seq }
+func (e *projectProgressEvent) Epoch() *string               { return e.epoch }
+func (e *projectProgressEvent) Snapshot() *projectSnapshot   { return e.snapshot }
+func (e *projectProgressEvent) Onboarding() *dataOnboarding  { return e.onboarding }
+func (e *projectProgressEvent) Pipeline() *analyticsPipeline { return e.pipeline }
+
+// --- Kind-specific details ---
+
+func (s *projectSnapshot) State() string { return s.state }
+
+func (o *dataOnboarding) SourceID() gql.ID   { return gql.ID(o.p.SourceID) }
+func (o *dataOnboarding) SourceName() string { return o.p.SourceName }
+func (o *dataOnboarding) SourceType() string { return o.p.SourceType }
+func (o *dataOnboarding) Status() string     { return o.p.Status }
+func (o *dataOnboarding) Progress() int32    { return int32(o.p.Progress) }
+func (o *dataOnboarding) RecordCount() int32 { return int32(o.p.RecordCount) }
+func (o *dataOnboarding) ErrorCount() int32  { return int32(o.p.ErrorCount) }
+func (o *dataOnboarding) Message() string    { return o.p.Message }
+
+func (a *analyticsPipeline) SourceID() gql.ID         { return gql.ID(a.p.SourceID) }
+func (a *analyticsPipeline) CurrentPhase() string     { return a.p.CurrentPhase }
+func (a *analyticsPipeline) TotalRecords() int32      { return int32(a.p.TotalRecords) }
+func (a *analyticsPipeline) ProcessedCount() int32    { return int32(a.p.ProcessedCount) }
+func (a *analyticsPipeline) SuccessCount() int32      { return int32(a.p.SuccessCount) }
+func (a *analyticsPipeline) FailedCount() int32       { return int32(a.p.FailedCount) }
+func (a *analyticsPipeline) Progress() int32          { return int32(a.p.Progress) }
+func (a *analyticsPipeline) EstimatedTimeMs() float64 { return float64(a.p.EstimatedTimeMs) }
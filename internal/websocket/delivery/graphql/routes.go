@@ -0,0 +1,13 @@
+package graphql
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/smap-hcmut/shared-libs/go/middleware"
+)
+
+// RegisterRoutes registers /graphql. Subscriptions authenticate like /ws
+// (token query, cookie or connection_init payload) inside the handler.
+func (h *handler) RegisterRoutes(r *gin.RouterGroup, mw *middleware.Middleware) {
+	r.GET("/graphql", h.HandleSubscriptions)
+	r.POST("/graphql", mw.Auth(), h.HandleQuery)
+}
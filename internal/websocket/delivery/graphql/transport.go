@@ -0,0 +1,257 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"notification-srv/internal/websocket"
+	"notification-srv/internal/websocket/transport"
+
+	gql "github.com/graph-gophers/graphql-go"
+)
+
+const (
+	writeWait      = 10 * time.Second
+	keepAlive      = 30 * time.Second
+	maxMessageSize = 64 * 1024
+)
+
+// serve runs the graphql-transport-ws protocol until the client disconnects.
+func (s *session) serve(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel() // Ends every operation and its Hub stream
+		s.conn.CloseNow()
+	}()
+
+	s.conn.SetReadLimit(maxMessageSize)
+	go s.keepAlive(ctx)
+
+	initTimer := time.AfterFunc(s.h.cfg.InitTimeout, func() {
+		s.mu.Lock()
+		initialised := s.init
+		s.mu.Unlock()
+		if !initialised {
+			s.close(closeInitTimeout, "Connection initialisation timeout")
+		}
+	})
+	defer initTimer.Stop()
+
+	for {
+		_, data, err := s.conn.Read(ctx)
+		if err != nil {
+			return
+		}
+
+		var msg wsMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			s.close(closeBadRequest, "Invalid message")
+			return
+		}
+		if !s.handle(ctx, msg) {
+			return
+		}
+	}
+}
+
+// handle processes one client message and reports whether the session goes on.
+func (s *session) handle(ctx context.Context, msg wsMessage) bool {
+	switch msg.Type {
+	case msgConnectionInit:
+		return s.handleInit(ctx, msg)
+
+	case msgPing:
+		s.write(wsMessage{Type: msgPong})
+		return true
+
+	case msgPong:
+		return true
+
+	case msgSubscribe:
+		return s.handleSubscribe(ctx, msg)
+
+	case msgComplete:
+		s.mu.Lock()
+		if stop, ok := s.subs[msg.ID]; ok {
+			stop()
+			delete(s.subs, msg.ID)
+		}
+		s.mu.Unlock()
+		return true
+
+	default:
+		s.close(closeBadRequest, "Unknown message type")
+		return false
+	}
+}
+
+func (s *session) handleInit(ctx context.Context, msg wsMessage) bool {
+	s.mu.Lock()
+	already := s.init
+	s.mu.Unlock()
+	if already {
+		s.close(closeTooManyInitialise, "Too many initialisation requests")
+		return false
+	}
+
+	// The upgrade request may already have been authenticated
+	if s.userID == "" {
+		var p initPayload
+		if len(msg.Payload) > 0 {
+			_ = json.Unmarshal(msg.Payload, &p)
+		}
+		userID, claims, err := s.h.verify(ctx, p.token())
+		if err != nil {
+			s.close(closeUnauthorized, "Unauthorized")
+			return false
+		}
+		// Banned users are refused like on the upgrade request
+		if err := s.h.uc.CheckBan(ctx, websocket.CheckBanInput{UserID: userID}); err != nil {
+			if errors.Is(err, websocket.ErrBanned) {
+				s.close(closeForbidden, "Forbidden")
+			} else {
+				s.close(closeUnauthorized, "Unauthorized")
+			}
+			return false
+		}
+		s.userID = userID
+		s.claims = claims
+	}
+
+	s.mu.Lock()
+	s.init = true
+	s.mu.Unlock()
+	s.write(wsMessage{Type: msgConnectionAck})
+	return true
+}
+
+func (s *session) handleSubscribe(ctx context.Context, msg wsMessage) bool {
+	var p subscribePayload
+	if msg.ID == "" || json.Unmarshal(msg.Payload, &p) != nil || p.Query == "" {
+		s.close(closeBadRequest, "Invalid subscribe message")
+		return false
+	}
+
+	s.mu.Lock()
+	if !s.init {
+		s.mu.Unlock()
+		s.close(closeUnauthorized, "Unauthorized")
+		return false
+	}
+	if _, exists := s.subs[msg.ID]; exists {
+		s.mu.Unlock()
+		s.close(closeDuplicateID, "Subscriber for "+msg.ID+" already exists")
+		return false
+	}
+	opCtx, stop := context.WithCancel(ctx)
+	s.subs[msg.ID] = stop
+	s.mu.Unlock()
+
+	opCtx = context.WithValue(opCtx, ctxKeyUserID, s.userID)
+	opCtx = context.WithValue(opCtx, ctxKeyUserAgent, s.userAgent)
+	opCtx = context.WithValue(opCtx, ctxKeyClaims, s.claims)
+	opCtx = context.WithValue(opCtx, ctxKeyRemoteIP, s.remoteIP)
+
+	responses, err := s.h.schema.Subscribe(opCtx, p.Query, p.OperationName, p.Variables)
+	if err != nil {
+		s.finish(msg.ID)
+		s.writeErrors(msg.ID, err)
+		return true
+	}
+
+	go s.stream(opCtx, msg.ID, responses)
+	return true
+}
+
+// stream forwards the results of one operation until it ends or the client completes it.
+func (s *session) stream(ctx context.Context, id string, responses <-chan any) {
+	first := true
+	for r := range responses {
+		resp, ok := r.(*gql.Response)
+		if !ok {
+			continue
+		}
+
+		// A request error (parse, validation, resolver setup) fails the whole operation
+		if first && resp.Data == nil && len(resp.Errors) > 0 {
+			s.finish(id)
+			payload, _ := json.Marshal(resp.Errors)
+			s.write(wsMessage{ID: id, Type: msgError, Payload: payload})
+			return
+		}
+		first = false
+
+		payload, err := json.Marshal(resp)
+		if err != nil {
+			continue
+		}
+		s.write(wsMessage{ID: id, Type: msgNext, Payload: payload})
+	}
+
+	// Only tell the client when the server ended the operation
+	if ctx.Err() == nil && s.finish(id) {
+		s.write(wsMessage{ID: id, Type: msgComplete})
+	}
+}
+
+// finish forgets an operation and reports whether it was still active.
+func (s *session) finish(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stop, ok := s.subs[id]
+	if ok {
+		stop()
+		delete(s.subs, id)
+	}
+	return ok
+}
+
+func (s *session) writeErrors(id string, err error) {
+	payload, _ := json.Marshal([]map[string]string{{"message": err.Error()}})
+	s.write(wsMessage{ID: id, Type: msgError, Payload: payload})
+}
+
+func (s *session) write(msg wsMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), writeWait)
+	defer cancel()
+	if err := s.conn.Write(ctx, transport.MessageText, data); err != nil {
+		s.conn.CloseNow()
+	}
+}
+
+func (s *session) close(code int, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.conn.Close(code, reason)
+}
+
+// keepAlive sends WebSocket pings so idle subscriptions survive proxies.
+func (s *session) keepAlive(ctx context.Context) {
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pingCtx, cancel := context.WithTimeout(ctx, writeWait)
+			err := s.conn.Ping(pingCtx)
+			cancel()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package graphql
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"notification-srv/internal/websocket"
+	"notification-srv/internal/websocket/transport"
+
+	gql "github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// graphql-transport-ws protocol (https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md).
+const (
+	subprotocol = "graphql-transport-ws"
+
+	msgConnectionInit = "connection_init"
+	msgConnectionAck  = "connection_ack"
+	msgPing           = "ping"
+	msgPong           = "pong"
+	msgSubscribe      = "subscribe"
+	msgNext           = "next"
+	msgError          = "error"
+	msgComplete       = "complete"
+)
+
+// Close codes defined by the protocol.
+const (
+	closeBadRequest        = 4400
+	closeUnauthorized      = 4401
+	closeForbidden         = 4403
+	closeInitTimeout       = 4408
+	closeDuplicateID       = 4409
+	closeTooManyInitialise = 4429
+)
+
+// ctxKey carries request values into resolvers.
+type ctxKey int
+
+const (
+	ctxKeyUserID ctxKey = iota
+	ctxKeyUserAgent
+	ctxKeyClaims
+	ctxKeyRemoteIP
+)
+
+// frame is a Hub frame as sent to /ws clients.
+type frame struct {
+	ID        string                `json:"id"`
+	Seq       uint64                `json:"seq"`
+	Epoch     string                `json:"epoch"`
+	Type      websocket.MessageType `json:"type"`
+	Timestamp time.Time             `json:"timestamp"`
+	Payload   json.RawMessage       `json:"payload"`
+}
+
+// session is one graphql-transport-ws connection. Writes are serialized by mu;
+// pings are not, so a pending pong does not hold up writes.
+type session struct {
+	h         *handler
+	conn      transport.Conn
+	userID    string
+	claims    map[string]any // Claims of the session's token, for quotas
+	userAgent string
+	remoteIP  string
+
+	mu   sync.Mutex
+	init bool
+	subs map[string]context.CancelFunc // Active operations by ID
+}
+
+// --- Resolvers ---
+
+type rootResolver struct {
+	uc websocket.UseCase
+}
+
+type projectProgressEvent struct {
+	id        gql.ID
+	kind      string
+	timestamp time.Time
+	projectID gql.ID
+	phase     *string
+	status    *string
+	progress  *int32
+	seq       *string
+	epoch     *string
+
+	snapshot   *projectSnapshot
+	onboarding *dataOnboarding
+	pipeline   *analyticsPipeline
+}
+
+type projectSnapshot struct {
+	state string
+}
+
+type dataOnboarding struct {
+	p websocket.DataOnboardingPayload
+}
+
+type analyticsPipeline struct {
+	p websocket.AnalyticsPipelinePayload
+}
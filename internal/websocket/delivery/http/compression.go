@@ -0,0 +1,35 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+)
+
+// compressionAllowed reports whether permessage-deflate may be offered to a client
+// with the given User-Agent.
+func (h *handler) compressionAllowed(userAgent string) bool {
+	if !h.wsConfig.EnableCompression {
+		return false
+	}
+	for _, denied := range h.wsConfig.CompressionDenylist {
+		if denied != "" && strings.Contains(userAgent, denied) {
+			return false
+		}
+	}
+	return true
+}
+
+// offersDeflate reports whether the client offered permessage-deflate in its
+// handshake. Combined with compressionAllowed this tells whether the upgrader
+// negotiated compression.
+func offersDeflate(header http.Header) bool {
+	for _, ext := range header.Values("Sec-WebSocket-Extensions") {
+		for _, part := range strings.Split(ext, ",") {
+			name, _, _ := strings.Cut(part, ";")
+			if strings.TrimSpace(name) == "permessage-deflate" {
+				return true
+			}
+		}
+	}
+	return false
+}
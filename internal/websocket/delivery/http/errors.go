@@ -1,22 +1,34 @@
 package http
 
 import (
-	"net/http"
-	"notification-srv/internal/websocket"
+	"errors"
 
-	"github.com/smap-hcmut/shared-libs/go/errors"
+	"notification-srv/internal/websocket"
 )
 
+// mapError passes through the domain errors this handler answers; Respond
+// derives their status and body from the errcode taxonomy.
 func (h *handler) mapError(err error) error {
-	switch err {
-	case websocket.ErrInvalidToken:
-		return errors.NewHTTPError(http.StatusUnauthorized, "Invalid or expired token")
-	case websocket.ErrMissingToken:
-		return errors.NewHTTPError(http.StatusUnauthorized, "Missing authentication token")
-	case websocket.ErrMaxConnectionsReached:
-		return errors.NewHTTPError(http.StatusServiceUnavailable, "Maximum connections reached")
-	case websocket.ErrUserNotFound:
-		return errors.NewHTTPError(http.StatusNotFound, "User not found")
+	switch {
+	case errors.Is(err, websocket.ErrInvalidToken),
+		errors.Is(err, websocket.ErrMissingToken),
+		errors.Is(err, websocket.ErrMaxConnectionsReached),
+		errors.Is(err, websocket.ErrInvalidMessage),
+		errors.Is(err, websocket.ErrUserNotFound),
+		errors.Is(err, websocket.ErrBanned),
+		errors.Is(err, websocket.ErrDelegationDenied),
+		errors.Is(err, websocket.ErrQuotaExceeded),
+		errors.Is(err, websocket.ErrNotPublic),
+		errors.Is(err, websocket.ErrPublicRateLimited),
+		errors.Is(err, websocket.ErrStageTimeout),
+		errors.Is(err, websocket.ErrUnknownSegment),
+		errors.Is(err, websocket.ErrSegmentsUnavailable),
+		errors.Is(err, websocket.ErrInvalidBan),
+		errors.Is(err, websocket.ErrBansUnavailable),
+		errors.Is(err, websocket.ErrInvalidTrace),
+		errors.Is(err, websocket.ErrTooManyTraces),
+		errors.Is(err, websocket.ErrStorage):
+		return err
 	default:
 		// Unknown errors panic to be caught by recovery middleware in development,
 		// or logged as 500 in production.
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	domain "notification-srv/internal/websocket"
+)
+
+// maxFingerprintLen bounds a forwarded fingerprint. JA3 and JA4 hashes are
+// well below it; raw JA3 strings of unusual clients may be longer and are
+// dropped rather than truncated.
+const maxFingerprintLen = 512
+
+// fingerprint reads the client fingerprints the edge forwarded in the
+// configured request headers.
+func (h *handler) fingerprint(header http.Header) domain.ClientFingerprint {
+	return domain.ClientFingerprint{
+		TLS:   fingerprintHeader(header, h.wsConfig.TLSFingerprintHeader),
+		HTTP2: fingerprintHeader(header, h.wsConfig.HTTP2FingerprintHeader),
+	}
+}
+
+// fingerprintHeader returns the value of the named header, or empty if the
+// name is not set or the value is too long or not printable ASCII.
+func fingerprintHeader(header http.Header, name string) string {
+	if name == "" {
+		return ""
+	}
+	value := strings.TrimSpace(header.Get(name))
+	if len(value) > maxFingerprintLen {
+		return ""
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < 0x20 || value[i] > 0x7e {
+			return ""
+		}
+	}
+	return value
+}
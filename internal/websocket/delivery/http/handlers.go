@@ -1,10 +1,13 @@
 package http
 
 import (
-	"net/http"
+	"errors"
+
+	domain "notification-srv/internal/websocket"
+	"notification-srv/internal/websocket/transport"
+	"notification-srv/pkg/errcode"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gorilla/websocket"
 	"github.com/smap-hcmut/shared-libs/go/response"
 )
 
@@ -13,43 +16,504 @@ import (
 // @Description Upgrade HTTP to WebSocket for real-time notifications. Requires valid JWT token in query 'token' or cookie.
 // @Tags Notification
 // @Param token query string true "JWT Token"
+// @Param subToken query string false "Project subscription token minted by smap-api; replaces token and receives only its project"
 // @Param project_id query string false "Project ID Filter"
+// @Param types query string false "Comma-separated message types to receive, e.g. project_progress,crisis_alert"
+// @Param min_importance query string false "Least important messages to receive: low, normal, high or critical"
+// @Param fields query string false "Comma-separated payload fields to keep, e.g. status,progress"
+// @Param client_label query string false "Frontend build, e.g. dashboard-v2 (letters, digits, . _ -; max 64)"
+// @Param asUser query string false "Subscribe to this user's stream instead; requires delegated access"
+// @Param X-Client-Version header string false "Client version, checked against the minimum of its label"
+// @Param client_version query string false "Client version, for browsers that cannot set X-Client-Version"
 // @Success 101 {string} string "Switching Protocols"
-// @Failure 401 {object} response.Resp "Unauthorized"
+// @Failure 401 {object} errcode.Problem "Unauthorized"
+// @Failure 403 {object} errcode.Problem "Banned, or not allowed to act as asUser"
+// @Failure 426 {object} upgradeRequiredResp "Client upgrade required"
+// @Failure 429 {object} errcode.Problem "Connection quota exceeded"
+// @Failure 504 {object} errcode.Problem "Connection setup timed out"
 // @Router /ws [GET]
 func (h *handler) HandleWebSocket(c *gin.Context) {
-	// 1. Process Request (Auth & Validation)
-	req, userID, err := h.processUpgradeRequest(c)
+	// 0. Outdated client builds are turned away before any other work
+	if !h.checkClientVersion(c) {
+		return
+	}
+
+	// 1. Process Request (Auth & Validation) within the auth budget
+	authCtx, cancel := stageContext(c.Request.Context(), h.wsConfig.AuthTimeout)
+	req, userID, err := h.processUpgradeRequest(authCtx, c)
+	if err == nil && h.stageTimedOut(authCtx, stageAuth, nil) {
+		err = domain.ErrStageTimeout
+	}
+	cancel()
 	if err != nil {
 		// Map domain error to HTTP error and send response
-		response.Error(c, h.mapError(err))
+		errcode.Respond(c, h.mapError(err))
 		return
 	}
 
-	// 2. Upgrade Connection
-	upgrader := websocket.Upgrader{
-		ReadBufferSize:  h.wsConfig.ReadBufferSize,
-		WriteBufferSize: h.wsConfig.WriteBufferSize,
-		CheckOrigin: func(r *http.Request) bool {
-			// Check against allowed origins or return true for now
-			return true
-		},
+	h.upgrade(c, req, userID, false)
+}
+
+// HandlePublicWebSocket upgrades an anonymous viewer of a public project.
+// @Summary Connect to a public project
+// @Description Upgrade HTTP to WebSocket without authentication to follow a project listed in websocket.public.projects, e.g. from a status page. Only that project's messages and broadcasts are delivered.
+// @Tags Notification
+// @Param project_id query string true "Public project ID"
+// @Param types query string false "Comma-separated message types to receive, e.g. project_progress,crisis_alert"
+// @Param min_importance query string false "Least important messages to receive: low, normal, high or critical"
+// @Param fields query string false "Comma-separated payload fields to keep, e.g. status,progress"
+// @Param client_label query string false "Frontend build, e.g. status-page (letters, digits, . _ -; max 64)"
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 403 {object} errcode.Problem "Banned"
+// @Failure 404 {object} errcode.Problem "Project is not public"
+// @Failure 429 {object} errcode.Problem "Anonymous connection limit reached"
+// @Failure 503 {object} errcode.Problem "Maximum connections reached"
+// @Router /ws/public [GET]
+func (h *handler) HandlePublicWebSocket(c *gin.Context) {
+	authCtx, cancel := stageContext(c.Request.Context(), h.wsConfig.AuthTimeout)
+	req, userID, err := h.processPublicUpgradeRequest(authCtx, c)
+	if err == nil && h.stageTimedOut(authCtx, stageAuth, nil) {
+		err = domain.ErrStageTimeout
+	}
+	cancel()
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	h.upgrade(c, req, userID, true)
+}
+
+// GetProtocol describes the client protocol of this server.
+// @Summary Get the client protocol
+// @Description Returns the version of the client protocol and the features this server has on: encodings a client may negotiate (permessage-deflate, the smap.delta.v1 subprotocol), whether frames may carry ack_required, whether frames carry seq so missed ones can be fetched again, the largest client frame, the ping interval and whether HEARTBEAT frames follow pings, and the client frame actions. With websocket.hello on, connections receive the same document first as a hello frame.
+// @Tags Notification
+// @Produce json
+// @Success 200 {object} protocolResp
+// @Router /protocol [GET]
+func (h *handler) GetProtocol(c *gin.Context) {
+	output, err := h.uc.GetProtocol(c.Request.Context())
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
 	}
 
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	response.OK(c, h.newProtocolResp(output))
+}
+
+// upgrade upgrades an admitted request and registers the connection with the
+// Hub under userID.
+func (h *handler) upgrade(c *gin.Context, req UpgradeReq, userID string, public bool) {
+	// 2. Upgrade Connection
+	// Compression is offered per client: some User-Agents (e.g. certain Mobile Safari
+	// versions) break on permessage-deflate and are denylisted via config.
+	compression := h.compressionAllowed(c.Request.UserAgent())
+	// Origins are not checked
+	conn, err := h.wsConfig.Transport.Upgrade(c.Writer, c.Request, transport.Options{
+		Subprotocols:     []string{domain.DeltaSubprotocol},
+		Compression:      compression,
+		ReadBufferSize:   h.wsConfig.ReadBufferSize,
+		WriteBufferSize:  h.wsConfig.WriteBufferSize,
+		HandshakeTimeout: h.wsConfig.UpgradeTimeout,
+	})
 	if err != nil {
-		h.logger.Errorf(c.Request.Context(), "upgrade failed: %v", err)
+		if !h.stageTimedOut(c.Request.Context(), stageUpgrade, err) {
+			h.logger.Errorf(c.Request.Context(), "upgrade failed: %v", err)
+		}
 		return
 	}
 
-	// 3. Register Connection via UseCase
-	input := req.toInput(conn, userID)
-	if err := h.uc.Register(c.Request.Context(), input); err != nil {
+	// 3. Register Connection via UseCase within the register budget
+	delta := conn.Subprotocol() == domain.DeltaSubprotocol
+	input := req.toInput(conn, userID, c.Request.UserAgent(), c.ClientIP(), compression && offersDeflate(c.Request.Header), delta)
+	input.Fingerprint = h.fingerprint(c.Request.Header)
+	input.Public = public
+	registerCtx, cancel := stageContext(c.Request.Context(), h.wsConfig.RegisterTimeout)
+	defer cancel()
+	if err := h.uc.Register(registerCtx, input); err != nil {
+		if h.stageTimedOut(registerCtx, stageRegister, err) {
+			closeTimedOut(conn)
+			return
+		}
+		if errors.Is(err, domain.ErrQuotaExceeded) {
+			// Another connection of the user took the last place since CheckQuota
+			conn.Close(transport.CloseTryAgainLater, "connection quota exceeded")
+			return
+		}
 		h.logger.Errorf(c.Request.Context(), "register failed: %v", err)
-		conn.Close()
+		conn.CloseNow()
 		return
 	}
 
 	// Connection is now managed by UseCase (Hub).
 	// We don't need to do anything else here.
 }
+
+// ListConnections lists live WebSocket connections for operators.
+// @Summary List connections
+// @Description List live WebSocket connections with per-connection details such as negotiated compression. Admin only.
+// @Tags Admin
+// @Produce json
+// @Param user_id query string false "Filter by user ID"
+// @Success 200 {object} listConnectionsResp
+// @Failure 401 {object} errcode.Problem "Unauthorized"
+// @Failure 403 {object} errcode.Problem "Forbidden"
+// @Security CookieAuth
+// @Security Bearer
+// @Router /admin/connections [GET]
+func (h *handler) ListConnections(c *gin.Context) {
+	req, err := h.processListConnectionsRequest(c)
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	output, err := h.uc.ListConnections(c.Request.Context(), req.toInput())
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	response.OK(c, h.newListConnectionsResp(output))
+}
+
+// GetStatsHistory returns the recent Hub stats samples of this replica.
+// @Summary Stats history
+// @Description Hub stats of this replica sampled every websocket.stats_history.interval and kept in memory for websocket.stats_history.retention: connections and unique users at each sample, and messages received, rejected, delivered and dropped since the previous one. Shows trends around an incident even when metrics scraping was broken. Admin only.
+// @Tags Admin
+// @Produce json
+// @Param window query string false "Only samples within this duration, e.g. 1h; default the whole history"
+// @Success 200 {object} getStatsHistoryResp
+// @Failure 400 {object} errcode.Problem "Bad Request"
+// @Failure 401 {object} errcode.Problem "Unauthorized"
+// @Failure 403 {object} errcode.Problem "Forbidden"
+// @Security CookieAuth
+// @Security Bearer
+// @Router /admin/stats/history [GET]
+func (h *handler) GetStatsHistory(c *gin.Context) {
+	req, err := h.processGetStatsHistoryRequest(c)
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	output, err := h.uc.GetStatsHistory(c.Request.Context(), req.toInput())
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	response.OK(c, h.newGetStatsHistoryResp(output))
+}
+
+// GetChannelStats returns per channel pattern delivery counters.
+// @Summary Channel statistics
+// @Description Messages received, transform errors, frames delivered and dropped, and messages without recipients, per channel pattern (IDs replaced by *) over the rolling window (websocket.stats_window) of this replica. Lets publisher teams check their integration is flowing.
+// @Tags Internal
+// @Produce json
+// @Param X-Internal-Key header string false "Internal service key, for unsigned requests"
+// @Param X-Signature-Key-Id header string false "Signing key ID (see pkg/signing)"
+// @Param X-Signature-Timestamp header string false "Unix seconds the request was signed at"
+// @Param X-Signature header string false "Hex HMAC-SHA256 request signature"
+// @Param pattern query string false "Glob over channel patterns, e.g. project:*" default(*)
+// @Success 200 {object} getChannelStatsResp
+// @Failure 400 {object} errcode.Problem "Bad Request"
+// @Failure 401 {object} errcode.Problem "Unauthorized"
+// @Security InternalKey
+// @Router /internal/stats/channels [GET]
+func (h *handler) GetChannelStats(c *gin.Context) {
+	req, err := h.processGetChannelStatsRequest(c)
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	output, err := h.uc.GetChannelStats(c.Request.Context(), req.toInput())
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	response.OK(c, h.newGetChannelStatsResp(output))
+}
+
+// SetMaintenance switches maintenance mode on or off on every replica.
+// @Summary Set maintenance mode
+// @Description While maintenance mode is on, only critical and terminal messages (crisis alerts, system notices, finished or failed onboardings, completed pipelines, finished campaigns) are delivered; everything else is dropped. Clients get a SYSTEM banner when the mode changes. The change is applied locally and published on control:maintenance:{on|off} for the other replicas. Admin only.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param body body setMaintenanceReq true "Maintenance toggle"
+// @Success 200 {object} maintenanceResp
+// @Failure 400 {object} errcode.Problem "Bad Request"
+// @Failure 401 {object} errcode.Problem "Unauthorized"
+// @Failure 403 {object} errcode.Problem "Forbidden"
+// @Security CookieAuth
+// @Security Bearer
+// @Router /admin/maintenance [POST]
+func (h *handler) SetMaintenance(c *gin.Context) {
+	req, sc, err := h.processSetMaintenanceRequest(c)
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	output, err := h.uc.SetMaintenance(c.Request.Context(), sc, req.toInput())
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	response.OK(c, h.newMaintenanceResp(output))
+}
+
+// GetMaintenance returns the maintenance mode of this replica.
+// @Summary Get maintenance mode
+// @Description Returns whether maintenance mode is on for this replica, with its reason and start time. Admin only.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} maintenanceResp
+// @Failure 401 {object} errcode.Problem "Unauthorized"
+// @Failure 403 {object} errcode.Problem "Forbidden"
+// @Security CookieAuth
+// @Security Bearer
+// @Router /admin/maintenance [GET]
+func (h *handler) GetMaintenance(c *gin.Context) {
+	output, err := h.uc.GetMaintenance(c.Request.Context())
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	response.OK(c, h.newMaintenanceResp(output))
+}
+
+// Broadcast sends an operator message to a segment of users.
+// @Summary Broadcast to a segment
+// @Description Sends a SYSTEM notification (system_event broadcast, title, message) to the users of a segment on every replica: all connected users (kind all), listed user IDs (kind users), users on a plan (kind plan, value = plan name), or users with a connection filtered to a project of an org (kind org, value = org ID). Plan and org segments are resolved from Redis sets. The response reports what the segment resolved to; delivery is asynchronous. Admin only.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param body body broadcastReq true "Segment and message"
+// @Success 200 {object} broadcastResp
+// @Failure 400 {object} errcode.Problem "Bad Request"
+// @Failure 401 {object} errcode.Problem "Unauthorized"
+// @Failure 403 {object} errcode.Problem "Forbidden"
+// @Security CookieAuth
+// @Security Bearer
+// @Router /admin/broadcast [POST]
+func (h *handler) Broadcast(c *gin.Context) {
+	req, sc, err := h.processBroadcastRequest(c)
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	output, err := h.uc.Broadcast(c.Request.Context(), sc, req.toInput())
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	response.OK(c, h.newBroadcastResp(output))
+}
+
+// Ban puts a user ID or an IP range on the deny-list.
+// @Summary Ban a user or IP range
+// @Description Bans a user ID (kind user) or an IP, or CIDR range (kind ip) for ttl_seconds on every replica. Banned clients get 403 on /ws before their token is checked, and their open connections are closed with code 4030. Admin only.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param body body banReq true "Ban"
+// @Success 200 {object} banResp
+// @Failure 400 {object} errcode.Problem "Bad Request"
+// @Failure 401 {object} errcode.Problem "Unauthorized"
+// @Failure 403 {object} errcode.Problem "Forbidden"
+// @Security CookieAuth
+// @Security Bearer
+// @Router /admin/bans [POST]
+func (h *handler) Ban(c *gin.Context) {
+	req, sc, err := h.processBanRequest(c)
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	output, err := h.uc.Ban(c.Request.Context(), sc, req.toInput())
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	response.OK(c, h.newBanResp(output))
+}
+
+// Unban lifts a ban.
+// @Summary Lift a ban
+// @Description Removes a user ID or IP range from the deny-list. The value must be the banned user ID or range (a single IP matches its /32 or /128 ban). Admin only.
+// @Tags Admin
+// @Produce json
+// @Param kind query string true "user or ip"
+// @Param value query string true "User ID, IP or CIDR range"
+// @Success 200 {object} response.Resp
+// @Failure 400 {object} errcode.Problem "Bad Request"
+// @Failure 401 {object} errcode.Problem "Unauthorized"
+// @Failure 403 {object} errcode.Problem "Forbidden"
+// @Security CookieAuth
+// @Security Bearer
+// @Router /admin/bans [DELETE]
+func (h *handler) Unban(c *gin.Context) {
+	req, sc, err := h.processUnbanRequest(c)
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	if err := h.uc.Unban(c.Request.Context(), sc, req.toInput()); err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	response.OK(c, nil)
+}
+
+// TraceUser turns on targeted tracing of a user.
+// @Summary Trace a user
+// @Description Logs every hop of the user's messages on every replica for ttl_seconds: one line per message with the time each step ended, the payload as published and the frame as routed, then a line per socket write and per ack. Latency exemplars of the user's messages carry their user_id. The TTL and the number of traced users are capped. Admin only.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param body body traceUserReq true "User to trace"
+// @Success 200 {object} userTraceResp
+// @Failure 400 {object} errcode.Problem "Bad Request"
+// @Failure 401 {object} errcode.Problem "Unauthorized"
+// @Failure 403 {object} errcode.Problem "Forbidden"
+// @Failure 429 {object} errcode.Problem "Too many users traced"
+// @Security CookieAuth
+// @Security Bearer
+// @Router /admin/traces [POST]
+func (h *handler) TraceUser(c *gin.Context) {
+	req, sc, err := h.processTraceUserRequest(c)
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	output, err := h.uc.TraceUser(c.Request.Context(), sc, req.toInput())
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	response.OK(c, h.newUserTraceResp(output))
+}
+
+// UntraceUser stops the targeted tracing of a user.
+// @Summary Stop tracing a user
+// @Description Stops tracing the user on every replica before the trace expires. Admin only.
+// @Tags Admin
+// @Produce json
+// @Param user_id query string true "Traced user ID"
+// @Success 200 {object} response.Resp
+// @Failure 400 {object} errcode.Problem "Bad Request"
+// @Failure 401 {object} errcode.Problem "Unauthorized"
+// @Failure 403 {object} errcode.Problem "Forbidden"
+// @Security CookieAuth
+// @Security Bearer
+// @Router /admin/traces [DELETE]
+func (h *handler) UntraceUser(c *gin.Context) {
+	req, sc, err := h.processUntraceUserRequest(c)
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	if err := h.uc.UntraceUser(c.Request.Context(), sc, req.toInput()); err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	response.OK(c, nil)
+}
+
+// ListUserTraces returns the users traced on this replica.
+// @Summary List traced users
+// @Description Returns the users under targeted tracing on this replica, with the expiry of their trace. Admin only.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} listUserTracesResp
+// @Failure 401 {object} errcode.Problem "Unauthorized"
+// @Failure 403 {object} errcode.Problem "Forbidden"
+// @Security CookieAuth
+// @Security Bearer
+// @Router /admin/traces [GET]
+func (h *handler) ListUserTraces(c *gin.Context) {
+	output, err := h.uc.ListUserTraces(c.Request.Context())
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	response.OK(c, h.newListUserTracesResp(output))
+}
+
+// ListProjectNotifications returns buffered frames a client missed on a project topic.
+// @Summary Recover missed project notifications
+// @Description Returns the frames of the caller's project topic with a sequence number greater than after_seq, oldest first. If the epoch differs from the one the client saw, or truncated is true, the client must resync instead of relying on the frames.
+// @Tags WebSocket
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param after_seq query int false "Last sequence number the client received" default(0)
+// @Success 200 {object} listProjectNotificationsResp
+// @Failure 400 {object} errcode.Problem "Bad Request"
+// @Failure 401 {object} errcode.Problem "Unauthorized"
+// @Security CookieAuth
+// @Security Bearer
+// @Router /api/projects/{id}/notifications [GET]
+func (h *handler) ListProjectNotifications(c *gin.Context) {
+	req, sc, err := h.processListProjectNotificationsRequest(c)
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	output, err := h.uc.ListProjectNotifications(c.Request.Context(), sc, req.toInput())
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	response.OK(c, h.newListProjectNotificationsResp(output))
+}
+
+// CheckContract verifies a sample publisher message without delivering it.
+// @Summary Check message contract
+// @Description Runs channel parsing, type detection, strict validation and transformation on a sample payload and returns every violation plus the normalized frame clients would receive. Used by publisher CI (see cmd/contract-check).
+// @Tags Internal
+// @Accept json
+// @Produce json
+// @Param X-Internal-Key header string false "Internal service key, for unsigned requests"
+// @Param X-Signature-Key-Id header string false "Signing key ID (see pkg/signing)"
+// @Param X-Signature-Timestamp header string false "Unix seconds the request was signed at"
+// @Param X-Signature header string false "Hex HMAC-SHA256 request signature"
+// @Param body body checkContractReq true "Sample message"
+// @Success 200 {object} checkContractResp
+// @Failure 400 {object} errcode.Problem "Bad Request"
+// @Failure 401 {object} errcode.Problem "Unauthorized"
+// @Security InternalKey
+// @Router /internal/contract-check [POST]
+func (h *handler) CheckContract(c *gin.Context) {
+	req, err := h.processCheckContractRequest(c)
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	output, err := h.uc.CheckContract(c.Request.Context(), req.toInput())
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+
+	response.OK(c, h.newCheckContractResp(output))
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"notification-srv/internal/websocket"
+	"notification-srv/internal/websocket/transport/gorilla"
 
 	"github.com/gin-gonic/gin"
 	"github.com/smap-hcmut/shared-libs/go/auth"
@@ -12,6 +13,10 @@ import (
 // Handler defines the HTTP handler interface for WebSocket.
 type Handler interface {
 	RegisterRoutes(r *gin.RouterGroup, mw *middleware.Middleware)
+	RegisterAPIRoutes(r *gin.RouterGroup, mw *middleware.Middleware)
+	RegisterAdminRoutes(r *gin.RouterGroup, auth ...gin.HandlerFunc)
+	RegisterInternalRoutes(r *gin.RouterGroup, auth gin.HandlerFunc)
+	RegisterWebTransportRoutes(r *gin.RouterGroup)
 }
 
 type handler struct {
@@ -24,6 +29,9 @@ type handler struct {
 }
 
 func New(uc websocket.UseCase, jwtMgr auth.Manager, logger log.Logger, wsCfg WSConfig, cookieCfg CookieConfig, env string) Handler {
+	if wsCfg.Transport == nil {
+		wsCfg.Transport = gorilla.New()
+	}
 	return &handler{
 		uc:          uc,
 		jwtMgr:      jwtMgr,
@@ -1,18 +1,53 @@
 package http
 
 import (
+	"encoding/json"
 	domain "notification-srv/internal/websocket"
+	"notification-srv/internal/websocket/transport"
+	"notification-srv/pkg/errcode"
+	"notification-srv/pkg/subtoken"
+	"regexp"
+	"strings"
+	"time"
 
-	"github.com/gorilla/websocket"
+	"github.com/quic-go/webtransport-go"
 )
 
 // --- Configuration DTOs ---
 
 type WSConfig struct {
+	Path            string // Route of the WebSocket endpoint
 	MaxConnections  int
 	ReadBufferSize  int
 	WriteBufferSize int
 	AllowedOrigins  []string
+
+	// WebSocket library the handshakes and connections go through (nil = gorilla)
+	Transport transport.Upgrader
+
+	// Compression
+	EnableCompression   bool
+	CompressionDenylist []string // User-Agent substrings for which permessage-deflate is never offered
+
+	// Verifies ?subToken= project subscription tokens (nil = not accepted)
+	SubTokens *subtoken.Verifier
+
+	// Accepts sessions on the experimental HTTP/3 listener (nil = disabled)
+	WebTransport *webtransport.Server
+
+	// Request headers carrying client fingerprints forwarded by the edge (empty = not captured)
+	TLSFingerprintHeader   string
+	HTTP2FingerprintHeader string
+
+	// Minimum client versions
+	MinClientVersions map[string]string // Client label ("*" = any other) to minimum version
+	UpgradeURL        string            // Where outdated clients get a new build
+	CloseOutdated     bool              // Close outdated WebSocket clients with 4260 instead of answering 426
+
+	// Budgets of the upgrade path stages (0 = unbounded)
+	AuthTimeout     time.Duration
+	UpgradeTimeout  time.Duration
+	RegisterTimeout time.Duration
 }
 
 type CookieConfig struct {
@@ -27,24 +62,581 @@ type CookieConfig struct {
 // --- Request DTOs ---
 
 type UpgradeReq struct {
-	Token     string `form:"token"`
-	ProjectID string `form:"project_id"`
+	Token       string `form:"token"`
+	SubToken    string `form:"subToken"` // Project subscription token; replaces token and pins project_id
+	ProjectID   string `form:"project_id"`
+	Types       string `form:"types"`        // Comma-separated message types, e.g. project_progress,crisis_alert
+	Fields      string `form:"fields"`       // Comma-separated payload fields, e.g. status,progress
+	ClientLabel string `form:"client_label"` // Frontend build, e.g. dashboard-v2
+	AsUser      string `form:"asUser"`       // Subscribe to this user's stream through delegated access
+
+	MinImportance string `form:"min_importance"` // low, normal, high or critical
+
+	delegatedBy string // Token owner once CheckDelegation let it act as AsUser
+	quota       int    // Resolved by CheckQuota
 }
 
+// clientLabelPattern bounds what a client label may contain, since it ends up
+// in logs and admin listings.
+var clientLabelPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
 func (r UpgradeReq) validate() error {
-	if r.Token == "" {
+	if r.Token == "" && r.SubToken == "" {
 		return domain.ErrMissingToken
 	}
 	// ProjectID is optional filter
+	// Hub identities with ":" are not users, e.g. anonymous viewers
+	if strings.Contains(r.AsUser, ":") {
+		return domain.ErrInvalidMessage
+	}
+	return r.validateFilters()
+}
+
+// validateFilters checks the client label and the message filters.
+func (r UpgradeReq) validateFilters() error {
+	if r.ClientLabel != "" && !clientLabelPattern.MatchString(r.ClientLabel) {
+		return domain.ErrInvalidMessage
+	}
+	for _, t := range r.messageTypes() {
+		if !domain.FilterableMessageTypes[t] {
+			return domain.ErrInvalidMessage
+		}
+	}
+	if r.MinImportance != "" {
+		if _, err := domain.ParseImportance(r.MinImportance); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// messageTypes splits the types filter. Names are case-insensitive.
+func (r UpgradeReq) messageTypes() []domain.MessageType {
+	var types []domain.MessageType
+	for _, name := range strings.Split(r.Types, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			types = append(types, domain.MessageType(strings.ToUpper(name)))
+		}
+	}
+	return types
+}
+
+// payloadFields splits the fields projection.
+func (r UpgradeReq) payloadFields() []string {
+	var fields []string
+	for _, name := range strings.Split(r.Fields, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
 // toInput maps the DTO and connection to the UseCase input.
-// Note: We cast *websocket.Conn to interface{} here.
-func (r UpgradeReq) toInput(conn *websocket.Conn, userID string) domain.ConnectionInput {
+// Note: We cast transport.Conn to interface{} here.
+func (r UpgradeReq) toInput(conn transport.Conn, userID, userAgent, remoteIP string, compression, delta bool) domain.ConnectionInput {
 	return domain.ConnectionInput{
-		UserID:    userID,
+		UserID:      userID,
+		ProjectID:   r.ProjectID,
+		ProjectOnly: r.SubToken != "",
+		UserAgent:   userAgent,
+		Compression: compression,
+		Conn:        conn,
+		Types:       r.messageTypes(),
+		Fields:      r.payloadFields(),
+		Delta:       delta,
+		RemoteIP:    remoteIP,
+		ClientLabel: r.ClientLabel,
+		DelegatedBy: r.delegatedBy,
+		Quota:       r.quota,
+
+		MinImportance: domain.Importance(r.MinImportance),
+	}
+}
+
+type listConnectionsReq struct {
+	UserID string `form:"user_id"`
+}
+
+func (r listConnectionsReq) toInput() domain.ListConnectionsInput {
+	return domain.ListConnectionsInput{UserID: r.UserID}
+}
+
+type getStatsHistoryReq struct {
+	Window time.Duration `form:"window"` // e.g. 1h; empty returns the whole history
+}
+
+func (r getStatsHistoryReq) toInput() domain.GetStatsHistoryInput {
+	return domain.GetStatsHistoryInput{Window: r.Window}
+}
+
+type listProjectNotificationsReq struct {
+	ProjectID string `uri:"id"`
+	AfterSeq  uint64 `form:"after_seq"`
+}
+
+func (r listProjectNotificationsReq) validate() error {
+	if r.ProjectID == "" {
+		return domain.ErrInvalidMessage
+	}
+	return nil
+}
+
+func (r listProjectNotificationsReq) toInput() domain.ListProjectNotificationsInput {
+	return domain.ListProjectNotificationsInput{
 		ProjectID: r.ProjectID,
-		Conn:      conn,
+		AfterSeq:  r.AfterSeq,
+	}
+}
+
+type checkContractReq struct {
+	Channel string          `json:"channel"` // Optional, e.g. project:{project_id}:user:{user_id}
+	Payload json.RawMessage `json:"payload"`
+}
+
+func (r checkContractReq) validate() error {
+	if len(r.Payload) == 0 {
+		return domain.ErrInvalidMessage
+	}
+	return nil
+}
+
+func (r checkContractReq) toInput() domain.CheckContractInput {
+	return domain.CheckContractInput{
+		Channel: r.Channel,
+		Payload: r.Payload,
+	}
+}
+
+type getChannelStatsReq struct {
+	Pattern string `form:"pattern"` // e.g. project:*
+}
+
+func (r getChannelStatsReq) toInput() domain.GetChannelStatsInput {
+	return domain.GetChannelStatsInput{Pattern: r.Pattern}
+}
+
+type setMaintenanceReq struct {
+	Enabled *bool  `json:"enabled"`
+	Reason  string `json:"reason"` // Shown to clients in the banner
+}
+
+func (r setMaintenanceReq) validate() error {
+	if r.Enabled == nil {
+		return domain.ErrInvalidMessage
+	}
+	return nil
+}
+
+func (r setMaintenanceReq) toInput() domain.SetMaintenanceInput {
+	return domain.SetMaintenanceInput{
+		Enabled: *r.Enabled,
+		Reason:  r.Reason,
+	}
+}
+
+type segmentReq struct {
+	Kind    string   `json:"kind"`               // all, users, plan, org
+	Value   string   `json:"value,omitempty"`    // Plan name or org ID
+	UserIDs []string `json:"user_ids,omitempty"` // Only for kind users
+}
+
+type broadcastReq struct {
+	Segment segmentReq `json:"segment"`
+	Title   string     `json:"title"`
+	Message string     `json:"message"`
+}
+
+func (r broadcastReq) validate() error {
+	if r.Message == "" || r.Segment.Kind == "" {
+		return domain.ErrInvalidMessage
+	}
+	switch r.Segment.Kind {
+	case domain.SegmentAll:
+	case domain.SegmentUsers:
+		if len(r.Segment.UserIDs) == 0 {
+			return domain.ErrInvalidMessage
+		}
+	default:
+		if r.Segment.Value == "" {
+			return domain.ErrInvalidMessage
+		}
+	}
+	return nil
+}
+
+func (r broadcastReq) toInput() domain.BroadcastInput {
+	return domain.BroadcastInput{
+		Segment: domain.Segment{
+			Kind:    r.Segment.Kind,
+			Value:   r.Segment.Value,
+			UserIDs: r.Segment.UserIDs,
+		},
+		Title:   r.Title,
+		Message: r.Message,
+	}
+}
+
+type banReq struct {
+	Kind       string `json:"kind"`        // user or ip
+	Value      string `json:"value"`       // User ID, IP or CIDR range
+	TTLSeconds int    `json:"ttl_seconds"` // Ban duration
+}
+
+func (r banReq) validate() error {
+	if r.Kind == "" || r.Value == "" || r.TTLSeconds <= 0 {
+		return domain.ErrInvalidMessage
+	}
+	return nil
+}
+
+func (r banReq) toInput() domain.BanInput {
+	return domain.BanInput{
+		Kind:  r.Kind,
+		Value: r.Value,
+		TTL:   time.Duration(r.TTLSeconds) * time.Second,
+	}
+}
+
+type unbanReq struct {
+	Kind  string `form:"kind"`
+	Value string `form:"value"`
+}
+
+func (r unbanReq) validate() error {
+	if r.Kind == "" || r.Value == "" {
+		return domain.ErrInvalidMessage
+	}
+	return nil
+}
+
+func (r unbanReq) toInput() domain.UnbanInput {
+	return domain.UnbanInput{
+		Kind:  r.Kind,
+		Value: r.Value,
+	}
+}
+
+type traceUserReq struct {
+	UserID     string `json:"user_id"`
+	TTLSeconds int    `json:"ttl_seconds"` // Trace duration
+}
+
+func (r traceUserReq) validate() error {
+	if r.UserID == "" || r.TTLSeconds <= 0 {
+		return domain.ErrInvalidMessage
+	}
+	return nil
+}
+
+func (r traceUserReq) toInput() domain.TraceUserInput {
+	return domain.TraceUserInput{
+		UserID: r.UserID,
+		TTL:    time.Duration(r.TTLSeconds) * time.Second,
+	}
+}
+
+type untraceUserReq struct {
+	UserID string `form:"user_id"`
+}
+
+func (r untraceUserReq) validate() error {
+	if r.UserID == "" {
+		return domain.ErrInvalidMessage
+	}
+	return nil
+}
+
+func (r untraceUserReq) toInput() domain.UntraceUserInput {
+	return domain.UntraceUserInput{UserID: r.UserID}
+}
+
+// --- Response DTOs ---
+
+type connectionResp struct {
+	UserID      string    `json:"user_id"`
+	ProjectID   string    `json:"project_id,omitempty"`
+	ProjectOnly bool      `json:"project_only"`
+	Public      bool      `json:"public"`
+	DelegatedBy string    `json:"delegated_by,omitempty"`
+	UserAgent   string    `json:"user_agent,omitempty"`
+	Compression bool      `json:"compression"`
+	ConnectedAt time.Time `json:"connected_at"`
+	RTTMs       float64   `json:"rtt_ms"`
+	Focus       string    `json:"focus,omitempty"`
+
+	Types  []domain.MessageType `json:"types,omitempty"`  // Empty receives all types
+	Fields []string             `json:"fields,omitempty"` // Empty sends full payloads
+	Delta  bool                 `json:"delta"`
+
+	RemoteIP    string `json:"remote_ip,omitempty"`
+	ClientLabel string `json:"client_label,omitempty"`
+
+	TLSFingerprint   string `json:"tls_fingerprint,omitempty"`
+	HTTP2Fingerprint string `json:"http2_fingerprint,omitempty"`
+
+	MinImportance domain.Importance `json:"min_importance,omitempty"` // Empty receives all
+
+	SendQueueLen int `json:"send_queue_len"`
+	SendQueueCap int `json:"send_queue_cap"`
+
+	Drops         map[string]uint64 `json:"drops,omitempty"`
+	TopDropReason string            `json:"top_drop_reason,omitempty"`
+}
+
+type upgradeRequiredResp struct {
+	errcode.Problem
+	ClientVersion string `json:"client_version"`
+	MinVersion    string `json:"min_version"`
+	UpgradeURL    string `json:"upgrade_url,omitempty"`
+}
+
+type listConnectionsResp struct {
+	Total       int              `json:"total"`
+	Connections []connectionResp `json:"connections"`
+}
+
+func (h *handler) newListConnectionsResp(output domain.ListConnectionsOutput) listConnectionsResp {
+	conns := make([]connectionResp, len(output.Connections))
+	for i, c := range output.Connections {
+		conns[i] = connectionResp{
+			UserID:      c.UserID,
+			ProjectID:   c.ProjectID,
+			ProjectOnly: c.ProjectOnly,
+			Public:      c.Public,
+			DelegatedBy: c.DelegatedBy,
+			UserAgent:   c.UserAgent,
+			Compression: c.Compression,
+			ConnectedAt: c.ConnectedAt,
+			RTTMs:       float64(c.RTT) / float64(time.Millisecond),
+			Focus:       c.Focus,
+			Types:       c.Types,
+			Fields:      c.Fields,
+			Delta:       c.Delta,
+			RemoteIP:    c.RemoteIP,
+			ClientLabel: c.ClientLabel,
+
+			TLSFingerprint:   c.Fingerprint.TLS,
+			HTTP2Fingerprint: c.Fingerprint.HTTP2,
+
+			MinImportance: c.MinImportance,
+
+			SendQueueLen: c.SendQueueLen,
+			SendQueueCap: c.SendQueueCap,
+
+			Drops:         c.Drops,
+			TopDropReason: c.TopDropReason,
+		}
+	}
+	return listConnectionsResp{
+		Total:       len(conns),
+		Connections: conns,
+	}
+}
+
+type statsSampleResp struct {
+	Time              time.Time `json:"time"`
+	ActiveConnections int       `json:"active_connections"`
+	UniqueUsers       int       `json:"unique_users"`
+	Received          uint64    `json:"received"`
+	TransformErrors   uint64    `json:"transform_errors"`
+	Delivered         uint64    `json:"delivered"`
+	Dropped           uint64    `json:"dropped"`
+}
+
+type getStatsHistoryResp struct {
+	IntervalSeconds int               `json:"interval_seconds"`
+	Samples         []statsSampleResp `json:"samples"`
+}
+
+func (h *handler) newGetStatsHistoryResp(output domain.GetStatsHistoryOutput) getStatsHistoryResp {
+	samples := make([]statsSampleResp, len(output.Samples))
+	for i, s := range output.Samples {
+		samples[i] = statsSampleResp{
+			Time:              s.Time,
+			ActiveConnections: s.ActiveConnections,
+			UniqueUsers:       s.UniqueUsers,
+			Received:          s.Received,
+			TransformErrors:   s.TransformErrors,
+			Delivered:         s.Delivered,
+			Dropped:           s.Dropped,
+		}
+	}
+	return getStatsHistoryResp{
+		IntervalSeconds: int(output.Interval.Seconds()),
+		Samples:         samples,
+	}
+}
+
+type checkContractResp struct {
+	Valid       bool                       `json:"valid"`
+	ChannelType string                     `json:"channel_type,omitempty"`
+	MessageType string                     `json:"message_type,omitempty"`
+	Errors      []string                   `json:"errors"`
+	Output      *domain.NotificationOutput `json:"output,omitempty"`
+}
+
+func (h *handler) newCheckContractResp(output domain.CheckContractOutput) checkContractResp {
+	errs := output.Errors
+	if errs == nil {
+		errs = []string{}
+	}
+	return checkContractResp{
+		Valid:       len(errs) == 0,
+		ChannelType: string(output.ChannelType),
+		MessageType: string(output.MessageType),
+		Errors:      errs,
+		Output:      output.Output,
+	}
+}
+
+type listProjectNotificationsResp struct {
+	Epoch     string            `json:"epoch"`
+	LastSeq   uint64            `json:"last_seq"`
+	Truncated bool              `json:"truncated"`
+	Frames    []json.RawMessage `json:"frames"`
+}
+
+func (h *handler) newListProjectNotificationsResp(output domain.ListProjectNotificationsOutput) listProjectNotificationsResp {
+	frames := output.Frames
+	if frames == nil {
+		frames = []json.RawMessage{}
+	}
+	return listProjectNotificationsResp{
+		Epoch:     output.Epoch,
+		LastSeq:   output.LastSeq,
+		Truncated: output.Truncated,
+		Frames:    frames,
+	}
+}
+
+type maintenanceResp struct {
+	Enabled bool       `json:"enabled"`
+	Reason  string     `json:"reason,omitempty"`
+	Since   *time.Time `json:"since,omitempty"`
+}
+
+func (h *handler) newMaintenanceResp(output domain.MaintenanceStatus) maintenanceResp {
+	resp := maintenanceResp{
+		Enabled: output.Enabled,
+		Reason:  output.Reason,
+	}
+	if !output.Since.IsZero() {
+		resp.Since = &output.Since
+	}
+	return resp
+}
+
+type protocolResp struct {
+	Version             string   `json:"version"`
+	Encodings           []string `json:"encodings"`
+	Ack                 bool     `json:"ack"`                   // Frames may carry ack_required
+	Resume              bool     `json:"resume"`                // Frames carry seq; fetch missed ones with after_seq
+	MaxMessageSize      int      `json:"max_message_size"`      // Largest client frame, in bytes
+	HeartbeatIntervalMs int64    `json:"heartbeat_interval_ms"` // Between pings
+	HeartbeatFrames     bool     `json:"heartbeat_frames"`
+	Actions             []string `json:"actions"`
+}
+
+func (h *handler) newProtocolResp(output domain.Protocol) protocolResp {
+	actions := make([]string, 0, len(output.Actions))
+	for _, a := range output.Actions {
+		actions = append(actions, string(a))
+	}
+	return protocolResp{
+		Version:             output.Version,
+		Encodings:           output.Encodings,
+		Ack:                 output.Ack,
+		Resume:              output.Resume,
+		MaxMessageSize:      output.MaxMessageSize,
+		HeartbeatIntervalMs: output.HeartbeatInterval.Milliseconds(),
+		HeartbeatFrames:     output.HeartbeatFrames,
+		Actions:             actions,
+	}
+}
+
+type broadcastResp struct {
+	ID       string `json:"id"`
+	All      bool   `json:"all,omitempty"`
+	Users    int    `json:"users"`    // Users the segment resolved to
+	Projects int    `json:"projects"` // Projects the segment resolved to
+}
+
+func (h *handler) newBroadcastResp(output domain.BroadcastOutput) broadcastResp {
+	return broadcastResp{
+		ID:       output.ID,
+		All:      output.All,
+		Users:    output.UserIDs,
+		Projects: output.ProjectIDs,
+	}
+}
+
+type banResp struct {
+	Kind      string    `json:"kind"`
+	Value     string    `json:"value"` // IPs are returned as CIDR ranges
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (h *handler) newBanResp(output domain.BanOutput) banResp {
+	return banResp{
+		Kind:      output.Kind,
+		Value:     output.Value,
+		ExpiresAt: output.ExpiresAt,
+	}
+}
+
+type userTraceResp struct {
+	UserID    string    `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (h *handler) newUserTraceResp(output domain.UserTrace) userTraceResp {
+	return userTraceResp{
+		UserID:    output.UserID,
+		ExpiresAt: output.ExpiresAt,
+	}
+}
+
+type listUserTracesResp struct {
+	Traces []userTraceResp `json:"traces"`
+}
+
+func (h *handler) newListUserTracesResp(output domain.ListUserTracesOutput) listUserTracesResp {
+	resp := listUserTracesResp{Traces: make([]userTraceResp, 0, len(output.Traces))}
+	for _, t := range output.Traces {
+		resp.Traces = append(resp.Traces, h.newUserTraceResp(t))
+	}
+	return resp
+}
+
+type channelStatsResp struct {
+	Channel         string `json:"channel"`
+	Received        uint64 `json:"received"`
+	TransformErrors uint64 `json:"transform_errors"`
+	Delivered       uint64 `json:"delivered"`
+	Dropped         uint64 `json:"dropped"`
+	NoRecipients    uint64 `json:"no_recipients"`
+}
+
+type getChannelStatsResp struct {
+	WindowSeconds int                `json:"window_seconds"`
+	Channels      []channelStatsResp `json:"channels"`
+}
+
+func (h *handler) newGetChannelStatsResp(output domain.GetChannelStatsOutput) getChannelStatsResp {
+	channels := make([]channelStatsResp, len(output.Channels))
+	for i, c := range output.Channels {
+		channels[i] = channelStatsResp{
+			Channel:         c.Channel,
+			Received:        c.Received,
+			TransformErrors: c.TransformErrors,
+			Delivered:       c.Delivered,
+			Dropped:         c.Dropped,
+			NoRecipients:    c.NoRecipients,
+		}
+	}
+	return getChannelStatsResp{
+		WindowSeconds: int(output.Window.Seconds()),
+		Channels:      channels,
 	}
 }
@@ -1,14 +1,19 @@
 package http
 
 import (
+	"context"
+
+	"notification-srv/internal/model"
 	"notification-srv/internal/websocket"
 
 	"github.com/gin-gonic/gin"
+	"github.com/smap-hcmut/shared-libs/go/auth"
 )
 
 // processUpgradeRequest handles the initial request processing before upgrade.
 // It extracts the token, validates it, and returns the upgrade request info and keys.
-func (h *handler) processUpgradeRequest(c *gin.Context) (UpgradeReq, string, error) {
+// Lookups run under ctx, which carries the auth stage budget.
+func (h *handler) processUpgradeRequest(ctx context.Context, c *gin.Context) (UpgradeReq, string, error) {
 	var req UpgradeReq
 
 	// 1. Bind Query Params (token, project_id)
@@ -17,7 +22,7 @@ func (h *handler) processUpgradeRequest(c *gin.Context) (UpgradeReq, string, err
 	}
 
 	// 2. Fallback: Check Cookie if token missing
-	if req.Token == "" {
+	if req.Token == "" && req.SubToken == "" {
 		if cookie, err := c.Cookie(h.cookieCfg.Name); err == nil {
 			req.Token = cookie
 		}
@@ -28,18 +33,271 @@ func (h *handler) processUpgradeRequest(c *gin.Context) (UpgradeReq, string, err
 		return UpgradeReq{}, "", err
 	}
 
-	// 4. Verify Token
-	payload, err := h.jwtMgr.Verify(req.Token)
+	// 4. Deny-list: the address is refused before any token work
+	if err := h.uc.CheckBan(ctx, websocket.CheckBanInput{RemoteIP: c.ClientIP()}); err != nil {
+		return UpgradeReq{}, "", err
+	}
+
+	// 5. Verify Token; a subscription token takes precedence and pins the project
+	userID, token, err := h.verifyUpgradeToken(ctx, &req)
 	if err != nil {
-		h.logger.Warnf(c.Request.Context(), "token verification failed: %v", err)
-		return UpgradeReq{}, "", websocket.ErrInvalidToken
+		return UpgradeReq{}, "", err
+	}
+	if err := h.uc.CheckBan(ctx, websocket.CheckBanInput{UserID: userID}); err != nil {
+		return UpgradeReq{}, "", err
+	}
+
+	// 6. Decoy projects are never issued: subscribing to one is reported
+	if err := h.uc.CheckDecoy(ctx, websocket.CheckDecoyInput{
+		UserID:    userID,
+		ProjectID: req.ProjectID,
+		RemoteIP:  c.ClientIP(),
+		Transport: "websocket",
+	}); err != nil {
+		return UpgradeReq{}, "", err
+	}
+
+	// 7. Delegated access: the connection joins another user's stream, and
+	// counts toward that user's quota
+	if req.AsUser != "" && req.AsUser != userID {
+		// A subscription token is scoped to its own user's project
+		if req.SubToken != "" {
+			return UpgradeReq{}, "", websocket.ErrInvalidMessage
+		}
+		if err := h.uc.CheckDelegation(ctx, websocket.DelegationInput{
+			ActorID:  userID,
+			UserID:   req.AsUser,
+			Claims:   model.TokenClaims(token),
+			RemoteIP: c.ClientIP(),
+		}); err != nil {
+			return UpgradeReq{}, "", err
+		}
+		req.delegatedBy, userID = userID, req.AsUser
+	}
+
+	// 8. Connection quota, which may depend on claims auth.Payload doesn't carry.
+	// A delegate's token describes the delegate, not the watched user, so its
+	// claims must not set that user's quota
+	quotaInput := websocket.QuotaInput{UserID: userID, Claims: model.TokenClaims(token)}
+	if req.delegatedBy != "" {
+		quotaInput.Claims = nil
+	}
+	if req.quota, err = h.uc.CheckQuota(ctx, quotaInput); err != nil {
+		return UpgradeReq{}, "", err
+	}
+
+	return req, userID, nil
+}
+
+// processPublicUpgradeRequest binds an anonymous upgrade and admits it as a
+// viewer of the public project. Returns the Hub identity of its viewers.
+func (h *handler) processPublicUpgradeRequest(ctx context.Context, c *gin.Context) (UpgradeReq, string, error) {
+	var req UpgradeReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return UpgradeReq{}, "", websocket.ErrInvalidMessage
+	}
+	// The viewer is anonymous whatever credentials it sends
+	req.Token, req.SubToken, req.AsUser = "", "", ""
+
+	if req.ProjectID == "" {
+		return UpgradeReq{}, "", websocket.ErrInvalidMessage
+	}
+	if err := req.validateFilters(); err != nil {
+		return UpgradeReq{}, "", err
+	}
+
+	if err := h.uc.CheckBan(ctx, websocket.CheckBanInput{RemoteIP: c.ClientIP()}); err != nil {
+		return UpgradeReq{}, "", err
+	}
+	out, err := h.uc.CheckPublic(ctx, websocket.CheckPublicInput{
+		ProjectID: req.ProjectID,
+		RemoteIP:  c.ClientIP(),
+	})
+	if err != nil {
+		return UpgradeReq{}, "", err
+	}
+	return req, out.UserID, nil
+}
+
+// verifyUpgradeToken verifies the subscription token if req has one, else the
+// user token, and returns the user and the verified token. A subscription
+// token sets req.ProjectID to its project; a different project_id is refused.
+func (h *handler) verifyUpgradeToken(ctx context.Context, req *UpgradeReq) (userID, token string, err error) {
+	if req.SubToken == "" {
+		payload, err := h.jwtMgr.Verify(req.Token)
+		if err != nil {
+			h.logger.Warnf(ctx, "token verification failed: %v", err)
+			return "", "", websocket.ErrInvalidToken
+		}
+		return payload.UserID, req.Token, nil
+	}
+
+	if h.wsConfig.SubTokens == nil {
+		return "", "", websocket.ErrInvalidToken
+	}
+	claims, err := h.wsConfig.SubTokens.Verify(req.SubToken)
+	if err != nil {
+		h.logger.Warnf(ctx, "subscription token verification failed: %v", err)
+		return "", "", websocket.ErrInvalidToken
+	}
+	if req.ProjectID != "" && req.ProjectID != claims.ProjectID {
+		h.logger.Warnf(ctx, "subscription token for project %s used for project %s", claims.ProjectID, req.ProjectID)
+		return "", "", websocket.ErrInvalidToken
+	}
+	req.ProjectID = claims.ProjectID
+	return claims.UserID, req.SubToken, nil
+}
+
+// processListConnectionsRequest binds the admin connection listing filters.
+func (h *handler) processListConnectionsRequest(c *gin.Context) (listConnectionsReq, error) {
+	var req listConnectionsReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return listConnectionsReq{}, websocket.ErrInvalidMessage
+	}
+	return req, nil
+}
+
+// processGetStatsHistoryRequest binds the history window.
+func (h *handler) processGetStatsHistoryRequest(c *gin.Context) (getStatsHistoryReq, error) {
+	var req getStatsHistoryReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return getStatsHistoryReq{}, websocket.ErrInvalidMessage
+	}
+	return req, nil
+}
+
+// processCheckContractRequest binds and validates a sample publisher message.
+func (h *handler) processCheckContractRequest(c *gin.Context) (checkContractReq, error) {
+	var req checkContractReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return checkContractReq{}, websocket.ErrInvalidMessage
+	}
+
+	if err := req.validate(); err != nil {
+		return checkContractReq{}, err
 	}
+	return req, nil
+}
 
-	// payload.UserID (assuming auth.Payload struct has UserID field based on pkg/jwt/interface.go usage of Verify returning auth.Payload)
-	// If auth.Payload is map or struct, we need to know.
-	// Based on "Verify(token string) (auth.Payload, error)" in interface.go.
-	// We assume payload has UserID field or method.
-	// Let's assume it's a struct with UserID.
+// processListProjectNotificationsRequest binds the project ID and after_seq and
+// extracts the caller scope set by the auth middleware.
+func (h *handler) processListProjectNotificationsRequest(c *gin.Context) (listProjectNotificationsReq, model.Scope, error) {
+	var req listProjectNotificationsReq
+	if err := c.ShouldBindUri(&req); err != nil {
+		return listProjectNotificationsReq{}, model.Scope{}, websocket.ErrInvalidMessage
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return listProjectNotificationsReq{}, model.Scope{}, websocket.ErrInvalidMessage
+	}
 
-	return req, payload.UserID, nil
+	if err := req.validate(); err != nil {
+		return listProjectNotificationsReq{}, model.Scope{}, err
+	}
+
+	sc := model.ToScope(auth.GetScopeFromContext(c.Request.Context()))
+	return req, sc, nil
+}
+
+// processSetMaintenanceRequest binds the maintenance toggle and extracts the
+// caller scope set by the auth middleware.
+func (h *handler) processSetMaintenanceRequest(c *gin.Context) (setMaintenanceReq, model.Scope, error) {
+	var req setMaintenanceReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return setMaintenanceReq{}, model.Scope{}, websocket.ErrInvalidMessage
+	}
+
+	if err := req.validate(); err != nil {
+		return setMaintenanceReq{}, model.Scope{}, err
+	}
+
+	sc := model.ToScope(auth.GetScopeFromContext(c.Request.Context()))
+	return req, sc, nil
+}
+
+// processBroadcastRequest binds the broadcast and extracts the caller scope
+// set by the auth middleware.
+func (h *handler) processBroadcastRequest(c *gin.Context) (broadcastReq, model.Scope, error) {
+	var req broadcastReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return broadcastReq{}, model.Scope{}, websocket.ErrInvalidMessage
+	}
+
+	if err := req.validate(); err != nil {
+		return broadcastReq{}, model.Scope{}, err
+	}
+
+	sc := model.ToScope(auth.GetScopeFromContext(c.Request.Context()))
+	return req, sc, nil
+}
+
+// processBanRequest binds the ban and extracts the caller scope set by the
+// auth middleware.
+func (h *handler) processBanRequest(c *gin.Context) (banReq, model.Scope, error) {
+	var req banReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return banReq{}, model.Scope{}, websocket.ErrInvalidMessage
+	}
+
+	if err := req.validate(); err != nil {
+		return banReq{}, model.Scope{}, err
+	}
+
+	sc := model.ToScope(auth.GetScopeFromContext(c.Request.Context()))
+	return req, sc, nil
+}
+
+// processTraceUserRequest binds the user to trace and extracts the caller
+// scope set by the auth middleware.
+func (h *handler) processTraceUserRequest(c *gin.Context) (traceUserReq, model.Scope, error) {
+	var req traceUserReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return traceUserReq{}, model.Scope{}, websocket.ErrInvalidMessage
+	}
+
+	if err := req.validate(); err != nil {
+		return traceUserReq{}, model.Scope{}, err
+	}
+
+	sc := model.ToScope(auth.GetScopeFromContext(c.Request.Context()))
+	return req, sc, nil
+}
+
+// processUntraceUserRequest binds the user to stop tracing and extracts the
+// caller scope.
+func (h *handler) processUntraceUserRequest(c *gin.Context) (untraceUserReq, model.Scope, error) {
+	var req untraceUserReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return untraceUserReq{}, model.Scope{}, websocket.ErrInvalidMessage
+	}
+
+	if err := req.validate(); err != nil {
+		return untraceUserReq{}, model.Scope{}, err
+	}
+
+	sc := model.ToScope(auth.GetScopeFromContext(c.Request.Context()))
+	return req, sc, nil
+}
+
+// processUnbanRequest binds the ban to lift and extracts the caller scope.
+func (h *handler) processUnbanRequest(c *gin.Context) (unbanReq, model.Scope, error) {
+	var req unbanReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return unbanReq{}, model.Scope{}, websocket.ErrInvalidMessage
+	}
+
+	if err := req.validate(); err != nil {
+		return unbanReq{}, model.Scope{}, err
+	}
+
+	sc := model.ToScope(auth.GetScopeFromContext(c.Request.Context()))
+	return req, sc, nil
+}
+
+// processGetChannelStatsRequest binds the channel pattern filter.
+func (h *handler) processGetChannelStatsRequest(c *gin.Context) (getChannelStatsReq, error) {
+	var req getChannelStatsReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return getChannelStatsReq{}, websocket.ErrInvalidMessage
+	}
+	return req, nil
 }
@@ -1,10 +1,15 @@
 package http
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/smap-hcmut/shared-libs/go/middleware"
 )
 
+// defaultWSPath is the WebSocket route when WSConfig.Path is empty.
+const defaultWSPath = "/ws"
+
 // RegisterRoutes registers the WebSocket routes.
 func (h *handler) RegisterRoutes(r *gin.RouterGroup, mw *middleware.Middleware) {
 	// WebSocket endpoint
@@ -12,8 +17,61 @@ func (h *handler) RegisterRoutes(r *gin.RouterGroup, mw *middleware.Middleware)
 	// because browser's WebSocket API doesn't allow custom headers for bearer token.
 	// So we might skip standard auth middleware here if it strictly requires Header.
 
-	ws := r.Group("/ws")
+	path := h.wsConfig.Path
+	if path == "" {
+		path = defaultWSPath
+	}
+	ws := r.Group(path)
 	{
 		ws.GET("", h.HandleWebSocket)
+		ws.GET("/public", h.HandlePublicWebSocket)
+	}
+	r.GET("/protocol", h.GetProtocol)
+}
+
+// RegisterWebTransportRoutes registers the WebTransport endpoint on the HTTP/3
+// listener, at the WebSocket path. Sessions authenticate inside the handler,
+// like /ws.
+func (h *handler) RegisterWebTransportRoutes(r *gin.RouterGroup) {
+	path := h.wsConfig.Path
+	if path == "" {
+		path = defaultWSPath
+	}
+	r.Handle(http.MethodConnect, path, h.HandleWebTransport)
+}
+
+// RegisterAPIRoutes registers authenticated REST endpoints that complement the socket.
+func (h *handler) RegisterAPIRoutes(r *gin.RouterGroup, mw *middleware.Middleware) {
+	projects := r.Group("/api/projects", mw.Auth())
+	{
+		projects.GET("/:id/notifications", h.ListProjectNotifications)
+	}
+}
+
+// RegisterAdminRoutes registers operator endpoints behind auth, which requires
+// an ADMIN token unless the listener itself is trusted.
+func (h *handler) RegisterAdminRoutes(r *gin.RouterGroup, auth ...gin.HandlerFunc) {
+	admin := r.Group("/admin", auth...)
+	{
+		admin.GET("/connections", h.ListConnections)
+		admin.GET("/stats/history", h.GetStatsHistory)
+		admin.GET("/maintenance", h.GetMaintenance)
+		admin.POST("/maintenance", h.SetMaintenance)
+		admin.POST("/broadcast", h.Broadcast)
+		admin.POST("/bans", h.Ban)
+		admin.DELETE("/bans", h.Unban)
+		admin.GET("/traces", h.ListUserTraces)
+		admin.POST("/traces", h.TraceUser)
+		admin.DELETE("/traces", h.UntraceUser)
+	}
+}
+
+// RegisterInternalRoutes registers service-to-service endpoints behind auth,
+// which accepts signed requests and, while allowed, X-Internal-Key.
+func (h *handler) RegisterInternalRoutes(r *gin.RouterGroup, auth gin.HandlerFunc) {
+	internal := r.Group("/internal", auth)
+	{
+		internal.POST("/contract-check", h.CheckContract)
+		internal.GET("/stats/channels", h.GetChannelStats)
 	}
 }
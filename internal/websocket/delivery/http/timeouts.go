@@ -0,0 +1,44 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"notification-srv/internal/metrics"
+	"notification-srv/internal/websocket/transport"
+)
+
+// Stages of the /ws upgrade path, each bounded by its own budget.
+const (
+	stageAuth     = "auth"
+	stageUpgrade  = "upgrade"
+	stageRegister = "register"
+)
+
+// stageContext bounds a stage by its budget; 0 leaves it bounded only by the request.
+func stageContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// stageTimedOut reports whether the stage overran its budget, counting it if so.
+func (h *handler) stageTimedOut(ctx context.Context, stage string, err error) bool {
+	var netErr net.Error
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) && !errors.Is(err, context.DeadlineExceeded) &&
+		!(errors.As(err, &netErr) && netErr.Timeout()) {
+		return false
+	}
+	metrics.StageTimeouts.WithLabelValues(stage).Inc()
+	h.logger.Warnf(ctx, "websocket %s stage timed out: %v", stage, err)
+	return true
+}
+
+// closeTimedOut closes an upgraded connection whose registration overran its
+// budget, asking the client to retry later.
+func closeTimedOut(conn transport.Conn) {
+	conn.Close(transport.CloseTryAgainLater, "connection setup timed out")
+}
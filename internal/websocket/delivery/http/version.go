@@ -0,0 +1,128 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"notification-srv/internal/metrics"
+	domain "notification-srv/internal/websocket"
+	"notification-srv/internal/websocket/transport"
+	"notification-srv/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// headerClientVersion carries the client build version. Browsers cannot set
+// headers on a WebSocket handshake, so ?client_version= is accepted as well.
+const headerClientVersion = "X-Client-Version"
+
+// anyClientLabel is the MinClientVersions key applying to labels without their own entry.
+const anyClientLabel = "*"
+
+// outdatedClient returns the label whose minimum version applies to the
+// client, that minimum, and whether version is below it. A missing or
+// unparsable version is outdated when a minimum applies. Labels are matched
+// case-insensitively, as config keys are lower-cased.
+func (h *handler) outdatedClient(label, version string) (policy, minVersion string, outdated bool) {
+	policy = strings.ToLower(label)
+	minVersion, ok := h.wsConfig.MinClientVersions[policy]
+	if !ok {
+		policy = anyClientLabel
+		if minVersion, ok = h.wsConfig.MinClientVersions[anyClientLabel]; !ok {
+			return "", "", false
+		}
+	}
+
+	v, ok := parseVersion(version)
+	if !ok {
+		return policy, minVersion, true
+	}
+	min, _ := parseVersion(minVersion)
+	return policy, minVersion, compareVersions(v, min) < 0
+}
+
+// rejectOutdated answers 426 Upgrade Required with a problem document carrying
+// the minimum version and the upgrade URL. With CloseOutdated, WebSocket handshakes are accepted and closed
+// with CloseCodeUpgradeRequired instead, since browsers don't expose the HTTP
+// status of a failed handshake.
+func (h *handler) rejectOutdated(c *gin.Context, version, minVersion string) {
+	if h.wsConfig.CloseOutdated && transport.IsUpgrade(c.Request) {
+		conn, err := h.wsConfig.Transport.Upgrade(c.Writer, c.Request, transport.Options{})
+		if err != nil {
+			return
+		}
+		reason := "upgrade required: min_version=" + minVersion
+		if h.wsConfig.UpgradeURL != "" {
+			reason += " url=" + h.wsConfig.UpgradeURL
+		}
+		if len(reason) > 123 {
+			reason = reason[:123] // Control frame payload limit, minus the code
+		}
+		conn.Close(domain.CloseCodeUpgradeRequired, reason)
+		return
+	}
+
+	errcode.WriteProblem(c, http.StatusUpgradeRequired, upgradeRequiredResp{
+		Problem:       errcode.NewProblem(c, domain.ErrClientOutdated),
+		ClientVersion: version,
+		MinVersion:    minVersion,
+		UpgradeURL:    h.wsConfig.UpgradeURL,
+	})
+	c.Abort()
+}
+
+// checkClientVersion rejects clients below the minimum version of their label.
+// Returns false if the request was answered.
+func (h *handler) checkClientVersion(c *gin.Context) bool {
+	version := c.GetHeader(headerClientVersion)
+	if version == "" {
+		version = c.Query("client_version")
+	}
+
+	policy, minVersion, outdated := h.outdatedClient(c.Query("client_label"), version)
+	if !outdated {
+		return true
+	}
+	metrics.OutdatedClients.WithLabelValues(policy).Inc()
+	h.rejectOutdated(c, version, minVersion)
+	return false
+}
+
+// parseVersion parses "1.2.3", with an optional "v" prefix and any
+// "-prerelease" or "+build" suffix ignored. Missing parts are 0.
+func parseVersion(s string) ([3]int, bool) {
+	var v [3]int
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	if s == "" {
+		return v, false
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	domain "notification-srv/internal/websocket"
+	"notification-srv/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/quic-go/webtransport-go"
+)
+
+// webTransportWriteWait bounds the write of one frame to the session stream.
+const webTransportWriteWait = 10 * time.Second
+
+// Session close codes, the WebSocket close codes of the same cases.
+const (
+	webTransportGoingAway     webtransport.SessionErrorCode = 1001 // Hub stream ended, e.g. shutdown
+	webTransportTryAgainLater webtransport.SessionErrorCode = 1013 // Hub registration failed
+)
+
+// HandleWebTransport accepts a WebTransport session on the HTTP/3 listener.
+// The CONNECT request is admitted exactly like a /ws upgrade: client version,
+// deny-list, token or subscription token, decoy, delegation and quota.
+func (h *handler) HandleWebTransport(c *gin.Context) {
+	if !h.checkClientVersion(c) {
+		return
+	}
+
+	authCtx, cancel := stageContext(c.Request.Context(), h.wsConfig.AuthTimeout)
+	req, userID, err := h.processUpgradeRequest(authCtx, c)
+	if err == nil && h.stageTimedOut(authCtx, stageAuth, nil) {
+		err = domain.ErrStageTimeout
+	}
+	cancel()
+	if err != nil {
+		errcode.Respond(c, h.mapError(err))
+		return
+	}
+	datagrams, _ := strconv.ParseBool(c.Query("datagrams"))
+
+	// The session needs the HTTP/3 writer, not gin's wrapper of it
+	sess, err := h.wsConfig.WebTransport.Upgrade(unwrapResponseWriter(c.Writer), c.Request)
+	if err != nil {
+		h.logger.Warnf(c.Request.Context(), "webtransport upgrade failed: %v", err)
+		errcode.Respond(c, h.mapError(domain.ErrInvalidMessage))
+		return
+	}
+
+	// The session outlives the CONNECT handler; its stream ends with it
+	frames, err := h.uc.Subscribe(sess.Context(), domain.SubscribeInput{
+		UserID:        userID,
+		ProjectID:     req.ProjectID,
+		UserAgent:     c.Request.UserAgent(),
+		Types:         req.messageTypes(),
+		MinImportance: domain.Importance(req.MinImportance),
+		Fields:        req.payloadFields(),
+		ClientLabel:   req.ClientLabel,
+		RemoteIP:      c.ClientIP(),
+		ProjectOnly:   req.SubToken != "",
+		DelegatedBy:   req.delegatedBy,
+		Quota:         req.quota,
+	})
+	if err != nil {
+		h.logger.Errorf(c.Request.Context(), "webtransport register failed: %v", err)
+		sess.CloseWithError(webTransportTryAgainLater, "register failed")
+		return
+	}
+	go h.serveWebTransport(sess, frames, datagrams)
+}
+
+// serveWebTransport writes the Hub frames of a session, one JSON frame per
+// line on a unidirectional stream. With datagrams, frames carrying seq go out
+// as datagrams when they fit: a lost one shows as a seq gap the client
+// recovers with ?after_seq=, instead of stalling the frames behind it.
+func (h *handler) serveWebTransport(sess *webtransport.Session, frames <-chan []byte, datagrams bool) {
+	ctx := sess.Context()
+	str, err := sess.OpenUniStreamSync(ctx)
+	if err != nil {
+		sess.CloseWithError(webTransportTryAgainLater, "open stream failed")
+		return
+	}
+
+	for frame := range frames {
+		if datagrams && sequencedFrame(frame) && sess.SendDatagram(frame) == nil {
+			continue
+		}
+		str.SetWriteDeadline(time.Now().Add(webTransportWriteWait))
+		if _, err := str.Write(append(frame[:len(frame):len(frame)], '\n')); err != nil {
+			h.logger.Warnf(context.Background(), "webtransport write failed: %v", err)
+			sess.CloseWithError(webTransportGoingAway, "write failed")
+			return
+		}
+	}
+
+	// The Hub ended the stream while the client is still there
+	if ctx.Err() == nil {
+		str.Close()
+		sess.CloseWithError(webTransportGoingAway, "stream closed by server")
+	}
+}
+
+// sequencedFrame reports whether a frame carries seq, so its loss is visible
+// to the client and recoverable.
+func sequencedFrame(frame []byte) bool {
+	var head struct {
+		Seq uint64 `json:"seq"`
+	}
+	return json.Unmarshal(frame, &head) == nil && head.Seq > 0
+}
+
+// unwrapResponseWriter returns the HTTP/3 response writer under middleware
+// wrappers such as gin's.
+func unwrapResponseWriter(w http.ResponseWriter) http.ResponseWriter {
+	for {
+		if _, ok := w.(http3.Hijacker); ok {
+			return w
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return w
+		}
+		w = u.Unwrap()
+	}
+}
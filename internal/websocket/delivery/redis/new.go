@@ -3,7 +3,9 @@ package redis
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 
+	"notification-srv/internal/lifecycle"
 	"notification-srv/internal/websocket"
 
 	"github.com/smap-hcmut/shared-libs/go/log"
@@ -15,24 +17,79 @@ import (
 type Subscriber interface {
 	Start() error
 	Shutdown(ctx context.Context) error
+
+	// HealthInfo reports when the subscriber last received traffic. For a
+	// multi-region subscriber it describes the primary (local) region.
+	HealthInfo() HealthInfo
+
+	// Upstreams reports the health of every Redis upstream, primary first.
+	Upstreams() []HealthInfo
 }
 
 type subscriber struct {
 	redis  pkgRedis.IRedis
 	uc     websocket.UseCase
 	logger log.Logger
+	sup    *lifecycle.Supervisor
+	cfg    Config
+
+	dispatchMu *sync.Mutex // Shared by all regions of a multi-region subscriber; nil otherwise
+	limiter    *rateLimiter
 
 	// Lifecycle fields
+	mu     sync.Mutex // Guards pubsub, which is replaced on reconnect
 	pubsub *redis.PubSub
 	wg     sync.WaitGroup
 	quit   chan struct{}
+	ctx    context.Context // Cancelled on Shutdown, for work outliving a message
+	cancel context.CancelFunc
+
+	// Activity (unix nanos; 0 = never)
+	startedAt       atomic.Int64
+	lastMessageAt   atomic.Int64
+	lastHeartbeatAt atomic.Int64
+	activeJobs      atomic.Int64
+	lastProbeAt     atomic.Int64
+	probeLatency    atomic.Int64 // time.Duration
 }
 
-func New(redis pkgRedis.IRedis, uc websocket.UseCase, logger log.Logger) Subscriber {
+func New(redis pkgRedis.IRedis, uc websocket.UseCase, logger log.Logger, sup *lifecycle.Supervisor, cfg Config) Subscriber {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &subscriber{
 		redis:  redis,
 		uc:     uc,
 		logger: logger,
+		sup:    sup,
+		cfg:    cfg,
 		quit:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+
+		limiter: newRateLimiter(cfg.RateLimit),
+	}
+}
+
+// multiRegion merges the streams of several regional subscribers.
+type multiRegion struct {
+	subs   []*subscriber // Primary (local) region first
+	logger log.Logger
+}
+
+// NewMultiRegion subscribes to every upstream and merges their streams into uc.
+// The first upstream is the primary (local) region. cfg applies to every
+// region, except Region and ChannelPrefix which come from the upstream.
+func NewMultiRegion(upstreams []Upstream, uc websocket.UseCase, logger log.Logger, sup *lifecycle.Supervisor, cfg Config) Subscriber {
+	dispatchMu := &sync.Mutex{}
+
+	m := &multiRegion{logger: logger}
+	for _, up := range upstreams {
+		regionCfg := cfg
+		regionCfg.Region = up.Region
+		regionCfg.ChannelPrefix = up.ChannelPrefix
+
+		s := New(up.Redis, uc, logger, sup, regionCfg).(*subscriber)
+		s.dispatchMu = dispatchMu
+		m.subs = append(m.subs, s)
 	}
+	return m
 }
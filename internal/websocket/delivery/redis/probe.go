@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"notification-srv/internal/metrics"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// probeMissTolerance is how many probe intervals may pass without a loopback
+// before the subscription is reported as broken.
+const probeMissTolerance = 3
+
+// probeChannel is the loopback channel of this instance on the upstream.
+func (s *subscriber) probeChannel() string {
+	return s.cfg.ChannelPrefix + "healthcheck:" + s.cfg.Probe.InstanceID
+}
+
+// probe publishes a timestamp to the loopback channel every interval until
+// the subscriber shuts down. Replies are handled in handleProbe.
+func (s *subscriber) probe(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Probe.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+			payload := strconv.FormatInt(time.Now().UnixNano(), 10)
+			if err := s.redis.GetClient().Publish(ctx, s.probeChannel(), payload).Err(); err != nil {
+				metrics.ProbeFailures.WithLabelValues(s.cfg.Region).Inc()
+				s.logger.Warnf(ctx, "redis probe publish failed: %v", err)
+			}
+			metrics.ProbeHealthy.WithLabelValues(s.cfg.Region).Set(boolToFloat(s.probeHealthy(time.Now())))
+		}
+	}
+}
+
+// handleProbe records the loopback latency of a probe published by this instance.
+func (s *subscriber) handleProbe(ctx context.Context, msg *redis.Message) {
+	sentAt, err := strconv.ParseInt(msg.Payload, 10, 64)
+	if err != nil {
+		s.logger.Warnf(ctx, "invalid probe payload on %s: %q", msg.Channel, msg.Payload)
+		return
+	}
+
+	now := time.Now()
+	latency := now.Sub(time.Unix(0, sentAt))
+	s.lastProbeAt.Store(now.UnixNano())
+	s.probeLatency.Store(int64(latency))
+	metrics.ProbeLatency.WithLabelValues(s.cfg.Region).Observe(latency.Seconds())
+}
+
+// probeHealthy reports whether a loopback arrived recently. It is always true
+// when probing is disabled, and during the first intervals after start.
+func (s *subscriber) probeHealthy(now time.Time) bool {
+	if !s.cfg.Probe.Enabled {
+		return true
+	}
+
+	since := s.lastProbeAt.Load()
+	if since == 0 {
+		since = s.startedAt.Load()
+	}
+	return now.Sub(time.Unix(0, since)) < probeMissTolerance*s.cfg.Probe.Interval
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
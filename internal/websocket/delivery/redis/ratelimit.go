@@ -0,0 +1,235 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"notification-srv/internal/metrics"
+	"notification-srv/internal/websocket"
+)
+
+// newRateLimiter returns nil when no channel is limited. A nil *rateLimiter
+// admits every message.
+func newRateLimiter(cfg RateLimitConfig) *rateLimiter {
+	limited := cfg.Rate > 0
+	for _, r := range cfg.Prefixes {
+		limited = limited || r.Rate > 0
+	}
+	if !limited {
+		return nil
+	}
+	return &rateLimiter{
+		cfg:     cfg,
+		buckets: make(map[string]*channelBucket),
+	}
+}
+
+// limitOf returns the rate of channel: that of the longest matching prefix,
+// else the default. Prefixes match case-insensitively, as config keys are
+// lowercased. Control channels are never limited.
+func (l *rateLimiter) limitOf(channel string) ChannelRate {
+	if strings.HasPrefix(channel, "control:") {
+		return ChannelRate{}
+	}
+	channel = strings.ToLower(channel)
+	limit, matched := l.cfg.ChannelRate, -1
+	for prefix, r := range l.cfg.Prefixes {
+		if len(prefix) > matched && strings.HasPrefix(channel, strings.ToLower(prefix)) {
+			limit, matched = r, len(prefix)
+		}
+	}
+	return limit
+}
+
+// admit reports whether input may be dispatched now. Otherwise, in coalesce
+// mode, input becomes the channel's pending message; replaced reports whether
+// it took the place of an earlier one, and flushIn is the delay after which
+// the caller must flush the channel (0 if a flush is already scheduled). A
+// pending update that ends a job is only replaced by another one: input is
+// dropped instead, as if not coalescing.
+func (l *rateLimiter) admit(input websocket.ProcessMessageInput, now time.Time) (ok, replaced bool, flushIn time.Duration) {
+	if l == nil {
+		return true, false, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.bucket(input.Channel, now)
+	if b == nil {
+		return true, false, 0
+	}
+	b.refill(now)
+	// A pending message goes first, so later ones wait behind it
+	if b.pending == nil && b.tokens >= 1 {
+		b.tokens--
+		return true, false, 0
+	}
+	if l.cfg.Mode != RateLimitCoalesce {
+		return false, false, 0
+	}
+
+	terminal := terminalUpdate(input.Payload)
+	if b.pendingTerminal && !terminal {
+		return false, false, 0
+	}
+	replaced = b.pending != nil
+	b.pending, b.pendingTerminal = &input, terminal
+	if replaced {
+		return false, true, 0
+	}
+	return false, false, b.wait()
+}
+
+// take returns the pending message of channel and consumes a token for it,
+// or the delay until a token is available.
+func (l *rateLimiter) take(channel string, now time.Time) (*websocket.ProcessMessageInput, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.buckets[channel]
+	if b == nil || b.pending == nil {
+		return nil, 0
+	}
+	b.refill(now)
+	if b.tokens < 1 {
+		return nil, b.wait()
+	}
+	b.tokens--
+	input := b.pending
+	b.pending, b.pendingTerminal = nil, false
+	return input, 0
+}
+
+// terminalUpdate reports whether payload ends a job: an onboarding that
+// completed or failed, a finished campaign or a pipeline at 100%.
+func terminalUpdate(payload []byte) bool {
+	var p jobProbe
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return false
+	}
+	switch {
+	case strings.EqualFold(p.Status, "completed"), strings.EqualFold(p.Status, "failed"):
+		return true
+	case strings.EqualFold(p.EventType, "finished"):
+		return true
+	}
+	return p.TotalRecords != nil && p.Progress >= 100
+}
+
+// bucket returns the bucket of channel, creating a full one, or nil if the
+// channel is unlimited. Must be called with mu held.
+func (l *rateLimiter) bucket(channel string, now time.Time) *channelBucket {
+	if b, ok := l.buckets[channel]; ok {
+		return b
+	}
+	limit := l.limitOf(channel)
+	if limit.Rate <= 0 {
+		return nil
+	}
+
+	if l.cfg.MaxChannels > 0 && len(l.buckets) >= l.cfg.MaxChannels {
+		l.prune(now)
+	}
+	burst := float64(max(limit.Burst, 1))
+	b := &channelBucket{rate: limit.Rate, burst: burst, tokens: burst, updated: now}
+	l.buckets[channel] = b
+	return b
+}
+
+// prune forgets the buckets that have refilled and hold no pending message;
+// they would be recreated as they are. Must be called with mu held.
+func (l *rateLimiter) prune(now time.Time) {
+	for channel, b := range l.buckets {
+		b.refill(now)
+		if b.pending == nil && b.tokens >= b.burst {
+			delete(l.buckets, channel)
+		}
+	}
+}
+
+// refill adds the tokens earned since the last update.
+func (b *channelBucket) refill(now time.Time) {
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.updated).Seconds()*b.rate)
+	b.updated = now
+}
+
+// wait is how long until the bucket holds a token.
+func (b *channelBucket) wait() time.Duration {
+	return max(time.Duration((1-b.tokens)/b.rate*float64(time.Second)), time.Millisecond)
+}
+
+// limit applies the channel's rate limit to input and reports whether it may
+// be dispatched now. Excess messages are dropped, or held and flushed later
+// in coalesce mode.
+func (s *subscriber) limit(ctx context.Context, input websocket.ProcessMessageInput) bool {
+	ok, replaced, flushIn := s.limiter.admit(input, time.Now())
+	if ok {
+		return true
+	}
+
+	kind := channelType(input.Channel)
+	switch {
+	case flushIn > 0:
+		metrics.InboundRateLimited.WithLabelValues(kind, "delayed").Inc()
+		s.flushLater(input.Channel, flushIn)
+	case replaced:
+		metrics.InboundRateLimited.WithLabelValues(kind, "coalesced").Inc()
+	default:
+		metrics.InboundRateLimited.WithLabelValues(kind, "dropped").Inc()
+		s.deadLetter(ctx, input)
+	}
+	return false
+}
+
+// flushLater dispatches the pending message of channel after the delay, or
+// later if the channel has no token by then. It runs under the subscriber's
+// context, as the message that scheduled it is long handled.
+func (s *subscriber) flushLater(channel string, after time.Duration) {
+	time.AfterFunc(after, func() {
+		if s.ctx.Err() != nil {
+			return
+		}
+		s.sup.Run(s.ctx, "redis-rate-flush", func() {
+			input, wait := s.limiter.take(channel, time.Now())
+			if input == nil {
+				if wait > 0 {
+					s.flushLater(channel, wait)
+				}
+				return
+			}
+			s.dispatch(tracer.WithTraceID(context.Background(), tracer.GenerateTraceID()), *input)
+		})
+	})
+}
+
+// deadLetter pushes a sample of the dropped messages to the DLQ list, newest
+// first, so publishers can see what was cut.
+func (s *subscriber) deadLetter(ctx context.Context, input websocket.ProcessMessageInput) {
+	cfg := s.cfg.RateLimit
+	if cfg.DLQKey == "" || rand.Float64() >= cfg.DLQSampleRate {
+		return
+	}
+
+	entry, err := json.Marshal(deadLetter{
+		Channel:   input.Channel,
+		Region:    input.Region,
+		Payload:   string(input.Payload),
+		DroppedAt: time.Now(),
+	})
+	if err != nil {
+		s.logger.Warnf(ctx, "rate limit DLQ: marshal: %v", err)
+		return
+	}
+
+	key := s.cfg.ChannelPrefix + cfg.DLQKey
+	pipe := s.redis.GetClient().Pipeline()
+	pipe.LPush(ctx, key, entry)
+	pipe.LTrim(ctx, key, 0, cfg.DLQMaxLen-1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Warnf(ctx, "rate limit DLQ push failed: key=%s: %v", key, err)
+	}
+}
@@ -0,0 +1,160 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"notification-srv/internal/websocket"
+)
+
+const testChannel = "project:p1:user:u1"
+
+func message(payload string) websocket.ProcessMessageInput {
+	return websocket.ProcessMessageInput{Channel: testChannel, Payload: []byte(payload)}
+}
+
+func TestRateLimiterDrop(t *testing.T) {
+	l := newRateLimiter(RateLimitConfig{ChannelRate: ChannelRate{Rate: 1, Burst: 2}, Mode: RateLimitDrop})
+	now := time.Unix(1700000000, 0)
+
+	for i := 0; i < 2; i++ {
+		if ok, _, _ := l.admit(message(`{}`), now); !ok {
+			t.Fatalf("message %d refused within the burst", i)
+		}
+	}
+	ok, replaced, flushIn := l.admit(message(`{}`), now)
+	if ok || replaced || flushIn != 0 {
+		t.Fatalf("admit() over the burst = %v, %v, %v; want a drop", ok, replaced, flushIn)
+	}
+	if input, wait := l.take(testChannel, now); input != nil || wait != 0 {
+		t.Errorf("take() = %v, %v; drop mode holds nothing", input, wait)
+	}
+
+	// One token a second
+	if ok, _, _ := l.admit(message(`{}`), now.Add(time.Second)); !ok {
+		t.Error("refused after a token was earned")
+	}
+}
+
+func TestRateLimiterCoalesce(t *testing.T) {
+	l := newRateLimiter(RateLimitConfig{ChannelRate: ChannelRate{Rate: 2, Burst: 1}, Mode: RateLimitCoalesce})
+	now := time.Unix(1700000000, 0)
+
+	if ok, _, _ := l.admit(message(`{"progress": 10}`), now); !ok {
+		t.Fatal("first message refused")
+	}
+
+	// The first held message schedules a flush once a token is earned
+	ok, replaced, flushIn := l.admit(message(`{"progress": 20}`), now)
+	if ok || replaced || flushIn != 500*time.Millisecond {
+		t.Fatalf("admit() = %v, %v, %v; want held with a flush in 500ms", ok, replaced, flushIn)
+	}
+	// Later ones replace it without another flush
+	ok, replaced, flushIn = l.admit(message(`{"progress": 30}`), now.Add(100*time.Millisecond))
+	if ok || !replaced || flushIn != 0 {
+		t.Fatalf("admit() = %v, %v, %v; want coalesced", ok, replaced, flushIn)
+	}
+
+	// Too early: the flush is rescheduled
+	if input, wait := l.take(testChannel, now.Add(200*time.Millisecond)); input != nil || wait != 300*time.Millisecond {
+		t.Fatalf("take() = %v, %v; want a wait of 300ms", input, wait)
+	}
+	input, _ := l.take(testChannel, now.Add(500*time.Millisecond))
+	if input == nil || string(input.Payload) != `{"progress": 30}` {
+		t.Fatalf("take() = %v, want the latest message", input)
+	}
+	if input, wait := l.take(testChannel, now.Add(time.Second)); input != nil || wait != 0 {
+		t.Errorf("take() = %v, %v after flushing; want nothing", input, wait)
+	}
+
+	// A new message waits behind a pending one even with a token
+	l.admit(message(`{"progress": 40}`), now.Add(time.Second))
+	l.admit(message(`{"progress": 45}`), now.Add(time.Second))
+	if ok, replaced, _ := l.admit(message(`{"progress": 50}`), now.Add(2*time.Second)); ok || !replaced {
+		t.Errorf("admit() = %v, %v; want it to replace the pending message", ok, replaced)
+	}
+}
+
+func TestRateLimiterCoalesceKeepsTerminalUpdates(t *testing.T) {
+	tests := []struct {
+		name         string
+		held, next   string
+		wantReplaced bool
+	}{
+		{"onboarding completed", `{"status": "completed"}`, `{"status": "processing"}`, false},
+		{"onboarding failed", `{"status": "FAILED"}`, `{"status": "pending"}`, false},
+		{"campaign finished", `{"event_type": "finished"}`, `{"event_type": "paused"}`, false},
+		{"pipeline done", `{"total_records": 10, "progress": 100}`, `{"total_records": 10, "progress": 50}`, false},
+		{"terminal by terminal", `{"status": "failed"}`, `{"status": "completed"}`, true},
+		{"running by terminal", `{"status": "processing"}`, `{"status": "completed"}`, true},
+		{"onboarding progress is not terminal", `{"status": "processing", "progress": 100}`, `{"status": "processing"}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newRateLimiter(RateLimitConfig{ChannelRate: ChannelRate{Rate: 1, Burst: 1}, Mode: RateLimitCoalesce})
+			now := time.Unix(1700000000, 0)
+			l.admit(message(`{}`), now)
+			l.admit(message(tt.held), now)
+
+			ok, replaced, _ := l.admit(message(tt.next), now)
+			if ok || replaced != tt.wantReplaced {
+				t.Fatalf("admit() = %v, %v; want replaced %v", ok, replaced, tt.wantReplaced)
+			}
+			want := tt.held
+			if tt.wantReplaced {
+				want = tt.next
+			}
+			if input, _ := l.take(testChannel, now.Add(time.Second)); input == nil || string(input.Payload) != want {
+				t.Errorf("take() = %v, want %s", input, want)
+			}
+		})
+	}
+}
+
+func TestRateLimiterChannels(t *testing.T) {
+	l := newRateLimiter(RateLimitConfig{
+		Prefixes: map[string]ChannelRate{
+			"project:":    {Rate: 1, Burst: 1},
+			"Project:p1:": {Rate: 1, Burst: 3},
+			"alert:":      {},
+			"control:":    {Rate: 1, Burst: 1},
+		},
+		Mode: RateLimitDrop,
+	})
+	now := time.Unix(1700000000, 0)
+
+	admitted := func(channel string, n int) int {
+		count := 0
+		for i := 0; i < n; i++ {
+			if ok, _, _ := l.admit(websocket.ProcessMessageInput{Channel: channel}, now); ok {
+				count++
+			}
+		}
+		return count
+	}
+	tests := []struct {
+		channel string
+		want    int
+	}{
+		{"project:p1:user:u1", 3}, // Longest prefix, in any case
+		{"project:p2:user:u1", 1},
+		{"alert:p1:user:u1", 10}, // Rate 0 is unlimited
+		{"campaign:c1:user:u1", 10},
+		{"control:purge:p1", 10}, // Never limited
+	}
+	for _, tt := range tests {
+		if got := admitted(tt.channel, 10); got != tt.want {
+			t.Errorf("%s: %d of 10 admitted, want %d", tt.channel, got, tt.want)
+		}
+	}
+}
+
+func TestNilRateLimiter(t *testing.T) {
+	if l := newRateLimiter(RateLimitConfig{Mode: RateLimitCoalesce}); l != nil {
+		t.Fatal("newRateLimiter() without a rate is not nil")
+	}
+	var l *rateLimiter
+	if ok, _, _ := l.admit(message(`{}`), time.Now()); !ok {
+		t.Error("nil limiter refused a message")
+	}
+}
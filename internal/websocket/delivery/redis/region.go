@@ -0,0 +1,50 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Start starts every region. If one fails, the regions already started are
+// stopped again so startup fails cleanly.
+func (m *multiRegion) Start() error {
+	for i, s := range m.subs {
+		if err := s.Start(); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				if stopErr := m.subs[j].Shutdown(context.Background()); stopErr != nil {
+					m.logger.Warnf(context.Background(), "rollback of region %s failed: %v", m.subs[j].cfg.Region, stopErr)
+				}
+			}
+			return fmt.Errorf("region %s: %w", s.cfg.Region, err)
+		}
+	}
+	return nil
+}
+
+func (m *multiRegion) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, s := range m.subs {
+		if err := s.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("region %s: %w", s.cfg.Region, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HealthInfo describes the primary region: a remote region going quiet
+// degrades the merged stream but must not take this instance out of rotation.
+func (m *multiRegion) HealthInfo() HealthInfo {
+	if len(m.subs) == 0 {
+		return HealthInfo{ProbeHealthy: true}
+	}
+	return m.subs[0].HealthInfo()
+}
+
+func (m *multiRegion) Upstreams() []HealthInfo {
+	infos := make([]HealthInfo, len(m.subs))
+	for i, s := range m.subs {
+		infos[i] = s.HealthInfo()
+	}
+	return infos
+}
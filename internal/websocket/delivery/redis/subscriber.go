@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -44,37 +45,95 @@ type subscriberImpl struct {
 // `new.go` will have the full struct definition.
 // `subscriber.go` will have the methods.
 
+// channels returns the subscribed patterns, including the optional heartbeat channel.
+func (s *subscriber) channels() []string {
+	channels := []string{
+		s.cfg.ChannelPrefix + "project:*:user:*",
+		s.cfg.ChannelPrefix + "campaign:*:user:*",
+		s.cfg.ChannelPrefix + "alert:*:user:*",
+		s.cfg.ChannelPrefix + "system:*",
+		s.cfg.ChannelPrefix + "control:*",
+	}
+	if hb := s.heartbeatChannel(); hb != "" {
+		channels = append(channels, hb)
+	}
+	if s.cfg.Probe.Enabled {
+		channels = append(channels, s.probeChannel())
+	}
+	return channels
+}
+
 func (s *subscriber) Start() error {
 	ctx := context.Background()
+	s.startedAt.Store(time.Now().UnixNano())
 
-	channels := []string{
-		"project:*:user:*",
-		"campaign:*:user:*",
-		"alert:*:user:*",
-		"system:*",
+	channels := s.channels()
+	if err := s.subscribe(ctx); err != nil {
+		return err
 	}
 
+	if s.cfg.Watchdog.Enabled {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.sup.Run(ctx, "redis-watchdog", func() { s.watch(ctx) })
+		}()
+	}
+
+	if s.cfg.Probe.Enabled {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.sup.Run(ctx, "redis-probe", func() { s.probe(ctx) })
+		}()
+	}
+
+	s.logger.Infof(ctx, "Redis subscriber started: region=%s channels=%v", s.cfg.Region, channels)
+	return nil
+}
+
+// subscribe opens a new pubsub, waits for confirmation and starts a supervised
+// listen loop on it. An existing pubsub is closed after the new one is live.
+func (s *subscriber) subscribe(ctx context.Context) error {
 	// Get underlying client
 	client := s.redis.GetClient()
-	s.pubsub = client.PSubscribe(ctx, channels...)
+	ps := client.PSubscribe(ctx, s.channels()...)
 
 	// Wait for confirmation that subscription is created
-	_, err := s.pubsub.Receive(ctx)
-	if err != nil {
+	if _, err := ps.Receive(ctx); err != nil {
+		ps.Close()
 		return fmt.Errorf("failed to subscribe: %w", err)
 	}
 
-	s.wg.Add(1)
-	go s.listen(ctx)
+	s.mu.Lock()
+	select {
+	case <-s.quit:
+		// Shutdown raced with a reconnect
+		s.mu.Unlock()
+		return ps.Close()
+	default:
+	}
+	old := s.pubsub
+	s.pubsub = ps
+	s.mu.Unlock()
 
-	s.logger.Infof(ctx, "Redis subscriber started on channels: %v", channels)
+	// A panic while handling a message restarts the loop instead of halting delivery
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.sup.Run(ctx, "redis-subscriber", func() { s.listen(ctx, ps) })
+	}()
+
+	if old != nil {
+		if err := old.Close(); err != nil {
+			s.logger.Warnf(ctx, "failed to close replaced pubsub: %v", err)
+		}
+	}
 	return nil
 }
 
-func (s *subscriber) listen(ctx context.Context) {
-	defer s.wg.Done()
-
-	ch := s.pubsub.Channel()
+func (s *subscriber) listen(ctx context.Context, ps *redis.PubSub) {
+	ch := ps.Channel()
 
 	for {
 		select {
@@ -84,6 +143,10 @@ func (s *subscriber) listen(ctx context.Context) {
 				case <-s.quit:
 					// Normal shutdown — pubsub closed as part of Shutdown()
 				default:
+					if s.current() != ps {
+						// Replaced by a reconnect
+						return
+					}
 					s.logger.Errorf(ctx, "notification-srv: redis pubsub channel closed unexpectedly — notifications halted")
 				}
 				return
@@ -95,14 +158,46 @@ func (s *subscriber) listen(ctx context.Context) {
 	}
 }
 
+func (s *subscriber) current() *redis.PubSub {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pubsub
+}
+
+// heartbeatChannel is the collector heartbeat channel on the upstream, or "" if unset.
+func (s *subscriber) heartbeatChannel() string {
+	if s.cfg.Watchdog.HeartbeatChannel == "" {
+		return ""
+	}
+	return s.cfg.ChannelPrefix + s.cfg.Watchdog.HeartbeatChannel
+}
+
+func (s *subscriber) Upstreams() []HealthInfo {
+	return []HealthInfo{s.HealthInfo()}
+}
+
+func (s *subscriber) HealthInfo() HealthInfo {
+	return HealthInfo{
+		Region:          s.cfg.Region,
+		StartedAt:       unixNanoTime(s.startedAt.Load()),
+		LastMessageAt:   unixNanoTime(s.lastMessageAt.Load()),
+		LastHeartbeatAt: unixNanoTime(s.lastHeartbeatAt.Load()),
+		ActiveJobs:      s.activeJobs.Load(),
+		LastProbeAt:     unixNanoTime(s.lastProbeAt.Load()),
+		ProbeLatency:    time.Duration(s.probeLatency.Load()),
+		ProbeHealthy:    s.probeHealthy(time.Now()),
+	}
+}
+
 func (s *subscriber) Shutdown(ctx context.Context) error {
 	close(s.quit)
-	if s.pubsub != nil {
-		if err := s.pubsub.Close(); err != nil {
+	s.cancel()
+	if ps := s.current(); ps != nil {
+		if err := ps.Close(); err != nil {
 			s.logger.Errorf(ctx, "failed to close pubsub: %v", err)
 		}
 	}
 	s.wg.Wait()
-	s.logger.Infof(ctx, "Redis subscriber stopped")
+	s.logger.Infof(ctx, "Redis subscriber stopped: region=%s", s.cfg.Region)
 	return nil
 }
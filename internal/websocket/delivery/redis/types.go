@@ -0,0 +1,153 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"notification-srv/internal/websocket"
+
+	pkgRedis "github.com/smap-hcmut/shared-libs/go/redis"
+)
+
+// Config holds the subscriber tunables.
+type Config struct {
+	// Upstream identity. Region tags every message read from this Redis;
+	// ChannelPrefix is prepended to every subscribed pattern and stripped from
+	// incoming channels (e.g. "eu:" for eu:project:{id}:user:{id}).
+	Region        string
+	ChannelPrefix string
+
+	Watchdog  WatchdogConfig
+	Probe     ProbeConfig
+	RateLimit RateLimitConfig
+}
+
+// Upstream is one regional Redis whose stream is merged by NewMultiRegion.
+type Upstream struct {
+	Region        string
+	Redis         pkgRedis.IRedis
+	ChannelPrefix string
+}
+
+// ProbeConfig controls the synthetic loopback heartbeat. The subscriber
+// publishes to healthcheck:{InstanceID}, which it also subscribes to, to detect
+// broken subscriptions without waiting for real traffic.
+type ProbeConfig struct {
+	Enabled    bool
+	Interval   time.Duration
+	InstanceID string // Unique per replica, e.g. the pod hostname
+}
+
+// RateLimitMode is what happens to a message over its channel's rate.
+type RateLimitMode string
+
+const (
+	// RateLimitDrop drops the message.
+	RateLimitDrop RateLimitMode = "drop"
+	// RateLimitCoalesce holds the channel's latest message and delivers it
+	// when the channel may publish again; the messages it replaces are dropped.
+	RateLimitCoalesce RateLimitMode = "coalesce"
+)
+
+// RateLimitConfig caps the inbound message rate of each channel with a token
+// bucket per channel (e.g. project:{project_id}:user:{user_id}).
+type RateLimitConfig struct {
+	ChannelRate                        // Default of every channel (Rate 0 = unlimited)
+	Prefixes    map[string]ChannelRate // By channel prefix, e.g. "alert:", in any case; the longest matching prefix wins
+	Mode        RateLimitMode
+	MaxChannels int // Buckets kept; idle full buckets are forgotten beyond this
+
+	// Optional Redis list receiving a sample of the dropped messages
+	DLQKey        string
+	DLQSampleRate float64 // Share of dropped messages pushed (0-1)
+	DLQMaxLen     int64   // The list is trimmed to this many entries
+}
+
+// ChannelRate is the rate limit of one channel.
+type ChannelRate struct {
+	Rate  float64 // Messages per second (0 = unlimited)
+	Burst int     // Messages allowed at once after a quiet period
+}
+
+// WatchdogConfig controls detection of silent subscriber stalls.
+type WatchdogConfig struct {
+	Enabled       bool
+	StallWindow   time.Duration // Silence tolerated while traffic is expected
+	CheckInterval time.Duration
+
+	// Optional channel on which the collector publishes {"active_jobs": N}.
+	// When set, silence only counts as a stall while jobs are active (or the
+	// heartbeat itself has gone quiet). When empty, any silence counts.
+	HeartbeatChannel string
+
+	OnStall StallFunc // Optional alert hook
+}
+
+// StallFunc is called once per stall episode.
+type StallFunc func(ctx context.Context, info StallInfo)
+
+// StallInfo describes a detected stall.
+type StallInfo struct {
+	Region        string
+	SilentFor     time.Duration
+	LastMessageAt time.Time // Zero if nothing was received since start
+	ActiveJobs    int64
+	Reconnected   bool // Whether the proactive pubsub reconnect succeeded
+}
+
+// HealthInfo is a snapshot of subscriber activity.
+type HealthInfo struct {
+	Region          string
+	StartedAt       time.Time
+	LastMessageAt   time.Time // Zero if nothing was received since start
+	LastHeartbeatAt time.Time // Zero if no heartbeat channel or none received
+	ActiveJobs      int64
+
+	// Synthetic loopback probe (zero values when probing is disabled)
+	LastProbeAt  time.Time
+	ProbeLatency time.Duration
+	ProbeHealthy bool // False when no loopback arrived within 3 probe intervals
+}
+
+// rateLimiter holds a token bucket per inbound channel.
+type rateLimiter struct {
+	cfg RateLimitConfig
+
+	mu      sync.Mutex
+	buckets map[string]*channelBucket
+}
+
+// channelBucket is the token bucket of one channel.
+type channelBucket struct {
+	rate    float64
+	burst   float64
+	tokens  float64
+	updated time.Time
+	pending *websocket.ProcessMessageInput // Coalesce mode: latest message waiting for a token
+
+	pendingTerminal bool // pending ends a job; only another terminal update replaces it
+}
+
+// jobProbe holds the fields of a publisher message that tell whether it ends
+// a job.
+type jobProbe struct {
+	Status       string          `json:"status"`        // Onboarding
+	EventType    string          `json:"event_type"`    // Campaign
+	Progress     float64         `json:"progress"`      // Analytics pipeline
+	TotalRecords json.RawMessage `json:"total_records"` // Only analytics pipelines have it
+}
+
+// deadLetter is a dropped message pushed to the DLQ list.
+type deadLetter struct {
+	Channel   string    `json:"channel"`
+	Region    string    `json:"region,omitempty"`
+	Payload   string    `json:"payload"`
+	DroppedAt time.Time `json:"dropped_at"`
+}
+
+// heartbeatMessage is published by the collector on the watchdog heartbeat channel.
+type heartbeatMessage struct {
+	ActiveJobs int64 `json:"active_jobs"`
+}
@@ -0,0 +1,87 @@
+package redis
+
+import (
+	"context"
+	"time"
+)
+
+// watch periodically checks for silent stalls until the subscriber shuts down.
+func (s *subscriber) watch(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Watchdog.CheckInterval)
+	defer ticker.Stop()
+
+	// Last-message time of the stall already reported, so each episode alerts once
+	var reported int64 = -1
+
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+			last := s.lastMessageAt.Load()
+			if last == reported || !s.stalled(time.Now()) {
+				continue
+			}
+			reported = last
+			s.onStall(ctx)
+		}
+	}
+}
+
+// stalled reports whether traffic is expected but none has arrived within the stall window.
+func (s *subscriber) stalled(now time.Time) bool {
+	window := s.cfg.Watchdog.StallWindow
+
+	// Measure silence from start-up until the first message arrives
+	since := s.lastMessageAt.Load()
+	if since == 0 {
+		since = s.startedAt.Load()
+	}
+	if now.Sub(time.Unix(0, since)) < window {
+		return false
+	}
+
+	if s.cfg.Watchdog.HeartbeatChannel == "" {
+		return true
+	}
+	// A quiet heartbeat means the pubsub itself may be dead
+	lastHB := s.lastHeartbeatAt.Load()
+	if lastHB == 0 || now.Sub(time.Unix(0, lastHB)) >= window {
+		return true
+	}
+	return s.activeJobs.Load() > 0
+}
+
+// onStall attempts a proactive reconnect and reports the stall.
+func (s *subscriber) onStall(ctx context.Context) {
+	info := s.HealthInfo()
+	silentFor := time.Since(info.LastMessageAt)
+	if info.LastMessageAt.IsZero() {
+		silentFor = time.Since(info.StartedAt)
+	}
+
+	s.logger.Warnf(ctx, "redis subscriber stalled: region=%s silent_for=%s active_jobs=%d, reconnecting", s.cfg.Region, silentFor, info.ActiveJobs)
+
+	reconnected := true
+	if err := s.subscribe(ctx); err != nil {
+		reconnected = false
+		s.logger.Errorf(ctx, "redis subscriber reconnect failed: region=%s err=%v", s.cfg.Region, err)
+	}
+
+	if s.cfg.Watchdog.OnStall != nil {
+		s.cfg.Watchdog.OnStall(ctx, StallInfo{
+			Region:        s.cfg.Region,
+			SilentFor:     silentFor,
+			LastMessageAt: info.LastMessageAt,
+			ActiveJobs:    info.ActiveJobs,
+			Reconnected:   reconnected,
+		})
+	}
+}
+
+func unixNanoTime(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
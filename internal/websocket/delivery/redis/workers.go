@@ -2,19 +2,78 @@ package redis
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
+	"time"
 
+	"notification-srv/internal/metrics"
 	"notification-srv/internal/websocket"
+	"notification-srv/pkg/errcode"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/smap-hcmut/shared-libs/go/tracing"
 )
 
+var tracer = tracing.NewTraceContext()
+
 func (s *subscriber) handleMessage(ctx context.Context, msg *redis.Message) {
+	if s.cfg.Probe.Enabled && msg.Channel == s.probeChannel() {
+		s.handleProbe(ctx, msg)
+		return
+	}
+	if hb := s.heartbeatChannel(); hb != "" && msg.Channel == hb {
+		s.handleHeartbeat(ctx, msg)
+		return
+	}
+	s.lastMessageAt.Store(time.Now().UnixNano())
+
+	// One trace per message ties its log lines to the exemplars of its latencies
+	ctx = tracer.WithTraceID(ctx, tracer.GenerateTraceID())
+
 	input := websocket.ProcessMessageInput{
-		Channel: msg.Channel,
+		Channel: strings.TrimPrefix(msg.Channel, s.cfg.ChannelPrefix),
 		Payload: []byte(msg.Payload),
+		Region:  s.cfg.Region,
+	}
+	metrics.InboundPayloadBytes.WithLabelValues(s.cfg.Region, channelType(input.Channel)).Observe(float64(len(input.Payload)))
+
+	if !s.limit(ctx, input) {
+		return
+	}
+	s.dispatch(ctx, input)
+}
+
+// channelType is the first segment of a channel, e.g. project for
+// project:{project_id}:user:{user_id}.
+func channelType(channel string) string {
+	t, _, _ := strings.Cut(channel, ":")
+	return t
+}
+
+// dispatch hands a message to the usecase.
+func (s *subscriber) dispatch(ctx context.Context, input websocket.ProcessMessageInput) {
+	// Regions merged into one stream are dispatched one at a time so per-topic
+	// sequence numbers stay in delivery order
+	if s.dispatchMu != nil {
+		s.dispatchMu.Lock()
+		defer s.dispatchMu.Unlock()
 	}
 
 	if err := s.uc.ProcessMessage(ctx, input); err != nil {
-		s.logger.Errorf(ctx, "process message failed: channel=%s err=%v", msg.Channel, err)
+		e := errcode.Classify(err)
+		metrics.ProcessErrors.WithLabelValues(e.Code, string(e.Category)).Inc()
+		s.logger.Errorf(ctx, "process message failed: channel=%s%s code=%s err=%v", s.cfg.ChannelPrefix, input.Channel, e.Code, err)
+	}
+}
+
+// handleHeartbeat records the collector's active job count. Heartbeats are
+// never forwarded to clients.
+func (s *subscriber) handleHeartbeat(ctx context.Context, msg *redis.Message) {
+	var hb heartbeatMessage
+	if err := json.Unmarshal([]byte(msg.Payload), &hb); err != nil {
+		s.logger.Warnf(ctx, "invalid heartbeat on %s: %v", msg.Channel, err)
+		return
 	}
+	s.activeJobs.Store(hb.ActiveJobs)
+	s.lastHeartbeatAt.Store(time.Now().UnixNano())
 }
@@ -1,24 +1,58 @@
 package websocket
 
-import "errors"
+import "notification-srv/pkg/errcode"
 
 var (
-	ErrInvalidToken          = errors.New("invalid or expired JWT token")
-	ErrMissingToken          = errors.New("missing JWT token")
-	ErrConnectionClosed      = errors.New("connection closed")
-	ErrMaxConnectionsReached = errors.New("maximum connections reached")
-	ErrUserNotFound          = errors.New("user not found in connection registry")
+	ErrInvalidToken          = errcode.New("auth.invalid_token", errcode.Unauthenticated, "invalid or expired JWT token", "Invalid or expired token")
+	ErrMissingToken          = errcode.New("auth.missing_token", errcode.Unauthenticated, "missing JWT token", "Missing authentication token")
+	ErrConnectionClosed      = errcode.NewRetryable("ws.connection_closed", errcode.Unavailable, "connection closed", "Connection closed")
+	ErrMaxConnectionsReached = errcode.NewRetryable("ws.max_connections", errcode.Unavailable, "maximum connections reached", "Maximum connections reached")
+	ErrUserNotFound          = errcode.New("ws.user_not_found", errcode.NotFound, "user not found in connection registry", "User not found")
+	ErrBanned                = errcode.New("auth.banned", errcode.Forbidden, "user or address is banned", "Banned")
+	ErrQuotaExceeded         = errcode.NewRetryable("ws.quota_exceeded", errcode.RateLimited, "connection quota exceeded", "Connection quota exceeded")
+	ErrStageTimeout          = errcode.NewRetryable("ws.setup_timeout", errcode.Timeout, "connection setup timed out", "Connection setup timed out")
+	ErrClientOutdated        = errcode.New("ws.client_outdated", errcode.UpgradeRequired, "client version below minimum", "Client upgrade required")
+	ErrDelegationDenied      = errcode.New("auth.delegation_denied", errcode.Forbidden, "not allowed to act as the user", "Forbidden")
+)
+
+// Public project errors
+var (
+	ErrNotPublic         = errcode.New("ws.not_public", errcode.NotFound, "project is not public", "Project not found")
+	ErrPublicRateLimited = errcode.NewRetryable("ws.public_rate_limited", errcode.RateLimited, "anonymous connection limit reached", "Too many connections")
 )
 
 // Message errors
 var (
-	ErrInvalidMessage     = errors.New("invalid message format")
-	ErrUnknownMessageType = errors.New("unknown message type")
-	ErrInvalidChannel     = errors.New("invalid Redis channel format")
+	ErrInvalidMessage     = errcode.New("message.invalid", errcode.Invalid, "invalid message format", "Invalid request")
+	ErrUnknownMessageType = errcode.New("message.unknown_type", errcode.Invalid, "unknown message type", "Unknown message type")
+	ErrInvalidChannel     = errcode.New("message.invalid_channel", errcode.Invalid, "invalid Redis channel format", "Invalid channel")
 )
 
 // Transform errors
 var (
-	ErrTransformFailed  = errors.New("message transformation failed")
-	ErrValidationFailed = errors.New("message validation failed")
+	ErrTransformFailed  = errcode.New("transform.failed", errcode.Internal, "message transformation failed", "Message transformation failed")
+	ErrValidationFailed = errcode.New("transform.validation_failed", errcode.Invalid, "message validation failed", "Message validation failed")
+)
+
+// Broadcast errors
+var (
+	ErrUnknownSegment      = errcode.New("broadcast.unknown_segment", errcode.Invalid, "unknown broadcast segment", "Unknown segment")
+	ErrSegmentsUnavailable = errcode.New("broadcast.segments_unavailable", errcode.NotImplemented, "no segment resolver configured", "Segment resolution is not configured")
+)
+
+// Deny-list errors
+var (
+	ErrInvalidBan      = errcode.New("ban.invalid", errcode.Invalid, "invalid ban kind or value", "Invalid ban kind or value")
+	ErrBansUnavailable = errcode.New("ban.unavailable", errcode.NotImplemented, "no deny-list store configured", "Deny-list is not configured")
+)
+
+// Targeted tracing errors
+var (
+	ErrInvalidTrace  = errcode.New("trace.invalid", errcode.Invalid, "invalid user ID or trace ttl", "Invalid user ID or ttl")
+	ErrTooManyTraces = errcode.New("trace.too_many", errcode.RateLimited, "too many users traced", "Too many users traced")
+)
+
+// Storage errors
+var (
+	ErrStorage = errcode.NewRetryable("storage.unavailable", errcode.Storage, "storage unavailable", "Storage temporarily unavailable")
 )
@@ -0,0 +1,126 @@
+package websocket_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	telemetryUC "notification-srv/internal/telemetry/usecase"
+	"notification-srv/internal/websocket"
+	"notification-srv/internal/websocket/usecase"
+	"notification-srv/pkg/notificationtest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/golden from the current pipeline output")
+
+// goldenFixture is a publisher message as read from Redis. Raw is used instead
+// of Payload for payloads that are not valid JSON.
+type goldenFixture struct {
+	Channel string          `json:"channel"`
+	Payload json.RawMessage `json:"payload"`
+	Raw     string          `json:"raw"`
+}
+
+// goldenResult is everything the pipeline produced for a fixture, with the
+// per-run values (frame id, timestamp, epoch) replaced by placeholders.
+type goldenResult struct {
+	ChannelType    websocket.ChannelType `json:"channel_type,omitempty"`
+	MessageType    websocket.MessageType `json:"message_type,omitempty"`
+	ContractErrors []string              `json:"contract_errors,omitempty"`
+	ProcessError   string                `json:"process_error,omitempty"`
+	Frame          map[string]any        `json:"frame"`
+}
+
+// TestGoldenPipeline runs every fixture in testdata/fixtures through the
+// contract check and ProcessMessage, and compares the result with
+// testdata/golden. Run with -update after an intended format change.
+func TestGoldenPipeline(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "fixtures", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			var fx goldenFixture
+			require.NoError(t, json.Unmarshal(data, &fx))
+
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			enc.SetIndent("", "  ")
+			require.NoError(t, enc.Encode(runGolden(t, fx)))
+			got := buf.Bytes()
+
+			goldenPath := filepath.Join("testdata", "golden", name+".json")
+			if *update {
+				require.NoError(t, os.WriteFile(goldenPath, got, 0o644))
+				return
+			}
+			want, err := os.ReadFile(goldenPath)
+			require.NoError(t, err, "missing golden file, run go test -run TestGoldenPipeline -update")
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+func runGolden(t *testing.T, fx goldenFixture) goldenResult {
+	payload := []byte(fx.Payload)
+	if fx.Raw != "" {
+		payload = []byte(fx.Raw)
+	}
+
+	logger := notificationtest.Logger{}
+	uc := usecase.New(logger, usecase.Config{
+		MaxConnections: 10,
+		Validation:     usecase.ValidationLenient,
+		Sequence:       usecase.SequenceConfig{BufferSize: 10, MaxTopics: 10},
+	}, &notificationtest.AlertRecorder{}, telemetryUC.New(logger, telemetryUC.Config{}), nil)
+	go uc.Run()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	frames, err := uc.Subscribe(ctx, websocket.SubscribeInput{UserID: "user_123"})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		stats, _ := uc.GetStats(ctx)
+		return stats.ActiveConnections == 1
+	}, time.Second, 5*time.Millisecond)
+
+	var res goldenResult
+	check, err := uc.CheckContract(ctx, websocket.CheckContractInput{Channel: fx.Channel, Payload: payload})
+	require.NoError(t, err)
+	res.ChannelType = check.ChannelType
+	res.MessageType = check.MessageType
+	res.ContractErrors = check.Errors
+
+	if err := uc.ProcessMessage(ctx, websocket.ProcessMessageInput{Channel: fx.Channel, Payload: payload}); err != nil {
+		res.ProcessError = err.Error()
+	}
+
+	// User frames are queued before ProcessMessage returns; broadcasts go
+	// through the Hub loop, so give those a moment.
+	select {
+	case frame := <-frames:
+		require.NoError(t, json.Unmarshal(frame, &res.Frame))
+		for _, key := range []string{"id", "timestamp", "epoch"} {
+			if _, ok := res.Frame[key]; ok {
+				res.Frame[key] = "<" + key + ">"
+			}
+		}
+	case <-time.After(100 * time.Millisecond):
+	}
+	return res
+}
@@ -2,6 +2,8 @@ package websocket
 
 import (
 	"context"
+
+	"notification-srv/internal/model"
 )
 
 // UseCase defines the business logic for the WebSocket domain.
@@ -10,20 +12,116 @@ type UseCase interface {
 	// Lifecycle
 	Run()
 	Shutdown(ctx context.Context) error
+	// Records Hub stats into the stats history until ctx is done
+	SampleStats(ctx context.Context)
 
 	// Connection Management
 	// Note: Register takes a Connection interface/struct defined in types.go or internal
 	Register(ctx context.Context, input ConnectionInput) error
 	Unregister(ctx context.Context, input ConnectionInput) error
 
+	// In-process Streams (e.g. GraphQL subscriptions, WebTransport sessions)
+	// Registers a Hub connection without a socket; frames arrive on the returned
+	// channel, which is closed when ctx ends or the Hub drops the connection
+	Subscribe(ctx context.Context, input SubscribeInput) (<-chan []byte, error)
+
+	// Hub Events
+	// Subscribes handler to the given event types (all types if none); call the
+	// returned function to unsubscribe
+	SubscribeHubEvents(types []HubEventType, handler HubEventHandler) (unsubscribe func())
+
+	// Protocol (Call by HTTP for clients)
+	// Describes the client protocol and the features this server has on
+	GetProtocol(ctx context.Context) (Protocol, error)
+
 	// Stats
 	GetStats(ctx context.Context) (HubStats, error)
+	ListConnections(ctx context.Context, input ListConnectionsInput) (ListConnectionsOutput, error)
+	// Returns the samples recorded by SampleStats, which outlive a broken metrics scrape
+	GetStatsHistory(ctx context.Context, input GetStatsHistoryInput) (GetStatsHistoryOutput, error)
+
+	// Gap Recovery
+	// Returns buffered frames of the caller's project topic with a sequence number after AfterSeq
+	ListProjectNotifications(ctx context.Context, sc model.Scope, input ListProjectNotificationsInput) (ListProjectNotificationsOutput, error)
+
+	// Channel Statistics (Call by HTTP for publisher teams)
+	// Per channel pattern counters over a rolling window
+	GetChannelStats(ctx context.Context, input GetChannelStatsInput) (GetChannelStatsOutput, error)
+
+	// Maintenance Mode
+	// While enabled only critical and terminal messages are delivered; the rest is dropped
+	SetMaintenance(ctx context.Context, sc model.Scope, input SetMaintenanceInput) (MaintenanceStatus, error)
+	GetMaintenance(ctx context.Context) (MaintenanceStatus, error)
+
+	// Targeted Tracing (Call by HTTP for operators)
+	// Logs every hop of one user's notifications, payloads included, on every
+	// replica until the trace expires
+	TraceUser(ctx context.Context, sc model.Scope, input TraceUserInput) (UserTrace, error)
+	UntraceUser(ctx context.Context, sc model.Scope, input UntraceUserInput) error
+	ListUserTraces(ctx context.Context) (ListUserTracesOutput, error)
+
+	// Broadcast (Call by HTTP for operators)
+	// Resolves the segment and sends a SYSTEM message to its users on every replica
+	Broadcast(ctx context.Context, sc model.Scope, input BroadcastInput) (BroadcastOutput, error)
+
+	// Deny-list (Call by HTTP for operators, and before every upgrade)
+	// Bans are shared by all replicas; banning closes the matching connections everywhere
+	Ban(ctx context.Context, sc model.Scope, input BanInput) (BanOutput, error)
+	Unban(ctx context.Context, sc model.Scope, input UnbanInput) error
+	// Returns ErrBanned if the user or IP is on the deny-list
+	CheckBan(ctx context.Context, input CheckBanInput) error
+
+	// Decoy Projects (Call before every project subscription, once the token is verified)
+	// Reports subscriptions to never-issued project IDs; returns ErrBanned if
+	// the subscriber was banned for it
+	CheckDecoy(ctx context.Context, input CheckDecoyInput) error
+
+	// Public Projects (Call before every anonymous upgrade)
+	// Admits an anonymous viewer of a public project; returns ErrNotPublic,
+	// ErrPublicRateLimited or ErrMaxConnectionsReached otherwise
+	CheckPublic(ctx context.Context, input CheckPublicInput) (CheckPublicOutput, error)
+
+	// Delegated Access (Call before an upgrade that asks for another user's stream)
+	// Audit logs the attempt; returns ErrDelegationDenied unless the
+	// DelegationAuthorizer allows the actor to act as the user
+	CheckDelegation(ctx context.Context, input DelegationInput) error
+
+	// Connection Quota (Call before every upgrade, once the token is verified)
+	// Returns the user's quota for Register or Subscribe, which enforce it as
+	// the connection is admitted. With the reject policy, returns
+	// ErrQuotaExceeded early if the user already has as many connections
+	CheckQuota(ctx context.Context, input QuotaInput) (int, error)
 
 	// Message Processing (Call by Redis Delivery or HTTP)
 	// Validates, Transforms, and Routes message to connected users
 	ProcessMessage(ctx context.Context, input ProcessMessageInput) error
 
+	// Contract Check (Call by HTTP for publisher CI)
+	// Runs parsing, validation and transformation on a sample message without routing it
+	CheckContract(ctx context.Context, input CheckContractInput) (CheckContractOutput, error)
+
 	// Event Callbacks (Call by Redis Delivery)
 	OnUserConnected(ctx context.Context, userID string) error
 	OnUserDisconnected(ctx context.Context, userID string, hasOtherConnections bool) error
 }
+
+// SegmentResolver resolves broadcast segments other than "all" and "users",
+// e.g. from a plan or org directory. Unknown kinds return ErrUnknownSegment.
+type SegmentResolver interface {
+	ResolveSegment(ctx context.Context, segment Segment) (SegmentTarget, error)
+}
+
+// DelegationAuthorizer decides whether a user may subscribe to another user's
+// stream, e.g. a manager following their team's dry runs. A grant covers the
+// whole stream: messages carry no dry-run marker to narrow it by, so the
+// authorizer should only grant users who may see all of it.
+type DelegationAuthorizer interface {
+	CanActAsUser(ctx context.Context, input DelegationInput) (bool, error)
+}
+
+// QuotaProvider resolves a user's connection quota, e.g. from a plan claim or
+// a billing service. ok is false when it has no quota for the user, in which
+// case the static default applies.
+type QuotaProvider interface {
+	ConnectionQuota(ctx context.Context, input QuotaInput) (limit int, ok bool, err error)
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// Repository groups the data the WebSocket domain reads from shared stores.
+type Repository interface {
+	ProjectStateRepository
+	LatestStateRepository
+	PresenceRepository
+	OfflineQueueRepository
+	ControlRepository
+	BanRepository
+	BackpressureRepository
+	CanaryRepository
+}
+
+// ProjectStateRepository reads project progress state written by the collector.
+type ProjectStateRepository interface {
+	// GetProjectState returns the raw JSON state of a project, or nil if the
+	// collector has not written any. userID selects the user's own state when
+	// the key pattern carries {user_id}.
+	GetProjectState(ctx context.Context, projectID, userID string) ([]byte, error)
+}
+
+// LatestStateRepository keeps the last frame delivered on each project topic,
+// so REST consumers and backfills read current progress from one place.
+type LatestStateRepository interface {
+	// SetLatestState replaces the latest frame of the user's project topic,
+	// expiring it after ttl.
+	SetLatestState(ctx context.Context, projectID, userID string, frame []byte, ttl time.Duration) error
+
+	// GetLatestState returns the latest frame of the user's project topic, or
+	// nil if none was delivered within its TTL.
+	GetLatestState(ctx context.Context, projectID, userID string) ([]byte, error)
+
+	// DeleteLatestStates removes the latest frames of every user's topic of
+	// the project and returns how many were removed.
+	DeleteLatestStates(ctx context.Context, projectID string) (int, error)
+}
+
+// PresenceRepository maintains the per-user presence key other services read
+// to tell whether a user currently has the dashboard open.
+type PresenceRepository interface {
+	// SetPresence (re)writes the user's presence key with the given TTL,
+	// recording the projects the user currently has on screen.
+	SetPresence(ctx context.Context, userID string, focusedProjects []string, ttl time.Duration) error
+
+	// DeletePresence removes the user's presence key.
+	DeletePresence(ctx context.Context, userID string) error
+
+	// IsPresent reports whether the user's presence key exists, i.e. whether
+	// the user has a connection open on any replica.
+	IsPresent(ctx context.Context, userID string) (bool, error)
+}
+
+// OfflineQueueRepository keeps the at-least-once frames of users with no
+// connection open, shared by all replicas.
+type OfflineQueueRepository interface {
+	// PushOffline appends frame to the user's queue, keeps the newest max
+	// frames and expires the queue ttl after the last push.
+	PushOffline(ctx context.Context, userID string, frame []byte, max int, ttl time.Duration) error
+
+	// PopOffline removes and returns the user's queued frames, oldest first.
+	PopOffline(ctx context.Context, userID string) ([][]byte, error)
+
+	// RemoveOffline removes the frames of the user's queue for which remove
+	// returns true, keeping the order and expiry of the others, and returns
+	// how many were removed.
+	RemoveOffline(ctx context.Context, userID string, remove func(frame []byte) bool) (int, error)
+}
+
+// ControlRepository publishes control events to every replica, this one included.
+type ControlRepository interface {
+	// PublishControl publishes payload on control:{event}:{entity_id}.
+	PublishControl(ctx context.Context, event, entityID string, payload []byte) error
+}
+
+// BanRepository keeps the deny-list shared by all replicas. Entries expire on
+// their own. Kind "user" bans a user ID; kind "ip" bans an IP range in CIDR
+// notation, and IsBanned takes a single IP for it.
+type BanRepository interface {
+	// SetBan bans value for ttl, replacing any existing ban.
+	SetBan(ctx context.Context, kind, value string, ttl time.Duration) error
+
+	// DeleteBan lifts a ban. Lifting a ban that does not exist is not an error.
+	DeleteBan(ctx context.Context, kind, value string) error
+
+	// IsBanned reports whether value is currently banned.
+	IsBanned(ctx context.Context, kind, value string) (bool, error)
+}
+
+// BackpressureRepository tells publishers to slow down.
+type BackpressureRepository interface {
+	// PublishBackpressure publishes payload on backpressure:{channel}.
+	PublishBackpressure(ctx context.Context, channel string, payload []byte) error
+}
+
+// CanaryRepository publishes the canary's probes like any upstream publisher.
+type CanaryRepository interface {
+	// PublishCanary publishes payload on project:{project_id}:user:{user_id}.
+	PublishCanary(ctx context.Context, projectID, userID string, payload []byte) error
+}
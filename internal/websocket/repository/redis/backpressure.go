@@ -0,0 +1,9 @@
+package redis
+
+import (
+	"context"
+)
+
+func (r *implRepository) PublishBackpressure(ctx context.Context, channel string, payload []byte) error {
+	return r.redis.GetClient().Publish(ctx, r.cfg.ChannelPrefix+"backpressure:"+channel, payload).Err()
+}
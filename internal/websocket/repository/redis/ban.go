@@ -0,0 +1,66 @@
+package redis
+
+import (
+	"context"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"notification-srv/internal/websocket"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// IP ranges are few and must be matched by containment, so they share one
+// sorted set scored by expiry instead of a key each.
+const banRangesValue = "ranges"
+
+func (r *implRepository) SetBan(ctx context.Context, kind, value string, ttl time.Duration) error {
+	if kind != websocket.BanKindIP {
+		return r.redis.Set(ctx, r.banKey(kind, value), time.Now().UTC().Format(time.RFC3339), ttl)
+	}
+
+	now := time.Now()
+	key := r.banKey(kind, banRangesValue)
+	pipe := r.redis.GetClient().TxPipeline()
+	pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(now.Unix(), 10))
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(now.Add(ttl).Unix()), Member: value})
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *implRepository) DeleteBan(ctx context.Context, kind, value string) error {
+	if kind != websocket.BanKindIP {
+		return r.redis.Delete(ctx, r.banKey(kind, value))
+	}
+	return r.redis.GetClient().ZRem(ctx, r.banKey(kind, banRangesValue), value).Err()
+}
+
+func (r *implRepository) IsBanned(ctx context.Context, kind, value string) (bool, error) {
+	if kind != websocket.BanKindIP {
+		return r.redis.Exists(ctx, r.banKey(kind, value))
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return false, nil
+	}
+	ranges, err := r.redis.GetClient().ZRangeByScore(ctx, r.banKey(kind, banRangesValue), &redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(time.Now().Unix(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return false, err
+	}
+	for _, s := range ranges {
+		if prefix, err := netip.ParsePrefix(s); err == nil && prefix.Contains(addr.Unmap()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *implRepository) banKey(kind, value string) string {
+	return strings.NewReplacer("{kind}", kind, "{value}", value).Replace(r.cfg.BanKeyPattern)
+}
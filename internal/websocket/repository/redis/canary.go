@@ -0,0 +1,10 @@
+package redis
+
+import (
+	"context"
+)
+
+func (r *implRepository) PublishCanary(ctx context.Context, projectID, userID string, payload []byte) error {
+	channel := r.cfg.ChannelPrefix + "project:" + projectID + ":user:" + userID
+	return r.redis.GetClient().Publish(ctx, channel, payload).Err()
+}
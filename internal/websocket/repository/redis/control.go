@@ -0,0 +1,10 @@
+package redis
+
+import (
+	"context"
+)
+
+func (r *implRepository) PublishControl(ctx context.Context, event, entityID string, payload []byte) error {
+	channel := r.cfg.ChannelPrefix + "control:" + event + ":" + entityID
+	return r.redis.GetClient().Publish(ctx, channel, payload).Err()
+}
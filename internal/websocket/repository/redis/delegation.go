@@ -0,0 +1,25 @@
+package redis
+
+import (
+	"context"
+	"strings"
+
+	"notification-srv/internal/websocket"
+
+	pkgRedis "github.com/smap-hcmut/shared-libs/go/redis"
+)
+
+// NewDelegationAuthorizer creates a DelegationAuthorizer reading the set of
+// users allowed to act as each user, named by cfg. A user without a set has
+// no delegates.
+func NewDelegationAuthorizer(redis pkgRedis.IRedis, cfg Config) websocket.DelegationAuthorizer {
+	return &implRepository{
+		redis: redis,
+		cfg:   cfg,
+	}
+}
+
+func (r *implRepository) CanActAsUser(ctx context.Context, input websocket.DelegationInput) (bool, error) {
+	key := strings.ReplaceAll(r.cfg.DelegateKeyPattern, "{user_id}", input.UserID)
+	return r.redis.GetClient().SIsMember(ctx, key, input.ActorID).Result()
+}
@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Keys fetched per SCAN call when deleting a project's latest states.
+const latestScanCount = 256
+
+func (r *implRepository) SetLatestState(ctx context.Context, projectID, userID string, frame []byte, ttl time.Duration) error {
+	return r.redis.Set(ctx, r.latestKey(projectID, userID), frame, ttl)
+}
+
+func (r *implRepository) GetLatestState(ctx context.Context, projectID, userID string) ([]byte, error) {
+	val, err := r.redis.Get(ctx, r.latestKey(projectID, userID))
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []byte(val), nil
+}
+
+// DeleteLatestStates scans for the project's keys, as the users who have one
+// are not recorded anywhere.
+func (r *implRepository) DeleteLatestStates(ctx context.Context, projectID string) (int, error) {
+	match := strings.NewReplacer("{project_id}", globEscape(projectID), "{user_id}", "*").Replace(globEscape(r.cfg.LatestKeyPattern))
+	client := r.redis.GetClient()
+
+	deleted := 0
+	iter := client.Scan(ctx, 0, match, latestScanCount).Iterator()
+	keys := make([]string, 0, latestScanCount)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) < latestScanCount {
+			continue
+		}
+		n, err := client.Del(ctx, keys...).Result()
+		deleted += int(n)
+		if err != nil {
+			return deleted, err
+		}
+		keys = keys[:0]
+	}
+	if err := iter.Err(); err != nil {
+		return deleted, err
+	}
+	if len(keys) > 0 {
+		n, err := client.Del(ctx, keys...).Result()
+		deleted += int(n)
+		if err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+func (r *implRepository) latestKey(projectID, userID string) string {
+	return strings.NewReplacer("{project_id}", projectID, "{user_id}", userID).Replace(r.cfg.LatestKeyPattern)
+}
+
+// globEscape quotes the characters SCAN MATCH treats as a pattern.
+func globEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`).Replace(s)
+}
@@ -0,0 +1,20 @@
+package redis
+
+import (
+	"notification-srv/internal/websocket/repository"
+
+	pkgRedis "github.com/smap-hcmut/shared-libs/go/redis"
+)
+
+type implRepository struct {
+	redis pkgRedis.IRedis
+	cfg   Config
+}
+
+// New creates a Redis-backed Repository.
+func New(redis pkgRedis.IRedis, cfg Config) repository.Repository {
+	return &implRepository{
+		redis: redis,
+		cfg:   cfg,
+	}
+}
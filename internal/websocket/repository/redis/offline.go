@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func (r *implRepository) PushOffline(ctx context.Context, userID string, frame []byte, max int, ttl time.Duration) error {
+	key := r.offlineKey(userID)
+	pipe := r.redis.GetClient().TxPipeline()
+	pipe.RPush(ctx, key, frame)
+	pipe.LTrim(ctx, key, int64(-max), -1)
+	pipe.Expire(ctx, key, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *implRepository) PopOffline(ctx context.Context, userID string) ([][]byte, error) {
+	key := r.offlineKey(userID)
+	pipe := r.redis.GetClient().TxPipeline()
+	frames := pipe.LRange(ctx, key, 0, -1)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	vals := frames.Val()
+	out := make([][]byte, len(vals))
+	for i, v := range vals {
+		out[i] = []byte(v)
+	}
+	return out, nil
+}
+
+// RemoveOffline rewrites the queue in a transaction watching the key, so a
+// frame pushed or popped meanwhile aborts the removal instead of being lost.
+func (r *implRepository) RemoveOffline(ctx context.Context, userID string, remove func(frame []byte) bool) (int, error) {
+	key := r.offlineKey(userID)
+	removed := 0
+	err := r.redis.GetClient().Watch(ctx, func(tx *redis.Tx) error {
+		vals, err := tx.LRange(ctx, key, 0, -1).Result()
+		if err != nil {
+			return err
+		}
+		keep := make([]interface{}, 0, len(vals))
+		for _, v := range vals {
+			if remove([]byte(v)) {
+				removed++
+			} else {
+				keep = append(keep, v)
+			}
+		}
+		if removed == 0 {
+			return nil
+		}
+		ttl, err := tx.PTTL(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, key)
+			if len(keep) > 0 {
+				pipe.RPush(ctx, key, keep...)
+				if ttl > 0 {
+					pipe.PExpire(ctx, key, ttl)
+				}
+			}
+			return nil
+		})
+		return err
+	}, key)
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+func (r *implRepository) offlineKey(userID string) string {
+	return strings.ReplaceAll(r.cfg.OfflineKeyPattern, "{user_id}", userID)
+}
@@ -0,0 +1,34 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+func (r *implRepository) SetPresence(ctx context.Context, userID string, focusedProjects []string, ttl time.Duration) error {
+	if focusedProjects == nil {
+		focusedProjects = []string{}
+	}
+	value, err := json.Marshal(presenceValue{
+		UpdatedAt:       time.Now().UTC(),
+		FocusedProjects: focusedProjects,
+	})
+	if err != nil {
+		return err
+	}
+	return r.redis.Set(ctx, r.presenceKey(userID), value, ttl)
+}
+
+func (r *implRepository) DeletePresence(ctx context.Context, userID string) error {
+	return r.redis.Delete(ctx, r.presenceKey(userID))
+}
+
+func (r *implRepository) IsPresent(ctx context.Context, userID string) (bool, error) {
+	return r.redis.Exists(ctx, r.presenceKey(userID))
+}
+
+func (r *implRepository) presenceKey(userID string) string {
+	return strings.ReplaceAll(r.cfg.PresenceKeyPattern, "{user_id}", userID)
+}
@@ -0,0 +1,22 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func (r *implRepository) GetProjectState(ctx context.Context, projectID, userID string) ([]byte, error) {
+	key := strings.NewReplacer("{project_id}", projectID, "{user_id}", userID).Replace(r.cfg.StateKeyPattern)
+
+	val, err := r.redis.Get(ctx, key)
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []byte(val), nil
+}
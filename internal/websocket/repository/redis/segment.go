@@ -0,0 +1,37 @@
+package redis
+
+import (
+	"context"
+	"strings"
+
+	"notification-srv/internal/websocket"
+
+	pkgRedis "github.com/smap-hcmut/shared-libs/go/redis"
+)
+
+// NewSegmentResolver creates a SegmentResolver reading the plan and org sets
+// named by cfg. Missing sets resolve to an empty segment.
+func NewSegmentResolver(redis pkgRedis.IRedis, cfg Config) websocket.SegmentResolver {
+	return &implRepository{
+		redis: redis,
+		cfg:   cfg,
+	}
+}
+
+func (r *implRepository) ResolveSegment(ctx context.Context, segment websocket.Segment) (websocket.SegmentTarget, error) {
+	switch segment.Kind {
+	case websocket.SegmentPlan:
+		users, err := r.members(ctx, r.cfg.PlanKeyPattern, "{plan}", segment.Value)
+		return websocket.SegmentTarget{UserIDs: users}, err
+	case websocket.SegmentOrg:
+		projects, err := r.members(ctx, r.cfg.OrgKeyPattern, "{org_id}", segment.Value)
+		return websocket.SegmentTarget{ProjectIDs: projects}, err
+	default:
+		return websocket.SegmentTarget{}, websocket.ErrUnknownSegment
+	}
+}
+
+func (r *implRepository) members(ctx context.Context, pattern, placeholder, value string) ([]string, error) {
+	key := strings.ReplaceAll(pattern, placeholder, value)
+	return r.redis.GetClient().SMembers(ctx, key).Result()
+}
@@ -0,0 +1,27 @@
+package redis
+
+import "time"
+
+// Config holds the keys and channels the repository uses.
+type Config struct {
+	StateKeyPattern    string // Collector state key, e.g. "project_state:{project_id}" or "project_state:{project_id}:{user_id}"
+	LatestKeyPattern   string // Latest frame of a project topic, e.g. "state:project:{project_id}:{user_id}"
+	PresenceKeyPattern string // Presence key, e.g. "presence:{user_id}"
+	OfflineKeyPattern  string // Offline queue of at-least-once frames, e.g. "offline:{user_id}"
+	ChannelPrefix      string // Prefix of the local region's channels, for control events and backpressure signals
+
+	// Broadcast segment sets maintained by the identity and project services
+	PlanKeyPattern string // Set of user IDs on a plan, e.g. "segment:plan:{plan}"
+	OrgKeyPattern  string // Set of project IDs of an org, e.g. "segment:org:{org_id}"
+	BanKeyPattern  string // Deny-list entry, e.g. "ban:{kind}:{value}"
+
+	// Set of user IDs allowed to act as a user, maintained by the identity
+	// service, e.g. "delegates:{user_id}"
+	DelegateKeyPattern string
+}
+
+// presenceValue is the JSON stored under a user's presence key.
+type presenceValue struct {
+	UpdatedAt       time.Time `json:"updated_at"`
+	FocusedProjects []string  `json:"focused_projects"` // Project views on screen; other channels skip these
+}
@@ -0,0 +1,62 @@
+package coder
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"notification-srv/internal/websocket/transport"
+
+	"github.com/coder/websocket"
+)
+
+// conn adapts *websocket.Conn to transport.Conn.
+type conn struct {
+	ws *websocket.Conn
+}
+
+func (c conn) Subprotocol() string {
+	return c.ws.Subprotocol()
+}
+
+func (c conn) SetReadLimit(limit int64) {
+	c.ws.SetReadLimit(limit)
+}
+
+func (c conn) Read(ctx context.Context) (transport.MessageType, []byte, error) {
+	typ, data, err := c.ws.Read(ctx)
+	if err != nil {
+		return 0, nil, closeError(err)
+	}
+	return transport.MessageType(typ), data, nil
+}
+
+func (c conn) Write(ctx context.Context, typ transport.MessageType, p []byte) error {
+	return c.ws.Write(ctx, websocket.MessageType(typ), p)
+}
+
+func (c conn) Writer(ctx context.Context, typ transport.MessageType) (io.WriteCloser, error) {
+	return c.ws.Writer(ctx, websocket.MessageType(typ))
+}
+
+func (c conn) Ping(ctx context.Context) error {
+	return c.ws.Ping(ctx)
+}
+
+// Close waits up to the library's handshake timeout for the peer's close frame.
+func (c conn) Close(code int, reason string) error {
+	return c.ws.Close(websocket.StatusCode(code), reason)
+}
+
+func (c conn) CloseNow() error {
+	return c.ws.CloseNow()
+}
+
+// closeError turns the library's close error into the transport's.
+func closeError(err error) error {
+	var ce websocket.CloseError
+	if errors.As(err, &ce) {
+		return &transport.CloseError{Code: int(ce.Code), Reason: ce.Reason}
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package coder
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+
+	"notification-srv/internal/websocket/transport"
+
+	"github.com/coder/websocket"
+)
+
+type upgrader struct{}
+
+// New returns the Upgrader backed by github.com/coder/websocket, whose reads
+// and writes take a context. Buffer sizes and the handshake timeout of
+// transport.Options are not used: the library sizes its own buffers and
+// writes the handshake response at once.
+func New() transport.Upgrader {
+	return upgrader{}
+}
+
+func (upgrader) Name() string {
+	return transport.LibraryCoder
+}
+
+func (upgrader) Upgrade(w http.ResponseWriter, r *http.Request, opts transport.Options) (transport.Conn, error) {
+	mode := websocket.CompressionDisabled
+	if opts.Compression {
+		mode = websocket.CompressionNoContextTakeover
+	}
+	ws, err := websocket.Accept(handshakeWriter{ResponseWriter: w}, r, &websocket.AcceptOptions{
+		Subprotocols:       opts.Subprotocols,
+		InsecureSkipVerify: true,
+		CompressionMode:    mode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return conn{ws: ws}, nil
+}
+
+// handshakeWriter lets Accept write the 101 status before hijacking. Accept
+// sets the status first, which middleware wrappers such as gin's take as a
+// written response and then refuse to hijack; the status goes to the writer
+// under the wrappers instead, which sends it once hijacked.
+type handshakeWriter struct {
+	http.ResponseWriter
+}
+
+func (w handshakeWriter) WriteHeader(code int) {
+	inner := w.ResponseWriter
+	for {
+		u, ok := inner.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		inner = u.Unwrap()
+	}
+	inner.WriteHeader(code)
+}
+
+func (w handshakeWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
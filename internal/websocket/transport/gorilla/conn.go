@@ -0,0 +1,129 @@
+package gorilla
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"notification-srv/internal/websocket/transport"
+
+	"github.com/gorilla/websocket"
+)
+
+// Time allowed to write a control frame when ctx has no deadline.
+const controlWait = 5 * time.Second
+
+// conn adapts *websocket.Conn to transport.Conn. Gorilla has no contexts: a
+// ctx deadline becomes the read or write deadline, and a cancelled ctx
+// interrupts a read. A write is only bounded by its deadline.
+type conn struct {
+	ws *websocket.Conn
+
+	pingSeq atomic.Int64
+	mu      sync.Mutex
+	pongs   map[string]chan struct{} // Pending pings by payload
+}
+
+func (c *conn) Subprotocol() string {
+	return c.ws.Subprotocol()
+}
+
+func (c *conn) SetReadLimit(limit int64) {
+	c.ws.SetReadLimit(limit)
+}
+
+func (c *conn) Read(ctx context.Context) (transport.MessageType, []byte, error) {
+	deadline, _ := ctx.Deadline()
+	c.ws.SetReadDeadline(deadline)
+	// The read deadline is the socket's, safe to move while ReadMessage waits
+	stop := context.AfterFunc(ctx, func() {
+		c.ws.SetReadDeadline(time.Now())
+	})
+	defer stop()
+
+	typ, data, err := c.ws.ReadMessage()
+	if err != nil {
+		return 0, nil, closeError(err)
+	}
+	return transport.MessageType(typ), data, nil
+}
+
+func (c *conn) Write(ctx context.Context, typ transport.MessageType, p []byte) error {
+	deadline, _ := ctx.Deadline()
+	c.ws.SetWriteDeadline(deadline)
+	return c.ws.WriteMessage(int(typ), p)
+}
+
+func (c *conn) Writer(ctx context.Context, typ transport.MessageType) (io.WriteCloser, error) {
+	deadline, _ := ctx.Deadline()
+	c.ws.SetWriteDeadline(deadline)
+	return c.ws.NextWriter(int(typ))
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	payload := strconv.FormatInt(c.pingSeq.Add(1), 10)
+	pong := make(chan struct{}, 1)
+	c.mu.Lock()
+	c.pongs[payload] = pong
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pongs, payload)
+		c.mu.Unlock()
+	}()
+
+	if err := c.ws.WriteControl(websocket.PingMessage, []byte(payload), controlDeadline(ctx)); err != nil {
+		return err
+	}
+	select {
+	case <-pong:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// handlePong wakes the Ping waiting for appData; unsolicited pongs are ignored.
+func (c *conn) handlePong(appData string) error {
+	c.mu.Lock()
+	if pong, ok := c.pongs[appData]; ok {
+		select {
+		case pong <- struct{}{}:
+		default:
+		}
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *conn) Close(code int, reason string) error {
+	err := c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(controlWait))
+	if cerr := c.ws.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (c *conn) CloseNow() error {
+	return c.ws.Close()
+}
+
+func controlDeadline(ctx context.Context) time.Time {
+	if deadline, ok := ctx.Deadline(); ok {
+		return deadline
+	}
+	return time.Now().Add(controlWait)
+}
+
+// closeError turns gorilla's close error into the transport's.
+func closeError(err error) error {
+	var ce *websocket.CloseError
+	if errors.As(err, &ce) {
+		return &transport.CloseError{Code: ce.Code, Reason: ce.Text}
+	}
+	return err
+}
@@ -0,0 +1,41 @@
+package gorilla
+
+import (
+	"net/http"
+
+	"notification-srv/internal/websocket/transport"
+
+	"github.com/gorilla/websocket"
+)
+
+type upgrader struct{}
+
+// New returns the Upgrader backed by github.com/gorilla/websocket.
+func New() transport.Upgrader {
+	return upgrader{}
+}
+
+func (upgrader) Name() string {
+	return transport.LibraryGorilla
+}
+
+func (upgrader) Upgrade(w http.ResponseWriter, r *http.Request, opts transport.Options) (transport.Conn, error) {
+	u := websocket.Upgrader{
+		ReadBufferSize:    opts.ReadBufferSize,
+		WriteBufferSize:   opts.WriteBufferSize,
+		EnableCompression: opts.Compression,
+		HandshakeTimeout:  opts.HandshakeTimeout,
+		Subprotocols:      opts.Subprotocols,
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+	ws, err := u.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &conn{ws: ws, pongs: make(map[string]chan struct{})}
+	ws.SetPongHandler(c.handlePong)
+	return c, nil
+}
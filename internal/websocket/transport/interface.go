@@ -0,0 +1,66 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// Upgrader accepts WebSocket handshakes with one WebSocket library, so the
+// library is chosen by config (websocket.transport) instead of being imported
+// by the connection code.
+type Upgrader interface {
+	// Name identifies the library in config and logs.
+	Name() string
+
+	// Upgrade completes the handshake. On failure the request has already been
+	// answered with an HTTP error.
+	Upgrade(w http.ResponseWriter, r *http.Request, opts Options) (Conn, error)
+}
+
+// Conn is an upgraded WebSocket connection. At most one goroutine reads and
+// one writes data messages at a time; Ping, Close and CloseNow may be called
+// concurrently with both.
+type Conn interface {
+	// Subprotocol returns the negotiated subprotocol, empty for none.
+	Subprotocol() string
+
+	// SetReadLimit bounds the size of a message from the peer; a larger one
+	// fails the read and closes the connection.
+	SetReadLimit(limit int64)
+
+	// Read returns the next data message. Control frames are answered while
+	// reading. A close frame from the peer returns a *CloseError.
+	Read(ctx context.Context) (MessageType, []byte, error)
+
+	// Write sends p as one data message.
+	Write(ctx context.Context, typ MessageType, p []byte) error
+
+	// Writer returns a writer for one data message, sent once it is closed.
+	// ctx bounds the whole message.
+	Writer(ctx context.Context, typ MessageType) (io.WriteCloser, error)
+
+	// Ping sends a ping and waits for its pong. The pong is only seen while a
+	// Read is in progress.
+	Ping(ctx context.Context) error
+
+	// Close sends a close frame with code and reason and closes the connection.
+	// CloseNoStatusReceived sends a close frame without a payload.
+	Close(code int, reason string) error
+
+	// CloseNow closes the connection without a close frame, e.g. once the peer
+	// stopped answering.
+	CloseNow() error
+}
+
+// Poller is implemented by connections whose reads can be driven by a shared
+// readiness poller instead of a goroutine blocked in Read, so an idle
+// connection holds no reader goroutine.
+type Poller interface {
+	// Poll hands reading over to the poller: onMessage is called with each data
+	// message, never concurrently for one connection, and onClose once with the
+	// error that ended reading, after which the connection is closed. Read must
+	// not be called after a successful Poll. It fails if the connection cannot
+	// be polled, e.g. off Linux or over TLS, leaving it to be read with Read.
+	Poll(onMessage func(MessageType, []byte), onClose func(error)) error
+}
@@ -0,0 +1,396 @@
+package netpoll
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"notification-srv/internal/websocket/transport"
+
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+)
+
+const (
+	// Time allowed to write a control frame when ctx has no deadline.
+	controlWait = 5 * time.Second
+
+	// Time allowed for the rest of a frame once a polled connection turned
+	// readable, so a peer trickling a frame cannot hold a goroutine.
+	frameWait = 10 * time.Second
+
+	// Buffer of a data message writer; longer messages are sent as fragments.
+	writeBufferSize = 4096
+)
+
+// ErrReadLimit is returned by reads of a message longer than the read limit.
+var ErrReadLimit = errors.New("netpoll: message exceeds the read limit")
+
+// conn adapts a connection upgraded by gobwas/ws to transport.Conn. Like the
+// gorilla adapter, a ctx deadline becomes the socket's read or write deadline
+// and a cancelled ctx interrupts a read.
+type conn struct {
+	nc    net.Conn
+	src   *source
+	rd    wsutil.Reader
+	limit int64 // Read limit in bytes; 0 = none
+
+	subprotocol string
+
+	wmu sync.Mutex // Held while a frame or data message is written
+
+	pingSeq atomic.Int64
+	mu      sync.Mutex
+	pongs   map[string]chan struct{} // Pending pings by payload
+
+	// Set by Poll
+	poller    poller
+	fd        int
+	gen       int32 // Tells the connection apart from a later one on the same fd
+	onMessage func(transport.MessageType, []byte)
+	onClose   func(error)
+	reading   atomic.Bool // A goroutine owns the reader
+	closeOnce sync.Once
+}
+
+func newConn(nc net.Conn, br *bufio.Reader, subprotocol string) *conn {
+	c := &conn{
+		nc:          nc,
+		src:         &source{br: br, nc: nc},
+		subprotocol: subprotocol,
+		pongs:       make(map[string]chan struct{}),
+	}
+	c.rd = wsutil.Reader{
+		Source:         c.src,
+		State:          ws.StateServerSide,
+		CheckUTF8:      true,
+		OnIntermediate: c.control,
+	}
+	return c
+}
+
+func (c *conn) Subprotocol() string {
+	return c.subprotocol
+}
+
+func (c *conn) SetReadLimit(limit int64) {
+	c.limit = limit
+	c.rd.MaxFrameSize = limit
+}
+
+func (c *conn) Read(ctx context.Context) (transport.MessageType, []byte, error) {
+	deadline, _ := ctx.Deadline()
+	c.nc.SetReadDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() {
+		c.nc.SetReadDeadline(time.Now())
+	})
+	defer stop()
+
+	for {
+		typ, data, err := c.next()
+		if err != nil || typ != 0 {
+			return typ, data, err
+		}
+	}
+}
+
+// next reads one frame, and the rest of its message if it starts one. It
+// answers a control frame and returns a zero type for it.
+func (c *conn) next() (transport.MessageType, []byte, error) {
+	hdr, err := c.rd.NextFrame()
+	if errors.Is(err, wsutil.ErrFrameTooLarge) {
+		return 0, nil, c.tooBig()
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	if hdr.OpCode.IsControl() {
+		return 0, nil, c.control(hdr, &c.rd)
+	}
+
+	typ := transport.MessageText
+	if hdr.OpCode == ws.OpBinary {
+		typ = transport.MessageBinary
+	}
+	var r io.Reader = &c.rd
+	if c.limit > 0 {
+		r = io.LimitReader(r, c.limit+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	if c.limit > 0 && int64(len(data)) > c.limit {
+		return 0, nil, c.tooBig()
+	}
+	return typ, data, nil
+}
+
+// tooBig closes the connection over a message longer than the read limit.
+func (c *conn) tooBig() error {
+	c.Close(int(ws.StatusMessageTooBig), "message too big")
+	return ErrReadLimit
+}
+
+// control answers a ping, wakes the Ping waiting for a pong, and echoes a
+// close frame, returning it as a *transport.CloseError.
+func (c *conn) control(hdr ws.Header, r io.Reader) error {
+	payload, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	switch hdr.OpCode {
+	case ws.OpPing:
+		return c.writeControl(ws.OpPong, payload, time.Now().Add(controlWait))
+	case ws.OpPong:
+		c.handlePong(string(payload))
+		return nil
+	case ws.OpClose:
+		code, reason := ws.ParseCloseFrameData(payload)
+		if code.Empty() {
+			c.writeControl(ws.OpClose, nil, time.Now().Add(controlWait))
+			return &transport.CloseError{Code: transport.CloseNoStatusReceived}
+		}
+		c.writeControl(ws.OpClose, ws.NewCloseFrameBody(code, ""), time.Now().Add(controlWait))
+		return &transport.CloseError{Code: int(code), Reason: reason}
+	}
+	return nil
+}
+
+func (c *conn) Write(ctx context.Context, typ transport.MessageType, p []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	deadline, _ := ctx.Deadline()
+	c.nc.SetWriteDeadline(deadline)
+	return ws.WriteFrame(c.nc, ws.NewFrame(opCode(typ), true, p))
+}
+
+// Writer holds the write lock until the message is closed, so a control frame
+// never lands between the header and payload of one of its frames.
+func (c *conn) Writer(ctx context.Context, typ transport.MessageType) (io.WriteCloser, error) {
+	c.wmu.Lock()
+	deadline, _ := ctx.Deadline()
+	c.nc.SetWriteDeadline(deadline)
+	return &messageWriter{c: c, w: wsutil.GetWriter(c.nc, ws.StateServerSide, opCode(typ), writeBufferSize)}, nil
+}
+
+type messageWriter struct {
+	c *conn
+	w *wsutil.Writer
+}
+
+func (m *messageWriter) Write(p []byte) (int, error) {
+	return m.w.Write(p)
+}
+
+func (m *messageWriter) Close() error {
+	err := m.w.Flush()
+	wsutil.PutWriter(m.w)
+	m.c.wmu.Unlock()
+	return err
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	payload := strconv.FormatInt(c.pingSeq.Add(1), 10)
+	pong := make(chan struct{}, 1)
+	c.mu.Lock()
+	c.pongs[payload] = pong
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pongs, payload)
+		c.mu.Unlock()
+	}()
+
+	if err := c.writeControl(ws.OpPing, []byte(payload), controlDeadline(ctx)); err != nil {
+		return err
+	}
+	select {
+	case <-pong:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// handlePong wakes the Ping waiting for payload; unsolicited pongs are ignored.
+func (c *conn) handlePong(payload string) {
+	c.mu.Lock()
+	if pong, ok := c.pongs[payload]; ok {
+		select {
+		case pong <- struct{}{}:
+		default:
+		}
+	}
+	c.mu.Unlock()
+}
+
+func (c *conn) Close(code int, reason string) error {
+	var body []byte
+	if code != transport.CloseNoStatusReceived {
+		body = ws.NewCloseFrameBody(ws.StatusCode(code), reason)
+	}
+	err := c.writeControl(ws.OpClose, body, time.Now().Add(controlWait))
+	if cerr := c.CloseNow(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// CloseNow also ends a polled connection that no goroutine is reading, which
+// would otherwise never notice: its descriptor leaves epoll before it closes.
+func (c *conn) CloseNow() error {
+	if c.poller != nil {
+		c.poller.remove(c)
+	}
+	err := c.nc.Close()
+	if c.poller != nil && c.reading.CompareAndSwap(false, true) {
+		go c.finish(net.ErrClosed)
+	}
+	return err
+}
+
+func (c *conn) writeControl(op ws.OpCode, payload []byte, deadline time.Time) error {
+	frame, err := ws.CompileFrame(ws.NewFrame(op, true, payload))
+	if err != nil {
+		return err
+	}
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	c.nc.SetWriteDeadline(deadline)
+	_, err = c.nc.Write(frame)
+	return err
+}
+
+func (c *conn) Poll(onMessage func(transport.MessageType, []byte), onClose func(error)) error {
+	p, err := sharedPoller()
+	if err != nil {
+		return err
+	}
+	fd, err := fileDescriptor(c.nc)
+	if err != nil {
+		return err
+	}
+
+	// Bytes that came with the handshake never make the socket readable: read
+	// them now, owning the reader before the poller can report c
+	pending := c.src.buffered()
+	c.reading.Store(pending)
+
+	c.poller, c.fd, c.onMessage, c.onClose = p, fd, onMessage, onClose
+	if err := p.add(c); err != nil {
+		c.poller = nil
+		c.reading.Store(false)
+		return err
+	}
+	if pending {
+		go c.read()
+	}
+	return nil
+}
+
+// readable is called by the poller once c turned readable. A report while
+// another goroutine owns the reader is dropped; that one re-arms the poller.
+func (c *conn) readable() {
+	if c.reading.CompareAndSwap(false, true) {
+		c.read()
+	}
+}
+
+// read reads complete frames, as long as bytes are buffered, then gives up
+// the reader and re-arms the poller. The caller owns the reader.
+func (c *conn) read() {
+	c.nc.SetReadDeadline(time.Now().Add(frameWait))
+	for {
+		typ, data, err := c.next()
+		if err != nil {
+			c.finish(err)
+			return
+		}
+		if typ != 0 {
+			c.onMessage(typ, data)
+		}
+		if !c.src.buffered() {
+			break
+		}
+	}
+
+	c.reading.Store(false)
+	if err := c.poller.rearm(c); err != nil && c.reading.CompareAndSwap(false, true) {
+		c.finish(err)
+	}
+}
+
+// finish ends a polled connection once, by its reader or by CloseNow.
+func (c *conn) finish(err error) {
+	c.closeOnce.Do(func() {
+		c.poller.remove(c)
+		c.nc.Close()
+		c.onClose(err)
+	})
+}
+
+// fileDescriptor returns the socket of nc, which must be a plain network
+// connection: a TLS one buffers records the descriptor does not show.
+func fileDescriptor(nc net.Conn) (int, error) {
+	sc, ok := nc.(syscall.Conn)
+	if !ok {
+		return 0, errNotPollable
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	fd := -1
+	if err := raw.Control(func(s uintptr) { fd = int(s) }); err != nil {
+		return 0, err
+	}
+	return fd, nil
+}
+
+var errNotPollable = errors.New("netpoll: connection has no pollable descriptor")
+
+// source reads the bytes buffered during the handshake, then the socket.
+type source struct {
+	br *bufio.Reader // Dropped once drained
+	nc net.Conn
+}
+
+func (s *source) Read(p []byte) (int, error) {
+	if s.br != nil {
+		if s.br.Buffered() > 0 {
+			return s.br.Read(p)
+		}
+		s.br = nil
+	}
+	return s.nc.Read(p)
+}
+
+// buffered reports whether bytes are waiting that the socket no longer shows.
+func (s *source) buffered() bool {
+	return s.br != nil && s.br.Buffered() > 0
+}
+
+func opCode(typ transport.MessageType) ws.OpCode {
+	if typ == transport.MessageBinary {
+		return ws.OpBinary
+	}
+	return ws.OpText
+}
+
+func controlDeadline(ctx context.Context) time.Time {
+	if deadline, ok := ctx.Deadline(); ok {
+		return deadline
+	}
+	return time.Now().Add(controlWait)
+}
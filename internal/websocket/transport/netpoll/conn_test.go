@@ -0,0 +1,257 @@
+package netpoll_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"notification-srv/internal/websocket/transport"
+	"notification-srv/internal/websocket/transport/netpoll"
+
+	"github.com/gobwas/ws"
+	gorilla "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// accept serves one upgrade with the netpoll transport and returns the
+// server's end of the connection and the client's.
+func accept(t *testing.T, subprotocols ...string) (transport.Conn, *gorilla.Conn) {
+	t.Helper()
+
+	conns := make(chan transport.Conn, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := netpoll.New().Upgrade(w, r, transport.Options{Subprotocols: []string{"smap.delta.v1", "json"}})
+		if err == nil {
+			conns <- conn
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	dialer := gorilla.Dialer{Subprotocols: subprotocols}
+	client, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	select {
+	case conn := <-conns:
+		t.Cleanup(func() { conn.CloseNow() })
+		return conn, client
+	case <-time.After(5 * time.Second):
+		t.Fatal("upgrade timed out")
+		return nil, nil
+	}
+}
+
+// polled is what a polled connection delivered.
+type polled struct {
+	messages chan string
+	closed   chan error
+}
+
+func poll(t *testing.T, conn transport.Conn) *polled {
+	t.Helper()
+	p := &polled{messages: make(chan string, 16), closed: make(chan error, 2)}
+	err := conn.(transport.Poller).Poll(func(typ transport.MessageType, data []byte) {
+		p.messages <- string(data)
+	}, func(err error) {
+		p.closed <- err
+	})
+	require.NoError(t, err)
+	return p
+}
+
+func (p *polled) message(t *testing.T) string {
+	t.Helper()
+	select {
+	case m := <-p.messages:
+		return m
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message")
+		return ""
+	}
+}
+
+func (p *polled) close(t *testing.T) error {
+	t.Helper()
+	select {
+	case err := <-p.closed:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("onClose not called")
+		return nil
+	}
+}
+
+func TestPollDeliversMessagesInOrder(t *testing.T) {
+	conn, client := accept(t)
+	conn.SetReadLimit(512)
+	p := poll(t, conn)
+
+	for _, m := range []string{"one", "two", "three"} {
+		require.NoError(t, client.WriteMessage(gorilla.TextMessage, []byte(m)))
+	}
+	assert.Equal(t, "one", p.message(t))
+	assert.Equal(t, "two", p.message(t))
+	assert.Equal(t, "three", p.message(t))
+
+	// A connection is re-armed after a pause
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, client.WriteMessage(gorilla.TextMessage, []byte("four")))
+	assert.Equal(t, "four", p.message(t))
+
+	require.NoError(t, client.WriteMessage(gorilla.CloseMessage, gorilla.FormatCloseMessage(gorilla.CloseNormalClosure, "bye")))
+	assert.Equal(t, transport.CloseNormalClosure, transport.CloseStatus(p.close(t)))
+}
+
+func TestPollReadsFramesSentWithTheHandshake(t *testing.T) {
+	conns := make(chan transport.Conn, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := netpoll.New().Upgrade(w, r, transport.Options{})
+		if err == nil {
+			conns <- conn
+		}
+	}))
+	defer server.Close()
+
+	nc, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	defer nc.Close()
+
+	// Request and first frame in one write, so the server reads both at once
+	frame, err := ws.CompileFrame(ws.MaskFrame(ws.NewTextFrame([]byte("early"))))
+	require.NoError(t, err)
+	request := "GET / HTTP/1.1\r\nHost: test\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n"
+	_, err = nc.Write(append([]byte(request), frame...))
+	require.NoError(t, err)
+
+	var conn transport.Conn
+	select {
+	case conn = <-conns:
+		defer conn.CloseNow()
+	case <-time.After(5 * time.Second):
+		t.Fatal("upgrade timed out")
+	}
+	assert.Equal(t, "early", poll(t, conn).message(t))
+}
+
+func TestPollAnswersPings(t *testing.T) {
+	conn, client := accept(t)
+	p := poll(t, conn)
+
+	// The client only answers pings while reading
+	go func() {
+		for {
+			if _, _, err := client.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, conn.Ping(ctx))
+
+	// And the server answers the client's
+	pong := make(chan string, 1)
+	client.SetPongHandler(func(data string) error {
+		pong <- data
+		return nil
+	})
+	require.NoError(t, client.WriteControl(gorilla.PingMessage, []byte("hi"), time.Now().Add(time.Second)))
+	select {
+	case data := <-pong:
+		assert.Equal(t, "hi", data)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no pong")
+	}
+
+	require.NoError(t, client.WriteMessage(gorilla.TextMessage, []byte("after pings")))
+	assert.Equal(t, "after pings", p.message(t))
+}
+
+func TestPollCloseNowEndsIdleConnection(t *testing.T) {
+	conn, _ := accept(t)
+	p := poll(t, conn)
+
+	require.NoError(t, conn.CloseNow())
+	assert.Error(t, p.close(t))
+	select {
+	case err := <-p.closed:
+		t.Fatalf("onClose called twice, again with %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPollReadLimit(t *testing.T) {
+	conn, client := accept(t)
+	conn.SetReadLimit(8)
+	p := poll(t, conn)
+
+	// One frame over the limit, and a message of frames that add up to more
+	require.NoError(t, client.WriteMessage(gorilla.TextMessage, []byte("longer than eight")))
+	assert.ErrorIs(t, p.close(t), netpoll.ErrReadLimit)
+
+	conn, client = accept(t)
+	conn.SetReadLimit(8)
+	p = poll(t, conn)
+	w, err := client.NextWriter(gorilla.TextMessage)
+	require.NoError(t, err)
+	for _, part := range []string{"12345", "67890"} {
+		_, err = w.Write([]byte(part))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	assert.ErrorIs(t, p.close(t), netpoll.ErrReadLimit)
+}
+
+func TestWriteAndRead(t *testing.T) {
+	conn, client := accept(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, conn.Write(ctx, transport.MessageText, []byte("hello")))
+	w, err := conn.Writer(ctx, transport.MessageText)
+	require.NoError(t, err)
+	long := strings.Repeat("x", 10000) // Fragmented by the writer's buffer
+	_, err = w.Write([]byte(long))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	_, data, err := client.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+	_, data, err = client.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, long, string(data))
+
+	require.NoError(t, client.WriteMessage(gorilla.BinaryMessage, []byte{1, 2}))
+	typ, data, err := conn.Read(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, transport.MessageBinary, typ)
+	assert.Equal(t, []byte{1, 2}, data)
+
+	// A cancelled ctx interrupts a read
+	readCtx, stop := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, stop)
+	_, _, err = conn.Read(readCtx)
+	assert.Error(t, err)
+
+	require.NoError(t, conn.Close(transport.CloseGoingAway, "restart"))
+	_, _, err = client.ReadMessage()
+	assert.True(t, gorilla.IsCloseError(err, gorilla.CloseGoingAway), "got %v", err)
+}
+
+func TestSubprotocolPrefersOurs(t *testing.T) {
+	conn, client := accept(t, "json", "smap.delta.v1")
+	assert.Equal(t, "smap.delta.v1", conn.Subprotocol())
+	assert.Equal(t, "smap.delta.v1", client.Subprotocol())
+
+	conn, _ = accept(t, "other")
+	assert.Empty(t, conn.Subprotocol())
+}
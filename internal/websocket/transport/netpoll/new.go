@@ -0,0 +1,66 @@
+package netpoll
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"notification-srv/internal/websocket/transport"
+
+	"github.com/gobwas/ws"
+)
+
+type upgrader struct{}
+
+// New returns the Upgrader backed by github.com/gobwas/ws. Its connections
+// implement transport.Poller: on Linux, a plain TCP connection is read from a
+// goroutine started when epoll reports it readable, so an idle connection
+// holds no reader goroutine. Compression and the buffer sizes of
+// transport.Options are not used; config refuses compression with this
+// library.
+func New() transport.Upgrader {
+	return upgrader{}
+}
+
+func (upgrader) Name() string {
+	return transport.LibraryNetpoll
+}
+
+func (upgrader) Upgrade(w http.ResponseWriter, r *http.Request, opts transport.Options) (transport.Conn, error) {
+	u := ws.HTTPUpgrader{
+		Timeout: opts.HandshakeTimeout,
+	}
+	if chosen := selectSubprotocol(r, opts.Subprotocols); chosen != "" {
+		u.Protocol = func(p string) bool {
+			return p == chosen
+		}
+	}
+
+	nc, rw, hs, err := u.Upgrade(r, w)
+	if err != nil {
+		// The error response was written to the hijacked connection
+		if nc != nil {
+			nc.Close()
+		}
+		return nil, err
+	}
+	return newConn(nc, rw.Reader, hs.Protocol), nil
+}
+
+// selectSubprotocol returns the first of ours the client offers, so our order
+// of preference wins as with the other libraries; gobwas/ws would take the
+// client's.
+func selectSubprotocol(r *http.Request, ours []string) string {
+	var offered []string
+	for _, v := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, p := range strings.Split(v, ",") {
+			offered = append(offered, strings.TrimSpace(p))
+		}
+	}
+	for _, p := range ours {
+		if slices.Contains(offered, p) {
+			return p
+		}
+	}
+	return ""
+}
@@ -0,0 +1,13 @@
+package netpoll
+
+// poller reports when polled connections turn readable. A connection is
+// reported once per add or rearm, by calling its readable method in a new
+// goroutine.
+type poller interface {
+	add(c *conn) error
+	rearm(c *conn) error
+
+	// remove stops watching c. It must be called before c's descriptor is
+	// closed, and may be called more than once.
+	remove(c *conn)
+}
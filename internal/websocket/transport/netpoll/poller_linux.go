@@ -0,0 +1,105 @@
+//go:build linux
+
+package netpoll
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// Readable or hung up; one-shot, so a connection is reported again only once
+// its reader re-arms it.
+const epollEvents = unix.EPOLLIN | unix.EPOLLRDHUP | unix.EPOLLONESHOT
+
+var (
+	shared     *epoll
+	sharedErr  error
+	sharedOnce sync.Once
+)
+
+// sharedPoller returns the process's epoll instance, started on first use.
+func sharedPoller() (poller, error) {
+	sharedOnce.Do(func() {
+		fd, err := unix.EpollCreate1(unix.EPOLL_CLOEXEC)
+		if err != nil {
+			sharedErr = fmt.Errorf("netpoll: epoll_create1: %w", err)
+			return
+		}
+		shared = &epoll{fd: fd, conns: make(map[int32]*conn)}
+		go shared.run()
+	})
+	if sharedErr != nil {
+		return nil, sharedErr
+	}
+	return shared, nil
+}
+
+// epoll watches the sockets of polled connections. The runtime's own poller
+// watches them too; the two instances do not interfere.
+type epoll struct {
+	fd int
+
+	mu    sync.Mutex
+	conns map[int32]*conn // By descriptor
+	gen   int32
+}
+
+func (e *epoll) add(c *conn) error {
+	e.mu.Lock()
+	e.gen++
+	c.gen = e.gen
+	e.conns[int32(c.fd)] = c
+	e.mu.Unlock()
+
+	if err := e.ctl(unix.EPOLL_CTL_ADD, c); err != nil {
+		e.remove(c)
+		return fmt.Errorf("netpoll: epoll_ctl add: %w", err)
+	}
+	return nil
+}
+
+func (e *epoll) rearm(c *conn) error {
+	return e.ctl(unix.EPOLL_CTL_MOD, c)
+}
+
+func (e *epoll) remove(c *conn) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conns[int32(c.fd)] != c {
+		return
+	}
+	delete(e.conns, int32(c.fd))
+	unix.EpollCtl(e.fd, unix.EPOLL_CTL_DEL, c.fd, nil)
+}
+
+func (e *epoll) ctl(op int, c *conn) error {
+	ev := unix.EpollEvent{Events: epollEvents, Fd: int32(c.fd), Pad: c.gen}
+	return unix.EpollCtl(e.fd, op, c.fd, &ev)
+}
+
+// run dispatches readiness to the connections for good.
+func (e *epoll) run() {
+	events := make([]unix.EpollEvent, 256)
+	for {
+		n, err := unix.EpollWait(e.fd, events, -1)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			// Only an invalid epoll descriptor fails here; every polled
+			// connection would hang
+			panic(fmt.Sprintf("netpoll: epoll_wait: %v", err))
+		}
+
+		e.mu.Lock()
+		for _, ev := range events[:n] {
+			// An event of a removed connection may name a new one on the same fd
+			if c := e.conns[ev.Fd]; c != nil && c.gen == ev.Pad {
+				go c.readable()
+			}
+		}
+		e.mu.Unlock()
+	}
+}
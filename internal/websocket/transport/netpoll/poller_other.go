@@ -0,0 +1,10 @@
+//go:build !linux
+
+package netpoll
+
+import "errors"
+
+// sharedPoller fails off Linux, so connections are read by a goroutine each.
+func sharedPoller() (poller, error) {
+	return nil, errors.New("netpoll: readiness polling needs Linux")
+}
@@ -0,0 +1,85 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Libraries selectable with websocket.transport.
+const (
+	LibraryGorilla = "gorilla" // github.com/gorilla/websocket
+	LibraryCoder   = "coder"   // github.com/coder/websocket, formerly nhooyr.io/websocket
+	LibraryNetpoll = "netpoll" // github.com/gobwas/ws, reads driven by epoll on Linux
+)
+
+// MessageType is the type of a data message (RFC 6455 opcodes).
+type MessageType int
+
+const (
+	MessageText   MessageType = 1
+	MessageBinary MessageType = 2
+)
+
+// Close codes (RFC 6455) the service sends or tells apart.
+const (
+	CloseNormalClosure    = 1000
+	CloseGoingAway        = 1001
+	CloseProtocolError    = 1002
+	CloseNoStatusReceived = 1005
+	CloseAbnormalClosure  = 1006
+	ClosePolicyViolation  = 1008
+	CloseServiceRestart   = 1012
+	CloseTryAgainLater    = 1013
+)
+
+// Options configure a handshake. Origins are not checked.
+type Options struct {
+	Subprotocols []string // Offered to the client, in order of preference
+	Compression  bool     // Negotiate permessage-deflate without context takeover
+
+	// Honoured by gorilla only
+	ReadBufferSize   int
+	WriteBufferSize  int
+	HandshakeTimeout time.Duration
+}
+
+// CloseError is returned by Read once the peer closed the connection. An
+// abrupt disconnect reads as CloseAbnormalClosure with gorilla and as a plain
+// I/O error with coder.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return "websocket: close " + strconv.Itoa(e.Code) + " " + e.Reason
+}
+
+// CloseStatus returns the close code of err, or -1 if it is not a CloseError.
+func CloseStatus(err error) int {
+	var ce *CloseError
+	if errors.As(err, &ce) {
+		return ce.Code
+	}
+	return -1
+}
+
+// IsUpgrade reports whether r asks for a WebSocket handshake.
+func IsUpgrade(r *http.Request) bool {
+	return headerHasToken(r.Header, "Connection", "upgrade") &&
+		headerHasToken(r.Header, "Upgrade", "websocket")
+}
+
+func headerHasToken(h http.Header, key, token string) bool {
+	for _, v := range h.Values(key) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -4,113 +4,34 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
-	"notification-srv/internal/alert"
+	telemetryUC "notification-srv/internal/telemetry/usecase"
 	wsConfig "notification-srv/internal/websocket/delivery/http" // Alias to avoid conflict
+	"notification-srv/internal/websocket/transport/netpoll"
 	"notification-srv/internal/websocket/usecase"
+	"notification-srv/pkg/notificationtest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
-	"github.com/smap-hcmut/shared-libs/go/auth"
-	"github.com/smap-hcmut/shared-libs/go/log"
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
-// --- Mocks ---
-
-type MockLogger struct {
-	mock.Mock
-}
-
-func (m *MockLogger) Info(ctx context.Context, args ...any)                     {}
-func (m *MockLogger) Infof(ctx context.Context, template string, args ...any)   {}
-func (m *MockLogger) Warn(ctx context.Context, args ...any)                     {}
-func (m *MockLogger) Warnf(ctx context.Context, template string, args ...any)   {}
-func (m *MockLogger) Error(ctx context.Context, args ...any)                    {}
-func (m *MockLogger) Errorf(ctx context.Context, template string, args ...any)  {}
-func (m *MockLogger) Fatal(ctx context.Context, args ...any)                    {}
-func (m *MockLogger) Fatalf(ctx context.Context, template string, args ...any)  {}
-func (m *MockLogger) Debug(ctx context.Context, args ...any)                    {}
-func (m *MockLogger) Debugf(ctx context.Context, template string, args ...any)  {}
-func (m *MockLogger) DPanic(ctx context.Context, args ...any)                   {}
-func (m *MockLogger) DPanicf(ctx context.Context, template string, args ...any) {}
-func (m *MockLogger) Panic(ctx context.Context, args ...any)                    {}
-func (m *MockLogger) Panicf(ctx context.Context, template string, args ...any)  {}
-
-func (m *MockLogger) WithTrace(ctx context.Context) log.Logger {
-	return m
-}
-
-type MockAlertUC struct {
-	mock.Mock
-}
-
-func (m *MockAlertUC) DispatchCrisisAlert(ctx context.Context, input alert.CrisisAlertInput) error {
-	args := m.Called(ctx, input)
-	return args.Error(0)
-}
-
-func (m *MockAlertUC) DispatchDataOnboarding(ctx context.Context, input alert.DataOnboardingInput) error {
-	args := m.Called(ctx, input)
-	return args.Error(0)
-}
-
-func (m *MockAlertUC) DispatchCampaignEvent(ctx context.Context, input alert.CampaignEventInput) error {
-	args := m.Called(ctx, input)
-	return args.Error(0)
-}
-
-type MockScopeManager struct {
-	mock.Mock
-}
-
-func (m *MockScopeManager) Verify(token string) (auth.Payload, error) {
-	args := m.Called(token)
-	return args.Get(0).(auth.Payload), args.Error(1)
-}
-
-func (m *MockScopeManager) VerifyWithTrace(ctx context.Context, token string) (auth.Payload, context.Context, error) {
-	args := m.Called(ctx, token)
-	return args.Get(0).(auth.Payload), ctx, args.Error(1)
-}
-
-func (m *MockScopeManager) CreateToken(payload auth.Payload) (string, error) {
-	args := m.Called(payload)
-	return args.String(0), args.Error(1)
-}
-
-func (m *MockScopeManager) CreateTokenWithTrace(ctx context.Context, payload auth.Payload) (string, context.Context, error) {
-	args := m.Called(ctx, payload)
-	return args.String(0), ctx, args.Error(1)
-}
-
-func (m *MockScopeManager) VerifyScope(scopeHeader string) (auth.Scope, error) {
-	args := m.Called(scopeHeader)
-	return args.Get(0).(auth.Scope), args.Error(1)
-}
-
-func (m *MockScopeManager) VerifyScopeWithTrace(ctx context.Context, scopeHeader string) (auth.Scope, error) {
-	args := m.Called(ctx, scopeHeader)
-	return args.Get(0).(auth.Scope), args.Error(1)
-}
-
 // --- Tests ---
 
 func TestWebSocketConnection(t *testing.T) {
 	// Setup
-	logger := &MockLogger{}
-	alertUC := &MockAlertUC{}
-	scopeMgr := &MockScopeManager{}
+	logger := notificationtest.Logger{}
+	alertUC := &notificationtest.AlertRecorder{}
+	scopeMgr := notificationtest.NewTokenManager()
 
-	// Mock Verify Token
-	scopeMgr.On("Verify", "valid_token").Return(auth.Payload{
-		UserID: "user_123",
-	}, nil)
+	// Accept the test token
+	scopeMgr.AddToken("valid_token", "user_123")
 
 	// Init UseCase
-	uc := usecase.New(logger, 100, alertUC)
+	uc := usecase.New(logger, usecase.Config{MaxConnections: 100}, alertUC, telemetryUC.New(logger, telemetryUC.Config{}), nil)
 	go uc.Run()
 	// defer uc.Shutdown(context.Background())
 
@@ -151,16 +72,16 @@ func TestWebSocketConnection(t *testing.T) {
 	}
 
 	// Verify Expectations
-	scopeMgr.AssertExpectations(t)
+	assert.Equal(t, 1, scopeMgr.Verified("valid_token"))
 }
 
 func TestWebSocketMissingToken(t *testing.T) {
 	// Setup
-	logger := &MockLogger{}
-	alertUC := &MockAlertUC{}
-	scopeMgr := &MockScopeManager{}
+	logger := notificationtest.Logger{}
+	alertUC := &notificationtest.AlertRecorder{}
+	scopeMgr := notificationtest.NewTokenManager()
 
-	uc := usecase.New(logger, 100, alertUC)
+	uc := usecase.New(logger, usecase.Config{MaxConnections: 100}, alertUC, telemetryUC.New(logger, telemetryUC.Config{}), nil)
 	handler := wsConfig.New(
 		uc,
 		scopeMgr,
@@ -189,3 +110,37 @@ func TestWebSocketMissingToken(t *testing.T) {
 	// Let's assert strictly on error existence first. status might be 400.
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 }
+
+func TestWebSocketNetpollTransport(t *testing.T) {
+	logger := notificationtest.Logger{}
+	scopeMgr := notificationtest.NewTokenManager()
+	scopeMgr.AddToken("valid_token", "user_123")
+
+	uc := usecase.New(logger, usecase.Config{MaxConnections: 100}, &notificationtest.AlertRecorder{}, telemetryUC.New(logger, telemetryUC.Config{}), nil)
+	go uc.Run()
+
+	handler := wsConfig.New(uc, scopeMgr, logger, wsConfig.WSConfig{MaxConnections: 10, Transport: netpoll.New()}, wsConfig.CookieConfig{}, "test")
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handler.RegisterRoutes(r.Group(""), nil)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws?token=valid_token", nil)
+	require.NoError(t, err)
+
+	// Client frames reach the connection through the poller
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"action":"ping"}`)))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"pong"`)
+
+	// Closing unregisters it
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	conn.Close()
+	assert.Eventually(t, func() bool {
+		stats, _ := uc.GetStats(context.Background())
+		return stats.ActiveConnections == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -1,6 +1,10 @@
 package websocket
 
-import "time"
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
 
 // --- Message Types ---
 type MessageType string
@@ -11,6 +15,79 @@ const (
 	MessageTypeCrisisAlert       MessageType = "CRISIS_ALERT"
 	MessageTypeCampaignEvent     MessageType = "CAMPAIGN_EVENT"
 	MessageTypeSystem            MessageType = "SYSTEM"
+	MessageTypeHeartbeat         MessageType = "HEARTBEAT"
+	MessageTypeProjectProgress   MessageType = "PROJECT_PROGRESS" // Snapshot pushed when a project-filtered connection opens
+
+	MessageTypeServiceAnnouncement MessageType = "SERVICE_ANNOUNCEMENT" // Operator banner from control:announcement
+
+	MessageTypeSecurityAlert     MessageType = "SECURITY_ALERT"     // Account security event, e.g. a new login
+	MessageTypeBillingEvent      MessageType = "BILLING_EVENT"      // Invoice, payment or plan change
+	MessageTypeSystemMaintenance MessageType = "SYSTEM_MAINTENANCE" // Scheduled maintenance window
+)
+
+// FilterableMessageTypes are the types a client may select with ?types= or a
+// subscribe frame. SYSTEM, SERVICE_ANNOUNCEMENT, SECURITY_ALERT,
+// SYSTEM_MAINTENANCE and HEARTBEAT frames are always delivered.
+var FilterableMessageTypes = map[MessageType]bool{
+	MessageTypeDataOnboarding:    true,
+	MessageTypeAnalyticsPipeline: true,
+	MessageTypeCrisisAlert:       true,
+	MessageTypeCampaignEvent:     true,
+	MessageTypeProjectProgress:   true,
+	MessageTypeBillingEvent:      true,
+}
+
+// PriorityMessageTypes take the priority lane: maintenance mode never
+// suppresses them, and a full send queue evicts its oldest frame for them
+// instead of refusing them.
+var PriorityMessageTypes = map[MessageType]bool{
+	MessageTypeSecurityAlert:     true,
+	MessageTypeBillingEvent:      true,
+	MessageTypeSystemMaintenance: true,
+}
+
+// --- Importance ---
+
+// Importance ranks how much a notification matters to the user. Clients may
+// ask for a minimum; channels that interrupt the user (push, email) should
+// only fire from ImportanceHigh up.
+type Importance string
+
+const (
+	ImportanceLow      Importance = "low"      // Progress ticks
+	ImportanceNormal   Importance = "normal"   // Finished work, informational alerts
+	ImportanceHigh     Importance = "high"     // Failures, warnings, system notices
+	ImportanceCritical Importance = "critical" // Critical crisis alerts
+)
+
+var importanceRanks = map[Importance]int{
+	ImportanceLow:      1,
+	ImportanceNormal:   2,
+	ImportanceHigh:     3,
+	ImportanceCritical: 4,
+}
+
+// Rank orders importances from 1 (low) to 4 (critical); it is 0 for an empty
+// or unknown importance.
+func (i Importance) Rank() int {
+	return importanceRanks[i]
+}
+
+// ParseImportance parses an importance name, case-insensitively.
+func ParseImportance(name string) (Importance, error) {
+	i := Importance(strings.ToLower(name))
+	if i.Rank() == 0 {
+		return "", ErrInvalidMessage
+	}
+	return i, nil
+}
+
+// DeliveryPolicy is the delivery guarantee of a message type.
+type DeliveryPolicy string
+
+const (
+	DeliveryAtMostOnce  DeliveryPolicy = "at_most_once"  // Fire-and-forget: a frame a connection misses is gone
+	DeliveryAtLeastOnce DeliveryPolicy = "at_least_once" // Resent until acked; kept in the offline queue while the user is away
 )
 
 // --- Channel Types ---
@@ -21,39 +98,577 @@ const (
 	ChannelTypeCampaign ChannelType = "campaign"
 	ChannelTypeAlert    ChannelType = "alert"
 	ChannelTypeSystem   ChannelType = "system"
+	ChannelTypeControl  ChannelType = "control"
+)
+
+// --- Control Events ---
+// Control channels carry lifecycle signals from other services (control:{event}[:{entity_id}]).
+// They are handled by the service itself and never forwarded as-is to clients.
+const (
+	ControlEventProjectDeleted   = "project_deleted"
+	ControlEventProjectCompleted = "project_completed" // The project's topic is cleaned up after a grace period
+	ControlEventProjectFailed    = "project_failed"    // Same as project_completed
+	ControlEventMaintenance      = "maintenance"       // control:maintenance:{on|off}, payload {"reason": "..."}
+	ControlEventBroadcast        = "broadcast"         // control:broadcast:{broadcast_id}, published by POST /admin/broadcast
+	ControlEventBan              = "ban"               // control:ban:{user|ip}, payload {"value": "..."}; closes banned connections
+	ControlEventAnnouncement     = "announcement"      // control:announcement, payload {"title", "body", "severity", "expires_at"}; replaces the active announcement
+	ControlEventTrace            = "trace"             // control:trace:{user_id}, payload {"expires_at": "..."}; a past expiry stops the trace
+)
+
+// Severities of a service announcement.
+const (
+	AnnouncementSeverityInfo     = "info"
+	AnnouncementSeverityWarning  = "warning"
+	AnnouncementSeverityCritical = "critical"
+)
+
+// Maintenance banner events, sent to clients as SYSTEM notifications.
+const (
+	SystemEventMaintenanceStarted = "maintenance_started"
+	SystemEventMaintenanceEnded   = "maintenance_ended"
+)
+
+// SystemEventBroadcast marks an operator message sent with POST /admin/broadcast.
+const SystemEventBroadcast = "broadcast"
+
+// --- Broadcast Segments ---
+// Kinds of SegmentTarget a broadcast may address. "all" and "users" are resolved
+// by the service; every other kind is passed to the SegmentResolver.
+const (
+	SegmentAll   = "all"   // Every connected user
+	SegmentUsers = "users" // The user IDs listed in the segment
+	SegmentPlan  = "plan"  // Users on the plan named by Value
+	SegmentOrg   = "org"   // Users with a connection filtered to a project of the org in Value
 )
 
+// --- Deny-list ---
+// Kinds of deny-list entries.
+const (
+	BanKindUser = "user" // A user ID
+	BanKindIP   = "ip"   // An IP range in CIDR notation; a single IP is stored as /32 or /128
+)
+
+// --- Backpressure ---
+
+// BackpressureSignal is published on backpressure:{channel} when connections
+// keep dropping the channel's frames because their send queues are full.
+// Publishers should wait SuggestedIntervalMs between messages on the channel
+// until signals stop.
+type BackpressureSignal struct {
+	Channel             string    `json:"channel"`               // Inbound channel, e.g. project:{project_id}:user:{user_id}
+	DropRate            float64   `json:"drop_rate"`             // Share of the channel's frames dropped in the window (0-1)
+	Messages            int       `json:"messages"`              // Messages received on the channel in the window
+	WindowMs            int64     `json:"window_ms"`             // Length of the window
+	SuggestedIntervalMs int64     `json:"suggested_interval_ms"` // Publish interval connections would have kept up with
+	Timestamp           time.Time `json:"timestamp"`
+}
+
+// --- Close Codes ---
+// Application close codes (4000-4999) sent to clients before the server closes a connection.
+const (
+	CloseCodeTopicGone = 4040 // The project the connection is filtered to no longer exists
+	CloseCodeBanned    = 4030 // The user or IP of the connection was put on the deny-list
+	CloseCodeReplaced  = 4090 // A newer connection of the user took the place of this one (quota policy kick_oldest)
+
+	CloseCodeUpgradeRequired = 4260 // The client build is below the minimum version; the reason carries the upgrade URL
+)
+
+// --- Hub Events ---
+// Lifecycle events published by the Hub to in-process subscribers (see UseCase.SubscribeHubEvents).
+
+// HubEventType identifies a Hub lifecycle event.
+type HubEventType string
+
+const (
+	HubEventConnectionOpened HubEventType = "connection_opened"
+	HubEventConnectionClosed HubEventType = "connection_closed"
+	HubEventTopicSubscribed  HubEventType = "topic_subscribed" // A connection filtered to a project opened
+	HubEventMessageDelivered HubEventType = "message_delivered"
+	HubEventMessageDropped   HubEventType = "message_dropped"
+)
+
+// Reasons of a HubEventMessageDropped.
+const (
+	DropReasonBufferFull  = "buffer_full" // Some recipients' send buffers were full
+	DropReasonMaintenance = "maintenance" // Suppressed by maintenance mode
+
+	DropReasonInvalidTransition = "invalid_transition" // Status does not follow the job's previous one (transitions mode suppress)
+	DropReasonDuplicateTerminal = "duplicate_terminal" // Repeats the job's terminal status within the dedupe window
+)
+
+// HubEvent is one Hub lifecycle event. Fields not relevant to the type are empty.
+type HubEvent struct {
+	Type HubEventType
+	Time time.Time
+
+	// Connection events
+	UserID          string
+	ProjectID       string // Project filter of the connection, or project of the message
+	Stream          bool   // In-process stream (e.g. GraphQL) rather than a WebSocket
+	Public          bool   // Anonymous viewer of a public project
+	DelegatedBy     string // User watching UserID's stream through delegated access
+	UserConnections int    // The user's open connections after the event
+	RemoteIP        string
+	Fingerprint     ClientFingerprint
+
+	// Topic and message events
+	Topic       string // e.g. project:{project_id}
+	MessageID   string
+	MessageType MessageType
+	Recipients  int    // Connections the message was queued to; -1 for broadcasts
+	Dropped     int    // Connections skipped because their send buffer was full
+	DropReason  string // HubEventMessageDropped only
+}
+
+// HubEventHandler receives Hub events. Each subscriber gets its events in
+// order on its own goroutine; events are dropped if it falls behind.
+type HubEventHandler func(HubEvent)
+
+// --- Inbound (client -> server) Protocol ---
+
+// InboundAction is the "action" of a control frame sent by the client.
+type InboundAction string
+
+const (
+	InboundActionPing      InboundAction = "ping"
+	InboundActionFocus     InboundAction = "focus"     // project_id on screen; empty when no project view is focused
+	InboundActionSubscribe InboundAction = "subscribe" // Replaces the message type and importance filters; empty receives all
+	InboundActionAck       InboundAction = "ack"       // Confirms a frame sent with ack_required
+)
+
+// InboundMessage is the envelope every client frame must follow.
+type InboundMessage struct {
+	Action    InboundAction `json:"action"`
+	ProjectID string        `json:"project_id,omitempty"` // focus only
+	Types     []MessageType `json:"types,omitempty"`      // subscribe only
+	ID        string        `json:"id,omitempty"`         // ack only
+
+	MinImportance Importance `json:"min_importance,omitempty"` // subscribe only
+}
+
+// Error frame codes returned to clients for protocol violations.
+const (
+	ErrorCodeBadRequest    = "bad_request"
+	ErrorCodeUnknownAction = "unknown_action"
+)
+
+// ErrorFrame is sent to the client when one of its frames is rejected.
+type ErrorFrame struct {
+	Type   string `json:"type"` // Always "error"
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// DeltaSubprotocol is the WebSocket subprotocol a client offers to receive
+// DeltaFrames instead of repeating full progress frames.
+const DeltaSubprotocol = "smap.delta.v1"
+
+// DeltaFrame replaces a notification frame for delta-encoding clients. Applying
+// Patch to the frame with ID BaseID gives the full frame, which becomes the base
+// of the next delta with the same collapse key.
+type DeltaFrame struct {
+	Type        string          `json:"type"` // Always "delta"
+	CollapseKey string          `json:"collapse_key"`
+	BaseID      string          `json:"base_id"`
+	Patch       json.RawMessage `json:"patch"` // RFC 7386 JSON Merge Patch of the whole frame
+}
+
+// PongFrame answers an application-level {"action":"ping"}.
+type PongFrame struct {
+	Type      string    `json:"type"` // Always "pong"
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ProtocolVersion is the version of the client protocol: frames, client
+// actions and close codes. It only changes on incompatible changes.
+const ProtocolVersion = "1"
+
+// InboundActions are the client frame actions, in the order they were added.
+var InboundActions = []InboundAction{InboundActionPing, InboundActionFocus, InboundActionSubscribe, InboundActionAck}
+
+// Protocol describes the client protocol and what this server supports of it,
+// so clients can feature-detect. Served at GET /protocol and, with
+// websocket.hello, sent as the first frame of a connection.
+type Protocol struct {
+	Version           string
+	Encodings         []string      // json, then the extensions and subprotocols a client may negotiate
+	Ack               bool          // Frames may carry ack_required
+	Resume            bool          // Frames carry seq and missed ones can be fetched again
+	MaxMessageSize    int           // Largest client frame, in bytes
+	HeartbeatInterval time.Duration // Between pings
+	HeartbeatFrames   bool          // A HEARTBEAT frame follows every ping
+	Actions           []InboundAction
+}
+
+// HelloFrame is the first frame of a connection with websocket.hello on. It
+// carries the same document as GET /protocol, except that ack is false on
+// connections that do not ack for their user.
+type HelloFrame struct {
+	Type                string          `json:"type"` // Always "hello"
+	Version             string          `json:"version"`
+	Encodings           []string        `json:"encodings"`
+	Ack                 bool            `json:"ack"`
+	Resume              bool            `json:"resume"`
+	MaxMessageSize      int             `json:"max_message_size"`
+	HeartbeatIntervalMs int64           `json:"heartbeat_interval_ms"`
+	HeartbeatFrames     bool            `json:"heartbeat_frames"`
+	Actions             []InboundAction `json:"actions"`
+}
+
 // --- UseCase Inputs ---
 
 // ProcessMessageInput is the raw input from Redis
 type ProcessMessageInput struct {
 	Channel string
 	Payload []byte
+	Region  string // Upstream region the message was read from; empty for single-region deployments
 }
 
 // ConnectionInput represents a new connection attempt
 type ConnectionInput struct {
+	UserID      string
+	ProjectID   string      // Optional filter
+	ProjectOnly bool        // Opened with a subscription token: only ProjectID's messages and broadcasts
+	UserAgent   string      // Client User-Agent, kept for diagnostics
+	Compression bool        // permessage-deflate negotiated during upgrade
+	Conn        interface{} // transport.Conn (handled as interface{} to avoid direct dependency in public type if preferred, or wrapped)
+
+	Types  []MessageType // Optional type filter; empty receives all types
+	Fields []string      // Optional payload fields to keep; empty sends full payloads
+	Delta  bool          // DeltaSubprotocol negotiated during upgrade
+
+	MinImportance Importance // Optional; less important messages of filterable types are not sent
+
+	RemoteIP    string // Client IP, for the deny-list and churn detection
+	ClientLabel string // Frontend build reported by the client, e.g. dashboard-v2
+
+	Fingerprint ClientFingerprint // Forwarded by the edge; empty unless websocket.fingerprint_headers are set
+
+	Public bool // Anonymous viewer of a public project; UserID is the one CheckPublic returned
+
+	DelegatedBy string // User acting as UserID, allowed by CheckDelegation; empty otherwise
+
+	Quota int // Connections UserID may hold on this replica, from CheckQuota; 0 = no quota
+}
+
+// ClientFingerprint identifies the client stack behind a connection, as
+// forwarded by the TLS-terminating edge. Fields not forwarded are empty.
+type ClientFingerprint struct {
+	TLS   string // TLS ClientHello fingerprint, e.g. a JA3 or JA4 hash
+	HTTP2 string // HTTP/2 fingerprint of the client's connection to the edge
+}
+
+// SubscribeInput registers an in-process stream with the same routing as a socket connection.
+type SubscribeInput struct {
+	UserID    string
+	ProjectID string        // Optional filter
+	UserAgent string        // Client User-Agent, kept for diagnostics
+	Types     []MessageType // Optional type filter; empty receives all types
+
+	// Optional, as on ConnectionInput
+	MinImportance Importance
+	Fields        []string
+	ClientLabel   string
+	RemoteIP      string
+	ProjectOnly   bool   // Opened with a subscription token: only ProjectID's messages and broadcasts
+	DelegatedBy   string // User watching UserID's stream through delegated access
+	Quota         int    // From CheckQuota; 0 = no quota
+}
+
+// ListConnectionsInput filters the admin connection listing.
+type ListConnectionsInput struct {
+	UserID string // Optional; empty lists all connections
+}
+
+// ListProjectNotificationsInput asks for buffered frames of a project topic after a sequence number.
+type ListProjectNotificationsInput struct {
+	ProjectID string
+	AfterSeq  uint64
+}
+
+// CheckContractInput is a sample publisher message to verify without delivering it.
+type CheckContractInput struct {
+	Channel string // Optional Redis channel the message would be published to
+	Payload []byte
+}
+
+// GetChannelStatsInput filters the per-channel statistics.
+type GetChannelStatsInput struct {
+	Pattern string // Glob over channel patterns, e.g. project:*; empty matches all
+}
+
+// GetStatsHistoryInput selects the recent samples of the stats history.
+type GetStatsHistoryInput struct {
+	Window time.Duration // Samples taken within it; zero returns the whole history
+}
+
+// SetMaintenanceInput switches maintenance mode on or off on every replica.
+type SetMaintenanceInput struct {
+	Enabled bool
+	Reason  string // Shown to clients in the banner
+}
+
+// TraceUserInput turns on targeted tracing of a user for TTL.
+type TraceUserInput struct {
+	UserID string
+	TTL    time.Duration
+}
+
+// UntraceUserInput turns targeted tracing of a user off.
+type UntraceUserInput struct {
+	UserID string
+}
+
+// Segment selects the users a broadcast is sent to.
+type Segment struct {
+	Kind    string   // One of the Segment* kinds, or a kind known to the SegmentResolver
+	Value   string   // Plan name, org ID, ...; unused by "all" and "users"
+	UserIDs []string // Only for "users"
+}
+
+// BroadcastInput is an operator message for a segment of users.
+type BroadcastInput struct {
+	Segment Segment
+	Title   string
+	Message string
+}
+
+// BanInput puts a user ID or an IP range on the deny-list.
+type BanInput struct {
+	Kind  string // BanKindUser or BanKindIP
+	Value string // User ID, IP or CIDR range
+	TTL   time.Duration
+}
+
+// UnbanInput lifts a ban. Value must match the banned user ID or range.
+type UnbanInput struct {
+	Kind  string
+	Value string
+}
+
+// CheckBanInput is checked against the deny-list; empty fields are skipped.
+type CheckBanInput struct {
+	UserID   string
+	RemoteIP string
+}
+
+// CheckDecoyInput is a project subscription checked against the decoy project IDs.
+type CheckDecoyInput struct {
 	UserID    string
-	ProjectID string      // Optional filter
-	Conn      interface{} // *websocket.Conn (handled as interface{} to avoid direct dependency in public type if preferred, or wrapped)
+	ProjectID string // Empty is never a decoy
+	RemoteIP  string
+	Transport string // Endpoint subscribed through, e.g. websocket or graphql
+}
+
+// CheckPublicInput is an anonymous subscription to a public project.
+type CheckPublicInput struct {
+	ProjectID string
+	RemoteIP  string
+}
+
+// CheckPublicOutput admits an anonymous viewer.
+type CheckPublicOutput struct {
+	UserID string // Hub identity shared by the project's anonymous viewers
+}
+
+// DelegationInput is a user asking to subscribe to another user's stream.
+type DelegationInput struct {
+	ActorID  string         // Token owner
+	UserID   string         // User whose stream is requested
+	Claims   map[string]any // Verified claims of the actor's token
+	RemoteIP string
+}
+
+// QuotaInput identifies the user a connection quota is resolved for.
+type QuotaInput struct {
+	UserID string
+	Claims map[string]any // Claims of the user's verified token; nil for a delegated connection
 }
 
 // --- UseCase Outputs ---
 
+// ChannelStats are the counters of one channel pattern over the stats window.
+type ChannelStats struct {
+	Channel         string // Channel pattern with IDs as *, e.g. project:*:user:*
+	Received        uint64 // Messages read from Redis
+	TransformErrors uint64 // Messages rejected by type detection, validation or transformation
+	Delivered       uint64 // Frames queued to connections (broadcasts not included)
+	Dropped         uint64 // Frames not queued: full send buffers and maintenance mode
+	NoRecipients    uint64 // Messages for users with no open connection
+}
+
+// GetChannelStatsOutput lists the matching channel patterns, sorted by name.
+type GetChannelStatsOutput struct {
+	Window   time.Duration
+	Channels []ChannelStats
+}
+
+// StatsSample is the Hub state of this replica at one point in time, with the
+// message counts since the previous sample.
+type StatsSample struct {
+	Time              time.Time
+	ActiveConnections int
+	UniqueUsers       int
+	Received          uint64 // Messages read from Redis
+	TransformErrors   uint64 // Messages rejected by type detection, validation or transformation
+	Delivered         uint64 // Frames queued to connections (broadcasts not included)
+	Dropped           uint64 // Frames not queued: full send buffers and maintenance mode
+}
+
+// GetStatsHistoryOutput lists the samples within the window, oldest first.
+type GetStatsHistoryOutput struct {
+	Interval time.Duration // Time between samples
+	Samples  []StatsSample
+}
+
+// MaintenanceStatus is the current maintenance mode of this replica.
+type MaintenanceStatus struct {
+	Enabled bool
+	Reason  string
+	Since   time.Time // Zero when disabled
+}
+
+// UserTrace is a user under targeted tracing.
+type UserTrace struct {
+	UserID    string
+	ExpiresAt time.Time
+}
+
+// ListUserTracesOutput holds the active traces of this replica.
+type ListUserTracesOutput struct {
+	Traces []UserTrace
+}
+
+// SegmentTarget is a resolved segment. A connection is targeted if its user is
+// listed or it is filtered to one of the projects; the other connections of
+// such a user are targeted too.
+type SegmentTarget struct {
+	All        bool
+	UserIDs    []string
+	ProjectIDs []string
+}
+
+// BroadcastOutput describes an accepted broadcast. Delivery happens on every
+// replica after the call returns, so only the resolved segment is reported.
+type BroadcastOutput struct {
+	ID         string // ID of the SYSTEM frame clients receive
+	All        bool
+	UserIDs    int // Users the segment resolved to
+	ProjectIDs int // Projects the segment resolved to
+}
+
+// BanOutput describes a stored ban.
+type BanOutput struct {
+	Kind      string
+	Value     string // Normalized: IPs become CIDR ranges
+	ExpiresAt time.Time
+}
+
+// ListProjectNotificationsOutput holds the frames a client missed, oldest first.
+type ListProjectNotificationsOutput struct {
+	Epoch     string
+	LastSeq   uint64            // Latest sequence number assigned on the topic
+	Frames    []json.RawMessage // Frames exactly as they were sent
+	Truncated bool              // Some frames after AfterSeq are no longer buffered; the client must resync
+}
+
+// CheckContractOutput is the full validation/transform result of a sample message.
+type CheckContractOutput struct {
+	ChannelType ChannelType
+	MessageType MessageType
+	Errors      []string            // Empty when the message would be delivered under strict validation
+	Output      *NotificationOutput // Normalized frame clients would receive; nil if the payload cannot be decoded
+}
+
 type HubStats struct {
 	ActiveConnections int
 	TotalUniqueUsers  int
 }
 
+// ConnectionInfo describes a single live connection for the admin listing.
+type ConnectionInfo struct {
+	UserID      string
+	ProjectID   string
+	ProjectOnly bool   // Opened with a subscription token or by an anonymous viewer
+	Public      bool   // Anonymous viewer of a public project
+	DelegatedBy string // User watching UserID's stream through delegated access
+	UserAgent   string
+	Compression bool
+	ConnectedAt time.Time
+	RTT         time.Duration // Smoothed ping/pong round-trip time; zero until the first pong
+	Focus       string        // Project view reported on screen by the client; empty if none
+	Types       []MessageType // Message type filter, sorted; empty receives all types
+	Fields      []string      // Payload field projection, sorted; empty sends full payloads
+	Delta       bool          // Receives DeltaFrames for progress updates
+	RemoteIP    string
+	ClientLabel string
+	Fingerprint ClientFingerprint
+
+	MinImportance Importance // Importance filter; empty receives all
+
+	SendQueueLen int // Frames waiting in the send queue
+	SendQueueCap int
+
+	Drops         map[string]uint64 // Frames that did not reach the connection, by reason (buffer_full, filtered, closed)
+	TopDropReason string            // Reason with the most drops; empty if none
+}
+
+type ListConnectionsOutput struct {
+	Connections []ConnectionInfo
+}
+
 // NotificationOutput is the final payload sent to the client
 type NotificationOutput struct {
+	ID        string      `json:"id"`               // Unique message ID, echoed back by clients in latency reports
+	Topic     string      `json:"topic,omitempty"`  // Sequence topic, e.g. project:{project_id} (project/campaign channels only)
+	Seq       uint64      `json:"seq,omitempty"`    // Per-topic sequence number, contiguous for the receiving user
+	Epoch     string      `json:"epoch,omitempty"`  // Sequence epoch; sequences restart when it changes
+	Region    string      `json:"region,omitempty"` // Upstream region the message came from (multi-region deployments)
 	Type      MessageType `json:"type"`
 	Timestamp time.Time   `json:"timestamp"`
 	Payload   interface{} `json:"payload"`
+
+	// Notifications with the same group key belong together (e.g. project:{project_id});
+	// one with the same collapse key replaces the previous one instead of stacking.
+	GroupKey    string `json:"group_key,omitempty"`
+	CollapseKey string `json:"collapse_key,omitempty"`
+
+	// Importance of a message from a publisher, set by the transform from its type and status
+	Importance Importance `json:"importance,omitempty"`
+
+	// Set on at-least-once frames: the client must answer {"action": "ack", "id": ...}
+	AckRequired bool `json:"ack_required,omitempty"`
+}
+
+// HeartbeatPayload lets clients display connection quality.
+type HeartbeatPayload struct {
+	ServerTime time.Time `json:"server_time"`
+	RTTMs      float64   `json:"rtt_ms"`
+}
+
+// ServiceAnnouncementPayload is an operator banner, e.g. "crawling is degraded".
+// It is sent to every client when published and to new clients while active.
+// When it is withdrawn clients get it again with Active false.
+type ServiceAnnouncementPayload struct {
+	ID        string     `json:"id"` // Same on every replica; a new announcement has a new ID
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Severity  string     `json:"severity"`             // info, warning or critical
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Clients hide the banner after this; nil if it has no expiry
+	Active    bool       `json:"active"`
 }
 
 // --- Payload Types (for Transformation) ---
 
+// ProjectProgressPayload is a snapshot of the collector's current project state,
+// pushed once on connect so the UI does not wait for the next publisher tick.
+type ProjectProgressPayload struct {
+	ProjectID string          `json:"project_id"`
+	Snapshot  bool            `json:"snapshot"`         // Always true: this is a backfill, not a live update
+	State     json.RawMessage `json:"state"`            // Collector state as stored, passed through unchanged
+	Latest    json.RawMessage `json:"latest,omitempty"` // Last frame delivered on the user's project topic
+}
+
 type DataOnboardingPayload struct {
 	ProjectID   string `json:"project_id"`
 	SourceID    string `json:"source_id"`
@@ -64,6 +679,7 @@ type DataOnboardingPayload struct {
 	RecordCount int    `json:"record_count"`
 	ErrorCount  int    `json:"error_count"`
 	Message     string `json:"message"`
+	Force       bool   `json:"force,omitempty"` // Deliver even if it repeats the job's last terminal status
 }
 
 type AnalyticsPipelinePayload struct {
@@ -76,6 +692,7 @@ type AnalyticsPipelinePayload struct {
 	Progress        int    `json:"progress"`
 	CurrentPhase    string `json:"current_phase"`
 	EstimatedTimeMs int64  `json:"estimated_time_ms"`
+	Force           bool   `json:"force,omitempty"` // Deliver even if it repeats the job's last terminal status
 }
 
 type CrisisAlertPayload struct {
@@ -100,4 +717,42 @@ type CampaignEventPayload struct {
 	ResourceName string `json:"resource_name"`
 	ResourceURL  string `json:"resource_url"`
 	Message      string `json:"message"`
+	Force        bool   `json:"force,omitempty"` // Deliver even if it repeats the campaign's last terminal event
+}
+
+// SecurityAlertPayload is an event on the user's account the user should know
+// about, e.g. a login from a new device or a revoked API key.
+type SecurityAlertPayload struct {
+	AlertID       string     `json:"alert_id"`
+	SecurityEvent string     `json:"security_event"` // e.g. new_login, password_changed, api_key_revoked
+	Severity      string     `json:"severity"`       // info, warning or critical
+	Message       string     `json:"message"`
+	IPAddress     string     `json:"ip_address,omitempty"`
+	Location      string     `json:"location,omitempty"`
+	UserAgent     string     `json:"user_agent,omitempty"`
+	OccurredAt    *time.Time `json:"occurred_at,omitempty"`
+	ActionURL     string     `json:"action_url,omitempty"` // e.g. the session review page
+}
+
+// BillingEventPayload is an invoice, payment or subscription change.
+type BillingEventPayload struct {
+	BillingEvent string `json:"billing_event"` // e.g. invoice_paid, payment_failed, plan_changed
+	InvoiceID    string `json:"invoice_id,omitempty"`
+	Plan         string `json:"plan,omitempty"`
+	Amount       int64  `json:"amount,omitempty"`   // In the currency's minor unit, e.g. cents
+	Currency     string `json:"currency,omitempty"` // ISO 4217, e.g. USD; required with an amount
+	Message      string `json:"message"`
+	ActionURL    string `json:"action_url,omitempty"` // e.g. the payment method page
+}
+
+// SystemMaintenancePayload is a maintenance window of the platform, from
+// scheduled to completed or cancelled.
+type SystemMaintenancePayload struct {
+	MaintenanceID string     `json:"maintenance_id"` // Same for every update of the window
+	Status        string     `json:"status"`         // scheduled, started, completed or cancelled
+	Title         string     `json:"title"`
+	Message       string     `json:"message"`
+	StartsAt      *time.Time `json:"starts_at,omitempty"`
+	EndsAt        *time.Time `json:"ends_at,omitempty"`
+	Services      []string   `json:"services,omitempty"` // Affected services; empty = the whole platform
 }
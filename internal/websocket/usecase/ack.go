@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+)
+
+// Upper bound on an offline queue write, so a slow Redis never piles up goroutines.
+const offlineTimeout = 2 * time.Second
+
+// Upper bound on removing a project's frames from the offline queues.
+const offlinePurgeTimeout = 10 * time.Second
+
+// DefaultDeliveryPolicies are the delivery guarantees of the message types
+// addressed to a user. Progress updates of a running job are always at most
+// once; only the update that finishes the job is acked.
+var DefaultDeliveryPolicies = map[ws.MessageType]ws.DeliveryPolicy{
+	ws.MessageTypeDataOnboarding:    ws.DeliveryAtLeastOnce,
+	ws.MessageTypeAnalyticsPipeline: ws.DeliveryAtLeastOnce,
+	ws.MessageTypeCampaignEvent:     ws.DeliveryAtLeastOnce,
+	ws.MessageTypeCrisisAlert:       ws.DeliveryAtLeastOnce,
+	ws.MessageTypeSecurityAlert:     ws.DeliveryAtLeastOnce,
+	ws.MessageTypeBillingEvent:      ws.DeliveryAtLeastOnce,
+}
+
+// newAckTracker returns nil when delivery guarantees are off. A nil
+// *ackTracker delivers every message at most once.
+func newAckTracker(cfg DeliveryConfig) *ackTracker {
+	if !cfg.Enabled {
+		return nil
+	}
+	policies := make(map[ws.MessageType]ws.DeliveryPolicy)
+	for _, table := range []map[ws.MessageType]ws.DeliveryPolicy{DefaultDeliveryPolicies, cfg.Policies} {
+		for msgType, policy := range table {
+			policies[msgType] = policy
+		}
+	}
+	return &ackTracker{
+		cfg:      cfg,
+		policies: policies,
+		pending:  make(map[string]map[string]*pendingAck),
+	}
+}
+
+// reliable reports whether output is delivered at least once: it is addressed
+// to a user, its type's policy says so and it is not the progress update of a
+// running job.
+func (t *ackTracker) reliable(parsed ParsedChannel, output ws.NotificationOutput) bool {
+	if t == nil || parsed.UserID == "" || t.policies[output.Type] != ws.DeliveryAtLeastOnce {
+		return false
+	}
+	if _, status, _, ok := terminalOf(output); ok && status == "" {
+		return false
+	}
+	return true
+}
+
+// track waits for the user's ack of frame id until p.deadline.
+func (t *ackTracker) track(userID, id string, p *pendingAck) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	frames, ok := t.pending[userID]
+	if !ok {
+		frames = make(map[string]*pendingAck)
+		t.pending[userID] = frames
+	}
+	frames[id] = p
+}
+
+// ack settles frame id of the user and reports whether it was pending.
+func (t *ackTracker) ack(userID, id string) bool {
+	if t == nil {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	frames := t.pending[userID]
+	if _, ok := frames[id]; !ok {
+		return false
+	}
+	delete(frames, id)
+	if len(frames) == 0 {
+		delete(t.pending, userID)
+	}
+	return true
+}
+
+// due removes and returns the frames whose deadline passed at now.
+func (t *ackTracker) due(now time.Time) []dueAck {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var due []dueAck
+	for userID, frames := range t.pending {
+		for id, p := range frames {
+			if now.Before(p.deadline) {
+				continue
+			}
+			due = append(due, dueAck{userID: userID, id: id, pendingAck: p})
+			delete(frames, id)
+		}
+		if len(frames) == 0 {
+			delete(t.pending, userID)
+		}
+	}
+	return due
+}
+
+// purgeProject forgets the pending frames of the project and returns the users
+// they were sent to.
+func (t *ackTracker) purgeProject(projectID string) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var users []string
+	for userID, frames := range t.pending {
+		n := len(frames)
+		for id, p := range frames {
+			if p.projectID == projectID {
+				delete(frames, id)
+			}
+		}
+		if len(frames) < n {
+			users = append(users, userID)
+		}
+		if len(frames) == 0 {
+			delete(t.pending, userID)
+		}
+	}
+	return users
+}
+
+// routeReliable routes an at-least-once frame and tracks it until acked. A
+// user with no connection here that can ack gets it from the offline queue.
+func (uc *implUseCase) routeReliable(parsed ParsedChannel, output ws.NotificationOutput, frame []byte) (int, int) {
+	recipients, dropped := uc.routeMessage(parsed, output.Type, output.Importance, frame)
+
+	switch {
+	case !uc.hasAcker(parsed.UserID):
+		uc.storeOffline(parsed.UserID, output.ID, frame)
+	case recipients > 0 || dropped > 0:
+		p := &pendingAck{
+			msgType:    output.Type,
+			importance: output.Importance,
+			frame:      frame,
+			deadline:   time.Now().Add(uc.acks.cfg.AckTimeout),
+		}
+		if parsed.ChannelType == ws.ChannelTypeProject {
+			p.projectID = parsed.EntityID
+		}
+		uc.acks.track(parsed.UserID, output.ID, p)
+	}
+	// Otherwise every connection of the user filters the message out
+	return recipients, dropped
+}
+
+// retryAcks resends unacked frames until the process exits.
+func (uc *implUseCase) retryAcks() {
+	ticker := time.NewTicker(uc.acks.cfg.AckTimeout / 2)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		uc.retryDue(now)
+	}
+}
+
+// retryDue resends the frames whose ack deadline passed, moves those of users
+// who left to the offline queue and gives up on those out of retries.
+func (uc *implUseCase) retryDue(now time.Time) {
+	for _, d := range uc.acks.due(now) {
+		switch {
+		case !uc.hasAcker(d.userID):
+			uc.storeOffline(d.userID, d.id, d.frame)
+		case d.retries >= uc.acks.cfg.MaxRetries:
+			metrics.ReliableDeliveries.WithLabelValues("expired").Inc()
+			uc.logger.Warnf(context.Background(), "at-least-once frame never acked: user_id=%s id=%s type=%s retries=%d",
+				d.userID, d.id, d.msgType, d.retries)
+		default:
+			var sent, dropped int
+			if d.projectID != "" {
+				sent, dropped = uc.hub.SendToUserWithProject(d.userID, d.projectID, d.msgType, d.importance, d.frame)
+			} else {
+				sent, dropped = uc.hub.SendToUser(d.userID, d.msgType, d.importance, d.frame)
+			}
+			if sent == 0 && dropped == 0 {
+				continue // Filtered out since it was sent
+			}
+			metrics.ReliableDeliveries.WithLabelValues("retried").Inc()
+			d.retries++
+			d.deadline = now.Add(uc.acks.cfg.AckTimeout)
+			uc.acks.track(d.userID, d.id, d.pendingAck)
+		}
+	}
+}
+
+// hasAcker reports whether the user has a connection here that acks for them.
+func (uc *implUseCase) hasAcker(userID string) bool {
+	for _, c := range uc.hub.Connections(userID) {
+		if c.acks != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// storeOffline queues frame for the user's next connection, unless presence
+// shows the user connected to another replica, which delivers it. It runs
+// off the caller's goroutine.
+func (uc *implUseCase) storeOffline(userID, id string, frame []byte) {
+	if uc.repo == nil {
+		metrics.ReliableDeliveries.WithLabelValues("lost").Inc()
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), offlineTimeout)
+		defer cancel()
+
+		if uc.presence != nil {
+			if present, err := uc.repo.IsPresent(ctx, userID); err == nil && present {
+				metrics.ReliableDeliveries.WithLabelValues("elsewhere").Inc()
+				return
+			}
+		}
+		cfg := uc.acks.cfg
+		if err := uc.repo.PushOffline(ctx, userID, frame, cfg.OfflineMaxFrames, cfg.OfflineTTL); err != nil {
+			metrics.ReliableDeliveries.WithLabelValues("lost").Inc()
+			uc.logger.Warnf(ctx, "offline queue write failed: user_id=%s id=%s err=%v", userID, id, err)
+			return
+		}
+		metrics.ReliableDeliveries.WithLabelValues("offline").Inc()
+	}()
+}
+
+// drainOffline queues the user's offline frames on a new connection, oldest
+// first, and tracks them until acked. Project-only connections leave the
+// queue to the user's full connections.
+func (uc *implUseCase) drainOffline(ctx context.Context, client *Connection) {
+	if client.acks == nil || client.projectOnly || uc.repo == nil {
+		return
+	}
+
+	frames, err := uc.repo.PopOffline(ctx, client.userID)
+	if err != nil {
+		uc.logger.Warnf(ctx, "offline queue read failed: user_id=%s err=%v", client.userID, err)
+		return
+	}
+
+	deadline := time.Now().Add(uc.acks.cfg.AckTimeout)
+	for _, frame := range frames {
+		var head offlineHead
+		if err := json.Unmarshal(frame, &head); err != nil || head.ID == "" {
+			continue
+		}
+		// Frames refused by a full queue are resent once the deadline passes
+		client.enqueueType(head.Type, client.shape(head.Type, frame))
+		uc.acks.track(client.userID, head.ID, &pendingAck{
+			msgType:    head.Type,
+			importance: head.Importance,
+			frame:      frame,
+			deadline:   deadline,
+		})
+		metrics.ReliableDeliveries.WithLabelValues("drained").Inc()
+	}
+}
+
+// dropReliable forgets the at-least-once frames of a project: those waiting
+// for an ack here and those in the offline queues of the users this replica
+// delivered the project to. Every replica does the same for its own users.
+func (uc *implUseCase) dropReliable(projectID string) {
+	if uc.acks == nil {
+		return
+	}
+	topic := topicName(ws.ChannelTypeProject, projectID)
+	users := append(uc.seq.users(topic), uc.acks.purgeProject(projectID)...)
+	if uc.repo == nil || len(users) == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), offlinePurgeTimeout)
+		defer cancel()
+
+		inTopic := func(frame []byte) bool {
+			var head offlineHead
+			return json.Unmarshal(frame, &head) == nil && head.Topic == topic
+		}
+		removed := 0
+		seen := make(map[string]struct{}, len(users))
+		for _, userID := range users {
+			if _, ok := seen[userID]; ok {
+				continue
+			}
+			seen[userID] = struct{}{}
+
+			n, err := uc.repo.RemoveOffline(ctx, userID, inTopic)
+			if err != nil {
+				uc.logger.Warnf(ctx, "offline queue purge failed: project_id=%s user_id=%s err=%v", projectID, userID, err)
+				continue
+			}
+			removed += n
+		}
+		uc.logger.Infof(ctx, "offline queues purged: project_id=%s users=%d removed_frames=%d", projectID, len(seen), removed)
+	}()
+}
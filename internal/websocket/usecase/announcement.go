@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	ws "notification-srv/internal/websocket"
+
+	"github.com/google/uuid"
+)
+
+// handleAnnouncementControl applies control:announcement: the announcement
+// replaces the active one and goes out to every connection of this replica.
+// An empty title and body withdraw the active announcement.
+func (uc *implUseCase) handleAnnouncementControl(ctx context.Context, payload []byte) error {
+	var ctl announcementControl
+	if err := json.Unmarshal(payload, &ctl); err != nil {
+		return ws.ErrInvalidMessage
+	}
+	if ctl.Title == "" && ctl.Body == "" {
+		uc.withdrawAnnouncement(ctx)
+		return nil
+	}
+
+	switch ctl.Severity {
+	case "":
+		ctl.Severity = ws.AnnouncementSeverityInfo
+	case ws.AnnouncementSeverityInfo, ws.AnnouncementSeverityWarning, ws.AnnouncementSeverityCritical:
+	default:
+		return ws.ErrInvalidMessage
+	}
+	if !ctl.ExpiresAt.IsZero() && !ctl.ExpiresAt.After(time.Now()) {
+		return ws.ErrInvalidMessage
+	}
+	if ctl.ID == "" {
+		// Every replica receives the same payload, so clients see one ID
+		ctl.ID = uuid.NewSHA1(uuid.NameSpaceOID, payload).String()
+	}
+
+	announcement := ws.ServiceAnnouncementPayload{
+		ID:       ctl.ID,
+		Title:    ctl.Title,
+		Body:     ctl.Body,
+		Severity: ctl.Severity,
+		Active:   true,
+	}
+	if !ctl.ExpiresAt.IsZero() {
+		announcement.ExpiresAt = &ctl.ExpiresAt
+	}
+	frame, err := announcementFrame(announcement)
+	if err != nil {
+		return err
+	}
+
+	a := &uc.announcement
+	a.mu.Lock()
+	a.current = &announcement
+	a.frame = frame
+	a.mu.Unlock()
+
+	uc.logger.Infof(ctx, "service announcement %s: severity=%s title=%q expires_at=%v",
+		announcement.ID, announcement.Severity, announcement.Title, ctl.ExpiresAt)
+	uc.hub.Broadcast(frame)
+	return nil
+}
+
+// withdrawAnnouncement clears the active announcement and tells clients to
+// hide it. Nothing is sent if none is active.
+func (uc *implUseCase) withdrawAnnouncement(ctx context.Context) {
+	a := &uc.announcement
+	a.mu.Lock()
+	current := a.current
+	a.current, a.frame = nil, nil
+	a.mu.Unlock()
+
+	if !activeAnnouncement(current, time.Now()) {
+		return
+	}
+
+	withdrawn := *current
+	withdrawn.Active = false
+	frame, err := announcementFrame(withdrawn)
+	if err != nil {
+		uc.logger.Warnf(ctx, "service announcement withdrawal: %v", err)
+		return
+	}
+
+	uc.logger.Infof(ctx, "service announcement %s withdrawn", current.ID)
+	uc.hub.Broadcast(frame)
+}
+
+// queueAnnouncement sends the active announcement to a new connection.
+func (uc *implUseCase) queueAnnouncement(client *Connection) {
+	a := &uc.announcement
+	a.mu.RLock()
+	current, frame := a.current, a.frame
+	a.mu.RUnlock()
+
+	if !activeAnnouncement(current, time.Now()) {
+		return
+	}
+	client.enqueue(frame)
+}
+
+// activeAnnouncement reports whether a is set and not expired at now.
+func activeAnnouncement(a *ws.ServiceAnnouncementPayload, now time.Time) bool {
+	return a != nil && (a.ExpiresAt == nil || now.Before(*a.ExpiresAt))
+}
+
+// announcementImportance maps announcement severities to frame importance.
+var announcementImportance = map[string]ws.Importance{
+	ws.AnnouncementSeverityInfo:     ws.ImportanceNormal,
+	ws.AnnouncementSeverityWarning:  ws.ImportanceHigh,
+	ws.AnnouncementSeverityCritical: ws.ImportanceCritical,
+}
+
+// announcementFrame builds the SERVICE_ANNOUNCEMENT notification.
+func announcementFrame(announcement ws.ServiceAnnouncementPayload) ([]byte, error) {
+	frame, err := json.Marshal(ws.NotificationOutput{
+		ID:         uuid.NewString(),
+		Type:       ws.MessageTypeServiceAnnouncement,
+		Timestamp:  time.Now(),
+		Payload:    announcement,
+		Importance: announcementImportance[announcement.Severity],
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal service announcement: %w", err)
+	}
+	return frame, nil
+}
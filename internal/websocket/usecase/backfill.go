@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	ws "notification-srv/internal/websocket"
+
+	"github.com/google/uuid"
+)
+
+// backfillProject queues a PROJECT_PROGRESS snapshot of the collector's current
+// project state, and of the latest frame of the user's project topic if kept,
+// on a new connection. Missing state or a failed lookup only means the client
+// waits for the next live update, so errors are logged, not returned.
+func (uc *implUseCase) backfillProject(ctx context.Context, client *Connection) {
+	if !uc.backfill.Enabled || uc.repo == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, uc.backfill.Timeout)
+	defer cancel()
+
+	// Anonymous viewers see the topic of the user feeding the public project
+	userID := client.userID
+	if client.public {
+		userID = uc.public.ownerOf(client.projectID)
+	}
+	latest := uc.latest.load(ctx, client.projectID, userID)
+
+	var state []byte
+	if uc.followsProject(client, userID, latest != nil) {
+		var err error
+		state, err = uc.repo.GetProjectState(ctx, client.projectID, userID)
+		if err != nil {
+			uc.logger.Warnf(ctx, "project backfill lookup failed: project_id=%s err=%v", client.projectID, err)
+			state = nil
+		}
+		if state != nil && !json.Valid(state) {
+			uc.logger.Warnf(ctx, "project backfill state is not JSON: project_id=%s", client.projectID)
+			state = nil
+		}
+	}
+	if state == nil && latest == nil {
+		return
+	}
+
+	msg, err := json.Marshal(ws.NotificationOutput{
+		ID:        uuid.NewString(),
+		Type:      ws.MessageTypeProjectProgress,
+		Timestamp: time.Now(),
+		Payload: ws.ProjectProgressPayload{
+			ProjectID: client.projectID,
+			Snapshot:  true,
+			State:     state,
+			Latest:    latest,
+		},
+		GroupKey: "project:" + client.projectID,
+	})
+	if err != nil {
+		uc.logger.Errorf(ctx, "marshal project backfill failed: %v", err)
+		return
+	}
+
+	// The send queue is empty at this point, so the frame always fits
+	client.enqueue(msg)
+}
+
+// followsProject reports whether the collector state of the client's project
+// may be sent to it. A user-scoped state key only holds the user's own state.
+// The global key holds any tenant's project, so it is read only for clients
+// admitted to the project (subscription tokens, public viewers) or users the
+// project's messages were published to: their topic has a latest frame or a
+// sequence on this replica.
+func (uc *implUseCase) followsProject(client *Connection, userID string, hasLatest bool) bool {
+	switch {
+	case uc.backfill.UserScoped, client.projectOnly, hasLatest:
+		return true
+	default:
+		return uc.seq.has(topicKey(topicName(ws.ChannelTypeProject, client.projectID), userID))
+	}
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+)
+
+// newBackpressureDetector returns nil when signals are off.
+func newBackpressureDetector(cfg BackpressureConfig) *backpressureDetector {
+	if cfg.Window <= 0 {
+		return nil
+	}
+	return &backpressureDetector{
+		cfg:      cfg,
+		channels: make(map[string]*channelLoad),
+	}
+}
+
+// observe counts a routed message. The first message of a new window returns
+// the channels of the window that ended which dropped enough of their frames.
+// Broadcasts (recipients -1) are not counted. A nil detector observes nothing.
+func (d *backpressureDetector) observe(channel, pattern string, recipients, dropped int, now time.Time) []backpressureEvent {
+	if d == nil || recipients < 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var events []backpressureEvent
+	if d.start.IsZero() {
+		d.start = now
+	} else if elapsed := now.Sub(d.start); elapsed >= d.cfg.Window {
+		events = d.overloaded(elapsed, now)
+		d.start = now
+		d.channels = make(map[string]*channelLoad)
+	}
+
+	load := d.channels[channel]
+	if load == nil {
+		load = &channelLoad{pattern: pattern}
+		d.channels[channel] = load
+	}
+	load.messages++
+	load.frames += recipients + dropped
+	load.dropped += dropped
+	return events
+}
+
+// overloaded returns the channels of the ended window at or above the
+// thresholds. Must be called with mu held.
+func (d *backpressureDetector) overloaded(elapsed time.Duration, now time.Time) []backpressureEvent {
+	var events []backpressureEvent
+	for channel, load := range d.channels {
+		if load.dropped == 0 || load.frames < d.cfg.MinFrames {
+			continue
+		}
+		rate := float64(load.dropped) / float64(load.frames)
+		if rate < d.cfg.MinDropRate {
+			continue
+		}
+		events = append(events, backpressureEvent{
+			pattern: load.pattern,
+			signal: ws.BackpressureSignal{
+				Channel:             channel,
+				DropRate:            rate,
+				Messages:            load.messages,
+				WindowMs:            elapsed.Milliseconds(),
+				SuggestedIntervalMs: suggestedInterval(elapsed, load.messages, rate).Milliseconds(),
+				Timestamp:           now,
+			},
+		})
+	}
+	return events
+}
+
+// suggestedInterval is the publish interval connections would have kept up
+// with: the window's average interval stretched by the share of frames that
+// got through, at most tenfold.
+func suggestedInterval(elapsed time.Duration, messages int, dropRate float64) time.Duration {
+	interval := elapsed / time.Duration(messages)
+	return time.Duration(float64(interval) / max(1-dropRate, 0.1))
+}
+
+// signalBackpressure counts a routed message and asks the publishers of the
+// channels that kept overflowing send queues in the last window to slow down.
+func (uc *implUseCase) signalBackpressure(ctx context.Context, channel string, parsed ParsedChannel, recipients, dropped int) {
+	if uc.backpressure == nil {
+		return
+	}
+	for _, e := range uc.backpressure.observe(channel, channelPattern(parsed), recipients, dropped, time.Now()) {
+		payload, err := json.Marshal(e.signal)
+		if err != nil {
+			uc.logger.Warnf(ctx, "backpressure signal: marshal: %v", err)
+			continue
+		}
+
+		metrics.BackpressureSignals.WithLabelValues(e.pattern).Inc()
+		uc.logger.Warnf(ctx, "backpressure: channel=%s drop_rate=%.2f messages=%d window_ms=%d suggested_interval_ms=%d",
+			e.signal.Channel, e.signal.DropRate, e.signal.Messages, e.signal.WindowMs, e.signal.SuggestedIntervalMs)
+
+		go func() {
+			if err := uc.repo.PublishBackpressure(context.Background(), e.signal.Channel, payload); err != nil {
+				uc.logger.Warnf(ctx, "backpressure signal failed: channel=%s: %v", e.signal.Channel, err)
+			}
+		}()
+	}
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/netip"
+	"time"
+
+	"notification-srv/internal/metrics"
+	"notification-srv/internal/model"
+	ws "notification-srv/internal/websocket"
+	"notification-srv/pkg/errcode"
+)
+
+func (uc *implUseCase) Ban(ctx context.Context, sc model.Scope, input ws.BanInput) (ws.BanOutput, error) {
+	output, err := uc.ban(ctx, input.Kind, input.Value, input.TTL)
+	if err != nil {
+		return ws.BanOutput{}, err
+	}
+
+	uc.logger.Infof(ctx, "banned: kind=%s value=%s ttl=%s by user_id=%s", output.Kind, output.Value, input.TTL, sc.UserID)
+	return output, nil
+}
+
+func (uc *implUseCase) Unban(ctx context.Context, sc model.Scope, input ws.UnbanInput) error {
+	if uc.repo == nil {
+		return ws.ErrBansUnavailable
+	}
+	value, err := normalizeBan(input.Kind, input.Value)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.repo.DeleteBan(ctx, input.Kind, value); err != nil {
+		return errcode.Wrap(ws.ErrStorage, err)
+	}
+	uc.logger.Infof(ctx, "unbanned: kind=%s value=%s by user_id=%s", input.Kind, value, sc.UserID)
+	return nil
+}
+
+// CheckBan looks the user and IP up on the deny-list. Lookup errors let the
+// connection through: an unreachable Redis must not lock every client out.
+func (uc *implUseCase) CheckBan(ctx context.Context, input ws.CheckBanInput) error {
+	if uc.repo == nil {
+		return nil
+	}
+
+	for _, ban := range [...]struct{ kind, value string }{{ws.BanKindUser, input.UserID}, {ws.BanKindIP, input.RemoteIP}} {
+		if ban.value == "" {
+			continue
+		}
+		banned, err := uc.repo.IsBanned(ctx, ban.kind, ban.value)
+		if err != nil {
+			uc.logger.Warnf(ctx, "deny-list lookup failed: kind=%s: %v", ban.kind, err)
+			continue
+		}
+		if banned {
+			metrics.BannedRejected.WithLabelValues(ban.kind).Inc()
+			return ws.ErrBanned
+		}
+	}
+	return nil
+}
+
+// ban stores the ban and publishes it on the control channel so every
+// replica, this one included, closes the matching connections.
+func (uc *implUseCase) ban(ctx context.Context, kind, value string, ttl time.Duration) (ws.BanOutput, error) {
+	if uc.repo == nil {
+		return ws.BanOutput{}, ws.ErrBansUnavailable
+	}
+	value, err := normalizeBan(kind, value)
+	if err != nil {
+		return ws.BanOutput{}, err
+	}
+	if ttl <= 0 {
+		return ws.BanOutput{}, ws.ErrInvalidBan
+	}
+
+	if err := uc.repo.SetBan(ctx, kind, value, ttl); err != nil {
+		return ws.BanOutput{}, errcode.Wrap(ws.ErrStorage, err)
+	}
+
+	payload, _ := json.Marshal(banControl{Value: value})
+	if err := uc.repo.PublishControl(ctx, ws.ControlEventBan, kind, payload); err != nil {
+		// The ban is stored, so other replicas still refuse new connections
+		uc.logger.Warnf(ctx, "ban control publish failed: %v", err)
+		uc.closeBanned(ctx, kind, value)
+	}
+
+	return ws.BanOutput{
+		Kind:      kind,
+		Value:     value,
+		ExpiresAt: time.Now().Add(ttl),
+	}, nil
+}
+
+// handleBanControl closes this replica's connections matching a new ban.
+func (uc *implUseCase) handleBanControl(ctx context.Context, kind string, payload []byte) error {
+	var ctl banControl
+	if err := json.Unmarshal(payload, &ctl); err != nil {
+		return ws.ErrInvalidMessage
+	}
+	value, err := normalizeBan(kind, ctl.Value)
+	if err != nil {
+		return err
+	}
+
+	uc.closeBanned(ctx, kind, value)
+	return nil
+}
+
+// closeBanned closes the connections of a banned user or IP range with
+// close code 4030. value must be normalized.
+func (uc *implUseCase) closeBanned(ctx context.Context, kind, value string) {
+	var match func(*Connection) bool
+	switch kind {
+	case ws.BanKindUser:
+		match = func(c *Connection) bool { return c.userID == value }
+	case ws.BanKindIP:
+		prefix := netip.MustParsePrefix(value)
+		match = func(c *Connection) bool {
+			addr, err := netip.ParseAddr(c.remoteIP)
+			return err == nil && prefix.Contains(addr.Unmap())
+		}
+	default:
+		return
+	}
+
+	closed := uc.hub.CloseWhere(match, ws.CloseCodeBanned, "banned")
+	uc.logger.Infof(ctx, "ban applied: kind=%s value=%s closed_connections=%d", kind, value, closed)
+}
+
+// normalizeBan validates a ban value. IP ranges are masked and single IPs
+// become /32 or /128 ranges, so a ban is lifted with the value it was stored under.
+func normalizeBan(kind, value string) (string, error) {
+	switch kind {
+	case ws.BanKindUser:
+		if value == "" {
+			return "", ws.ErrInvalidBan
+		}
+		return value, nil
+	case ws.BanKindIP:
+		if prefix, err := netip.ParsePrefix(value); err == nil {
+			return prefix.Masked().String(), nil
+		}
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return "", ws.ErrInvalidBan
+		}
+		addr = addr.Unmap().WithZone("")
+		return netip.PrefixFrom(addr, addr.BitLen()).String(), nil
+	}
+	return "", ws.ErrInvalidBan
+}
@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	ws "notification-srv/internal/websocket"
+	"notification-srv/pkg/notificationtest"
+)
+
+var benchPayload = []byte(`{"project_id":"proj_001","source_id":"src_fb_01","source_name":"Facebook Page - VinFast",` +
+	`"source_type":"FACEBOOK","status":"PROCESSING","progress":45,"record_count":680,"error_count":0,"message":""}`)
+
+// benchHub returns a Hub with conns connections for user u1, half of them
+// filtered to project p1 and the rest unfiltered.
+func benchHub(conns int) (*Hub, []*Connection) {
+	return benchHubProjects(conns, func(i int) string {
+		if i%2 == 0 {
+			return "p1"
+		}
+		return ""
+	})
+}
+
+// benchHubProjects returns a Hub with conns connections for user u1, the i-th
+// filtered to project(i).
+func benchHubProjects(conns int, project func(i int) string) (*Hub, []*Connection) {
+	h := newHub(notificationtest.Logger{}, 0)
+	clients := make([]*Connection, conns)
+	for i := range clients {
+		c := &Connection{hub: h, send: newSendQueue(256, SendQueueDropNewest), closeReq: make(chan closeFrame, 1), userID: "u1"}
+		c.projectID = project(i)
+		h.addClient(c)
+		clients[i] = c
+	}
+	return h, clients
+}
+
+func drain(clients []*Connection) {
+	for _, c := range clients {
+		c.send.take(nil)
+	}
+}
+
+func BenchmarkHubSend(b *testing.B) {
+	frame := []byte(`{"id":"1","type":"DATA_ONBOARDING","payload":{}}`)
+	for _, conns := range []int{1, 4, 16, 50} {
+		b.Run(fmt.Sprintf("conns=%d", conns), func(b *testing.B) {
+			h, clients := benchHub(conns)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				h.SendToUserWithProject("u1", "p1", ws.MessageTypeDataOnboarding, ws.ImportanceLow, frame)
+				if i%128 == 127 {
+					drain(clients)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkHubSendSpread sends to one project of a user with 50 connections,
+// each filtered to a different project, as a dashboard with many tabs does.
+func BenchmarkHubSendSpread(b *testing.B) {
+	frame := []byte(`{"id":"1","type":"DATA_ONBOARDING","payload":{}}`)
+	h, clients := benchHubProjects(50, func(i int) string { return fmt.Sprintf("p%d", i) })
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		h.SendToUserWithProject("u1", "p1", ws.MessageTypeDataOnboarding, ws.ImportanceLow, frame)
+		if i%128 == 127 {
+			drain(clients)
+		}
+	}
+}
+
+func BenchmarkHubSendFiltered(b *testing.B) {
+	frame := []byte(`{"id":"1","type":"DATA_ONBOARDING","payload":{}}`)
+	h, clients := benchHub(16)
+	for _, c := range clients {
+		if err := c.setTypes([]ws.MessageType{ws.MessageTypeCrisisAlert}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		h.SendToUser("u1", ws.MessageTypeDataOnboarding, ws.ImportanceLow, frame)
+	}
+}
+
+// benchBatchPayload is a crisis alert carrying a batch of sample mentions, the
+// largest payloads on the subscriber path.
+var benchBatchPayload = func() []byte {
+	mentions := make([]string, 200)
+	for i := range mentions {
+		mentions[i] = fmt.Sprintf("Mention %d: battery range much lower than advertised after the update", i)
+	}
+	payload, _ := json.Marshal(ws.CrisisAlertPayload{
+		ProjectID: "proj_001", ProjectName: "VinFast", Severity: "critical", AlertType: "sentiment_spike",
+		Metric: "negative_ratio", CurrentValue: 0.62, Threshold: 0.4,
+		AffectedAspects: []string{"battery", "range", "software"}, SampleMentions: mentions,
+		TimeWindow: "1h", ActionRequired: "review",
+	})
+	return payload
+}()
+
+func BenchmarkTransform(b *testing.B) {
+	b.Run("progress", func(b *testing.B) { benchTransform(b, benchPayload) })
+	b.Run("batch", func(b *testing.B) { benchTransform(b, benchBatchPayload) })
+}
+
+// benchTransform runs the subscriber→Hub path of ProcessMessage on payload:
+// type detection, decoding and encoding of the frame.
+func benchTransform(b *testing.B, payload []byte) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		msgType, err := detectMessageType(payload)
+		if err != nil {
+			b.Fatal(err)
+		}
+		data, _, err := decodeTyped(msgType, payload, false)
+		if err != nil {
+			b.Fatal(err)
+		}
+		groupKey, collapseKey := notificationKeys(data)
+		if _, err := marshalOutput(ws.NotificationOutput{
+			Type:        msgType,
+			Payload:     data,
+			GroupKey:    groupKey,
+			CollapseKey: collapseKey,
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDecodeBatch compares the payload decoders of the transform layer on
+// the batch payload; B/op is the memory a message costs to decode.
+func BenchmarkDecodeBatch(b *testing.B) {
+	decoders := []struct {
+		name   string
+		decode payloadDecoder
+	}{
+		{"encoding-json", decodePayload},
+		{streamingTransformVersion, decodePayloadStreaming},
+	}
+	for _, d := range decoders {
+		for _, strict := range []bool{false, true} {
+			b.Run(fmt.Sprintf("%s/strict=%t", d.name, strict), func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(len(benchBatchPayload)))
+				for i := 0; i < b.N; i++ {
+					if _, _, err := decodeTypedWith(d.decode, ws.MessageTypeCrisisAlert, benchBatchPayload, strict); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	var d ws.DataOnboardingPayload
+	if err := json.Unmarshal(benchPayload, &d); err != nil {
+		b.Fatal(err)
+	}
+	frame, err := json.Marshal(ws.NotificationOutput{ID: "1", Type: ws.MessageTypeDataOnboarding, Payload: d,
+		CollapseKey: "project:proj_001:onboarding:src_fb_01"})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("json", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := json.Marshal(ws.NotificationOutput{Type: ws.MessageTypeDataOnboarding, Payload: d}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("projection", func(b *testing.B) {
+		p := newProjection([]string{"status", "progress"})
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p.apply(frame)
+		}
+	})
+
+	b.Run("delta", func(b *testing.B) {
+		e := newDeltaEncoder(1 << 30)
+		e.encode(frame)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			e.encode(frame)
+		}
+	})
+}
+
+// BenchmarkFanoutCounting measures per-frame counting from many writePumps at
+// once, the pattern of a high-fanout broadcast.
+func BenchmarkFanoutCounting(b *testing.B) {
+	frame := []byte(`{"id":"1","type":"PROJECT_PROGRESS","collapse_key":"project:p1","payload":{"progress":1}}`)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		e := newDeltaEncoder(4)
+		for i := 0; pb.Next(); i++ {
+			e.encode(frame)
+			if i%1024 == 1023 {
+				e.flushMetrics()
+			}
+		}
+		e.flushMetrics()
+	})
+}
@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"notification-srv/internal/metrics"
+	"notification-srv/internal/model"
+	ws "notification-srv/internal/websocket"
+	"notification-srv/pkg/errcode"
+
+	"github.com/google/uuid"
+)
+
+// Broadcast resolves the segment and publishes the message on the control
+// channel; every replica, this one included, delivers it to its own
+// connections. Without a repository it is delivered locally.
+func (uc *implUseCase) Broadcast(ctx context.Context, sc model.Scope, input ws.BroadcastInput) (ws.BroadcastOutput, error) {
+	target, err := uc.resolveSegment(ctx, input.Segment)
+	if err != nil {
+		return ws.BroadcastOutput{}, err
+	}
+
+	id := uuid.NewString()
+	frame, err := json.Marshal(ws.NotificationOutput{
+		ID:        id,
+		Type:      ws.MessageTypeSystem,
+		Timestamp: time.Now(),
+		Payload: map[string]string{
+			"system_event": ws.SystemEventBroadcast,
+			"title":        input.Title,
+			"message":      input.Message,
+		},
+	})
+	if err != nil {
+		return ws.BroadcastOutput{}, fmt.Errorf("marshal broadcast: %w", err)
+	}
+
+	metrics.Broadcasts.WithLabelValues(input.Segment.Kind).Inc()
+	uc.logger.Infof(ctx, "broadcast %s: segment=%s value=%q users=%d projects=%d by user_id=%s",
+		id, input.Segment.Kind, input.Segment.Value, len(target.UserIDs), len(target.ProjectIDs), sc.UserID)
+
+	output := ws.BroadcastOutput{
+		ID:         id,
+		All:        target.All,
+		UserIDs:    len(target.UserIDs),
+		ProjectIDs: len(target.ProjectIDs),
+	}
+	if !target.All && len(target.UserIDs) == 0 && len(target.ProjectIDs) == 0 {
+		return output, nil
+	}
+
+	if uc.repo != nil {
+		payload, err := json.Marshal(broadcastControl{
+			All:        target.All,
+			UserIDs:    target.UserIDs,
+			ProjectIDs: target.ProjectIDs,
+			Frame:      frame,
+		})
+		if err != nil {
+			return ws.BroadcastOutput{}, fmt.Errorf("marshal broadcast control: %w", err)
+		}
+		err = uc.repo.PublishControl(ctx, ws.ControlEventBroadcast, id, payload)
+		if err == nil {
+			return output, nil
+		}
+		// The other replicas miss it; at least reach the users connected here
+		uc.logger.Warnf(ctx, "broadcast control publish failed: %v", err)
+	}
+
+	uc.deliverBroadcast(ctx, target, frame)
+	return output, nil
+}
+
+// resolveSegment turns a segment into its target. "all" and "users" need no
+// lookup; other kinds go to the configured resolver.
+func (uc *implUseCase) resolveSegment(ctx context.Context, segment ws.Segment) (ws.SegmentTarget, error) {
+	switch segment.Kind {
+	case ws.SegmentAll:
+		return ws.SegmentTarget{All: true}, nil
+	case ws.SegmentUsers:
+		return ws.SegmentTarget{UserIDs: segment.UserIDs}, nil
+	}
+
+	if uc.segments == nil {
+		return ws.SegmentTarget{}, ws.ErrSegmentsUnavailable
+	}
+	target, err := uc.segments.ResolveSegment(ctx, segment)
+	return target, errcode.Wrap(ws.ErrStorage, err)
+}
+
+// handleBroadcastControl delivers a broadcast published by any replica.
+func (uc *implUseCase) handleBroadcastControl(ctx context.Context, payload []byte) error {
+	var ctl broadcastControl
+	if err := json.Unmarshal(payload, &ctl); err != nil || len(ctl.Frame) == 0 {
+		return ws.ErrInvalidMessage
+	}
+
+	uc.deliverBroadcast(ctx, ws.SegmentTarget{
+		All:        ctl.All,
+		UserIDs:    ctl.UserIDs,
+		ProjectIDs: ctl.ProjectIDs,
+	}, ctl.Frame)
+	return nil
+}
+
+// deliverBroadcast queues frame to the connections of this replica in target.
+func (uc *implUseCase) deliverBroadcast(ctx context.Context, target ws.SegmentTarget, frame []byte) {
+	if target.All {
+		uc.hub.Broadcast(frame)
+		return
+	}
+
+	sent, dropped := uc.hub.SendToSegment(stringSet(target.UserIDs), stringSet(target.ProjectIDs),
+		ws.MessageTypeSystem, importanceOf(ws.MessageTypeSystem, nil), frame)
+	uc.logger.Debugf(ctx, "broadcast delivered: sent=%d dropped=%d", sent, dropped)
+}
+
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"notification-srv/internal/alert"
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+)
+
+// Results of a canary probe, as counted in metrics.CanaryProbes.
+const (
+	canaryOK            = "ok"
+	canarySlow          = "slow"
+	canaryLost          = "lost"
+	canaryPublishFailed = "publish_failed"
+)
+
+// newCanary returns nil when the canary is disabled.
+func newCanary(cfg CanaryConfig) *canary {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &canary{
+		cfg:     cfg,
+		source:  "canary-" + cfg.InstanceID,
+		pending: make(map[int]time.Time),
+	}
+}
+
+// runCanary opens the canary user's loopback connection and probes through
+// it every interval until the connection is closed, e.g. on shutdown.
+func (uc *implUseCase) runCanary() {
+	c := uc.canary
+	if !c.started.CompareAndSwap(false, true) {
+		return // Run again after a Hub restart; the first canary is still probing
+	}
+
+	ctx := context.Background()
+	frames, err := uc.Subscribe(ctx, ws.SubscribeInput{
+		UserID:    c.cfg.UserID,
+		ProjectID: c.cfg.ProjectID,
+		UserAgent: "notification-srv canary",
+		Types:     []ws.MessageType{ws.MessageTypeAnalyticsPipeline},
+	})
+	if err != nil {
+		uc.logger.Errorf(ctx, "canary subscribe failed: %v", err)
+		return
+	}
+	metrics.CanaryHealthy.Set(1)
+
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case frame, ok := <-frames:
+			if !ok {
+				uc.logger.Infof(ctx, "canary stopped: loopback connection closed")
+				return
+			}
+			uc.receiveProbe(ctx, frame, time.Now())
+		case now := <-ticker.C:
+			uc.expireProbes(ctx, now)
+			// Maintenance mode holds back progress updates, probes included
+			if status, _ := uc.GetMaintenance(ctx); status.Enabled {
+				continue
+			}
+			uc.publishProbe(ctx, now)
+		}
+	}
+}
+
+// publishProbe publishes the next probe on the canary user's project channel,
+// as a running analytics pipeline: delivered at most once, never alerted on.
+func (uc *implUseCase) publishProbe(ctx context.Context, now time.Time) {
+	c := uc.canary
+	seq := c.next
+	c.next++
+
+	payload, _ := json.Marshal(ws.AnalyticsPipelinePayload{
+		ProjectID:      c.cfg.ProjectID,
+		SourceID:       c.source,
+		ProcessedCount: seq,
+		CurrentPhase:   "canary",
+	})
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+	if uc.repo == nil {
+		uc.recordProbe(ctx, canaryPublishFailed, 0)
+		return
+	}
+	if err := uc.repo.PublishCanary(ctx, c.cfg.ProjectID, c.cfg.UserID, payload); err != nil {
+		uc.logger.Warnf(ctx, "canary probe publish failed: %v", err)
+		uc.recordProbe(ctx, canaryPublishFailed, 0)
+		return
+	}
+	c.pending[seq] = now
+}
+
+// receiveProbe settles a probe of this replica that reached the loopback
+// connection. Other replicas' probes and probes already lost are ignored.
+func (uc *implUseCase) receiveProbe(ctx context.Context, frame []byte, now time.Time) {
+	c := uc.canary
+	var head canaryHead
+	if err := json.Unmarshal(frame, &head); err != nil || head.Payload.SourceID != c.source {
+		return
+	}
+	sentAt, ok := c.pending[head.Payload.ProcessedCount]
+	if !ok {
+		return
+	}
+	delete(c.pending, head.Payload.ProcessedCount)
+
+	latency := now.Sub(sentAt)
+	metrics.CanaryLatency.Observe(latency.Seconds())
+	if latency > c.cfg.Threshold {
+		uc.recordProbe(ctx, canarySlow, latency)
+		return
+	}
+	uc.recordProbe(ctx, canaryOK, latency)
+}
+
+// expireProbes counts the probes not received within the timeout as lost.
+func (uc *implUseCase) expireProbes(ctx context.Context, now time.Time) {
+	c := uc.canary
+	for seq, sentAt := range c.pending {
+		if now.Sub(sentAt) < c.cfg.Timeout {
+			continue
+		}
+		delete(c.pending, seq)
+		uc.recordProbe(ctx, canaryLost, 0)
+	}
+}
+
+// recordProbe counts a probe's result and reports a run of FailuresToAlert
+// failed probes once, until a probe succeeds again.
+func (uc *implUseCase) recordProbe(ctx context.Context, result string, latency time.Duration) {
+	c := uc.canary
+	metrics.CanaryProbes.WithLabelValues(result).Inc()
+
+	if result == canaryOK {
+		if c.alerted {
+			uc.logger.Infof(ctx, "canary recovered after %d failed probes", c.failures)
+			metrics.CanaryHealthy.Set(1)
+		}
+		c.failures, c.alerted, c.lastSuccessAt = 0, false, time.Now()
+		return
+	}
+
+	c.failures++
+	uc.logger.Warnf(ctx, "canary probe %s: failures=%d latency=%s threshold=%s", result, c.failures, latency, c.cfg.Threshold)
+	if c.alerted || c.failures < c.cfg.FailuresToAlert {
+		return
+	}
+	c.alerted = true
+	metrics.CanaryHealthy.Set(0)
+
+	input := alert.CanaryFailureInput{
+		InstanceID:    c.cfg.InstanceID,
+		Result:        result,
+		Failures:      c.failures,
+		Threshold:     c.cfg.Threshold,
+		LastSuccessAt: c.lastSuccessAt,
+	}
+	if result == canarySlow {
+		input.Latency = latency
+	}
+	go func() {
+		if err := uc.alertUC.DispatchCanaryFailure(context.Background(), input); err != nil {
+			uc.logger.Warnf(context.Background(), "canary alert dispatch failed: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"notification-srv/internal/alert"
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+)
+
+// newChurnDetector returns nil when detection is disabled.
+func newChurnDetector(cfg ChurnConfig) *churnDetector {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &churnDetector{
+		cfg:     cfg,
+		users:   make(map[string]int),
+		ips:     make(map[string]map[string]bool),
+		flagged: make(map[string]bool),
+	}
+}
+
+// record counts a connect and returns the thresholds it crossed. A nil
+// detector records nothing.
+func (d *churnDetector) record(userID, ip string, now time.Time) []churnEvent {
+	if d == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if now.Sub(d.start) >= d.cfg.Window {
+		d.start = now
+		d.users = make(map[string]int)
+		d.ips = make(map[string]map[string]bool)
+		d.flagged = make(map[string]bool)
+	}
+
+	var events []churnEvent
+
+	d.users[userID]++
+	if n := d.users[userID]; n > d.cfg.MaxUserConnects {
+		events = d.flag(events, ws.BanKindUser, userID, n, d.cfg.MaxUserConnects)
+	}
+
+	if ip != "" {
+		users := d.ips[ip]
+		if users == nil {
+			users = make(map[string]bool)
+			d.ips[ip] = users
+		}
+		users[userID] = true
+		if n := len(users); n > d.cfg.MaxIPUsers {
+			events = d.flag(events, ws.BanKindIP, ip, n, d.cfg.MaxIPUsers)
+		}
+	}
+	return events
+}
+
+// flag appends an event unless the subject was already reported in this window.
+func (d *churnDetector) flag(events []churnEvent, kind, subject string, count, threshold int) []churnEvent {
+	key := kind + ":" + subject
+	if d.flagged[key] {
+		return events
+	}
+	d.flagged[key] = true
+	return append(events, churnEvent{kind: kind, subject: subject, count: count, threshold: threshold})
+}
+
+// checkChurn records the connect and reports every threshold it crossed:
+// a security event in the log, a Discord alert and, if configured, a
+// temporary ban that closes the subject's connections on every replica.
+func (uc *implUseCase) checkChurn(ctx context.Context, userID, ip string) {
+	for _, e := range uc.churn.record(userID, ip, time.Now()) {
+		metrics.ChurnDetected.WithLabelValues(e.kind).Inc()
+
+		var bannedFor time.Duration
+		if uc.churn.cfg.BanTTL > 0 {
+			if _, err := uc.ban(ctx, e.kind, e.subject, uc.churn.cfg.BanTTL); err != nil {
+				uc.logger.Warnf(ctx, "churn ban failed: kind=%s subject=%s: %v", e.kind, e.subject, err)
+			} else {
+				bannedFor = uc.churn.cfg.BanTTL
+			}
+		}
+
+		uc.logger.Warnf(ctx, "security event: connection churn kind=%s subject=%s count=%d threshold=%d window=%s banned_for=%s",
+			e.kind, e.subject, e.count, e.threshold, uc.churn.cfg.Window, bannedFor)
+
+		if uc.alertUC == nil {
+			continue
+		}
+		input := alert.ConnectionChurnInput{
+			Kind:      e.kind,
+			Subject:   e.subject,
+			Count:     e.count,
+			Threshold: e.threshold,
+			Window:    uc.churn.cfg.Window,
+			BannedFor: bannedFor,
+		}
+		go func() {
+			if err := uc.alertUC.DispatchConnectionChurn(context.Background(), input); err != nil {
+				uc.logger.Warnf(ctx, "alert dispatch failed: %v", err)
+			}
+		}()
+	}
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"notification-srv/internal/metrics"
+)
+
+const (
+	// A connection is slow when its RTT or send buffer occupancy is at or above
+	// these, and fast when both are below the fast thresholds.
+	coalesceSlowRTT  = 500 * time.Millisecond
+	coalesceFastRTT  = 150 * time.Millisecond
+	coalesceSlowFill = 0.5
+	coalesceFastFill = 0.1
+
+	// Consecutive slow (fast) flushes before the interval is stretched (shrunk).
+	coalesceStreak = 3
+)
+
+// coalescer holds frames with a collapse key and keeps only the latest per key
+// until the next flush. The flush interval adapts to the connection: it doubles
+// from the minimum towards the maximum while the connection is slow and halves
+// back once it is fast again. Owned by writePump, except that drop may be
+// called from any goroutine; a nil coalescer holds nothing.
+type coalescer struct {
+	levels []time.Duration // Minimum interval, doubled up to the maximum
+	level  int
+	slow   int // Consecutive slow evaluations
+	fast   int // Consecutive fast evaluations
+
+	mu      sync.Mutex     // Guards pending and frames
+	pending map[string]int // Collapse key -> index in frames
+	frames  [][]byte
+
+	replaced int // Frames replaced since the last flushMetrics
+}
+
+func newCoalescer(cfg CoalesceConfig) *coalescer {
+	if !cfg.Enabled {
+		return nil
+	}
+	levels := []time.Duration{cfg.MinInterval}
+	for d := cfg.MinInterval * 2; d < cfg.MaxInterval; d *= 2 {
+		levels = append(levels, d)
+	}
+	if cfg.MaxInterval > cfg.MinInterval {
+		levels = append(levels, cfg.MaxInterval)
+	}
+	return &coalescer{levels: levels, pending: make(map[string]int)}
+}
+
+// hold keeps message for the next flush if it has a collapse key, replacing a
+// pending frame with the same key. Returns false if the frame must be written now.
+func (c *coalescer) hold(message []byte) bool {
+	if c == nil {
+		return false
+	}
+	var head heldHead
+	if err := json.Unmarshal(message, &head); err != nil || head.CollapseKey == "" {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if i, ok := c.pending[head.CollapseKey]; ok {
+		c.frames[i] = message
+		c.replaced++
+		return true
+	}
+	c.pending[head.CollapseKey] = len(c.frames)
+	c.frames = append(c.frames, message)
+	return true
+}
+
+// held reports whether frames are waiting for a flush.
+func (c *coalescer) held() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.frames) > 0
+}
+
+// interval is the current flush interval of the connection.
+func (c *coalescer) interval() time.Duration {
+	return c.levels[c.level]
+}
+
+// take returns the pending frames in arrival order of their keys and clears them.
+func (c *coalescer) take() [][]byte {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	frames := c.frames
+	c.frames = nil
+	clear(c.pending)
+	return frames
+}
+
+// drop discards the held frames of topic, e.g. project:{project_id}, and
+// returns how many were held.
+func (c *coalescer) drop(topic string) int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dropped := 0
+	kept := c.frames[:0]
+	clear(c.pending)
+	for _, frame := range c.frames {
+		var head heldHead
+		if json.Unmarshal(frame, &head) == nil && head.Topic == topic {
+			dropped++
+			continue
+		}
+		c.pending[head.CollapseKey] = len(kept)
+		kept = append(kept, frame)
+	}
+	c.frames = kept
+	return dropped
+}
+
+// adapt moves the flush interval one level after coalesceStreak consecutive
+// slow or fast evaluations, so a single slow pong does not flap the interval.
+func (c *coalescer) adapt(rtt time.Duration, queued, capacity int) {
+	fill := float64(queued) / float64(capacity)
+	switch {
+	case rtt >= coalesceSlowRTT || fill >= coalesceSlowFill:
+		c.slow, c.fast = c.slow+1, 0
+	case rtt < coalesceFastRTT && fill < coalesceFastFill:
+		c.slow, c.fast = 0, c.fast+1
+	default:
+		c.slow, c.fast = 0, 0
+	}
+
+	if c.slow >= coalesceStreak && c.level < len(c.levels)-1 {
+		c.slow = 0
+		c.level++
+		metrics.CoalesceAdjustments.WithLabelValues("up").Inc()
+		if c.level == 1 {
+			metrics.CoalesceStretched.Inc()
+		}
+	}
+	if c.fast >= coalesceStreak && c.level > 0 {
+		c.fast = 0
+		c.level--
+		metrics.CoalesceAdjustments.WithLabelValues("down").Inc()
+		if c.level == 0 {
+			metrics.CoalesceStretched.Dec()
+		}
+	}
+}
+
+// close releases the connection's share of the stretched gauge.
+func (c *coalescer) close() {
+	if c != nil && c.level > 0 {
+		metrics.CoalesceStretched.Dec()
+	}
+}
+
+// flushMetrics adds the frames replaced since the last call to the shared counter.
+func (c *coalescer) flushMetrics() {
+	if c == nil || c.replaced == 0 {
+		return
+	}
+	metrics.CoalescedFrames.Add(float64(c.replaced))
+	c.replaced = 0
+}
@@ -2,9 +2,14 @@ package usecase
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
-	"github.com/gorilla/websocket"
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+	"notification-srv/internal/websocket/transport"
+
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/smap-hcmut/shared-libs/go/log"
 )
 
@@ -20,6 +25,9 @@ const (
 
 	// Maximum message size allowed from peer.
 	maxMessageSize = 512
+
+	// Weight of the newest sample in the smoothed round-trip time.
+	rttAlpha = 0.2
 )
 
 // Connection is a middleman between the websocket connection and the hub.
@@ -27,41 +35,132 @@ type Connection struct {
 	hub *Hub
 
 	// The websocket connection.
-	// We use interface{} in public types but cast here to *websocket.Conn
-	// to access low-level methods.
-	conn *websocket.Conn
+	// We use interface{} in public types but cast here to transport.Conn,
+	// which hides the WebSocket library chosen by config.
+	conn transport.Conn
+
+	// Bounded queue of outbound messages.
+	send *sendQueue
+
+	// Write latency histogram of the connection's client label, resolved once
+	// by writePump.
+	writeLatency prometheus.Observer
+
+	// Frames that did not reach the connection, by reason.
+	drops dropCounts
+
+	// Close frame requested by the server, written by writePump after any
+	// queued messages have been flushed.
+	closeReq chan closeFrame
+
+	userID    string
+	projectID string // Optional project filter; empty receives all of the user's projects
+
+	// Opened with a subscription token: receives the messages of projectID and
+	// broadcasts, never the user's own notifications.
+	projectOnly bool
+	public      bool   // Anonymous viewer of a public project; also projectOnly
+	delegatedBy string // User watching userID's stream through delegated access
+
+	userAgent   string
+	remoteIP    string // Empty for in-process streams
+	clientLabel string // Frontend build reported by the client; may be empty
+	fingerprint ws.ClientFingerprint
+	metricLabel string // clientLabel bounded to the configured labels, for metrics
+	compression bool   // permessage-deflate negotiated for this connection
+	connectedAt time.Time
+
+	// Smoothed ping/pong round-trip time in nanoseconds (EWMA), updated by ping.
+	rttNanos  atomic.Int64
+	pinging   atomic.Bool // A ping is waiting for its pong
+	heartbeat bool        // Send a heartbeat frame alongside every ping
+
+	// Consecutive malformed/unknown client frames; reset by any valid frame.
+	// Only touched by the reader: readPump, or the transport's poller.
+	violations    int
+	maxViolations int // Close the connection once violations reaches this (0 = never)
+
+	// Presence key writer; nil when presence is disabled or the connection has no socket.
+	presence *presence
+
+	// At-least-once tracker the client's acks go to; nil when delivery
+	// guarantees are off or the connection does not ack for its user.
+	acks *ackTracker
+
+	// Users under targeted tracing, whose written frames are logged; nil for
+	// in-process streams.
+	traces *userTraces
+
+	// Project view on screen (string), set by focus frames from readPump.
+	focus atomic.Value
+
+	// Message types the client asked for; nil receives all types.
+	// Read by the Hub on every send, replaced by subscribe frames.
+	types atomic.Pointer[typeSet]
+
+	// Least important message the client asked for (ws.Importance); empty
+	// receives all. Replaced by subscribe frames.
+	minImportance atomic.Value
 
-	// Buffered channel of outbound messages.
-	send chan []byte
+	// Payload fields the client asked for; nil sends full payloads.
+	fields *projection
 
-	userID string
+	// Delta encoder for clients that negotiated the delta subprotocol; nil
+	// otherwise. Only touched by writePump.
+	delta *deltaEncoder
+
+	// Holds collapse_key frames between flushes; nil when coalescing is
+	// disabled. Only touched by writePump, except to drop a project's frames.
+	coalesce *coalescer
+}
+
+// closeFrame is a close frame requested by the server.
+type closeFrame struct {
+	code   int
+	reason string
+}
+
+// startReading hands the connection's messages to handleInbound. A transport
+// that polls for readiness reads them on demand, so an idle connection holds
+// no reader goroutine; otherwise readPump runs in its own goroutine.
+func (c *Connection) startReading() {
+	c.conn.SetReadLimit(maxMessageSize)
+
+	if p, ok := c.conn.(transport.Poller); ok {
+		if err := p.Poll(c.handleInbound, c.readDone); err == nil {
+			return
+		}
+	}
+	go c.readPump()
 }
 
 // readPump pumps messages from the websocket connection to the hub.
 // The application runs readPump in a per-connection goroutine.
 // The application ensures that there is at most one reader on a connection
-// by executing all reads from this goroutine.
+// by executing all reads from this goroutine. Reading also receives the pongs
+// ping waits for; a peer that stops answering is disconnected by ping.
 func (c *Connection) readPump() {
-	defer func() {
-		c.hub.unregister <- c
-		c.conn.Close()
-	}()
-
-	c.conn.SetReadLimit(maxMessageSize)
-	c.conn.SetReadDeadline(time.Now().Add(pongWait))
-	c.conn.SetPongHandler(func(string) error { c.conn.SetReadDeadline(time.Now().Add(pongWait)); return nil })
-
 	for {
-		_, _, err := c.conn.ReadMessage()
+		msgType, data, err := c.conn.Read(context.Background())
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				c.hub.logger.Warnf(context.Background(), "websocket: unexpected close error user_id=%s: %v", c.userID, err)
-			}
-			break
+			c.readDone(err)
+			return
 		}
-		// We currently don't handle incoming messages from client (mostly strictly server-to-client push)
-		// If we did, we would process them here.
+		c.handleInbound(msgType, data)
+	}
+}
+
+// readDone unregisters the connection once reading ended with err.
+func (c *Connection) readDone(err error) {
+	kind := "normal"
+	if code := transport.CloseStatus(err); code != -1 && code != transport.CloseGoingAway && code != transport.CloseAbnormalClosure {
+		kind = "unexpected"
+		c.hub.logger.Warnf(context.Background(), "websocket: unexpected close error user_id=%s client_label=%q: %v", c.userID, c.clientLabel, err)
 	}
+	metrics.Disconnects.WithLabelValues(c.metricLabel, kind).Inc()
+
+	c.hub.unregister <- c
+	c.conn.CloseNow()
 }
 
 // writePump pumps messages from the hub to the websocket connection.
@@ -69,43 +168,160 @@ func (c *Connection) readPump() {
 // The application ensures that there is at most one writer to a connection
 // by executing all writes from this goroutine.
 func (c *Connection) writePump(logger log.Logger) {
+	c.writeLatency = metrics.WriteDuration.WithLabelValues(c.metricLabel)
 	ticker := time.NewTicker(pingPeriod)
+	pings, stopPings := context.WithCancel(context.Background())
 	defer func() {
 		ticker.Stop()
-		c.conn.Close()
+		stopPings()
+		c.flushFrameMetrics()
+		c.coalesce.close()
+		c.conn.CloseNow()
 	}()
 
+	// Fires when the coalescer's held frames are due; nil while none are held.
+	var flush <-chan time.Time
+
+	// Reused between wake-ups
+	var queued, frames [][]byte
+
 	for {
 		select {
-		case message, ok := <-c.send:
-			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if !ok {
-				// The hub closed the channel.
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
-				return
-			}
+		case <-c.send.ready:
+			var open bool
+			queued, open = c.send.take(queued[:0])
 
-			w, err := c.conn.NextWriter(websocket.TextMessage)
-			if err != nil {
+			// Write every queued message as one websocket message, except
+			// those the coalescer holds for its next flush.
+			frames = frames[:0]
+			for _, message := range queued {
+				frames = c.unheld(frames, message)
+			}
+			if flush == nil && c.coalesce.held() {
+				flush = time.After(c.coalesce.interval())
+			}
+			if len(frames) > 0 {
+				if err := c.writeFrames(frames); err != nil {
+					return
+				}
+			}
+			if !open {
+				// The hub closed the queue.
+				c.conn.Close(transport.CloseNoStatusReceived, "")
 				return
 			}
-			w.Write(message)
 
-			// Add queued chat messages to the current websocket message.
-			n := len(c.send)
-			for i := 0; i < n; i++ {
-				w.Write(<-c.send)
+		case <-flush:
+			flush = nil
+			c.coalesce.adapt(c.rtt(), c.send.len(), c.send.cap())
+			// Empty if the held frames were dropped meanwhile
+			if held := c.coalesce.take(); len(held) > 0 {
+				if err := c.writeFrames(held); err != nil {
+					return
+				}
 			}
 
-			if err := w.Close(); err != nil {
-				return
+		case req := <-c.closeReq:
+			// Flush what is already held and queued (e.g. the notice explaining the close).
+			for _, message := range c.coalesce.take() {
+				if err := c.write(c.encode(message)); err != nil {
+					return
+				}
+			}
+			queued, _ = c.send.take(queued[:0])
+			for _, message := range queued {
+				if err := c.write(c.encode(message)); err != nil {
+					return
+				}
 			}
+			c.conn.Close(req.code, req.reason)
+			return
 
 		case <-ticker.C:
-			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				return
+			c.flushFrameMetrics()
+			go c.ping(pings)
+			if c.heartbeat {
+				if err := c.write(c.heartbeatFrame()); err != nil {
+					return
+				}
 			}
 		}
 	}
 }
+
+// flushFrameMetrics publishes the per-frame counts writePump accumulated since
+// the last flush. Counting locally and flushing once per ping period keeps
+// thousands of writePumps from contending on the same counters at high fanout.
+func (c *Connection) flushFrameMetrics() {
+	c.coalesce.flushMetrics()
+	c.delta.flushMetrics()
+}
+
+// unheld appends message to frames unless the coalescer holds it.
+func (c *Connection) unheld(frames [][]byte, message []byte) [][]byte {
+	if c.coalesce.hold(message) {
+		return frames
+	}
+	return append(frames, message)
+}
+
+// writeFrames writes frames as a single text message and records the time
+// until it was flushed. Frames carry no trace, so there is no exemplar.
+func (c *Connection) writeFrames(frames [][]byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), writeWait)
+	defer cancel()
+
+	start := time.Now()
+	w, err := c.conn.Writer(ctx, transport.MessageText)
+	if err != nil {
+		return err
+	}
+	for _, message := range frames {
+		w.Write(c.encode(message))
+	}
+	err = w.Close()
+	c.writeLatency.Observe(time.Since(start).Seconds())
+	if c.traces.traced(c.userID) {
+		c.traceWrite(frames, start)
+	}
+	return err
+}
+
+// write writes message as one text message within writeWait.
+func (c *Connection) write(message []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), writeWait)
+	defer cancel()
+	return c.conn.Write(ctx, transport.MessageText, message)
+}
+
+// ping measures the round-trip time of a ping and refreshes the user's
+// presence once the pong arrives. A peer that does not answer within pongWait
+// is disconnected, which ends reading and writePump. It runs in its own
+// goroutine so writePump keeps writing while the pong is pending; ctx ends
+// with writePump.
+func (c *Connection) ping(ctx context.Context) {
+	if !c.pinging.CompareAndSwap(false, true) {
+		return
+	}
+	defer c.pinging.Store(false)
+
+	ctx, cancel := context.WithTimeout(ctx, pongWait)
+	defer cancel()
+
+	start := time.Now()
+	if err := c.conn.Ping(ctx); err != nil {
+		c.conn.CloseNow()
+		return
+	}
+	c.observeRTT(time.Since(start))
+	c.presence.refresh(c.userID)
+}
+
+// closeWith asks writePump to send a close frame with the given code and reason
+// and then terminate the connection. It never blocks; repeated requests are ignored.
+func (c *Connection) closeWith(code int, reason string) {
+	select {
+	case c.closeReq <- closeFrame{code: code, reason: reason}:
+	default:
+	}
+}
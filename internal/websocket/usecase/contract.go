@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ws "notification-srv/internal/websocket"
+)
+
+// CheckContract validates a sample message the way ProcessMessage would, but
+// always in strict mode and without routing, metrics or alerts. Every problem
+// found is reported instead of stopping at the first one.
+func (uc *implUseCase) CheckContract(ctx context.Context, input ws.CheckContractInput) (ws.CheckContractOutput, error) {
+	var out ws.CheckContractOutput
+
+	if input.Channel != "" {
+		parsed, err := parseChannel(input.Channel)
+		if err != nil {
+			out.Errors = append(out.Errors, fmt.Sprintf("channel %q: %v", input.Channel, err))
+		} else {
+			out.ChannelType = parsed.ChannelType
+		}
+	}
+
+	msgType, err := detectMessageType(input.Payload)
+	if err != nil {
+		out.Errors = append(out.Errors, fmt.Sprintf("detect type: %v", err))
+		return out, nil
+	}
+	out.MessageType = msgType
+
+	data, violation, err := decodeTyped(msgType, input.Payload, true)
+	if err != nil {
+		out.Errors = append(out.Errors, fmt.Sprintf("decode: %v", err))
+		return out, nil
+	}
+	out.Errors = append(out.Errors, flattenErrors(violation)...)
+
+	out.Output = &ws.NotificationOutput{
+		Type:      msgType,
+		Timestamp: time.Now(),
+		Payload:   data,
+	}
+	return out, nil
+}
+
+// flattenErrors expands errors.Join trees into one message per violation.
+func flattenErrors(err error) []string {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var msgs []string
+		for _, e := range joined.Unwrap() {
+			msgs = append(msgs, flattenErrors(e)...)
+		}
+		return msgs
+	}
+	return []string{err.Error()}
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	ws "notification-srv/internal/websocket"
+
+	"github.com/google/uuid"
+)
+
+// handleControl dispatches a control channel event.
+// Unknown events are logged and ignored so publishers can roll out new events first.
+func (uc *implUseCase) handleControl(ctx context.Context, parsed ParsedChannel, payload []byte) error {
+	switch parsed.SubType {
+	case ws.ControlEventProjectDeleted:
+		return uc.handleProjectDeleted(ctx, parsed.EntityID)
+	case ws.ControlEventProjectCompleted, ws.ControlEventProjectFailed:
+		return uc.scheduleProjectGC(ctx, parsed.EntityID, parsed.SubType)
+	case ws.ControlEventMaintenance:
+		return uc.handleMaintenanceControl(ctx, parsed.EntityID, payload)
+	case ws.ControlEventBroadcast:
+		return uc.handleBroadcastControl(ctx, payload)
+	case ws.ControlEventBan:
+		return uc.handleBanControl(ctx, parsed.EntityID, payload)
+	case ws.ControlEventAnnouncement:
+		return uc.handleAnnouncementControl(ctx, payload)
+	case ws.ControlEventTrace:
+		return uc.handleTraceControl(ctx, parsed.EntityID, payload)
+	default:
+		uc.logger.Warnf(ctx, "unknown control event: %s", parsed.SubType)
+		return nil
+	}
+}
+
+// handleProjectDeleted notifies and closes every connection filtered to the deleted
+// project with close code 4040, so clients stop waiting on a topic that is gone.
+// The project's history goes with it: everything purgeProject drops, and the
+// latest states shared by all replicas.
+func (uc *implUseCase) handleProjectDeleted(ctx context.Context, projectID string) error {
+	if projectID == "" {
+		return ws.ErrInvalidChannel
+	}
+
+	notice, err := json.Marshal(ws.NotificationOutput{
+		ID:        uuid.NewString(),
+		Type:      ws.MessageTypeSystem,
+		Timestamp: time.Now(),
+		Payload: map[string]string{
+			"system_event": ws.ControlEventProjectDeleted,
+			"project_id":   projectID,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("marshal project deleted notice: %w", err)
+	}
+
+	closed := uc.hub.CloseProject(projectID, notice, ws.CloseCodeTopicGone, "topic gone")
+	purged := uc.purgeProject(projectID)
+	uc.latest.drop(projectID)
+	uc.logger.Infof(ctx, "project deleted: project_id=%s closed_connections=%d purged_buffers=%d", projectID, closed, purged)
+	return nil
+}
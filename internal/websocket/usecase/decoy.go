@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"notification-srv/internal/alert"
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+)
+
+// decoyPattern returns the first decoy pattern projectID matches, or empty if
+// it is not a decoy.
+func (uc *implUseCase) decoyPattern(projectID string) string {
+	if projectID == "" {
+		return ""
+	}
+	for _, pattern := range uc.decoy.Patterns {
+		if ok, _ := path.Match(pattern, projectID); ok {
+			return pattern
+		}
+	}
+	return ""
+}
+
+// CheckDecoy reports a subscription to a decoy project: a security event in
+// the log, a Discord alert and, if configured, a temporary ban that closes the
+// subscriber's connections on every replica. Without a ban the subscription
+// goes ahead, so the caller cannot tell decoys from other projects.
+func (uc *implUseCase) CheckDecoy(ctx context.Context, input ws.CheckDecoyInput) error {
+	pattern := uc.decoyPattern(input.ProjectID)
+	if pattern == "" {
+		return nil
+	}
+	metrics.DecoySubscriptions.WithLabelValues(input.Transport).Inc()
+
+	var bannedFor time.Duration
+	if uc.decoy.BanTTL > 0 {
+		subjects := [...]struct{ kind, value string }{{ws.BanKindUser, input.UserID}, {ws.BanKindIP, input.RemoteIP}}
+		for _, s := range subjects {
+			if s.value == "" || (s.kind == ws.BanKindIP && !uc.decoy.BanIP) {
+				continue
+			}
+			if _, err := uc.ban(ctx, s.kind, s.value, uc.decoy.BanTTL); err != nil {
+				uc.logger.Warnf(ctx, "decoy ban failed: kind=%s subject=%s: %v", s.kind, s.value, err)
+				continue
+			}
+			bannedFor = uc.decoy.BanTTL
+		}
+	}
+
+	uc.logger.Warnf(ctx, "security event: decoy project subscription project_id=%s pattern=%s user_id=%s remote_ip=%s transport=%s banned_for=%s",
+		input.ProjectID, pattern, input.UserID, input.RemoteIP, input.Transport, bannedFor)
+
+	if uc.alertUC != nil {
+		alertInput := alert.DecoyProjectInput{
+			ProjectID: input.ProjectID,
+			Pattern:   pattern,
+			UserID:    input.UserID,
+			RemoteIP:  input.RemoteIP,
+			Transport: input.Transport,
+			BannedFor: bannedFor,
+		}
+		go func() {
+			if err := uc.alertUC.DispatchDecoyProject(context.Background(), alertInput); err != nil {
+				uc.logger.Warnf(ctx, "alert dispatch failed: %v", err)
+			}
+		}()
+	}
+
+	if bannedFor > 0 {
+		return ws.ErrBanned
+	}
+	return nil
+}
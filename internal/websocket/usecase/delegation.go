@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+)
+
+// CheckDelegation lets input.ActorID subscribe to input.UserID's stream if
+// the authorizer allows it. Every attempt leaves an audit log line; lookup
+// errors deny.
+func (uc *implUseCase) CheckDelegation(ctx context.Context, input ws.DelegationInput) error {
+	if input.ActorID == input.UserID {
+		return nil
+	}
+
+	result := "granted"
+	if uc.delegation == nil {
+		result = "disabled"
+	} else {
+		ok, err := uc.delegation.CanActAsUser(ctx, input)
+		switch {
+		case err != nil:
+			result = "error"
+			uc.logger.Warnf(ctx, "delegation lookup failed: actor_id=%s user_id=%s: %v", input.ActorID, input.UserID, err)
+		case !ok:
+			result = "denied"
+		}
+	}
+	metrics.DelegatedSubscriptions.WithLabelValues(result).Inc()
+
+	if result != "granted" {
+		uc.logger.Warnf(ctx, "audit: delegated subscription %s: actor_id=%s user_id=%s remote_ip=%s",
+			result, input.ActorID, input.UserID, input.RemoteIP)
+		return ws.ErrDelegationDenied
+	}
+	uc.logger.Infof(ctx, "audit: delegated subscription granted: actor_id=%s user_id=%s remote_ip=%s",
+		input.ActorID, input.UserID, input.RemoteIP)
+	return nil
+}
@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+)
+
+// maxDeltaBases caps the collapse keys remembered per connection; past it the
+// encoder starts over with full frames.
+const maxDeltaBases = 256
+
+var errNullValue = errors.New("frame has a null value")
+
+// deltaBase is the last frame a client holds for one collapse key.
+type deltaBase struct {
+	id     string
+	frame  []byte
+	deltas int // Deltas sent since the last full frame
+}
+
+// deltaEncoder turns consecutive frames with the same collapse key into JSON
+// Merge Patches. It is owned by writePump and not safe for concurrent use.
+type deltaEncoder struct {
+	snapshotEvery int
+	bases         map[string]deltaBase
+
+	// Frames encoded since the last flushMetrics
+	deltas, fulls int
+}
+
+func newDeltaEncoder(snapshotEvery int) *deltaEncoder {
+	return &deltaEncoder{
+		snapshotEvery: snapshotEvery,
+		bases:         make(map[string]deltaBase),
+	}
+}
+
+// encode returns the delta frame for message, or message itself when it has no
+// collapse key, a full snapshot is due, or the delta would not be smaller.
+func (e *deltaEncoder) encode(message []byte) []byte {
+	var head struct {
+		ID          string `json:"id"`
+		CollapseKey string `json:"collapse_key"`
+	}
+	if err := json.Unmarshal(message, &head); err != nil || head.CollapseKey == "" {
+		return message
+	}
+
+	base, ok := e.bases[head.CollapseKey]
+	if ok && base.deltas+1 < e.snapshotEvery {
+		if out, ok := e.delta(base, head.CollapseKey, message); ok {
+			e.bases[head.CollapseKey] = deltaBase{id: head.ID, frame: message, deltas: base.deltas + 1}
+			e.deltas++
+			return out
+		}
+	}
+
+	if !ok && len(e.bases) >= maxDeltaBases {
+		e.bases = make(map[string]deltaBase)
+	}
+	e.bases[head.CollapseKey] = deltaBase{id: head.ID, frame: message}
+	e.fulls++
+	return message
+}
+
+// flushMetrics adds the frames encoded since the last call to the shared counters.
+func (e *deltaEncoder) flushMetrics() {
+	if e == nil {
+		return
+	}
+	if e.deltas > 0 {
+		metrics.DeltaFrames.WithLabelValues("delta").Add(float64(e.deltas))
+	}
+	if e.fulls > 0 {
+		metrics.DeltaFrames.WithLabelValues("full").Add(float64(e.fulls))
+	}
+	e.deltas, e.fulls = 0, 0
+}
+
+func (e *deltaEncoder) delta(base deltaBase, collapseKey string, message []byte) ([]byte, bool) {
+	patch, err := mergePatch(base.frame, message)
+	if err != nil {
+		return nil, false
+	}
+	out, err := json.Marshal(ws.DeltaFrame{
+		Type:        "delta",
+		CollapseKey: collapseKey,
+		BaseID:      base.id,
+		Patch:       patch,
+	})
+	if err != nil || len(out) >= len(message) {
+		return nil, false
+	}
+	return out, true
+}
+
+// mergePatch builds the RFC 7386 merge patch turning the object from into to.
+// Arrays are replaced whole. Null values in to cannot be expressed (null means
+// delete) and return errNullValue.
+func mergePatch(from, to []byte) ([]byte, error) {
+	var src, dst map[string]json.RawMessage
+	if err := json.Unmarshal(from, &src); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(to, &dst); err != nil {
+		return nil, err
+	}
+
+	patch := make(map[string]json.RawMessage)
+	for k, v := range dst {
+		if bytes.Equal(v, []byte("null")) {
+			return nil, errNullValue
+		}
+		old, ok := src[k]
+		if ok && bytes.Equal(old, v) {
+			continue
+		}
+		if ok && isJSONObject(old) && isJSONObject(v) {
+			sub, err := mergePatch(old, v)
+			if err != nil {
+				return nil, err
+			}
+			patch[k] = sub
+			continue
+		}
+		patch[k] = v
+	}
+	for k := range src {
+		if _, ok := dst[k]; !ok {
+			patch[k] = json.RawMessage("null")
+		}
+	}
+	return json.Marshal(patch)
+}
+
+func isJSONObject(v json.RawMessage) bool {
+	v = bytes.TrimSpace(v)
+	return len(v) > 0 && v[0] == '{'
+}
+
+// encode passes a queued frame through the connection's delta encoder, if any.
+func (c *Connection) encode(message []byte) []byte {
+	if c.delta == nil {
+		return message
+	}
+	return c.delta.encode(message)
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"sync/atomic"
+
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+)
+
+// dropReason is why a frame did not reach a connection.
+type dropReason int
+
+const (
+	dropBufferFull dropReason = iota // Send queue full: the frame, or with drop_oldest the oldest queued one
+	dropFiltered                     // Message type not subscribed to by the connection
+	dropClosed                       // Connection closing or gone
+	numDropReasons
+)
+
+var dropReasonNames = [numDropReasons]string{
+	dropBufferFull: "buffer_full",
+	dropFiltered:   "filtered",
+	dropClosed:     "closed",
+}
+
+func (r dropReason) String() string {
+	return dropReasonNames[r]
+}
+
+// dropCounts counts the frames a connection dropped, by reason.
+type dropCounts [numDropReasons]atomic.Uint64
+
+// drop records a frame dropped for reason.
+func (c *Connection) drop(reason dropReason) {
+	c.drops[reason].Add(1)
+	metrics.FramesDropped.WithLabelValues(reason.String(), c.metricLabel).Inc()
+}
+
+// enqueue pushes message to the send queue and records a drop if it was
+// refused, or if the oldest queued frame was evicted for it. Returns whether
+// message was queued.
+func (c *Connection) enqueue(message []byte) bool {
+	return c.queued(c.send.push(message))
+}
+
+// enqueuePriority is enqueue for frames of the priority lane: the oldest queued
+// frame is evicted for message rather than message being refused.
+func (c *Connection) enqueuePriority(message []byte) bool {
+	return c.queued(c.send.pushPriority(message))
+}
+
+// enqueueType enqueues message in the lane of msgType.
+func (c *Connection) enqueueType(msgType ws.MessageType, message []byte) bool {
+	if ws.PriorityMessageTypes[msgType] {
+		return c.enqueuePriority(message)
+	}
+	return c.enqueue(message)
+}
+
+// queued records the drop of a push, if any, and reports whether the frame was
+// queued.
+func (c *Connection) queued(result pushResult) bool {
+	switch result {
+	case pushQueued:
+		return true
+	case pushEvicted:
+		c.drop(dropBufferFull)
+		return true
+	case pushFull:
+		c.drop(dropBufferFull)
+	case pushClosed:
+		c.drop(dropClosed)
+	}
+	return false
+}
+
+// dropStats returns the connection's non-zero drop counts by reason name and
+// the reason with the most drops, empty if it dropped nothing.
+func (c *Connection) dropStats() (counts map[string]uint64, top string) {
+	var most uint64
+	for r := dropReason(0); r < numDropReasons; r++ {
+		n := c.drops[r].Load()
+		if n == 0 {
+			continue
+		}
+		if counts == nil {
+			counts = make(map[string]uint64)
+		}
+		counts[r.String()] = n
+		if n > most {
+			most, top = n, r.String()
+		}
+	}
+	return counts, top
+}
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+
+	"github.com/smap-hcmut/shared-libs/go/log"
+)
+
+// Events buffered per subscriber before new ones are dropped.
+const eventQueueSize = 1024
+
+func newEventBus(logger log.Logger) *eventBus {
+	return &eventBus{
+		logger: logger,
+		subs:   make(map[int]*eventSubscriber),
+	}
+}
+
+func (uc *implUseCase) SubscribeHubEvents(types []ws.HubEventType, handler ws.HubEventHandler) func() {
+	return uc.hub.events.subscribe(types, handler)
+}
+
+// subscribe registers handler for types (every type if empty) and returns the
+// function that unsubscribes it.
+func (b *eventBus) subscribe(types []ws.HubEventType, handler ws.HubEventHandler) func() {
+	sub := &eventSubscriber{
+		queue:   make(chan ws.HubEvent, eventQueueSize),
+		handler: handler,
+		logger:  b.logger,
+	}
+	if len(types) > 0 {
+		sub.types = make(map[ws.HubEventType]bool, len(types))
+		for _, t := range types {
+			sub.types[t] = true
+		}
+	}
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = sub
+	b.count.Add(1)
+	b.mu.Unlock()
+
+	go sub.run()
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.subs[id]; ok {
+			delete(b.subs, id)
+			b.count.Add(-1)
+			close(sub.queue)
+		}
+	}
+}
+
+// enabled reports whether anyone listens, so callers can skip building events.
+func (b *eventBus) enabled() bool {
+	return b.count.Load() > 0
+}
+
+// publish hands e to every subscriber of its type without blocking.
+func (b *eventBus) publish(e ws.HubEvent) {
+	if !b.enabled() {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subs {
+		if sub.types != nil && !sub.types[e.Type] {
+			continue
+		}
+		select {
+		case sub.queue <- e:
+		default:
+			metrics.HubEventsDropped.WithLabelValues(string(e.Type)).Inc()
+		}
+	}
+}
+
+// run delivers queued events until the subscriber is removed. A panicking
+// handler loses that event only.
+func (s *eventSubscriber) run() {
+	for e := range s.queue {
+		s.handle(e)
+	}
+}
+
+func (s *eventSubscriber) handle(e ws.HubEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Errorf(context.Background(), "hub event handler panic on %s: %v", e.Type, r)
+		}
+	}()
+	s.handler(e)
+}
+
+// connectionEvent builds a connection lifecycle event. Must be called with the Hub lock held.
+func (h *Hub) connectionEvent(t ws.HubEventType, client *Connection) ws.HubEvent {
+	return ws.HubEvent{
+		Type:            t,
+		UserID:          client.userID,
+		ProjectID:       client.projectID,
+		Stream:          client.conn == nil,
+		Public:          client.public,
+		DelegatedBy:     client.delegatedBy,
+		UserConnections: len(h.users[client.userID]),
+		RemoteIP:        client.remoteIP,
+		Fingerprint:     client.fingerprint,
+	}
+}
+
+// publishDelivery reports the Hub outcome of routing one message.
+func (uc *implUseCase) publishDelivery(parsed ParsedChannel, output ws.NotificationOutput, recipients, dropped int) {
+	if !uc.hub.events.enabled() {
+		return
+	}
+
+	e := ws.HubEvent{
+		Type:        ws.HubEventMessageDelivered,
+		UserID:      parsed.UserID,
+		Topic:       output.Topic,
+		MessageID:   output.ID,
+		MessageType: output.Type,
+		Recipients:  recipients,
+		Dropped:     dropped,
+	}
+	if parsed.ChannelType == ws.ChannelTypeProject {
+		e.ProjectID = parsed.EntityID
+	}
+	uc.hub.events.publish(e)
+
+	if dropped > 0 {
+		e.Type = ws.HubEventMessageDropped
+		e.DropReason = ws.DropReasonBufferFull
+		uc.hub.events.publish(e)
+	}
+}
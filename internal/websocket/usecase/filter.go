@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	ws "notification-srv/internal/websocket"
+)
+
+// typeSet is an immutable allow-set of message types.
+type typeSet map[ws.MessageType]bool
+
+// newTypeSet builds the allow-set for a connection. Names are case-insensitive.
+// An empty list returns nil, which accepts every type.
+func newTypeSet(types []ws.MessageType) (*typeSet, error) {
+	if len(types) == 0 {
+		return nil, nil
+	}
+	set := make(typeSet, len(types))
+	for _, t := range types {
+		t = ws.MessageType(strings.ToUpper(string(t)))
+		if !ws.FilterableMessageTypes[t] {
+			return nil, fmt.Errorf("%w: %s", ws.ErrUnknownMessageType, t)
+		}
+		set[t] = true
+	}
+	return &set, nil
+}
+
+// setTypes replaces the connection's type filter.
+func (c *Connection) setTypes(types []ws.MessageType) error {
+	set, err := newTypeSet(types)
+	if err != nil {
+		return err
+	}
+	c.types.Store(set)
+	return nil
+}
+
+// accepts reports whether a frame of type t should be queued to the connection.
+// Types outside FilterableMessageTypes (SYSTEM, HEARTBEAT) always pass.
+func (c *Connection) accepts(t ws.MessageType) bool {
+	set := c.types.Load()
+	if set == nil || !ws.FilterableMessageTypes[t] {
+		return true
+	}
+	return (*set)[t]
+}
+
+// setMinImportance replaces the connection's importance filter. Names are
+// case-insensitive; empty receives all.
+func (c *Connection) setMinImportance(name ws.Importance) error {
+	var min ws.Importance
+	if name != "" {
+		var err error
+		if min, err = ws.ParseImportance(string(name)); err != nil {
+			return fmt.Errorf("%w: importance %s", err, name)
+		}
+	}
+	c.minImportance.Store(min)
+	return nil
+}
+
+// importanceFilter returns the connection's minimum importance, empty if none.
+func (c *Connection) importanceFilter() ws.Importance {
+	min, _ := c.minImportance.Load().(ws.Importance)
+	return min
+}
+
+// wants reports whether a frame of type t and importance i should be queued
+// to the connection: its type is accepted and it is at least as important as
+// the client asked. Frames without an importance and of types outside
+// FilterableMessageTypes pass the importance filter.
+func (c *Connection) wants(t ws.MessageType, i ws.Importance) bool {
+	if !c.accepts(t) {
+		return false
+	}
+	min := c.importanceFilter()
+	return min == "" || i == "" || !ws.FilterableMessageTypes[t] || i.Rank() >= min.Rank()
+}
+
+// typeList returns the connection's type filter sorted, or nil if it has none.
+func (c *Connection) typeList() []ws.MessageType {
+	set := c.types.Load()
+	if set == nil {
+		return nil
+	}
+	types := make([]ws.MessageType, 0, len(*set))
+	for t := range *set {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ws "notification-srv/internal/websocket"
+)
+
+// fixtureSeeds returns the channels and payloads of the golden fixtures, so
+// the fuzzers start from realistic publisher messages.
+func fixtureSeeds(f *testing.F) (channels []string, payloads [][]byte) {
+	paths, err := filepath.Glob(filepath.Join("..", "testdata", "fixtures", "*.json"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		var fx struct {
+			Channel string          `json:"channel"`
+			Payload json.RawMessage `json:"payload"`
+			Raw     string          `json:"raw"`
+		}
+		if err := json.Unmarshal(data, &fx); err != nil {
+			f.Fatalf("%s: %v", path, err)
+		}
+		channels = append(channels, fx.Channel)
+		if fx.Raw != "" {
+			payloads = append(payloads, []byte(fx.Raw))
+		} else {
+			payloads = append(payloads, fx.Payload)
+		}
+	}
+	return channels, payloads
+}
+
+func FuzzParseChannel(f *testing.F) {
+	channels, _ := fixtureSeeds(f)
+	for _, ch := range append(channels, "control:project_deleted:proj_001", "system:", "::", "project::user:") {
+		f.Add(ch)
+	}
+
+	f.Fuzz(func(t *testing.T, channel string) {
+		parsed, err := parseChannel(channel)
+		if err != nil {
+			if !errors.Is(err, ws.ErrInvalidChannel) {
+				t.Fatalf("parseChannel(%q) returned unexpected error %v", channel, err)
+			}
+			return
+		}
+		if parsed.ChannelType == "" {
+			t.Fatalf("parseChannel(%q) accepted without a channel type", channel)
+		}
+		if !strings.HasPrefix(channel, string(parsed.ChannelType)+":") {
+			t.Fatalf("parseChannel(%q) = %q, not the channel prefix", channel, parsed.ChannelType)
+		}
+		switch parsed.ChannelType {
+		case ws.ChannelTypeProject, ws.ChannelTypeCampaign, ws.ChannelTypeAlert:
+			if !strings.HasSuffix(channel, ":"+parsed.UserID) {
+				t.Fatalf("parseChannel(%q) user %q is not the last segment", channel, parsed.UserID)
+			}
+		}
+	})
+}
+
+func FuzzTransform(f *testing.F) {
+	_, payloads := fixtureSeeds(f)
+	for _, p := range payloads {
+		f.Add(p, false)
+		f.Add(p, true)
+	}
+
+	f.Fuzz(func(t *testing.T, payload []byte, strict bool) {
+		msgType, err := detectMessageType(payload)
+		if err != nil {
+			return
+		}
+
+		data, _, err := decodeTyped(msgType, payload, strict)
+		if errors.Is(err, ws.ErrUnknownMessageType) {
+			t.Fatalf("detected type %s cannot be decoded", msgType)
+		}
+		if err != nil {
+			return
+		}
+
+		groupKey, collapseKey := notificationKeys(data)
+		if collapseKey != "" && !strings.HasPrefix(collapseKey, groupKey+":") {
+			t.Fatalf("collapse key %q is outside group %q", collapseKey, groupKey)
+		}
+		if _, err := json.Marshal(ws.NotificationOutput{Type: msgType, Payload: data}); err != nil {
+			t.Fatalf("decoded %s payload does not marshal: %v", msgType, err)
+		}
+	})
+}
+
+// FuzzStreamingDecode checks that the streaming candidate transformer accepts
+// and rejects the same payloads as encoding/json and yields the same frames.
+// The decoded values are compared encoded, as invalid UTF-8 only gets replaced
+// by encoding/json on decode but always does on encode.
+func FuzzStreamingDecode(f *testing.F) {
+	_, payloads := fixtureSeeds(f)
+	for _, p := range payloads {
+		f.Add(p, false)
+		f.Add(p, true)
+	}
+
+	f.Fuzz(func(t *testing.T, payload []byte, strict bool) {
+		msgType, err := detectMessageType(payload)
+		if err != nil {
+			return
+		}
+
+		want, wantViolation, wantErr := decodeTyped(msgType, payload, strict)
+		got, gotViolation, gotErr := decodeTypedWith(decodePayloadStreaming, msgType, payload, strict)
+		if (wantErr == nil) != (gotErr == nil) || (wantViolation == nil) != (gotViolation == nil) {
+			t.Fatalf("%s payload: encoding/json err=%v violation=%v, streaming err=%v violation=%v",
+				msgType, wantErr, wantViolation, gotErr, gotViolation)
+		}
+		if wantErr != nil {
+			return
+		}
+		wantFrame, _ := json.Marshal(want)
+		gotFrame, _ := json.Marshal(got)
+		if !bytes.Equal(wantFrame, gotFrame) {
+			t.Fatalf("%s payload decoded differently:\nencoding/json %s\nstreaming     %s", msgType, wantFrame, gotFrame)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	ws "notification-srv/internal/websocket"
+
+	"github.com/google/uuid"
+)
+
+func newTopicGC(grace time.Duration) *topicGC {
+	return &topicGC{
+		grace:   grace,
+		pending: make(map[string]*time.Timer),
+	}
+}
+
+// scheduleProjectGC cleans up a finished project's topic after the grace
+// period, unless new messages arrive on it first. A repeated event restarts it.
+func (uc *implUseCase) scheduleProjectGC(ctx context.Context, projectID, event string) error {
+	if projectID == "" {
+		return ws.ErrInvalidChannel
+	}
+	g := uc.gc
+	if g.grace <= 0 {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if t, ok := g.pending[projectID]; ok {
+		t.Stop()
+	}
+	var t *time.Timer
+	t = time.AfterFunc(g.grace, func() {
+		if g.take(projectID, t) {
+			uc.collectProject(projectID, event)
+		}
+	})
+	g.pending[projectID] = t
+
+	uc.logger.Infof(ctx, "project topic cleanup scheduled: project_id=%s event=%s grace=%s", projectID, event, g.grace)
+	return nil
+}
+
+// cancel drops the pending cleanup of a project that is active again.
+func (g *topicGC) cancel(projectID string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if t, ok := g.pending[projectID]; ok {
+		t.Stop()
+		delete(g.pending, projectID)
+	}
+}
+
+// take removes a fired cleanup and reports whether it was still the pending one.
+func (g *topicGC) take(projectID string, t *time.Timer) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.pending[projectID] != t {
+		return false
+	}
+	delete(g.pending, projectID)
+	return true
+}
+
+// purgeProject drops what this replica keeps of the project: at-least-once
+// frames pending or queued offline, replay buffers, job statuses and frames
+// held for a coalesced flush. Connections viewing the project no longer do,
+// and their users' presence is rewritten without it. Returns the number of
+// replay buffers dropped.
+func (uc *implUseCase) purgeProject(projectID string) int {
+	// Before the replay buffers, which tell the users the project was delivered to
+	uc.dropReliable(projectID)
+
+	prefix := topicName(ws.ChannelTypeProject, projectID) + ":"
+	purged := uc.seq.purge(prefix)
+	uc.transitions.purge(prefix)
+	uc.terminals.purge(prefix)
+
+	_, unfocused := uc.hub.forgetProject(projectID)
+	for _, userID := range unfocused {
+		uc.presence.refresh(userID)
+	}
+	return purged
+}
+
+// collectProject closes the connections filtered to the project with close
+// code 4040 and purges the project (see purgeProject). Connections without a
+// project filter are kept; they may still follow the user's other projects.
+func (uc *implUseCase) collectProject(projectID, event string) {
+	ctx := context.Background()
+
+	notice, err := json.Marshal(ws.NotificationOutput{
+		ID:        uuid.NewString(),
+		Type:      ws.MessageTypeSystem,
+		Timestamp: time.Now(),
+		Payload: map[string]string{
+			"system_event": event,
+			"project_id":   projectID,
+		},
+	})
+	if err != nil {
+		uc.logger.Errorf(ctx, "marshal project cleanup notice: %v", err)
+		return
+	}
+
+	closed := uc.hub.CloseProject(projectID, notice, ws.CloseCodeTopicGone, "topic gone")
+	purged := uc.purgeProject(projectID)
+	uc.logger.Infof(ctx, "project topic cleaned up: project_id=%s closed_connections=%d purged_buffers=%d", projectID, closed, purged)
+}
@@ -13,6 +13,7 @@ import (
 // - campaign:{campaign_id}:user:{user_id}
 // - alert:{subtype}:user:{user_id}
 // - system:{subtype}
+// - control:{event}[:{entity_id}]
 func parseChannel(channel string) (ParsedChannel, error) {
 	parts := strings.Split(channel, ":")
 	if len(parts) < 2 {
@@ -52,6 +53,17 @@ func parseChannel(channel string) (ParsedChannel, error) {
 		result.ChannelType = websocket.ChannelTypeSystem
 		result.SubType = parts[1]
 
+	case "control":
+		// control:project_deleted:{project_id}, control:announcement
+		if len(parts) > 3 {
+			return ParsedChannel{}, websocket.ErrInvalidChannel
+		}
+		result.ChannelType = websocket.ChannelTypeControl
+		result.SubType = parts[1]
+		if len(parts) == 3 {
+			result.EntityID = parts[2]
+		}
+
 	default:
 		return ParsedChannel{}, websocket.ErrInvalidChannel
 	}
@@ -66,32 +78,45 @@ func parseChannel(channel string) (ParsedChannel, error) {
 // Better approach: Rely on the structure or distinct fields.
 // For this strict implementation, let's assume specific unique fields.
 func detectMessageType(payload []byte) (websocket.MessageType, error) {
-	var partial map[string]interface{}
-	if err := json.Unmarshal(payload, &partial); err != nil {
+	// Only the presence of the fields below matters, so values aren't decoded
+	partial := probePool.Get().(*typeProbe)
+	defer probePool.Put(partial)
+	*partial = typeProbe{}
+	if err := json.Unmarshal(payload, partial); err != nil {
 		return "", err
 	}
 
 	// Heuristics based on unique fields
-	if _, ok := partial["source_id"]; ok {
+	if partial.SourceID {
 		// Could be DataOnboarding or AnalyticsPipeline
 		// Check for specific fields
-		if _, ok := partial["total_records"]; ok {
+		if partial.TotalRecords {
 			return websocket.MessageTypeAnalyticsPipeline, nil
 		}
-		if _, ok := partial["record_count"]; ok {
+		if partial.RecordCount {
 			return websocket.MessageTypeDataOnboarding, nil
 		}
 	}
 
-	if _, ok := partial["alert_type"]; ok {
+	if partial.AlertType {
 		return websocket.MessageTypeCrisisAlert, nil
 	}
 
-	if _, ok := partial["campaign_id"]; ok {
+	if partial.CampaignID {
 		return websocket.MessageTypeCampaignEvent, nil
 	}
 
-	if _, ok := partial["system_event"]; ok {
+	if partial.SecurityEvent {
+		return websocket.MessageTypeSecurityAlert, nil
+	}
+	if partial.BillingEvent {
+		return websocket.MessageTypeBillingEvent, nil
+	}
+	if partial.MaintenanceID {
+		return websocket.MessageTypeSystemMaintenance, nil
+	}
+
+	if partial.SystemEvent {
 		return websocket.MessageTypeSystem, nil
 	}
 
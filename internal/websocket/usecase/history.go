@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	ws "notification-srv/internal/websocket"
+)
+
+// newStatsHistory returns nil when sampling is disabled. A nil *statsHistory
+// records nothing and returns no samples.
+func newStatsHistory(cfg StatsHistoryConfig) *statsHistory {
+	if cfg.Interval <= 0 {
+		return nil
+	}
+	size := int(cfg.Retention / cfg.Interval)
+	if size < 1 {
+		size = 1
+	}
+	return &statsHistory{
+		interval: cfg.Interval,
+		samples:  make([]ws.StatsSample, size),
+	}
+}
+
+// record stores a sample, turning the running totals into counts since the
+// previous sample.
+func (h *statsHistory) record(sample ws.StatsSample, totals channelCounts) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sample.Received = totals.received - h.last.received
+	sample.TransformErrors = totals.transformErrors - h.last.transformErrors
+	sample.Delivered = totals.delivered - h.last.delivered
+	sample.Dropped = totals.dropped - h.last.dropped
+	h.last = totals
+
+	h.samples[h.next] = sample
+	h.next = (h.next + 1) % len(h.samples)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// since returns the samples taken after from, oldest first.
+func (h *statsHistory) since(from time.Time) []ws.StatsSample {
+	if h == nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	start, n := 0, h.next
+	if h.full {
+		start, n = h.next, len(h.samples)
+	}
+	out := make([]ws.StatsSample, 0, n)
+	for i := 0; i < n; i++ {
+		s := h.samples[(start+i)%len(h.samples)]
+		if s.Time.After(from) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (uc *implUseCase) SampleStats(ctx context.Context) {
+	if uc.history == nil {
+		return
+	}
+
+	ticker := time.NewTicker(uc.history.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			active, unique := uc.hub.Stats()
+			uc.history.record(ws.StatsSample{
+				Time:              now,
+				ActiveConnections: active,
+				UniqueUsers:       unique,
+			}, uc.stats.totals())
+		}
+	}
+}
+
+func (uc *implUseCase) GetStatsHistory(ctx context.Context, input ws.GetStatsHistoryInput) (ws.GetStatsHistoryOutput, error) {
+	if input.Window < 0 {
+		return ws.GetStatsHistoryOutput{}, ws.ErrInvalidMessage
+	}
+	if uc.history == nil {
+		return ws.GetStatsHistoryOutput{}, nil
+	}
+
+	var from time.Time
+	if input.Window > 0 {
+		from = time.Now().Add(-input.Window)
+	}
+	return ws.GetStatsHistoryOutput{
+		Interval: uc.history.interval,
+		Samples:  uc.history.since(from),
+	}, nil
+}
@@ -1,8 +1,12 @@
 package usecase
 
 import (
+	"sort"
 	"sync"
 
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+
 	"github.com/smap-hcmut/shared-libs/go/log"
 )
 
@@ -15,11 +19,13 @@ type Hub struct {
 	// user_id -> set of connections
 	users map[string]map[*Connection]bool
 
-	// Inbound messages from the connections.
-	broadcast chan []byte
+	// Project filter index of each user's connections, so project messages
+	// only visit the connections that may want them.
+	// user_id -> project_id ("" = unfiltered) -> set of connections
+	projects map[string]map[string]map[*Connection]bool
 
-	// Register requests from the connections.
-	register chan *Connection
+	// Inbound messages from the connections.
+	broadcast chan broadcastFrame
 
 	// Unregister requests from connections.
 	unregister chan *Connection
@@ -27,16 +33,27 @@ type Hub struct {
 	// Lock for maps
 	mu sync.RWMutex
 
+	// Lifecycle events for in-process subscribers
+	events *eventBus
+
 	logger log.Logger
 }
 
+// broadcastFrame is a message for every connection, queued in the lane of
+// msgType (empty for frames of the service itself).
+type broadcastFrame struct {
+	msgType ws.MessageType
+	message []byte
+}
+
 func newHub(logger log.Logger, maxConnections int) *Hub {
 	return &Hub{
-		broadcast:  make(chan []byte),
-		register:   make(chan *Connection),
+		broadcast:  make(chan broadcastFrame),
 		unregister: make(chan *Connection),
 		clients:    make(map[*Connection]bool),
 		users:      make(map[string]map[*Connection]bool),
+		projects:   make(map[string]map[string]map[*Connection]bool),
+		events:     newEventBus(logger),
 		logger:     logger,
 	}
 }
@@ -44,65 +61,359 @@ func newHub(logger log.Logger, maxConnections int) *Hub {
 func (h *Hub) run() {
 	for {
 		select {
-		case client := <-h.register:
-			h.mu.Lock()
-			h.clients[client] = true
-			if _, ok := h.users[client.userID]; !ok {
-				h.users[client.userID] = make(map[*Connection]bool)
+		case client := <-h.unregister:
+			h.removeClient(client)
+
+		case frame := <-h.broadcast:
+			h.broadcastMessage(frame)
+		}
+	}
+}
+
+// The run loop may be restarted by a supervisor after a panic, so the handlers
+// below release the lock with defer to never leave the Hub locked.
+
+func (h *Hub) addClient(client *Connection) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.add(client)
+}
+
+// admit adds client unless its user already holds quota connections here
+// (0 = no quota). With kickOldest the user's oldest connections are asked to
+// close instead, and their number is returned. Counting and adding under one
+// lock keeps concurrent registrations of a user within the quota.
+func (h *Hub) admit(client *Connection, quota int, kickOldest bool) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	kicked := 0
+	if quota > 0 {
+		conns := h.openConnections(client.userID)
+		if excess := len(conns) - quota + 1; excess > 0 {
+			if !kickOldest {
+				return 0, ws.ErrQuotaExceeded
+			}
+			for _, c := range conns[:excess] {
+				c.closeWith(ws.CloseCodeReplaced, "signed in elsewhere")
 			}
-			h.users[client.userID][client] = true
-			h.mu.Unlock()
+			kicked = excess
+		}
+	}
+	h.add(client)
+	return kicked, nil
+}
 
-		case client := <-h.unregister:
-			h.mu.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.send)
-
-				if userConns, ok := h.users[client.userID]; ok {
-					delete(userConns, client)
-					if len(userConns) == 0 {
-						delete(h.users, client.userID)
-					}
-				}
+// add indexes client and announces it. Must be called with mu held.
+func (h *Hub) add(client *Connection) {
+	h.clients[client] = true
+	metrics.ActiveConnections.WithLabelValues(client.metricLabel).Inc()
+	if client.public {
+		metrics.PublicActiveConnections.Inc()
+	}
+	if _, ok := h.users[client.userID]; !ok {
+		h.users[client.userID] = make(map[*Connection]bool)
+	}
+	h.users[client.userID][client] = true
+
+	byProject := h.projects[client.userID]
+	if byProject == nil {
+		byProject = make(map[string]map[*Connection]bool)
+		h.projects[client.userID] = byProject
+	}
+	if _, ok := byProject[client.projectID]; !ok {
+		byProject[client.projectID] = make(map[*Connection]bool)
+	}
+	byProject[client.projectID][client] = true
+
+	h.events.publish(h.connectionEvent(ws.HubEventConnectionOpened, client))
+	if client.projectID != "" {
+		e := h.connectionEvent(ws.HubEventTopicSubscribed, client)
+		e.Topic = topicName(ws.ChannelTypeProject, client.projectID)
+		h.events.publish(e)
+	}
+}
+
+func (h *Hub) removeClient(client *Connection) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.clients[client]; ok {
+		delete(h.clients, client)
+		client.send.close()
+		metrics.ActiveConnections.WithLabelValues(client.metricLabel).Dec()
+		if client.public {
+			metrics.PublicActiveConnections.Dec()
+		}
+
+		if userConns, ok := h.users[client.userID]; ok {
+			delete(userConns, client)
+			if len(userConns) == 0 {
+				delete(h.users, client.userID)
+			}
+		}
+		if byProject, ok := h.projects[client.userID]; ok {
+			delete(byProject[client.projectID], client)
+			if len(byProject[client.projectID]) == 0 {
+				delete(byProject, client.projectID)
 			}
-			h.mu.Unlock()
-
-		case message := <-h.broadcast:
-			h.mu.RLock()
-			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client)
-				}
+			if len(byProject) == 0 {
+				delete(h.projects, client.userID)
 			}
-			h.mu.RUnlock()
+		}
+		h.events.publish(h.connectionEvent(ws.HubEventConnectionClosed, client))
+	}
+}
+
+// broadcastMessage queues frame to every client and drops those too slow to
+// take it as if they had unregistered.
+func (h *Hub) broadcastMessage(frame broadcastFrame) {
+	for _, client := range h.enqueueAll(frame) {
+		h.removeClient(client)
+	}
+}
+
+// enqueueAll queues frame to every client and returns those whose queue was full.
+func (h *Hub) enqueueAll(frame broadcastFrame) []*Connection {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var full []*Connection
+	for client := range h.clients {
+		if !client.enqueueType(frame.msgType, frame.message) {
+			full = append(full, client)
+		}
+	}
+	return full
+}
+
+// SendToUser sends a message to all active connections of a specific user
+// that want msgType at that importance, except project-only ones.
+// Returns how many connections the message was queued to and how many were
+// skipped because their buffer was full.
+func (h *Hub) SendToUser(userID string, msgType ws.MessageType, importance ws.Importance, message []byte) (sent, dropped int) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return sendToSet(h.users[userID], msgType, importance, message, true)
+}
+
+// SendToUserWithProject sends a project-scoped message to the user's connections
+// that either have no project filter or are filtered to that project, and
+// want msgType at that importance. Returns the same counts as SendToUser.
+func (h *Hub) SendToUserWithProject(userID, projectID string, msgType ws.MessageType, importance ws.Importance, message []byte) (sent, dropped int) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	byProject := h.projects[userID]
+	if set := byProject[""]; len(set) > 0 {
+		sent, dropped = sendToSet(set, msgType, importance, message, false)
+	}
+	if set := byProject[projectID]; projectID != "" && len(set) > 0 {
+		s, d := sendToSet(set, msgType, importance, message, false)
+		sent, dropped = sent+s, dropped+d
+	}
+	return sent, dropped
+}
+
+// sendToSet queues a message to the connections of set that want msgType at
+// that importance. A message that is not project-scoped (userWide) skips
+// project-only connections. Returns the same counts as SendToUser. Must be
+// called with mu held.
+func sendToSet(set map[*Connection]bool, msgType ws.MessageType, importance ws.Importance, message []byte, userWide bool) (sent, dropped int) {
+	for client := range set {
+		if userWide && client.projectOnly {
+			continue
+		}
+		if !client.wants(msgType, importance) {
+			client.drop(dropFiltered)
+			continue
+		}
+		if client.enqueueType(msgType, client.shape(msgType, message)) {
+			sent++
+		} else {
+			// Queue full or connection dead; the writePump deals with the connection.
+			// enqueue recorded the reason.
+			dropped++
 		}
 	}
+	return sent, dropped
 }
 
-// SendToUser sends a message to all active connections of a specific user.
-func (h *Hub) SendToUser(userID string, message []byte) {
+// SendToSegment sends a message to every connection of the listed users and of
+// the users with a connection filtered to one of the listed projects, that
+// wants msgType at that importance, except project-only ones. Returns the
+// same counts as SendToUser.
+func (h *Hub) SendToSegment(userIDs, projectIDs map[string]bool, msgType ws.MessageType, importance ws.Importance, message []byte) (sent, dropped int) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	if conns, ok := h.users[userID]; ok {
-		for client := range conns {
-			select {
-			case client.send <- message:
-			default:
-				// Buffer full or connection dead, we might close it here or let the writePump handle it
-				// For safety in this tight loop, we skip blocking
+	users := make(map[string]bool, len(userIDs))
+	for userID := range userIDs {
+		users[userID] = true
+	}
+	if len(projectIDs) > 0 {
+		for client := range h.clients {
+			if client.projectID != "" && projectIDs[client.projectID] {
+				users[client.userID] = true
 			}
 		}
 	}
+
+	for userID := range users {
+		s, d := sendToSet(h.users[userID], msgType, importance, message, true)
+		sent, dropped = sent+s, dropped+d
+	}
+	return sent, dropped
+}
+
+// CloseProject sends notice to every connection filtered to projectID and then
+// closes it with the given close code. Returns the number of connections closed.
+func (h *Hub) CloseProject(projectID string, notice []byte, code int, reason string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	closed := 0
+	for client := range h.clients {
+		if client.projectID != projectID {
+			continue
+		}
+		client.enqueue(notice)
+		client.closeWith(code, reason)
+		closed++
+	}
+	return closed
+}
+
+// forgetProject drops the frames of the project that connections hold for a
+// coalesced flush and clears the project view they have on screen. Returns
+// the number of frames dropped and the users whose view was cleared.
+func (h *Hub) forgetProject(projectID string) (int, []string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	topic := topicName(ws.ChannelTypeProject, projectID)
+	dropped := 0
+	var users []string
+	seen := make(map[string]bool)
+	for client := range h.clients {
+		dropped += client.coalesce.drop(topic)
+		if client.unfocus(projectID) && !seen[client.userID] {
+			seen[client.userID] = true
+			users = append(users, client.userID)
+		}
+	}
+	return dropped, users
+}
+
+// CloseWhere asks every connection for which match returns true to close
+// with the given close code. Returns the number of connections asked.
+func (h *Hub) CloseWhere(match func(*Connection) bool, code int, reason string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	closed := 0
+	for client := range h.clients {
+		if match(client) {
+			client.closeWith(code, reason)
+			closed++
+		}
+	}
+	return closed
+}
+
+// openConnections returns the user's connections that are not already
+// closing, oldest first. Must be called with mu held.
+func (h *Hub) openConnections(userID string) []*Connection {
+	conns := make([]*Connection, 0, len(h.users[userID]))
+	for client := range h.users[userID] {
+		if len(client.closeReq) == 0 {
+			conns = append(conns, client)
+		}
+	}
+	sort.Slice(conns, func(i, j int) bool {
+		return conns[i].connectedAt.Before(conns[j].connectedAt)
+	})
+	return conns
+}
+
+// CloseAll asks every connection to close with the given close code after its
+// queued frames. Returns the number of connections asked.
+func (h *Hub) CloseAll(code int, reason string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.clients {
+		client.closeWith(code, reason)
+	}
+	return len(h.clients)
+}
+
+// sendTo queues a message for a single connection if it is still registered.
+// Holding the read lock guarantees the send channel is not closed concurrently.
+func (h *Hub) sendTo(client *Connection, message []byte) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if _, ok := h.clients[client]; !ok {
+		return false
+	}
+	return client.enqueue(message)
 }
 
 // Broadcast sends a message to all active connections.
 func (h *Hub) Broadcast(message []byte) {
-	h.broadcast <- message
+	h.broadcast <- broadcastFrame{message: message}
+}
+
+// BroadcastType is Broadcast in the lane of msgType.
+func (h *Hub) BroadcastType(msgType ws.MessageType, message []byte) {
+	h.broadcast <- broadcastFrame{msgType: msgType, message: message}
+}
+
+// Connections returns a snapshot of the user's connections, or of all
+// connections when userID is empty.
+func (h *Hub) Connections(userID string) []*Connection {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	set := h.clients
+	if userID != "" {
+		set = h.users[userID]
+	}
+
+	conns := make([]*Connection, 0, len(set))
+	for client := range set {
+		conns = append(conns, client)
+	}
+	return conns
+}
+
+// ConnectionsFrom returns how many connections the users hold and how many of
+// them come from ip.
+func (h *Hub) ConnectionsFrom(userIDs []string, ip string) (total, fromIP int) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, userID := range userIDs {
+		for client := range h.users[userID] {
+			total++
+			if client.remoteIP == ip {
+				fromIP++
+			}
+		}
+	}
+	return total, fromIP
+}
+
+// UserConnections returns the number of connections of the user that are not
+// already closing.
+func (h *Hub) UserConnections(userID string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.openConnections(userID))
 }
 
 // Stats returns the current statistics of the hub.
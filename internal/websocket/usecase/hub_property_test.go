@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"fmt"
+	"testing"
+
+	ws "notification-srv/internal/websocket"
+	"notification-srv/pkg/notificationtest"
+
+	"pgregory.net/rapid"
+)
+
+// TestHubRoutingProperties drives a Hub through random register, unregister,
+// drain and send sequences and checks after every send that:
+//   - a frame reaches exactly the user's connections whose project filter is
+//     empty or matches, and whose type filter accepts it;
+//   - sent counts the matching connections with buffer room, dropped the full
+//     ones, and no other connection's buffer changes.
+func TestHubRoutingProperties(t *testing.T) {
+	users := []string{"u1", "u2", "u3"}
+	projects := []string{"", "p1", "p2"}
+	types := []ws.MessageType{
+		ws.MessageTypeDataOnboarding,
+		ws.MessageTypeAnalyticsPipeline,
+		ws.MessageTypeCrisisAlert,
+		ws.MessageTypeSystem,
+	}
+
+	rapid.Check(t, func(t *rapid.T) {
+		h := newHub(notificationtest.Logger{}, 0)
+		var conns []*Connection
+		msgID := 0
+
+		t.Repeat(map[string]func(*rapid.T){
+			"register": func(t *rapid.T) {
+				c := &Connection{
+					hub:       h,
+					send:      newSendQueue(rapid.IntRange(1, 3).Draw(t, "buffer"), SendQueueDropNewest),
+					closeReq:  make(chan closeFrame, 1),
+					userID:    rapid.SampledFrom(users).Draw(t, "user"),
+					projectID: rapid.SampledFrom(projects).Draw(t, "project"),
+				}
+				filter := rapid.SliceOfDistinct(rapid.SampledFrom(types[:3]), func(m ws.MessageType) ws.MessageType { return m }).Draw(t, "types")
+				if err := c.setTypes(filter); err != nil {
+					t.Fatal(err)
+				}
+				h.addClient(c)
+				conns = append(conns, c)
+			},
+
+			"unregister": func(t *rapid.T) {
+				if len(conns) == 0 {
+					t.Skip("no connections")
+				}
+				i := rapid.IntRange(0, len(conns)-1).Draw(t, "conn")
+				h.removeClient(conns[i])
+				conns = append(conns[:i], conns[i+1:]...)
+			},
+
+			"drain": func(t *rapid.T) {
+				if len(conns) == 0 {
+					t.Skip("no connections")
+				}
+				c := conns[rapid.IntRange(0, len(conns)-1).Draw(t, "conn")]
+				c.send.take(nil)
+			},
+
+			"send": func(t *rapid.T) {
+				userID := rapid.SampledFrom(users).Draw(t, "user")
+				projectID := rapid.SampledFrom(projects).Draw(t, "project")
+				msgType := rapid.SampledFrom(types).Draw(t, "type")
+				msgID++
+				message := []byte(fmt.Sprintf(`{"id":"%d"}`, msgID))
+
+				before := make([]int, len(conns))
+				for i, c := range conns {
+					before[i] = c.send.len()
+				}
+
+				var sent, dropped int
+				if projectID != "" {
+					sent, dropped = h.SendToUserWithProject(userID, projectID, msgType, ws.ImportanceLow, message)
+				} else {
+					sent, dropped = h.SendToUser(userID, msgType, ws.ImportanceLow, message)
+				}
+
+				wantSent, wantDropped := 0, 0
+				for i, c := range conns {
+					matches := c.userID == userID && c.accepts(msgType) &&
+						(projectID == "" || c.projectID == "" || c.projectID == projectID)
+					switch {
+					case !matches:
+						if c.send.len() != before[i] {
+							t.Fatalf("frame for user=%s project=%q type=%s reached user=%s project=%q",
+								userID, projectID, msgType, c.userID, c.projectID)
+						}
+					case before[i] == c.send.cap():
+						wantDropped++
+						if c.send.len() != before[i] {
+							t.Fatalf("full buffer of user=%s grew", c.userID)
+						}
+					default:
+						wantSent++
+						if c.send.len() != before[i]+1 {
+							t.Fatalf("matching connection user=%s project=%q did not get the frame", c.userID, c.projectID)
+						}
+					}
+				}
+				if sent != wantSent || dropped != wantDropped {
+					t.Fatalf("sent=%d dropped=%d, want sent=%d dropped=%d", sent, dropped, wantSent, wantDropped)
+				}
+			},
+		})
+	})
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"testing"
+
+	ws "notification-srv/internal/websocket"
+	"notification-srv/pkg/notificationtest"
+)
+
+func TestSendToSegmentFilters(t *testing.T) {
+	h := newHub(notificationtest.Logger{}, 0)
+	conn := func(userID, projectID string) *Connection {
+		c := &Connection{
+			hub:       h,
+			send:      newSendQueue(4, SendQueueDropNewest),
+			closeReq:  make(chan closeFrame, 1),
+			userID:    userID,
+			projectID: projectID,
+		}
+		h.addClient(c)
+		return c
+	}
+
+	plain := conn("u1", "")
+	typed := conn("u1", "")
+	typed.setTypes([]ws.MessageType{ws.MessageTypeDataOnboarding})
+	important := conn("u1", "")
+	important.setMinImportance(ws.ImportanceHigh)
+	projected := conn("u1", "")
+	projected.fields = newProjection([]string{"severity"})
+	projectOnly := conn("u1", "p9")
+	projectOnly.projectOnly = true
+	byProject := conn("u2", "p1") // Reached through the project
+	other := conn("u3", "")
+
+	message := []byte(`{"type":"CRISIS_ALERT","payload":{"severity":"info","metric":"volume"}}`)
+	sent, dropped := h.SendToSegment(map[string]bool{"u1": true}, map[string]bool{"p1": true},
+		ws.MessageTypeCrisisAlert, ws.ImportanceNormal, message)
+	if sent != 3 || dropped != 0 {
+		t.Errorf("SendToSegment() = %d, %d; want 3 sent, 0 dropped", sent, dropped)
+	}
+
+	for name, c := range map[string]*Connection{
+		"type filter":       typed,
+		"importance filter": important,
+		"project-only":      projectOnly,
+		"other user":        other,
+	} {
+		if n := c.send.len(); n != 0 {
+			t.Errorf("%s connection got %d frames, want none", name, n)
+		}
+	}
+	for name, c := range map[string]*Connection{"plain": plain, "by project": byProject} {
+		if frames, _ := c.send.take(nil); len(frames) != 1 || string(frames[0]) != string(message) {
+			t.Errorf("%s connection got %q, want the message", name, frames)
+		}
+	}
+	want := `{"payload":{"severity":"info"},"type":"CRISIS_ALERT"}`
+	if frames, _ := projected.send.take(nil); len(frames) != 1 || string(frames[0]) != want {
+		t.Errorf("projected connection got %q, want %s", frames, want)
+	}
+
+	// SYSTEM broadcasts pass the type and importance filters
+	sent, _ = h.SendToSegment(map[string]bool{"u1": true}, nil, ws.MessageTypeSystem, ws.ImportanceHigh, []byte(`{"type":"SYSTEM"}`))
+	if sent != 4 {
+		t.Errorf("SYSTEM sent to %d connections, want 4", sent)
+	}
+}
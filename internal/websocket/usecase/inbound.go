@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+	"notification-srv/internal/websocket/transport"
+)
+
+// handleInbound validates a client frame against the inbound schema and
+// dispatches it. Violations are answered with an error frame; after
+// maxViolations consecutive violations the connection is closed.
+func (c *Connection) handleInbound(msgType transport.MessageType, data []byte) {
+	if msgType != transport.MessageText {
+		c.rejectInbound(ws.ErrorCodeBadRequest, "only JSON text frames are accepted")
+		return
+	}
+
+	var msg ws.InboundMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		c.rejectInbound(ws.ErrorCodeBadRequest, "frame is not valid JSON")
+		return
+	}
+
+	switch msg.Action {
+	case ws.InboundActionPing:
+		c.reply(ws.PongFrame{Type: "pong", Timestamp: time.Now()})
+	case ws.InboundActionFocus:
+		c.setFocus(msg.ProjectID)
+	case ws.InboundActionSubscribe:
+		if err := c.setTypes(msg.Types); err != nil {
+			c.rejectInbound(ws.ErrorCodeBadRequest, err.Error())
+			return
+		}
+		if err := c.setMinImportance(msg.MinImportance); err != nil {
+			c.rejectInbound(ws.ErrorCodeBadRequest, err.Error())
+			return
+		}
+	case ws.InboundActionAck:
+		if msg.ID == "" {
+			c.rejectInbound(ws.ErrorCodeBadRequest, "missing id")
+			return
+		}
+		if c.acks.ack(c.userID, msg.ID) {
+			metrics.ReliableDeliveries.WithLabelValues("acked").Inc()
+		}
+		if c.traces.traced(c.userID) {
+			c.hub.logger.Infof(context.Background(), "trace: acked user_id=%s id=%s", c.userID, msg.ID)
+		}
+	case "":
+		c.rejectInbound(ws.ErrorCodeBadRequest, "missing action")
+		return
+	default:
+		c.rejectInbound(ws.ErrorCodeUnknownAction, "unknown action: "+string(msg.Action))
+		return
+	}
+
+	c.violations = 0
+}
+
+// rejectInbound answers a protocol violation and closes the connection once the
+// consecutive violation limit is reached.
+func (c *Connection) rejectInbound(code, detail string) {
+	c.violations++
+	c.reply(ws.ErrorFrame{Type: "error", Code: code, Detail: detail})
+
+	if c.maxViolations > 0 && c.violations >= c.maxViolations {
+		c.hub.logger.Warnf(context.Background(), "websocket: closing after %d protocol violations user_id=%s", c.violations, c.userID)
+		c.closeWith(transport.ClosePolicyViolation, "too many protocol violations")
+	}
+}
+
+// reply queues a server frame for the client without blocking readPump.
+func (c *Connection) reply(frame any) {
+	data, err := json.Marshal(frame)
+	if err != nil {
+		return
+	}
+	c.hub.sendTo(c, data)
+}
+
+// setFocus records the project view the client has on screen and republishes
+// the user's presence so other channels can skip redundant notifications.
+func (c *Connection) setFocus(projectID string) {
+	if prev, _ := c.focus.Swap(projectID).(string); prev == projectID {
+		return
+	}
+	c.presence.refresh(c.userID)
+}
+
+// unfocus clears the project view if it is projectID and reports whether it was.
+func (c *Connection) unfocus(projectID string) bool {
+	return c.focus.CompareAndSwap(projectID, "")
+}
+
+// focused returns the project view on screen, or "" if none.
+func (c *Connection) focused() string {
+	projectID, _ := c.focus.Load().(string)
+	return projectID
+}
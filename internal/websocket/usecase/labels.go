@@ -0,0 +1,20 @@
+package usecase
+
+// Metric label values of connections without a configured client label.
+const (
+	clientLabelNone  = "none"  // The client sent no label
+	clientLabelOther = "other" // The label is not in websocket.client_labels
+)
+
+// metricLabel bounds a client label to the configured ones, so clients
+// cannot grow the metric cardinality.
+func (uc *implUseCase) metricLabel(label string) string {
+	switch {
+	case label == "":
+		return clientLabelNone
+	case uc.clientLabels[label]:
+		return label
+	default:
+		return clientLabelOther
+	}
+}
@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+	"notification-srv/internal/websocket/repository"
+
+	"github.com/smap-hcmut/shared-libs/go/log"
+)
+
+// Upper bound on a latest state write, so a slow Redis delays only later states.
+const latestStateTimeout = 2 * time.Second
+
+// Upper bound on deleting a project's latest states, which scans the keyspace.
+const latestPurgeTimeout = 30 * time.Second
+
+// newLatestState returns nil when the latest state key is disabled or there is
+// no repository, and otherwise starts its writer.
+func newLatestState(cfg LatestStateConfig, repo repository.LatestStateRepository, logger log.Logger) *latestState {
+	if !cfg.Enabled || repo == nil {
+		return nil
+	}
+	l := &latestState{
+		repo:    repo,
+		logger:  logger,
+		ttl:     cfg.TTL,
+		pending: make(map[latestTopic][]byte),
+		wake:    make(chan struct{}, 1),
+	}
+	go l.run()
+	return l
+}
+
+// store queues frame as the latest state of a delivered message's project
+// topic. Messages on other channels are not kept.
+func (l *latestState) store(parsed ParsedChannel, frame []byte) {
+	if l == nil || parsed.ChannelType != ws.ChannelTypeProject || parsed.UserID == "" {
+		return
+	}
+
+	l.mu.Lock()
+	l.pending[latestTopic{projectID: parsed.EntityID, userID: parsed.UserID}] = frame
+	l.mu.Unlock()
+	l.signal()
+}
+
+// drop forgets the pending frames of a deleted project and queues the
+// deletion of its stored ones, after any write already under way.
+func (l *latestState) drop(projectID string) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	for topic := range l.pending {
+		if topic.projectID == projectID {
+			delete(l.pending, topic)
+		}
+	}
+	l.dropped = append(l.dropped, projectID)
+	l.mu.Unlock()
+	l.signal()
+}
+
+func (l *latestState) signal() {
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run writes the pending frames, one at a time so the writes of a topic keep
+// their order, then deletes the states of dropped projects.
+func (l *latestState) run() {
+	for range l.wake {
+		l.mu.Lock()
+		pending := l.pending
+		dropped := l.dropped
+		l.pending = make(map[latestTopic][]byte, len(pending))
+		l.dropped = nil
+		l.mu.Unlock()
+
+		for topic, frame := range pending {
+			l.write(topic, frame)
+		}
+		for _, projectID := range dropped {
+			l.delete(projectID)
+		}
+	}
+}
+
+func (l *latestState) write(topic latestTopic, frame []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), latestStateTimeout)
+	defer cancel()
+
+	if err := l.repo.SetLatestState(ctx, topic.projectID, topic.userID, frame, l.ttl); err != nil {
+		metrics.LatestStateWrites.WithLabelValues("error").Inc()
+		l.logger.Warnf(ctx, "latest state write failed: project_id=%s user_id=%s err=%v", topic.projectID, topic.userID, err)
+		return
+	}
+	metrics.LatestStateWrites.WithLabelValues("ok").Inc()
+}
+
+func (l *latestState) delete(projectID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), latestPurgeTimeout)
+	defer cancel()
+
+	deleted, err := l.repo.DeleteLatestStates(ctx, projectID)
+	if err != nil {
+		l.logger.Warnf(ctx, "latest state purge failed: project_id=%s deleted=%d err=%v", projectID, deleted, err)
+		return
+	}
+	l.logger.Infof(ctx, "latest state purged: project_id=%s deleted=%d", projectID, deleted)
+}
+
+// load returns the latest frame of the user's project topic, nil if there is
+// none or the lookup failed.
+func (l *latestState) load(ctx context.Context, projectID, userID string) []byte {
+	if l == nil {
+		return nil
+	}
+	frame, err := l.repo.GetLatestState(ctx, projectID, userID)
+	if err != nil {
+		l.logger.Warnf(ctx, "latest state lookup failed: project_id=%s user_id=%s err=%v", projectID, userID, err)
+		return nil
+	}
+	return frame
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"notification-srv/internal/metrics"
+	"notification-srv/internal/model"
+	ws "notification-srv/internal/websocket"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maintenanceOn  = "on"
+	maintenanceOff = "off"
+)
+
+// SetMaintenance switches maintenance mode on this replica and publishes the
+// change on the control channel so the other replicas follow.
+func (uc *implUseCase) SetMaintenance(ctx context.Context, sc model.Scope, input ws.SetMaintenanceInput) (ws.MaintenanceStatus, error) {
+	uc.applyMaintenance(ctx, input.Enabled, input.Reason)
+	uc.logger.Infof(ctx, "maintenance mode set: enabled=%t reason=%q by user_id=%s", input.Enabled, input.Reason, sc.UserID)
+
+	if uc.repo != nil {
+		state := maintenanceOff
+		if input.Enabled {
+			state = maintenanceOn
+		}
+		payload, _ := json.Marshal(maintenanceControl{Reason: input.Reason})
+		if err := uc.repo.PublishControl(ctx, ws.ControlEventMaintenance, state, payload); err != nil {
+			// This replica is switched; the others keep their mode until the next toggle
+			uc.logger.Warnf(ctx, "maintenance control publish failed: %v", err)
+		}
+	}
+
+	return uc.GetMaintenance(ctx)
+}
+
+func (uc *implUseCase) GetMaintenance(ctx context.Context) (ws.MaintenanceStatus, error) {
+	m := &uc.maintenance
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return ws.MaintenanceStatus{
+		Enabled: m.enabled,
+		Reason:  m.reason,
+		Since:   m.since,
+	}, nil
+}
+
+// handleMaintenanceControl applies control:maintenance:{on|off} from any replica.
+func (uc *implUseCase) handleMaintenanceControl(ctx context.Context, state string, payload []byte) error {
+	var enabled bool
+	switch state {
+	case maintenanceOn:
+		enabled = true
+	case maintenanceOff:
+	default:
+		return ws.ErrInvalidChannel
+	}
+
+	var ctl maintenanceControl
+	if len(payload) > 0 {
+		// A missing or malformed reason only costs the banner its text
+		_ = json.Unmarshal(payload, &ctl)
+	}
+
+	uc.applyMaintenance(ctx, enabled, ctl.Reason)
+	return nil
+}
+
+// applyMaintenance switches the mode and, if it changed, broadcasts the banner.
+func (uc *implUseCase) applyMaintenance(ctx context.Context, enabled bool, reason string) {
+	m := &uc.maintenance
+	m.mu.Lock()
+	changed := m.enabled != enabled
+	m.enabled = enabled
+	m.reason = reason
+	if changed {
+		m.since = time.Time{}
+		if enabled {
+			m.since = time.Now()
+		}
+	}
+	m.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if enabled {
+		metrics.MaintenanceEnabled.Set(1)
+		uc.logger.Infof(ctx, "maintenance mode started: reason=%q", reason)
+	} else {
+		metrics.MaintenanceEnabled.Set(0)
+		uc.logger.Infof(ctx, "maintenance mode ended")
+	}
+	uc.hub.Broadcast(maintenanceBanner(enabled, reason))
+}
+
+// queueMaintenanceBanner tells a new connection that maintenance mode is on.
+func (uc *implUseCase) queueMaintenanceBanner(client *Connection) {
+	status, _ := uc.GetMaintenance(context.Background())
+	if !status.Enabled {
+		return
+	}
+
+	client.enqueue(maintenanceBanner(true, status.Reason))
+}
+
+// deliverable reports whether a message goes out under the current mode.
+// In maintenance mode only critical and terminal messages do.
+func (uc *implUseCase) deliverable(output ws.NotificationOutput) bool {
+	m := &uc.maintenance
+	m.mu.RLock()
+	enabled := m.enabled
+	m.mu.RUnlock()
+
+	return !enabled || critical(output)
+}
+
+// critical reports whether a message must reach clients even in maintenance
+// mode: crisis alerts, system notices, priority types, finished or failed
+// onboardings and completed pipelines.
+func critical(output ws.NotificationOutput) bool {
+	if ws.PriorityMessageTypes[output.Type] {
+		return true
+	}
+	switch p := output.Payload.(type) {
+	case ws.CrisisAlertPayload:
+		return true
+	case ws.DataOnboardingPayload:
+		status := strings.ToUpper(p.Status)
+		return status == "COMPLETED" || status == "FAILED"
+	case ws.AnalyticsPipelinePayload:
+		return p.Progress >= 100
+	case ws.CampaignEventPayload:
+		return strings.ToUpper(p.EventType) == "FINISHED"
+	}
+	return output.Type == ws.MessageTypeSystem
+}
+
+// maintenanceBanner builds the SYSTEM notification announcing a mode change.
+func maintenanceBanner(enabled bool, reason string) []byte {
+	event := ws.SystemEventMaintenanceEnded
+	if enabled {
+		event = ws.SystemEventMaintenanceStarted
+	}
+
+	frame, _ := json.Marshal(ws.NotificationOutput{
+		ID:        uuid.NewString(),
+		Type:      ws.MessageTypeSystem,
+		Timestamp: time.Now(),
+		Payload: map[string]string{
+			"system_event": event,
+			"reason":       reason,
+		},
+	})
+	return frame
+}
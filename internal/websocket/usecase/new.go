@@ -2,12 +2,16 @@ package usecase
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"notification-srv/internal/alert"
+	"notification-srv/internal/metrics"
+	"notification-srv/internal/sink"
+	"notification-srv/internal/telemetry"
 	ws "notification-srv/internal/websocket"
+	"notification-srv/internal/websocket/repository"
+	"notification-srv/internal/websocket/transport"
+	"time"
 
-	"github.com/gorilla/websocket"
 	"github.com/smap-hcmut/shared-libs/go/log"
 )
 
@@ -16,47 +20,225 @@ type implUseCase struct {
 	hub            *Hub
 	logger         log.Logger
 	alertUC        alert.UseCase
+	telemetryUC    telemetry.UseCase
+	repo           repository.Repository
 	maxConnections int
+	heartbeat      bool
+	hello          bool
+	compression    bool
+	maxViolations  int
+	validation     ValidationMode
+	disabledTypes  map[ws.MessageType]bool
+
+	shadowTransform transformFunc
+	shadowVersion   string
+	shadowPercent   int
+
+	backfill BackfillConfig
+	latest   *latestState
+	acks     *ackTracker
+	traces   *userTraces
+	canary   *canary
+	seq      *sequencer
+	sinks    []sink.Sink
+	presence *presence
+
+	maintenance  maintenanceState
+	announcement announcementState
+	stats        *channelStats
+	history      *statsHistory
+	gc           *topicGC
+	transitions  *transitionValidator
+	terminals    *terminalDedupe
+	sanitizer    *sanitizer
+
+	deltaSnapshotEvery int
+	coalesce           CoalesceConfig
+
+	segments   ws.SegmentResolver
+	delegation ws.DelegationAuthorizer
+	churn      *churnDetector
+	decoy      DecoyConfig
+	public     *publicViewers
+
+	backpressure *backpressureDetector
+
+	quota QuotaConfig
+
+	clientLabels map[string]bool
+
+	sendQueue SendQueueConfig
 }
 
 // New creates a new WebSocket UseCase.
-func New(logger log.Logger, maxConnections int, alertUC alert.UseCase) ws.UseCase {
-	hub := newHub(logger, maxConnections)
-	return &implUseCase{
+func New(logger log.Logger, cfg Config, alertUC alert.UseCase, telemetryUC telemetry.UseCase, repo repository.Repository) ws.UseCase {
+	hub := newHub(logger, cfg.MaxConnections)
+	uc := &implUseCase{
 		hub:            hub,
 		logger:         logger,
 		alertUC:        alertUC,
-		maxConnections: maxConnections,
+		telemetryUC:    telemetryUC,
+		repo:           repo,
+		maxConnections: cfg.MaxConnections,
+		heartbeat:      cfg.Heartbeat,
+		hello:          cfg.Protocol.Hello,
+		compression:    cfg.Protocol.Compression,
+		maxViolations:  cfg.MaxProtocolViolations,
+		validation:     cfg.Validation,
+		disabledTypes:  cfg.DisabledTypes,
+		backfill:       cfg.Backfill,
+		latest:         newLatestState(cfg.LatestState, repo, logger),
+		acks:           newAckTracker(cfg.Delivery),
+		traces:         newUserTraces(cfg.UserTrace),
+		canary:         newCanary(cfg.Canary),
+		seq:            newSequencer(cfg.Sequence),
+		sinks:          cfg.Sinks,
+		presence:       newPresence(cfg.Presence, repo, logger, hub),
+		stats:          newChannelStats(cfg.StatsWindow),
+		history:        newStatsHistory(cfg.StatsHistory),
+		gc:             newTopicGC(cfg.TopicGCGrace),
+		transitions:    newTransitionValidator(cfg.Transitions),
+		terminals:      newTerminalDedupe(cfg.TerminalDedupe),
+		sanitizer:      newSanitizer(cfg.Sanitize),
+
+		deltaSnapshotEvery: cfg.DeltaSnapshotEvery,
+		coalesce:           cfg.Coalesce,
+
+		segments:   cfg.Segments,
+		delegation: cfg.Delegation,
+		churn:      newChurnDetector(cfg.Churn),
+		decoy:      cfg.Decoy,
+		public:     newPublicViewers(cfg.Public),
+
+		backpressure: newBackpressureDetector(cfg.Backpressure),
+
+		quota: cfg.Quota,
+
+		clientLabels: stringSet(cfg.ClientLabels),
+
+		sendQueue: cfg.SendQueue,
+	}
+	if uc.sendQueue.Size <= 0 {
+		uc.sendQueue.Size = defaultSendQueueSize
+	}
+
+	if cfg.Shadow.Version != "" {
+		fn, ok := shadowTransformers[cfg.Shadow.Version]
+		if !ok {
+			logger.Warnf(context.Background(), "shadow transform %q is not registered, shadowing disabled", cfg.Shadow.Version)
+		} else {
+			uc.shadowTransform = fn
+			uc.shadowVersion = cfg.Shadow.Version
+			uc.shadowPercent = cfg.Shadow.Percent
+		}
 	}
+
+	return uc
 }
 
 func (uc *implUseCase) Run() {
+	if uc.acks != nil {
+		go uc.retryAcks()
+	}
+	if uc.canary != nil {
+		go uc.runCanary()
+	}
 	uc.hub.run()
 }
 
+// Shutdown drains the Hub: every connection is closed with 1012 (service
+// restart) so clients reconnect, to a newer process during a handoff. It
+// returns once all connections are gone or ctx ends.
 func (uc *implUseCase) Shutdown(ctx context.Context) error {
-	// Implement graceful shutdown of hub if needed
-	return nil
+	n := uc.hub.CloseAll(transport.CloseServiceRestart, "server restarting")
+	uc.logger.Infof(ctx, "websocket: draining %d connections", n)
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if active, _ := uc.hub.Stats(); active == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			active, _ := uc.hub.Stats()
+			uc.logger.Warnf(ctx, "websocket: shutdown with %d connections still open", active)
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
 }
 
 func (uc *implUseCase) Register(ctx context.Context, input ws.ConnectionInput) error {
-	conn, ok := input.Conn.(*websocket.Conn)
+	conn, ok := input.Conn.(transport.Conn)
 	if !ok {
 		return fmt.Errorf("invalid connection type")
 	}
+	if !input.Public {
+		uc.checkChurn(ctx, input.UserID, input.RemoteIP)
+	}
 
 	client := &Connection{
-		hub:    uc.hub,
-		conn:   conn,
-		send:   make(chan []byte, 256),
-		userID: input.UserID,
+		hub:       uc.hub,
+		conn:      conn,
+		send:      uc.newSendQueue(input.ClientLabel),
+		closeReq:  make(chan closeFrame, 1),
+		userID:    input.UserID,
+		projectID: input.ProjectID,
+
+		projectOnly: input.ProjectOnly || input.Public,
+		public:      input.Public,
+		delegatedBy: input.DelegatedBy,
+		userAgent:   input.UserAgent,
+		remoteIP:    input.RemoteIP,
+		clientLabel: input.ClientLabel,
+		fingerprint: input.Fingerprint,
+		metricLabel: uc.metricLabel(input.ClientLabel),
+		compression: input.Compression,
+		connectedAt: time.Now(),
+		heartbeat:   uc.heartbeat,
+
+		maxViolations: uc.maxViolations,
+		presence:      uc.presence,
+		acks:          uc.acks,
+		traces:        uc.traces,
+		fields:        newProjection(input.Fields),
+		coalesce:      newCoalescer(uc.coalesce),
+	}
+	if input.Public || input.DelegatedBy != "" {
+		client.presence = nil
+		client.acks = nil
+	}
+	if input.Delta {
+		client.delta = newDeltaEncoder(uc.deltaSnapshotEvery)
+	}
+	if err := client.setTypes(input.Types); err != nil {
+		return err
 	}
+	if err := client.setMinImportance(input.MinImportance); err != nil {
+		return err
+	}
+
+	// Queue the hello, then the project snapshot, before the pumps start so
+	// they are the first frames
+	uc.queueHello(client)
+	if client.projectID != "" && client.accepts(ws.MessageTypeProjectProgress) {
+		uc.backfillProject(ctx, client)
+	}
+	uc.queueMaintenanceBanner(client)
+	uc.queueAnnouncement(client)
+	uc.drainOffline(ctx, client)
 
-	uc.hub.register <- client
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := uc.admit(ctx, client, input.Quota); err != nil {
+		return err
+	}
 
-	// Start the pumps
+	// Start the pumps; reading first, as writePump may close the connection
+	client.startReading()
 	go client.writePump(uc.logger)
-	go client.readPump()
 
 	return nil
 }
@@ -74,27 +256,129 @@ func (uc *implUseCase) GetStats(ctx context.Context) (ws.HubStats, error) {
 	}, nil
 }
 
+func (uc *implUseCase) ListConnections(ctx context.Context, input ws.ListConnectionsInput) (ws.ListConnectionsOutput, error) {
+	conns := uc.hub.Connections(input.UserID)
+
+	infos := make([]ws.ConnectionInfo, len(conns))
+	for i, c := range conns {
+		drops, topDrop := c.dropStats()
+		infos[i] = ws.ConnectionInfo{
+			UserID:      c.userID,
+			ProjectID:   c.projectID,
+			ProjectOnly: c.projectOnly,
+			Public:      c.public,
+			DelegatedBy: c.delegatedBy,
+			UserAgent:   c.userAgent,
+			Compression: c.compression,
+			ConnectedAt: c.connectedAt,
+			RTT:         c.rtt(),
+			Focus:       c.focused(),
+			Types:       c.typeList(),
+			Fields:      c.fieldList(),
+			Delta:       c.delta != nil,
+			RemoteIP:    c.remoteIP,
+			ClientLabel: c.clientLabel,
+			Fingerprint: c.fingerprint,
+
+			MinImportance: c.importanceFilter(),
+
+			SendQueueLen: c.send.len(),
+			SendQueueCap: c.send.cap(),
+
+			Drops:         drops,
+			TopDropReason: topDrop,
+		}
+	}
+	return ws.ListConnectionsOutput{Connections: infos}, nil
+}
+
 func (uc *implUseCase) ProcessMessage(ctx context.Context, input ws.ProcessMessageInput) error {
+	receivedAt := time.Now()
+
 	// 1. Parse channel
 	parsed, err := parseChannel(input.Channel)
 	if err != nil {
+		uc.stats.add(invalidChannelPattern(input.Channel), channelCounts{received: 1})
 		uc.logger.Warnf(ctx, "parse channel failed: %v", err)
 		return nil // Swallow error to avoid spamming logs/retries for invalid channels
 	}
 
+	// Counted per channel pattern for publisher teams, whatever the outcome below
+	counts := channelCounts{received: 1}
+	defer func() { uc.stats.add(channelPattern(parsed), counts) }()
+
+	// A finished project that is active again keeps its topic
+	if parsed.ChannelType == ws.ChannelTypeProject {
+		uc.gc.cancel(parsed.EntityID)
+	}
+
+	// Control channels are service-internal lifecycle signals, not client payloads
+	if parsed.ChannelType == ws.ChannelTypeControl {
+		return uc.handleControl(ctx, parsed, input.Payload)
+	}
+
+	// Targeted tracing logs every hop of the messages of a few users
+	tr := uc.startTrace(parsed, input, receivedAt)
+	defer tr.log(ctx, uc.logger)
+
 	// 2. Detect message type
 	msgType, err := detectMessageType(input.Payload)
 	if err != nil {
+		counts.transformErrors++
 		uc.logger.Warnf(ctx, "detect type failed: %v", err) // Log info/warn
 		// We might fail here or default to SYSTEM? For now return error
+		tr.done("undetected")
+		return nil
+	}
+	if uc.disabledTypes[msgType] {
+		counts.dropped++
+		metrics.DisabledTypeDropped.WithLabelValues(string(msgType)).Inc()
+		uc.logger.Debugf(ctx, "message type disabled, dropped: type=%s channel=%s", msgType, input.Channel)
+		tr.done("type_disabled")
 		return nil
 	}
 
 	// 3. Validate & Transform
+	start := time.Now()
 	output, err := uc.transformMessage(ctx, msgType, input.Payload)
+	tr.observe(ctx, metrics.TransformDuration.WithLabelValues(string(msgType)), time.Since(start).Seconds())
+	if uc.shouldShadow() {
+		go uc.runShadow(ctx, msgType, input.Payload, output, err)
+	}
 	if err != nil {
+		counts.transformErrors++
+		tr.done("transform_failed")
 		return fmt.Errorf("transform: %w", err)
 	}
+	tr.hop("transformed")
+
+	// A job update must follow the job's previous status, e.g. not a failed onboarding processing again
+	if !uc.checkTransition(ctx, parsed, output) {
+		counts.dropped++
+		uc.hub.events.publish(ws.HubEvent{
+			Type:        ws.HubEventMessageDropped,
+			UserID:      parsed.UserID,
+			MessageID:   output.ID,
+			MessageType: output.Type,
+			DropReason:  ws.DropReasonInvalidTransition,
+		})
+		tr.done("invalid_transition")
+		return nil
+	}
+
+	// Publishers often repeat a finished job's last update
+	if !uc.dedupeTerminal(ctx, parsed, output) {
+		counts.dropped++
+		uc.hub.events.publish(ws.HubEvent{
+			Type:        ws.HubEventMessageDropped,
+			UserID:      parsed.UserID,
+			MessageID:   output.ID,
+			MessageType: output.Type,
+			DropReason:  ws.DropReasonDuplicateTerminal,
+		})
+		tr.done("duplicate_terminal")
+		return nil
+	}
 
 	// 4. Dispatch to alert channel (Discord) if needed
 	// Note: We use the alertUC for this.
@@ -168,27 +452,107 @@ func (uc *implUseCase) ProcessMessage(ctx context.Context, input ws.ProcessMessa
 		}
 	}
 
-	// 5. Route to WebSocket connections
-	outputBytes, err := json.Marshal(output)
+	// Maintenance mode keeps sockets calm: only critical and terminal messages go out
+	if !uc.deliverable(output) {
+		metrics.MaintenanceSuppressed.WithLabelValues(string(output.Type)).Inc()
+		counts.dropped++
+		uc.hub.events.publish(ws.HubEvent{
+			Type:        ws.HubEventMessageDropped,
+			UserID:      parsed.UserID,
+			MessageID:   output.ID,
+			MessageType: output.Type,
+			DropReason:  ws.DropReasonMaintenance,
+		})
+		tr.done("maintenance")
+		return nil
+	}
+
+	// 5. Tag the origin region and assign the per-topic sequence number
+	output.Region = input.Region
+	reliable := uc.acks.reliable(parsed, output)
+	output.AckRequired = reliable
+	var seqKey string
+	if sequenced(parsed) {
+		output.Topic = topicName(parsed.ChannelType, parsed.EntityID)
+		seqKey = topicKey(output.Topic, parsed.UserID)
+		output.Seq = uc.seq.next(seqKey)
+		output.Epoch = uc.seq.epoch
+	}
+
+	// 6. Route to WebSocket connections
+	outputBytes, err := marshalOutput(output)
 	if err != nil {
-		return fmt.Errorf("marshal output: %w", err)
+		return fmt.Errorf("%w: marshal output: %v", ws.ErrTransformFailed, err)
 	}
 
-	uc.routeMessage(parsed, outputBytes)
+	if seqKey != "" {
+		uc.seq.record(seqKey, output.Seq, outputBytes)
+	}
+	if tr != nil {
+		tr.frame = outputBytes
+	}
+	start = time.Now()
+	var recipients, dropped int
+	if reliable {
+		recipients, dropped = uc.routeReliable(parsed, output, outputBytes)
+	} else {
+		recipients, dropped = uc.routeMessage(parsed, output.Type, output.Importance, outputBytes)
+	}
+	tr.observe(ctx, metrics.EnqueueDuration, time.Since(start).Seconds())
+	if tr != nil {
+		tr.hop("routed")
+		tr.outcome = fmt.Sprintf("routed recipients=%d dropped=%d", recipients, dropped)
+	}
+	if recipients > 0 {
+		counts.delivered = uint64(recipients)
+	} else if recipients == 0 && dropped == 0 {
+		counts.noRecipients++
+	}
+	counts.dropped += uint64(dropped)
+	uc.latest.store(parsed, outputBytes)
+	uc.signalBackpressure(ctx, input.Channel, parsed, recipients, dropped)
+	uc.publishDelivery(parsed, output, recipients, dropped)
+	uc.mirror(ctx, parsed, output, outputBytes, delivery{
+		receivedAt: receivedAt,
+		recipients: recipients,
+		dropped:    dropped,
+	})
+
+	// 7. Remember emit time so client latency reports can be joined with it
+	uc.telemetryUC.RecordEmit(ctx, telemetry.RecordEmitInput{
+		MessageID:   output.ID,
+		MessageType: string(output.Type),
+		UserID:      parsed.UserID,
+		EmittedAt:   time.Now(),
+	})
 	return nil
 }
 
-func (uc *implUseCase) routeMessage(parsed ParsedChannel, message []byte) {
+// routeMessage returns how many connections the message was queued to (-1 for
+// broadcasts, which the Hub fans out asynchronously) and how many were skipped
+// because their buffer was full. Connections filtering the message out count as neither.
+func (uc *implUseCase) routeMessage(parsed ParsedChannel, msgType ws.MessageType, importance ws.Importance, message []byte) (int, int) {
 	// Broad strategy:
 	// If UserID is present, send to that user.
 	// If UserID is empty, it might be a broadcast (e.g. system wide).
 	// Currently our parsing logic enforces UserID for most types except System.
 
-	if parsed.UserID != "" {
-		uc.hub.SendToUser(parsed.UserID, message)
+	if parsed.UserID != "" && parsed.ChannelType == ws.ChannelTypeProject {
+		sent, dropped := uc.hub.SendToUserWithProject(parsed.UserID, parsed.EntityID, msgType, importance, message)
+		// The owner's channel of a public project also feeds its anonymous viewers
+		if viewer := uc.public.viewerOf(parsed.EntityID, parsed.UserID); viewer != "" {
+			s, d := uc.hub.SendToUserWithProject(viewer, parsed.EntityID, msgType, importance, message)
+			metrics.PublicFramesSent.Add(float64(s))
+			sent, dropped = sent+s, dropped+d
+		}
+		return sent, dropped
+	} else if parsed.UserID != "" {
+		return uc.hub.SendToUser(parsed.UserID, msgType, importance, message)
 	} else if parsed.ChannelType == ws.ChannelTypeSystem {
-		uc.hub.Broadcast(message)
+		uc.hub.BroadcastType(msgType, message)
+		return -1, 0
 	}
+	return 0, 0
 }
 
 func (uc *implUseCase) OnUserConnected(ctx context.Context, userID string) error {
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+
+	ws "notification-srv/internal/websocket"
+)
+
+// Scratch space for the subscriber→Hub path, reused across messages. Nothing
+// taken from these pools outlives the call that took it: frames handed to
+// connections, the replay buffer and sinks are always copies.
+
+// maxPooledFrame keeps the buffer of an occasional huge frame out of the pool.
+const maxPooledFrame = 64 << 10
+
+// present is set when its field occurs in a payload, whatever the value,
+// without decoding the value.
+type present bool
+
+func (p *present) UnmarshalJSON([]byte) error {
+	*p = true
+	return nil
+}
+
+// typeProbe holds the fields detectMessageType tells message types apart by.
+type typeProbe struct {
+	SourceID     present `json:"source_id"`
+	TotalRecords present `json:"total_records"`
+	RecordCount  present `json:"record_count"`
+	AlertType    present `json:"alert_type"`
+	CampaignID   present `json:"campaign_id"`
+	SystemEvent  present `json:"system_event"`
+
+	SecurityEvent present `json:"security_event"`
+	BillingEvent  present `json:"billing_event"`
+	MaintenanceID present `json:"maintenance_id"`
+}
+
+var probePool = sync.Pool{New: func() any { return new(typeProbe) }}
+
+// envelopePool holds NotificationOutputs to marshal through a pointer, which
+// saves boxing a copy of the envelope for every frame.
+var envelopePool = sync.Pool{New: func() any { return new(ws.NotificationOutput) }}
+
+// frameEncoder is a JSON encoder writing into its own buffer.
+type frameEncoder struct {
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+var encoderPool = sync.Pool{New: func() any {
+	e := new(frameEncoder)
+	e.enc = json.NewEncoder(&e.buf)
+	return e
+}}
+
+// marshalOutput encodes output like json.Marshal using pooled scratch space.
+// The returned frame is a copy the caller may queue and retain.
+func marshalOutput(output ws.NotificationOutput) ([]byte, error) {
+	env := envelopePool.Get().(*ws.NotificationOutput)
+	*env = output
+	e := encoderPool.Get().(*frameEncoder)
+	defer func() {
+		*env = ws.NotificationOutput{} // Don't pin the payload
+		envelopePool.Put(env)
+		if e.buf.Cap() <= maxPooledFrame {
+			e.buf.Reset()
+			encoderPool.Put(e)
+		}
+	}()
+
+	if err := e.enc.Encode(env); err != nil {
+		return nil, err
+	}
+	b := e.buf.Bytes()
+	return append([]byte(nil), b[:len(b)-1]...), nil // Without Encode's trailing newline
+}
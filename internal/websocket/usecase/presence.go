@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	ws "notification-srv/internal/websocket"
+	"notification-srv/internal/websocket/repository"
+
+	"github.com/smap-hcmut/shared-libs/go/log"
+)
+
+// Upper bound on a presence write, so a slow Redis never piles up goroutines.
+const presenceTimeout = 2 * time.Second
+
+// newPresence returns nil when presence is disabled or there is no repository.
+func newPresence(cfg PresenceConfig, repo repository.PresenceRepository, logger log.Logger, hub *Hub) *presence {
+	if !cfg.Enabled || repo == nil {
+		return nil
+	}
+	p := &presence{repo: repo, logger: logger, ttl: cfg.TTL, hub: hub}
+	hub.events.subscribe([]ws.HubEventType{ws.HubEventConnectionOpened, ws.HubEventConnectionClosed}, p.onHubEvent)
+	return p
+}
+
+// onHubEvent marks users online when a WebSocket opens and offline when their
+// last connection closes. In-process streams have no pongs to keep the key
+// alive, anonymous viewers are not users, and a delegate watching a stream
+// does not make its user online.
+func (p *presence) onHubEvent(e ws.HubEvent) {
+	if e.Stream || e.Public || e.DelegatedBy != "" {
+		return
+	}
+	switch e.Type {
+	case ws.HubEventConnectionOpened:
+		p.refresh(e.UserID)
+	case ws.HubEventConnectionClosed:
+		if e.UserConnections == 0 {
+			p.clear(e.UserID)
+		}
+	}
+}
+
+// refresh marks the user online for another TTL, with the projects currently
+// focused on any of their connections. It runs off the caller's goroutine.
+func (p *presence) refresh(userID string) {
+	if p == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), presenceTimeout)
+		defer cancel()
+
+		if err := p.repo.SetPresence(ctx, userID, p.focusedProjects(userID), p.ttl); err != nil {
+			p.logger.Warnf(ctx, "presence refresh failed: user_id=%s err=%v", userID, err)
+		}
+	}()
+}
+
+// clear marks the user offline once their last connection is gone.
+func (p *presence) clear(userID string) {
+	if p == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), presenceTimeout)
+		defer cancel()
+
+		if err := p.repo.DeletePresence(ctx, userID); err != nil {
+			p.logger.Warnf(ctx, "presence clear failed: user_id=%s err=%v", userID, err)
+		}
+	}()
+}
+
+// focusedProjects returns the distinct projects focused across the user's connections.
+func (p *presence) focusedProjects(userID string) []string {
+	var projects []string
+	seen := make(map[string]bool)
+	for _, c := range p.hub.Connections(userID) {
+		if id := c.focused(); id != "" && !seen[id] {
+			seen[id] = true
+			projects = append(projects, id)
+		}
+	}
+	return projects
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"encoding/json"
+	"sort"
+
+	ws "notification-srv/internal/websocket"
+)
+
+// projection keeps only selected top-level payload fields of a frame. It is
+// compiled once per connection and read-only afterwards.
+type projection struct {
+	fields map[string]bool
+	names  []string // Sorted, for the admin listing
+}
+
+// newProjection compiles the payload fields a client asked for. An empty list
+// returns nil, which sends payloads unchanged.
+func newProjection(fields []string) *projection {
+	if len(fields) == 0 {
+		return nil
+	}
+	p := &projection{fields: make(map[string]bool, len(fields))}
+	for _, f := range fields {
+		if !p.fields[f] {
+			p.fields[f] = true
+			p.names = append(p.names, f)
+		}
+	}
+	sort.Strings(p.names)
+	return p
+}
+
+// apply rewrites the payload of a notification frame. Frames whose payload is
+// not a JSON object are returned unchanged.
+func (p *projection) apply(message []byte) []byte {
+	var frame map[string]json.RawMessage
+	if err := json.Unmarshal(message, &frame); err != nil {
+		return message
+	}
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(frame["payload"], &payload); err != nil {
+		return message
+	}
+
+	for name := range payload {
+		if !p.fields[name] {
+			delete(payload, name)
+		}
+	}
+	projected, err := json.Marshal(payload)
+	if err != nil {
+		return message
+	}
+	frame["payload"] = projected
+
+	out, err := json.Marshal(frame)
+	if err != nil {
+		return message
+	}
+	return out
+}
+
+// shape returns the frame as this connection should receive it: projected for
+// notification types, unchanged for SYSTEM and HEARTBEAT frames.
+func (c *Connection) shape(msgType ws.MessageType, message []byte) []byte {
+	if c.fields == nil || !ws.FilterableMessageTypes[msgType] {
+		return message
+	}
+	return c.fields.apply(message)
+}
+
+// fieldList returns the connection's payload projection, or nil if it has none.
+func (c *Connection) fieldList() []string {
+	if c.fields == nil {
+		return nil
+	}
+	return c.fields.names
+}
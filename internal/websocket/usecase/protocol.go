@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+
+	ws "notification-srv/internal/websocket"
+)
+
+// Encodings a client may negotiate, in the order GET /protocol lists them.
+const (
+	encodingJSON    = "json"
+	encodingDeflate = "permessage-deflate"
+)
+
+func (uc *implUseCase) GetProtocol(ctx context.Context) (ws.Protocol, error) {
+	return uc.protocol(), nil
+}
+
+// protocol describes the client protocol as configured on this replica.
+func (uc *implUseCase) protocol() ws.Protocol {
+	encodings := []string{encodingJSON}
+	if uc.compression {
+		encodings = append(encodings, encodingDeflate)
+	}
+	encodings = append(encodings, ws.DeltaSubprotocol)
+
+	return ws.Protocol{
+		Version:           ws.ProtocolVersion,
+		Encodings:         encodings,
+		Ack:               uc.acks != nil,
+		Resume:            uc.seq.bufferSize > 0,
+		MaxMessageSize:    maxMessageSize,
+		HeartbeatInterval: pingPeriod,
+		HeartbeatFrames:   uc.heartbeat,
+		Actions:           ws.InboundActions,
+	}
+}
+
+// queueHello queues the HELLO frame, ahead of every other frame. Public and
+// delegated connections do not ack, so their hello says so.
+func (uc *implUseCase) queueHello(client *Connection) {
+	if !uc.hello {
+		return
+	}
+
+	p := uc.protocol()
+	frame, err := json.Marshal(ws.HelloFrame{
+		Type:                "hello",
+		Version:             p.Version,
+		Encodings:           p.Encodings,
+		Ack:                 p.Ack && client.acks != nil,
+		Resume:              p.Resume,
+		MaxMessageSize:      p.MaxMessageSize,
+		HeartbeatIntervalMs: p.HeartbeatInterval.Milliseconds(),
+		HeartbeatFrames:     p.HeartbeatFrames,
+		Actions:             p.Actions,
+	})
+	if err != nil {
+		return
+	}
+	client.enqueue(frame)
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+)
+
+// publicUserPrefix starts the Hub identity of anonymous viewers. User IDs in
+// channel names cannot contain ":", so no publisher can address it directly.
+const publicUserPrefix = "public:"
+
+// maxConnectBuckets bounds the per-IP upgrade allowances kept at once.
+const maxConnectBuckets = 10000
+
+// publicViewer returns the Hub identity of the anonymous viewers of a project.
+func publicViewer(projectID string) string {
+	return publicUserPrefix + projectID
+}
+
+// newPublicViewers returns nil when no project is public. A nil
+// *publicViewers admits no one.
+func newPublicViewers(cfg PublicConfig) *publicViewers {
+	if len(cfg.Projects) == 0 {
+		return nil
+	}
+	viewers := make([]string, 0, len(cfg.Projects))
+	for projectID := range cfg.Projects {
+		viewers = append(viewers, publicViewer(projectID))
+	}
+	return &publicViewers{
+		cfg:     cfg,
+		viewers: viewers,
+		buckets: make(map[string]*connectBucket),
+	}
+}
+
+// viewerOf returns the Hub identity of the project's anonymous viewers if the
+// project is public and fed by userID's channel, else empty.
+func (p *publicViewers) viewerOf(projectID, userID string) string {
+	if p == nil || projectID == "" || p.cfg.Projects[projectID] != userID {
+		return ""
+	}
+	return publicViewer(projectID)
+}
+
+// ownerOf returns the user whose channel feeds the public project, else empty.
+func (p *publicViewers) ownerOf(projectID string) string {
+	if p == nil {
+		return ""
+	}
+	return p.cfg.Projects[projectID]
+}
+
+// allow takes one upgrade from the allowance of ip. Once maxConnectBuckets
+// IPs hold a partial allowance, new IPs are refused until some refill.
+func (p *publicViewers) allow(ip string, now time.Time) bool {
+	if p.cfg.ConnectRate <= 0 {
+		return true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	b, ok := p.buckets[ip]
+	if ok {
+		b.tokens = p.refill(b, now)
+		b.at = now
+	} else {
+		if len(p.buckets) >= maxConnectBuckets {
+			p.prune(now)
+			if len(p.buckets) >= maxConnectBuckets {
+				return false
+			}
+		}
+		b = &connectBucket{tokens: float64(p.cfg.ConnectBurst), at: now}
+		p.buckets[ip] = b
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// refill returns the allowance of b at now.
+func (p *publicViewers) refill(b *connectBucket, now time.Time) float64 {
+	return math.Min(float64(p.cfg.ConnectBurst), b.tokens+now.Sub(b.at).Seconds()*p.cfg.ConnectRate)
+}
+
+// prune forgets the IPs whose allowance is full again. Must be called with mu
+// held.
+func (p *publicViewers) prune(now time.Time) {
+	for ip, b := range p.buckets {
+		if p.refill(b, now) >= float64(p.cfg.ConnectBurst) {
+			delete(p.buckets, ip)
+		}
+	}
+}
+
+// CheckPublic admits an anonymous viewer of a public project, within the
+// per-IP upgrade rate, the per-IP connections and the replica's anonymous
+// connections.
+func (uc *implUseCase) CheckPublic(ctx context.Context, input ws.CheckPublicInput) (ws.CheckPublicOutput, error) {
+	p := uc.public
+	if p == nil || p.cfg.Projects[input.ProjectID] == "" {
+		metrics.PublicConnects.WithLabelValues("not_public").Inc()
+		return ws.CheckPublicOutput{}, ws.ErrNotPublic
+	}
+
+	if !p.allow(input.RemoteIP, time.Now()) {
+		metrics.PublicConnects.WithLabelValues("rate_limited").Inc()
+		uc.logger.Debugf(ctx, "public upgrade rate limited: project_id=%s remote_ip=%s", input.ProjectID, input.RemoteIP)
+		return ws.CheckPublicOutput{}, ws.ErrPublicRateLimited
+	}
+
+	total, fromIP := uc.hub.ConnectionsFrom(p.viewers, input.RemoteIP)
+	if p.cfg.MaxConnections > 0 && total >= p.cfg.MaxConnections {
+		metrics.PublicConnects.WithLabelValues("max_connections").Inc()
+		uc.logger.Warnf(ctx, "public connections at limit: %d", total)
+		return ws.CheckPublicOutput{}, ws.ErrMaxConnectionsReached
+	}
+	if p.cfg.MaxPerIP > 0 && fromIP >= p.cfg.MaxPerIP {
+		metrics.PublicConnects.WithLabelValues("ip_limit").Inc()
+		uc.logger.Debugf(ctx, "public connections per IP at limit: remote_ip=%s connections=%d", input.RemoteIP, fromIP)
+		return ws.CheckPublicOutput{}, ws.ErrPublicRateLimited
+	}
+
+	metrics.PublicConnects.WithLabelValues("accepted").Inc()
+	return ws.CheckPublicOutput{UserID: publicViewer(input.ProjectID)}, nil
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+)
+
+// CheckQuota resolves the user's connection quota, which Register and
+// Subscribe enforce once the connection is ready to join the Hub. With the
+// reject policy a user already at the quota is refused here, before the
+// upgrade; kick_oldest leaves the user's connections alone until then, so a
+// failed upgrade costs the user nothing.
+func (uc *implUseCase) CheckQuota(ctx context.Context, input ws.QuotaInput) (int, error) {
+	limit := uc.connectionQuota(ctx, input)
+	if limit <= 0 {
+		return 0, nil
+	}
+
+	if uc.quota.Policy != QuotaKickOldest {
+		if n := uc.hub.UserConnections(input.UserID); n >= limit {
+			metrics.QuotaRejected.Inc()
+			uc.logger.Infof(ctx, "connection quota exceeded: user_id=%s connections=%d limit=%d", input.UserID, n, limit)
+			return 0, ws.ErrQuotaExceeded
+		}
+	}
+	return limit, nil
+}
+
+// admit adds client to the Hub within its user's quota: with the reject
+// policy it is refused if the user is at the quota, with kick_oldest the
+// user's oldest connections make room for it.
+func (uc *implUseCase) admit(ctx context.Context, client *Connection, quota int) error {
+	kicked, err := uc.hub.admit(client, quota, uc.quota.Policy == QuotaKickOldest)
+	if err != nil {
+		metrics.QuotaRejected.Inc()
+		uc.logger.Infof(ctx, "connection quota exceeded on register: user_id=%s limit=%d", client.userID, quota)
+		return err
+	}
+	if kicked > 0 {
+		metrics.QuotaKicked.Add(float64(kicked))
+		uc.logger.Infof(ctx, "connection quota reached: user_id=%s limit=%d, closed %d oldest", client.userID, quota, kicked)
+	}
+	return nil
+}
+
+// connectionQuota asks the provider, falling back to the static default.
+func (uc *implUseCase) connectionQuota(ctx context.Context, input ws.QuotaInput) int {
+	if uc.quota.Provider == nil {
+		return uc.quota.DefaultPerUser
+	}
+
+	limit, ok, err := uc.quota.Provider.ConnectionQuota(ctx, input)
+	if err != nil {
+		uc.logger.Warnf(ctx, "connection quota lookup failed for user_id=%s: %v", input.UserID, err)
+		return uc.quota.DefaultPerUser
+	}
+	if !ok {
+		return uc.quota.DefaultPerUser
+	}
+	return limit
+}
+
+// claimQuotas maps the value of one token claim (e.g. plan) to a quota.
+type claimQuotas struct {
+	claim  string
+	limits map[string]int
+}
+
+// NewClaimQuotas returns a QuotaProvider that reads the claim from the token
+// and looks its value up in limits. Users without the claim, or with a value
+// not in limits, get no quota from it.
+func NewClaimQuotas(claim string, limits map[string]int) ws.QuotaProvider {
+	return claimQuotas{claim: claim, limits: limits}
+}
+
+func (q claimQuotas) ConnectionQuota(ctx context.Context, input ws.QuotaInput) (int, bool, error) {
+	value, _ := input.Claims[q.claim].(string)
+	if value == "" {
+		return 0, false, nil
+	}
+	limit, ok := q.limits[value]
+	return limit, ok, nil
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	ws "notification-srv/internal/websocket"
+	"notification-srv/pkg/notificationtest"
+)
+
+func newQuotaUseCase(policy QuotaPolicy) *implUseCase {
+	return &implUseCase{
+		hub:    newHub(notificationtest.Logger{}, 0),
+		logger: notificationtest.Logger{},
+		quota:  QuotaConfig{DefaultPerUser: 2, Policy: policy},
+	}
+}
+
+func newQuotaClient(h *Hub, userID string, connectedAt time.Time) *Connection {
+	return &Connection{
+		hub:         h,
+		send:        newSendQueue(4, SendQueueDropNewest),
+		closeReq:    make(chan closeFrame, 1),
+		userID:      userID,
+		connectedAt: connectedAt,
+	}
+}
+
+func TestQuotaRejectConcurrentAdmits(t *testing.T) {
+	uc := newQuotaUseCase(QuotaReject)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	admitted, rejected := 0, 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := uc.admit(ctx, newQuotaClient(uc.hub, "u1", time.Now()), 2)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				admitted++
+			case errors.Is(err, ws.ErrQuotaExceeded):
+				rejected++
+			default:
+				t.Errorf("admit: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if admitted != 2 || rejected != 18 {
+		t.Fatalf("admitted %d rejected %d, want 2 and 18", admitted, rejected)
+	}
+	if _, err := uc.CheckQuota(ctx, ws.QuotaInput{UserID: "u1"}); !errors.Is(err, ws.ErrQuotaExceeded) {
+		t.Fatalf("CheckQuota at the quota: %v, want ErrQuotaExceeded", err)
+	}
+}
+
+func TestQuotaKickOldestOnlyOnAdmit(t *testing.T) {
+	uc := newQuotaUseCase(QuotaKickOldest)
+	ctx := context.Background()
+
+	start := time.Now()
+	oldest := newQuotaClient(uc.hub, "u1", start)
+	newer := newQuotaClient(uc.hub, "u1", start.Add(time.Second))
+	for _, c := range []*Connection{oldest, newer} {
+		if err := uc.admit(ctx, c, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Resolving the quota of a third connection closes nothing yet
+	quota, err := uc.CheckQuota(ctx, ws.QuotaInput{UserID: "u1"})
+	if err != nil || quota != 2 {
+		t.Fatalf("CheckQuota = %d, %v; want 2, nil", quota, err)
+	}
+	if len(oldest.closeReq) != 0 || len(newer.closeReq) != 0 {
+		t.Fatal("CheckQuota closed a connection before the new one was admitted")
+	}
+
+	if err := uc.admit(ctx, newQuotaClient(uc.hub, "u1", start.Add(2*time.Second)), quota); err != nil {
+		t.Fatal(err)
+	}
+	if len(oldest.closeReq) != 1 {
+		t.Fatal("oldest connection not asked to close")
+	}
+	if req := <-oldest.closeReq; req.code != ws.CloseCodeReplaced {
+		t.Fatalf("close code %d, want %d", req.code, ws.CloseCodeReplaced)
+	}
+	if len(newer.closeReq) != 0 {
+		t.Fatal("newer connection asked to close")
+	}
+}
@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"context"
+
+	"notification-srv/internal/model"
+	ws "notification-srv/internal/websocket"
+)
+
+func (uc *implUseCase) ListProjectNotifications(ctx context.Context, sc model.Scope, input ws.ListProjectNotificationsInput) (ws.ListProjectNotificationsOutput, error) {
+	if input.ProjectID == "" {
+		return ws.ListProjectNotificationsOutput{}, ws.ErrInvalidMessage
+	}
+
+	// Callers only ever see their own topic, so no extra authorization is needed
+	key := topicKey(topicName(ws.ChannelTypeProject, input.ProjectID), sc.UserID)
+	frames, lastSeq, truncated := uc.seq.since(key, input.AfterSeq)
+
+	return ws.ListProjectNotificationsOutput{
+		Epoch:     uc.seq.epoch,
+		LastSeq:   lastSeq,
+		Frames:    frames,
+		Truncated: truncated,
+	}, nil
+}
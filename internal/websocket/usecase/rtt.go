@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"encoding/json"
+	"time"
+
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+)
+
+// observeRTT folds the round-trip time of a ping answered by the peer into
+// the connection's EWMA.
+func (c *Connection) observeRTT(sample time.Duration) {
+	metrics.ConnectionRTT.Observe(sample.Seconds())
+
+	prev := c.rttNanos.Load()
+	if prev == 0 {
+		c.rttNanos.Store(int64(sample))
+		return
+	}
+	c.rttNanos.Store(int64(rttAlpha*float64(sample) + (1-rttAlpha)*float64(prev)))
+}
+
+// rtt returns the smoothed round-trip time, or zero before the first pong.
+func (c *Connection) rtt() time.Duration {
+	return time.Duration(c.rttNanos.Load())
+}
+
+// heartbeatFrame builds the periodic heartbeat sent to clients that opted in.
+func (c *Connection) heartbeatFrame() []byte {
+	now := time.Now()
+	frame, _ := json.Marshal(ws.NotificationOutput{
+		Type:      ws.MessageTypeHeartbeat,
+		Timestamp: now,
+		Payload: ws.HeartbeatPayload{
+			ServerTime: now,
+			RTTMs:      float64(c.rtt()) / float64(time.Millisecond),
+		},
+	})
+	return frame
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"html"
+	"maps"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+)
+
+// DefaultSanitizeRules are the length limits of the free-text fields, the
+// only fields cleaned: IDs, statuses and URLs are left alone. Names and phases
+// are short; messages and sample mentions carry crawled or user-written text
+// of any length.
+var DefaultSanitizeRules = map[string]SanitizeRule{
+	"source_name":      {MaxLength: 200},
+	"message":          {MaxLength: 2000},
+	"current_phase":    {MaxLength: 100},
+	"project_name":     {MaxLength: 200},
+	"affected_aspects": {MaxLength: 100},
+	"sample_mentions":  {MaxLength: 500},
+	"time_window":      {MaxLength: 100},
+	"action_required":  {MaxLength: 2000},
+	"campaign_name":    {MaxLength: 200},
+	"resource_name":    {MaxLength: 200},
+	"location":         {MaxLength: 200},
+	"user_agent":       {MaxLength: 500},
+	"title":            {MaxLength: 200},
+}
+
+// newSanitizer returns nil when sanitization is disabled. A nil *sanitizer
+// returns payloads unchanged.
+func newSanitizer(cfg SanitizeConfig) *sanitizer {
+	if !cfg.Enabled {
+		return nil
+	}
+	rules := maps.Clone(DefaultSanitizeRules)
+	maps.Copy(rules, cfg.Fields)
+	return &sanitizer{rules: rules}
+}
+
+// payload returns data with its free-text fields cleaned.
+func (s *sanitizer) payload(msgType ws.MessageType, data any) any {
+	if s == nil {
+		return data
+	}
+
+	switch p := data.(type) {
+	case ws.DataOnboardingPayload:
+		p.SourceName = s.text(msgType, "source_name", p.SourceName)
+		p.Message = s.text(msgType, "message", p.Message)
+		return p
+
+	case ws.AnalyticsPipelinePayload:
+		p.CurrentPhase = s.text(msgType, "current_phase", p.CurrentPhase)
+		return p
+
+	case ws.CrisisAlertPayload:
+		p.ProjectName = s.text(msgType, "project_name", p.ProjectName)
+		p.AffectedAspects = s.texts(msgType, "affected_aspects", p.AffectedAspects)
+		p.SampleMentions = s.texts(msgType, "sample_mentions", p.SampleMentions)
+		p.TimeWindow = s.text(msgType, "time_window", p.TimeWindow)
+		p.ActionRequired = s.text(msgType, "action_required", p.ActionRequired)
+		return p
+
+	case ws.CampaignEventPayload:
+		p.CampaignName = s.text(msgType, "campaign_name", p.CampaignName)
+		p.ResourceName = s.text(msgType, "resource_name", p.ResourceName)
+		p.Message = s.text(msgType, "message", p.Message)
+		return p
+
+	case ws.SecurityAlertPayload:
+		p.Message = s.text(msgType, "message", p.Message)
+		p.Location = s.text(msgType, "location", p.Location)
+		p.UserAgent = s.text(msgType, "user_agent", p.UserAgent)
+		return p
+
+	case ws.BillingEventPayload:
+		p.Message = s.text(msgType, "message", p.Message)
+		return p
+
+	case ws.SystemMaintenancePayload:
+		p.Title = s.text(msgType, "title", p.Title)
+		p.Message = s.text(msgType, "message", p.Message)
+		return p
+	}
+	return data
+}
+
+// texts cleans every element of a list field into a new slice, as the payload
+// shares its slices with the message it was copied from.
+func (s *sanitizer) texts(msgType ws.MessageType, field string, values []string) []string {
+	if values == nil {
+		return nil
+	}
+	cleaned := make([]string, len(values))
+	for i, v := range values {
+		cleaned[i] = s.text(msgType, field, v)
+	}
+	return cleaned
+}
+
+// text makes v valid UTF-8, strips its control characters, cuts it to the
+// field's length and escapes it, counting each change. The length is that of
+// the text before escaping, so an entity is never cut in half.
+func (s *sanitizer) text(msgType ws.MessageType, field, v string) string {
+	rule := s.rules[field]
+
+	if !utf8.ValidString(v) {
+		v = strings.ToValidUTF8(v, string(utf8.RuneError))
+		metrics.SanitizedFields.WithLabelValues(string(msgType), field, "invalid_utf8").Inc()
+	}
+	if strings.IndexFunc(v, stripped) >= 0 {
+		v = strings.Map(func(r rune) rune {
+			if stripped(r) {
+				return -1
+			}
+			return r
+		}, v)
+		metrics.SanitizedFields.WithLabelValues(string(msgType), field, "control_chars").Inc()
+	}
+	if rule.MaxLength > 0 && utf8.RuneCountInString(v) > rule.MaxLength {
+		v = truncate(v, rule.MaxLength)
+		metrics.SanitizedFields.WithLabelValues(string(msgType), field, "truncated").Inc()
+	}
+	if rule.EscapeHTML {
+		v = html.EscapeString(v)
+	}
+	return v
+}
+
+// stripped reports whether r is a control character removed from text.
+// Newlines and tabs are kept.
+func stripped(r rune) bool {
+	return unicode.IsControl(r) && r != '\n' && r != '\t'
+}
+
+// truncate cuts s, longer than limit characters, to limit characters, the
+// last one an ellipsis.
+func truncate(s string, limit int) string {
+	n := 0
+	for i := range s {
+		if n == limit-1 {
+			return s[:i] + "…"
+		}
+		n++
+	}
+	return s
+}
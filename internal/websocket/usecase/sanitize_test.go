@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	ws "notification-srv/internal/websocket"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		limit int
+		want  string
+	}{
+		{"limit 1", "abc", 1, "…"},
+		{"ascii", "abcdef", 4, "abc…"},
+		{"two-byte runes", "héllo wörld", 5, "héll…"},
+		{"three-byte runes", "日本語テキスト", 4, "日本語…"},
+		{"four-byte runes", "😀😀😀", 2, "😀…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncate(tt.in, tt.limit); got != tt.want {
+				t.Errorf("truncate(%q, %d) = %q, want %q", tt.in, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizerText(t *testing.T) {
+	s := newSanitizer(SanitizeConfig{Enabled: true, Fields: map[string]SanitizeRule{
+		"plain":   {MaxLength: 4},
+		"escaped": {MaxLength: 4, EscapeHTML: true},
+	}})
+
+	tests := []struct {
+		name  string
+		field string
+		in    string
+		want  string
+	}{
+		{"at the limit", "plain", "日本語テ", "日本語テ"},
+		{"over the limit", "plain", "日本語テキ", "日本語…"},
+		{"invalid utf-8", "plain", "a\xffb", "a�b"},
+		{"invalid utf-8 counts as one character", "plain", "ab\xff\xfecd", "ab�…"},
+		{"control characters", "plain", "a\x00\x1b\nb", "a\nb"},
+		{"control characters go before the limit", "plain", "\x00\x00abcd", "abcd"},
+		{"escaped after truncating", "escaped", "<b>bold</b>", "&lt;b&gt;…"},
+		{"escaping never cut", "escaped", "a&b&", "a&amp;b&amp;"},
+		{"field without a rule", "other", strings.Repeat("<x>", 1000), strings.Repeat("<x>", 1000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.text(ws.MessageTypeCrisisAlert, tt.field, tt.in); got != tt.want {
+				t.Errorf("text(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizerPayloadCopiesLists(t *testing.T) {
+	s := newSanitizer(SanitizeConfig{Enabled: true, Fields: map[string]SanitizeRule{
+		"affected_aspects": {MaxLength: 3},
+	}})
+	aspects := []string{"pricing", "ok"}
+	in := ws.CrisisAlertPayload{AffectedAspects: aspects}
+
+	out := s.payload(ws.MessageTypeCrisisAlert, in).(ws.CrisisAlertPayload)
+	if want := []string{"pr…", "ok"}; !reflect.DeepEqual(out.AffectedAspects, want) {
+		t.Errorf("AffectedAspects = %q, want %q", out.AffectedAspects, want)
+	}
+	if want := []string{"pricing", "ok"}; !reflect.DeepEqual(aspects, want) {
+		t.Errorf("input slice changed to %q", aspects)
+	}
+	if out.SampleMentions != nil {
+		t.Errorf("SampleMentions = %q, want nil kept nil", out.SampleMentions)
+	}
+}
+
+func TestNilSanitizer(t *testing.T) {
+	var s *sanitizer
+	in := ws.CrisisAlertPayload{ProjectName: "<b>\x00"}
+	if got := s.payload(ws.MessageTypeCrisisAlert, in); !reflect.DeepEqual(got, in) {
+		t.Errorf("payload() = %+v, want it unchanged", got)
+	}
+	if newSanitizer(SanitizeConfig{}) != nil {
+		t.Error("newSanitizer() of a disabled config is not nil")
+	}
+}
@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"strings"
+	"sync"
+
+	"notification-srv/internal/metrics"
+)
+
+// defaultSendQueueSize is the queue size when none is configured.
+const defaultSendQueueSize = 256
+
+// sendQueue is a connection's bounded queue of outbound frames: a ring buffer
+// the Hub pushes to and writePump drains. Unlike a channel it can evict the
+// oldest frame when full and be inspected without receiving.
+type sendQueue struct {
+	mu     sync.Mutex
+	buf    [][]byte
+	head   int // Index of the oldest frame
+	n      int
+	policy SendQueuePolicy
+	closed bool
+
+	// Signalled after a push or close; the consumer then takes everything queued.
+	ready chan struct{}
+}
+
+func newSendQueue(size int, policy SendQueuePolicy) *sendQueue {
+	if size < 1 {
+		size = 1
+	}
+	return &sendQueue{
+		buf:    make([][]byte, size),
+		policy: policy,
+		ready:  make(chan struct{}, 1),
+	}
+}
+
+// pushResult is the outcome of sendQueue.push.
+type pushResult int
+
+const (
+	pushQueued  pushResult = iota
+	pushEvicted            // Queued in place of the oldest frame
+	pushFull               // Refused, the queue is full
+	pushClosed             // Refused, the queue is closed
+)
+
+// push queues message. When the queue is full the message is refused, or with
+// SendQueueDropOldest the oldest frame is evicted for it.
+func (q *sendQueue) push(message []byte) pushResult {
+	return q.pushWith(message, q.policy)
+}
+
+// pushPriority queues message, evicting the oldest frame for it when the queue
+// is full whatever the policy.
+func (q *sendQueue) pushPriority(message []byte) pushResult {
+	return q.pushWith(message, SendQueueDropOldest)
+}
+
+func (q *sendQueue) pushWith(message []byte, policy SendQueuePolicy) pushResult {
+	result := pushQueued
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return pushClosed
+	}
+	if q.n == len(q.buf) {
+		if policy != SendQueueDropOldest {
+			q.mu.Unlock()
+			return pushFull
+		}
+		q.buf[q.head] = nil
+		q.head = (q.head + 1) % len(q.buf)
+		q.n--
+		metrics.SendQueueEvicted.Inc()
+		result = pushEvicted
+	}
+	q.buf[(q.head+q.n)%len(q.buf)] = message
+	q.n++
+	q.mu.Unlock()
+
+	q.signal()
+	return result
+}
+
+// take appends every queued frame to dst, oldest first, and reports whether
+// the queue is still open. Once it returns false nothing more will arrive.
+func (q *sendQueue) take(dst [][]byte) ([][]byte, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for ; q.n > 0; q.n-- {
+		dst = append(dst, q.buf[q.head])
+		q.buf[q.head] = nil
+		q.head = (q.head + 1) % len(q.buf)
+	}
+	return dst, !q.closed
+}
+
+// close ends the queue; frames already queued can still be taken.
+func (q *sendQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.signal()
+}
+
+func (q *sendQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// len is the number of queued frames.
+func (q *sendQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.n
+}
+
+// cap is the capacity of the queue.
+func (q *sendQueue) cap() int {
+	return len(q.buf)
+}
+
+// sendQueueSize returns the queue size of a connection with the given client
+// label: the label's own size if configured, the default otherwise.
+func (uc *implUseCase) sendQueueSize(clientLabel string) int {
+	if size, ok := uc.sendQueue.ClassSizes[strings.ToLower(clientLabel)]; ok && clientLabel != "" {
+		return size
+	}
+	return uc.sendQueue.Size
+}
+
+func (uc *implUseCase) newSendQueue(clientLabel string) *sendQueue {
+	return newSendQueue(uc.sendQueueSize(clientLabel), uc.sendQueue.Policy)
+}
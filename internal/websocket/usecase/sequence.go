@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"container/list"
+	"encoding/json"
+	"strings"
+
+	ws "notification-srv/internal/websocket"
+
+	"github.com/google/uuid"
+)
+
+func newSequencer(cfg SequenceConfig) *sequencer {
+	return &sequencer{
+		epoch:      uuid.NewString(),
+		bufferSize: cfg.BufferSize,
+		maxTopics:  cfg.MaxTopics,
+		topics:     make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// sequenced reports whether frames on a channel get sequence numbers: only
+// user-targeted entity topics (project and campaign channels).
+func sequenced(parsed ParsedChannel) bool {
+	return parsed.UserID != "" && parsed.EntityID != ""
+}
+
+// topicName is the topic as shown to clients, e.g. project:{project_id}.
+func topicName(channelType ws.ChannelType, entityID string) string {
+	return string(channelType) + ":" + entityID
+}
+
+// topicKey identifies a sequence internally. Each user has its own sequence so
+// the numbers a client sees are contiguous.
+func topicKey(topic, userID string) string {
+	return topic + ":user:" + userID
+}
+
+// next returns the next sequence number of topic. Callers must record the
+// frame with the returned number before calling next again for the same topic.
+func (s *sequencer) next(topic string) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tl := s.touch(topic)
+	tl.seq++
+	return tl.seq
+}
+
+// record buffers a sent frame for replay.
+func (s *sequencer) record(topic string, seq uint64, data []byte) {
+	if s.bufferSize <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tl := s.touch(topic)
+	if len(tl.frames) < s.bufferSize {
+		tl.frames = append(tl.frames, seqFrame{seq: seq, data: data})
+		return
+	}
+	tl.frames[tl.start] = seqFrame{seq: seq, data: data}
+	tl.start = (tl.start + 1) % len(tl.frames)
+}
+
+// since returns the buffered frames with a sequence number after afterSeq, the
+// topic's latest sequence number, and whether frames in between were evicted.
+func (s *sequencer) since(topic string, afterSeq uint64) (frames []json.RawMessage, lastSeq uint64, truncated bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.topics[topic]
+	if !ok {
+		// Unknown topic: nothing was sent since this epoch began (or it was evicted)
+		return nil, 0, afterSeq > 0
+	}
+	tl := el.Value.(*topicLog)
+
+	n := len(tl.frames)
+	for i := 0; i < n; i++ {
+		f := tl.frames[(tl.start+i)%n]
+		if f.seq <= afterSeq {
+			continue
+		}
+		if len(frames) == 0 && f.seq > afterSeq+1 {
+			truncated = true
+		}
+		frames = append(frames, json.RawMessage(f.data))
+	}
+	if len(frames) == 0 && tl.seq > afterSeq {
+		truncated = true
+	}
+	return frames, tl.seq, truncated
+}
+
+// has reports whether topic has a log, without touching it.
+func (s *sequencer) has(topic string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.topics[topic]
+	return ok
+}
+
+// purge drops the logs of every topic starting with prefix and returns how
+// many were dropped.
+func (s *sequencer) purge(prefix string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := 0
+	for topic, el := range s.topics {
+		if strings.HasPrefix(topic, prefix) {
+			s.lru.Remove(el)
+			delete(s.topics, topic)
+			purged++
+		}
+	}
+	return purged
+}
+
+// users returns the users with a log of topic, e.g. project:{project_id}.
+func (s *sequencer) users(topic string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefix := topicKey(topic, "")
+	var users []string
+	for key := range s.topics {
+		if userID, ok := strings.CutPrefix(key, prefix); ok {
+			users = append(users, userID)
+		}
+	}
+	return users
+}
+
+// touch returns the log of topic, creating it and evicting the least recently
+// used topic if needed. Must be called with mu held.
+func (s *sequencer) touch(topic string) *topicLog {
+	if el, ok := s.topics[topic]; ok {
+		s.lru.MoveToFront(el)
+		return el.Value.(*topicLog)
+	}
+
+	tl := &topicLog{topic: topic}
+	s.topics[topic] = s.lru.PushFront(tl)
+
+	if s.maxTopics > 0 && s.lru.Len() > s.maxTopics {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.topics, oldest.Value.(*topicLog).topic)
+	}
+	return tl
+}
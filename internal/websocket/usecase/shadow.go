@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"sort"
+	"strings"
+
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+)
+
+// maxLoggedDiffs caps how many differing fields are written to a single log line.
+const maxLoggedDiffs = 10
+
+// shouldShadow reports whether the current message is sampled for a shadow run.
+func (uc *implUseCase) shouldShadow() bool {
+	if uc.shadowTransform == nil || uc.shadowPercent <= 0 {
+		return false
+	}
+	return rand.IntN(100) < uc.shadowPercent
+}
+
+// runShadow runs the candidate transformer and records how its output differs
+// from the delivered one. It never affects delivery.
+func (uc *implUseCase) runShadow(ctx context.Context, msgType ws.MessageType, payload []byte, primary ws.NotificationOutput, primaryErr error) {
+	shadow, shadowErr := uc.shadowTransform(uc, ctx, msgType, payload)
+
+	switch {
+	case primaryErr != nil || shadowErr != nil:
+		if (primaryErr == nil) == (shadowErr == nil) {
+			metrics.ShadowRuns.WithLabelValues(string(msgType), "match").Inc()
+			return
+		}
+		metrics.ShadowRuns.WithLabelValues(string(msgType), "error_mismatch").Inc()
+		uc.logger.Warnf(ctx, "shadow transform %s: error mismatch: type=%s primary_err=%v shadow_err=%v",
+			uc.shadowVersion, msgType, primaryErr, shadowErr)
+		return
+	}
+
+	diffs, err := diffOutputs(primary, shadow)
+	if err != nil {
+		metrics.ShadowRuns.WithLabelValues(string(msgType), "error").Inc()
+		uc.logger.Warnf(ctx, "shadow transform %s: diff failed: %v", uc.shadowVersion, err)
+		return
+	}
+	if len(diffs) == 0 {
+		metrics.ShadowRuns.WithLabelValues(string(msgType), "match").Inc()
+		return
+	}
+
+	metrics.ShadowRuns.WithLabelValues(string(msgType), "diff").Inc()
+	for _, field := range diffs {
+		metrics.ShadowFieldDiffs.WithLabelValues(string(msgType), field).Inc()
+	}
+	logged := diffs
+	if len(logged) > maxLoggedDiffs {
+		logged = logged[:maxLoggedDiffs]
+	}
+	uc.logger.Warnf(ctx, "shadow transform %s: %d field(s) differ: type=%s fields=%s",
+		uc.shadowVersion, len(diffs), msgType, strings.Join(logged, ","))
+}
+
+// diffOutputs returns the sorted JSON paths whose values differ between a and b.
+// Per-emit fields (id, timestamp) are ignored.
+func diffOutputs(a, b ws.NotificationOutput) ([]string, error) {
+	a.ID, b.ID = "", ""
+	b.Timestamp = a.Timestamp
+
+	fa, err := flattenJSON(a)
+	if err != nil {
+		return nil, err
+	}
+	fb, err := flattenJSON(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []string
+	for path, va := range fa {
+		if vb, ok := fb[path]; !ok || !reflect.DeepEqual(va, vb) {
+			diffs = append(diffs, path)
+		}
+	}
+	for path := range fb {
+		if _, ok := fa[path]; !ok {
+			diffs = append(diffs, path)
+		}
+	}
+	sort.Strings(diffs)
+	return diffs, nil
+}
+
+// flattenJSON marshals v and flattens it into leaf paths such as "payload.progress".
+// Arrays are compared as a whole to keep the path set bounded.
+func flattenJSON(v any) (map[string]any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var tree any
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]any)
+	var walk func(prefix string, node any)
+	walk = func(prefix string, node any) {
+		obj, ok := node.(map[string]any)
+		if !ok {
+			out[prefix] = node
+			return
+		}
+		for k, child := range obj {
+			if prefix == "" {
+				walk(k, child)
+			} else {
+				walk(fmt.Sprintf("%s.%s", prefix, k), child)
+			}
+		}
+	}
+	walk("", tree)
+	return out, nil
+}
@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"notification-srv/internal/sink"
+	ws "notification-srv/internal/websocket"
+)
+
+// mirror reports a routed notification to every outbound sink, whether or not
+// it reached a connection. Sinks queue without blocking, so delivery latency
+// is unaffected.
+func (uc *implUseCase) mirror(ctx context.Context, parsed ParsedChannel, output ws.NotificationOutput, frame []byte, d delivery) {
+	if len(uc.sinks) == 0 {
+		return
+	}
+
+	n := sink.Notification{
+		ID:          output.ID,
+		Type:        string(output.Type),
+		Importance:  string(output.Importance),
+		ChannelType: string(parsed.ChannelType),
+		EntityID:    parsed.EntityID,
+		UserID:      parsed.UserID,
+		Region:      output.Region,
+		Recipients:  d.recipients,
+		Dropped:     d.dropped,
+		Size:        len(frame),
+		ReceivedAt:  d.receivedAt,
+		DeliveredAt: time.Now(),
+		Frame:       frame,
+	}
+	for _, s := range uc.sinks {
+		s.Publish(ctx, n)
+	}
+}
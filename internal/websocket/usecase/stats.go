@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	ws "notification-srv/internal/websocket"
+)
+
+// Buckets per stats window; the window is accurate to one bucket.
+const statsBuckets = 30
+
+func newChannelStats(window time.Duration) *channelStats {
+	interval := window / statsBuckets
+	if interval < time.Second {
+		interval = time.Second
+	}
+	return &channelStats{
+		window:   window,
+		interval: interval,
+		channels: make(map[string][]statsBucket),
+	}
+}
+
+// add records the counts of one message on its channel pattern.
+func (s *channelStats) add(channel string, c channelCounts) {
+	slot := time.Now().UnixNano() / int64(s.interval)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ring, ok := s.channels[channel]
+	if !ok {
+		ring = make([]statsBucket, statsBuckets+1)
+		s.channels[channel] = ring
+	}
+	b := &ring[slot%int64(len(ring))]
+	if b.slot != slot {
+		*b = statsBucket{slot: slot}
+	}
+	b.counts.received += c.received
+	b.counts.transformErrors += c.transformErrors
+	b.counts.delivered += c.delivered
+	b.counts.dropped += c.dropped
+	b.counts.noRecipients += c.noRecipients
+
+	s.total.received += c.received
+	s.total.transformErrors += c.transformErrors
+	s.total.delivered += c.delivered
+	s.total.dropped += c.dropped
+	s.total.noRecipients += c.noRecipients
+}
+
+// totals returns the counts of all channels since start.
+func (s *channelStats) totals() channelCounts {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.total
+}
+
+// sum returns the counts of every channel pattern matching pattern over the
+// window. Patterns without traffic in the window are left out.
+func (s *channelStats) sum(pattern string) map[string]channelCounts {
+	oldest := time.Now().Add(-s.window).UnixNano() / int64(s.interval)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make(map[string]channelCounts)
+	for channel, ring := range s.channels {
+		if ok, _ := path.Match(pattern, channel); !ok {
+			continue
+		}
+
+		var total channelCounts
+		for _, b := range ring {
+			if b.slot <= oldest {
+				continue
+			}
+			total.received += b.counts.received
+			total.transformErrors += b.counts.transformErrors
+			total.delivered += b.counts.delivered
+			total.dropped += b.counts.dropped
+			total.noRecipients += b.counts.noRecipients
+		}
+		if total.received > 0 {
+			out[channel] = total
+		}
+	}
+	return out
+}
+
+func (uc *implUseCase) GetChannelStats(ctx context.Context, input ws.GetChannelStatsInput) (ws.GetChannelStatsOutput, error) {
+	pattern := input.Pattern
+	if pattern == "" {
+		pattern = "*"
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return ws.GetChannelStatsOutput{}, ws.ErrInvalidMessage
+	}
+
+	sums := uc.stats.sum(pattern)
+	channels := make([]ws.ChannelStats, 0, len(sums))
+	for channel, c := range sums {
+		channels = append(channels, ws.ChannelStats{
+			Channel:         channel,
+			Received:        c.received,
+			TransformErrors: c.transformErrors,
+			Delivered:       c.delivered,
+			Dropped:         c.dropped,
+			NoRecipients:    c.noRecipients,
+		})
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Channel < channels[j].Channel })
+
+	return ws.GetChannelStatsOutput{
+		Window:   uc.stats.window,
+		Channels: channels,
+	}, nil
+}
+
+// channelPattern replaces the IDs of a parsed channel with *, e.g.
+// project:{project_id}:user:{user_id} becomes project:*:user:*.
+func channelPattern(parsed ParsedChannel) string {
+	switch parsed.ChannelType {
+	case ws.ChannelTypeProject, ws.ChannelTypeCampaign:
+		return string(parsed.ChannelType) + ":*:user:*"
+	case ws.ChannelTypeAlert:
+		return "alert:" + parsed.SubType + ":user:*"
+	case ws.ChannelTypeSystem:
+		return "system:" + parsed.SubType
+	case ws.ChannelTypeControl:
+		if parsed.EntityID == "" {
+			return "control:" + parsed.SubType
+		}
+		return "control:" + parsed.SubType + ":*"
+	}
+	return "unknown"
+}
+
+// invalidChannelPattern keys channels that do not parse by their first segment.
+func invalidChannelPattern(channel string) string {
+	prefix, _, _ := strings.Cut(channel, ":")
+	return "invalid:" + prefix
+}
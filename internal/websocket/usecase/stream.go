@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	ws "notification-srv/internal/websocket"
+)
+
+// Subscribe registers a Connection without a socket. The Hub routes to it like
+// any other connection (including the project snapshot), and the caller reads
+// frames from the returned channel instead of a writePump.
+func (uc *implUseCase) Subscribe(ctx context.Context, input ws.SubscribeInput) (<-chan []byte, error) {
+	if input.UserID == "" {
+		return nil, ws.ErrInvalidMessage
+	}
+
+	client := &Connection{
+		hub:       uc.hub,
+		send:      uc.newSendQueue(input.ClientLabel),
+		closeReq:  make(chan closeFrame, 1),
+		userID:    input.UserID,
+		projectID: input.ProjectID,
+
+		projectOnly: input.ProjectOnly,
+		delegatedBy: input.DelegatedBy,
+		userAgent:   input.UserAgent,
+		remoteIP:    input.RemoteIP,
+		clientLabel: input.ClientLabel,
+		metricLabel: uc.metricLabel(input.ClientLabel),
+		connectedAt: time.Now(),
+		fields:      newProjection(input.Fields),
+	}
+	if err := client.setTypes(input.Types); err != nil {
+		return nil, err
+	}
+	if err := client.setMinImportance(input.MinImportance); err != nil {
+		return nil, err
+	}
+
+	if client.projectID != "" && client.accepts(ws.MessageTypeProjectProgress) {
+		uc.backfillProject(ctx, client)
+	}
+	uc.queueAnnouncement(client)
+
+	if err := uc.admit(ctx, client, input.Quota); err != nil {
+		return nil, err
+	}
+
+	// Unregistering closes the send queue, which ends the caller's stream
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-client.closeReq:
+			// Closed by the server, e.g. the project was deleted
+		}
+		uc.hub.unregister <- client
+	}()
+
+	frames := make(chan []byte)
+	go func() {
+		defer close(frames)
+		var batch [][]byte
+		for {
+			select {
+			case <-client.send.ready:
+			case <-ctx.Done():
+				return
+			}
+			var open bool
+			batch, open = client.send.take(batch[:0])
+			for _, frame := range batch {
+				select {
+				case frames <- frame:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if !open {
+				return
+			}
+		}
+	}()
+	return frames, nil
+}
@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"time"
+
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+)
+
+// newTerminalDedupe returns nil when the window is 0. A nil *terminalDedupe
+// suppresses nothing.
+func newTerminalDedupe(cfg TerminalDedupeConfig) *terminalDedupe {
+	if cfg.Window <= 0 {
+		return nil
+	}
+	return &terminalDedupe{
+		window:    cfg.Window,
+		maxTopics: cfg.MaxTopics,
+		topics:    make(map[string]*list.Element),
+		lru:       list.New(),
+	}
+}
+
+// terminalOf returns the job of a job update, its terminal status (empty if
+// the job is still running) and whether the publisher forced delivery. ok is
+// false for messages that are not job updates.
+func terminalOf(output ws.NotificationOutput) (job, status string, force, ok bool) {
+	switch p := output.Payload.(type) {
+	case ws.DataOnboardingPayload:
+		if s := strings.ToLower(p.Status); s == "completed" || s == "failed" {
+			status = s
+		}
+		return p.SourceID, status, p.Force, true
+	case ws.AnalyticsPipelinePayload:
+		if p.Progress >= 100 {
+			status = "completed"
+		}
+		return p.SourceID, status, p.Force, true
+	case ws.CampaignEventPayload:
+		if strings.EqualFold(p.EventType, "finished") {
+			status = "finished"
+		}
+		return p.CampaignID, status, p.Force, true
+	}
+	return "", "", false, false
+}
+
+// repeat reports whether a terminal status repeats the job's last one within
+// the window, and remembers it unless it is a repeat that is not forced. An
+// update that is not terminal forgets the job, so a job that runs again may
+// finish again.
+func (d *terminalDedupe) repeat(key, status string, force bool, now time.Time) bool {
+	if d == nil {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if status == "" {
+		if el, ok := d.topics[key]; ok {
+			d.lru.Remove(el)
+			delete(d.topics, key)
+		}
+		return false
+	}
+
+	ts := d.touch(key)
+	repeated := ts.status == status && now.Sub(ts.at) < d.window
+	if !repeated || force {
+		ts.status, ts.at = status, now
+	}
+	return repeated
+}
+
+// purge forgets every job whose key starts with prefix.
+func (d *terminalDedupe) purge(prefix string) {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for key, el := range d.topics {
+		if strings.HasPrefix(key, prefix) {
+			d.lru.Remove(el)
+			delete(d.topics, key)
+		}
+	}
+}
+
+// touch returns the entry of key, creating it and evicting the least recently
+// updated job if needed. Must be called with mu held.
+func (d *terminalDedupe) touch(key string) *terminalSeen {
+	if el, ok := d.topics[key]; ok {
+		d.lru.MoveToFront(el)
+		return el.Value.(*terminalSeen)
+	}
+
+	ts := &terminalSeen{key: key}
+	d.topics[key] = d.lru.PushFront(ts)
+
+	if d.maxTopics > 0 && d.lru.Len() > d.maxTopics {
+		oldest := d.lru.Back()
+		d.lru.Remove(oldest)
+		delete(d.topics, oldest.Value.(*terminalSeen).key)
+	}
+	return ts
+}
+
+// dedupeTerminal reports whether a job update should be delivered: not if it
+// repeats the job's last terminal status within the window, unless forced.
+func (uc *implUseCase) dedupeTerminal(ctx context.Context, parsed ParsedChannel, output ws.NotificationOutput) bool {
+	if uc.terminals == nil || !sequenced(parsed) {
+		return true
+	}
+	job, status, force, ok := terminalOf(output)
+	if !ok {
+		return true
+	}
+
+	key := topicKey(topicName(parsed.ChannelType, parsed.EntityID), parsed.UserID) + ":" + job
+	if !uc.terminals.repeat(key, status, force, time.Now()) {
+		return true
+	}
+
+	if force {
+		metrics.DuplicateTerminals.WithLabelValues(string(output.Type), "forced").Inc()
+		uc.logger.Infof(ctx, "repeated terminal update forced: type=%s job=%s status=%s", output.Type, key, status)
+		return true
+	}
+	metrics.DuplicateTerminals.WithLabelValues(string(output.Type), "suppressed").Inc()
+	uc.logger.Debugf(ctx, "repeated terminal update suppressed: type=%s job=%s status=%s", output.Type, key, status)
+	return false
+}
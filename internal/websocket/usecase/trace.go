@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"notification-srv/internal/metrics"
+	"notification-srv/internal/model"
+	ws "notification-srv/internal/websocket"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smap-hcmut/shared-libs/go/log"
+)
+
+// TraceUser turns on targeted tracing of a user on this replica and publishes
+// it on the control channel so the other replicas follow.
+func (uc *implUseCase) TraceUser(ctx context.Context, sc model.Scope, input ws.TraceUserInput) (ws.UserTrace, error) {
+	if input.UserID == "" || strings.Contains(input.UserID, ":") || input.TTL <= 0 || input.TTL > uc.traces.maxTTL {
+		return ws.UserTrace{}, ws.ErrInvalidTrace
+	}
+
+	expiresAt := time.Now().Add(input.TTL)
+	if !uc.traces.set(input.UserID, expiresAt) {
+		return ws.UserTrace{}, ws.ErrTooManyTraces
+	}
+	uc.logger.Infof(ctx, "user trace started: user_id=%s expires_at=%s by user_id=%s",
+		input.UserID, expiresAt.Format(time.RFC3339), sc.UserID)
+	uc.publishTrace(ctx, input.UserID, expiresAt)
+
+	return ws.UserTrace{UserID: input.UserID, ExpiresAt: expiresAt}, nil
+}
+
+func (uc *implUseCase) UntraceUser(ctx context.Context, sc model.Scope, input ws.UntraceUserInput) error {
+	if input.UserID == "" || strings.Contains(input.UserID, ":") {
+		return ws.ErrInvalidTrace
+	}
+
+	uc.traces.set(input.UserID, time.Time{})
+	uc.logger.Infof(ctx, "user trace stopped: user_id=%s by user_id=%s", input.UserID, sc.UserID)
+	uc.publishTrace(ctx, input.UserID, time.Time{})
+	return nil
+}
+
+func (uc *implUseCase) ListUserTraces(ctx context.Context) (ws.ListUserTracesOutput, error) {
+	return ws.ListUserTracesOutput{Traces: uc.traces.list(time.Now())}, nil
+}
+
+// publishTrace tells the other replicas to trace the user until expiresAt.
+func (uc *implUseCase) publishTrace(ctx context.Context, userID string, expiresAt time.Time) {
+	if uc.repo == nil {
+		return
+	}
+	payload, _ := json.Marshal(traceControl{ExpiresAt: expiresAt})
+	if err := uc.repo.PublishControl(ctx, ws.ControlEventTrace, userID, payload); err != nil {
+		// This replica traces the user; the others only see the published traces
+		uc.logger.Warnf(ctx, "trace control publish failed: %v", err)
+	}
+}
+
+// handleTraceControl applies control:trace:{user_id} from any replica.
+func (uc *implUseCase) handleTraceControl(ctx context.Context, userID string, payload []byte) error {
+	if userID == "" {
+		return ws.ErrInvalidChannel
+	}
+	var ctl traceControl
+	if err := json.Unmarshal(payload, &ctl); err != nil {
+		return ws.ErrInvalidMessage
+	}
+
+	if !uc.traces.set(userID, ctl.ExpiresAt) {
+		uc.logger.Warnf(ctx, "user trace of user_id=%s not started: %d users traced already", userID, uc.traces.maxUsers)
+	}
+	return nil
+}
+
+// newUserTraces returns an empty set of traces bounded by cfg.
+func newUserTraces(cfg UserTraceConfig) *userTraces {
+	return &userTraces{
+		until:    make(map[string]time.Time),
+		maxTTL:   cfg.MaxTTL,
+		maxUsers: cfg.MaxUsers,
+	}
+}
+
+// set traces userID until the given time, or stops tracing it if that time
+// has passed. It reports false if the user is not traced yet and MaxUsers are.
+func (t *userTraces) set(userID string, until time.Time) bool {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for id, u := range t.until {
+		if !now.Before(u) {
+			delete(t.until, id)
+		}
+	}
+	if !now.Before(until) {
+		delete(t.until, userID)
+		return true
+	}
+	if _, ok := t.until[userID]; !ok && len(t.until) >= t.maxUsers {
+		return false
+	}
+	t.until[userID] = until
+	return true
+}
+
+// traced reports whether the user is under targeted tracing.
+func (t *userTraces) traced(userID string) bool {
+	if t == nil || userID == "" {
+		return false
+	}
+
+	t.mu.RLock()
+	until, ok := t.until[userID]
+	t.mu.RUnlock()
+	return ok && time.Now().Before(until)
+}
+
+// list returns the traces active at now, by user ID.
+func (t *userTraces) list(now time.Time) []ws.UserTrace {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	traces := make([]ws.UserTrace, 0, len(t.until))
+	for userID, until := range t.until {
+		if now.Before(until) {
+			traces = append(traces, ws.UserTrace{UserID: userID, ExpiresAt: until})
+		}
+	}
+	sort.Slice(traces, func(i, j int) bool { return traces[i].UserID < traces[j].UserID })
+	return traces
+}
+
+// startTrace returns the trace of a message addressed to a traced user, nil
+// otherwise. A nil *messageTrace records nothing.
+func (uc *implUseCase) startTrace(parsed ParsedChannel, input ws.ProcessMessageInput, receivedAt time.Time) *messageTrace {
+	if !uc.traces.traced(parsed.UserID) {
+		return nil
+	}
+	return &messageTrace{
+		userID:  parsed.UserID,
+		channel: input.Channel,
+		start:   receivedAt,
+		payload: input.Payload,
+	}
+}
+
+// hop records that the message finished a step.
+func (t *messageTrace) hop(name string) {
+	if t == nil {
+		return
+	}
+	t.hops = append(t.hops, traceHop{name: name, at: time.Now()})
+}
+
+// done records the last step and how the message ended.
+func (t *messageTrace) done(outcome string) {
+	if t == nil {
+		return
+	}
+	t.hop(outcome)
+	t.outcome = outcome
+}
+
+// observe observes v, with a user_id exemplar for a traced message.
+func (t *messageTrace) observe(ctx context.Context, o prometheus.Observer, v float64) {
+	if t == nil {
+		metrics.ObserveWithTrace(ctx, o, v)
+		return
+	}
+	metrics.ObserveWithUser(ctx, o, v, t.userID)
+}
+
+// log writes the trace as one line: the hops as offsets from reception, then
+// the payload as published and the frame as routed.
+func (t *messageTrace) log(ctx context.Context, logger log.Logger) {
+	if t == nil {
+		return
+	}
+	var hops strings.Builder
+	for _, h := range t.hops {
+		fmt.Fprintf(&hops, " %s=+%s", h.name, h.at.Sub(t.start))
+	}
+	logger.Infof(ctx, "trace: user_id=%s channel=%s outcome=%s received_at=%s hops:%s payload=%s frame=%s",
+		t.userID, t.channel, t.outcome, t.start.Format(time.RFC3339Nano), hops.String(), t.payload, t.frame)
+}
+
+// traceWrite logs the frames written to a traced user's socket.
+func (c *Connection) traceWrite(frames [][]byte, start time.Time) {
+	ids := make([]string, 0, len(frames))
+	for _, frame := range frames {
+		var head struct {
+			ID string `json:"id"`
+		}
+		if json.Unmarshal(frame, &head) == nil && head.ID != "" {
+			ids = append(ids, head.ID)
+		}
+	}
+	c.hub.logger.Infof(context.Background(), "trace: written user_id=%s remote_ip=%s client_label=%s ids=%v write=%s",
+		c.userID, c.remoteIP, c.clientLabel, ids, time.Since(start))
+}
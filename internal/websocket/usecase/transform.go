@@ -2,60 +2,150 @@ package usecase
 
 import (
 	"context"
-	"encoding/json"
+	"strings"
 	"time"
 
 	"notification-srv/internal/websocket"
+
+	"github.com/google/uuid"
 )
 
 // transformMessage transforms raw payload into a proper NotificationOutput based on message type.
 func (uc *implUseCase) transformMessage(ctx context.Context, msgType websocket.MessageType, payload []byte) (websocket.NotificationOutput, error) {
-	output := websocket.NotificationOutput{
-		Type:      msgType,
-		Timestamp: time.Now(),
+	return uc.transformWith(ctx, decodePayload, msgType, payload)
+}
+
+// transformWith is transformMessage with the given JSON decoder.
+func (uc *implUseCase) transformWith(ctx context.Context, decode payloadDecoder, msgType websocket.MessageType, payload []byte) (websocket.NotificationOutput, error) {
+	data, violation, err := decodeTypedWith(decode, msgType, payload, uc.validation == ValidationStrict)
+	if err != nil {
+		return websocket.NotificationOutput{}, err
+	}
+	if err := uc.enforceValidation(ctx, msgType, violation); err != nil {
+		return websocket.NotificationOutput{}, err
 	}
+	data = uc.sanitizer.payload(msgType, data)
 
-	switch msgType {
-	case websocket.MessageTypeDataOnboarding:
-		var data websocket.DataOnboardingPayload
-		if err := json.Unmarshal(payload, &data); err != nil {
-			return websocket.NotificationOutput{}, websocket.ErrInvalidMessage
+	groupKey, collapseKey := notificationKeys(data)
+	return websocket.NotificationOutput{
+		ID:          uuid.NewString(),
+		Type:        msgType,
+		Timestamp:   time.Now(),
+		Payload:     data,
+		GroupKey:    groupKey,
+		CollapseKey: collapseKey,
+		Importance:  importanceOf(msgType, data),
+	}, nil
+}
+
+// importanceOf ranks a notification by its type and status: progress ticks
+// are low, finished work is normal, failures and warnings are high, and only
+// critical crisis and security alerts are critical.
+func importanceOf(msgType websocket.MessageType, data any) websocket.Importance {
+	switch p := data.(type) {
+	case websocket.CrisisAlertPayload:
+		switch strings.ToLower(p.Severity) {
+		case "critical":
+			return websocket.ImportanceCritical
+		case "warning":
+			return websocket.ImportanceHigh
 		}
-		// Validate/Transform logic if needed (e.g. strict status check)
-		output.Payload = data
+		return websocket.ImportanceNormal
 
-	case websocket.MessageTypeAnalyticsPipeline:
-		var data websocket.AnalyticsPipelinePayload
-		if err := json.Unmarshal(payload, &data); err != nil {
-			return websocket.NotificationOutput{}, websocket.ErrInvalidMessage
+	case websocket.DataOnboardingPayload:
+		switch strings.ToLower(p.Status) {
+		case "failed":
+			return websocket.ImportanceHigh
+		case "completed":
+			return websocket.ImportanceNormal
 		}
-		output.Payload = data
+		return websocket.ImportanceLow
 
-	case websocket.MessageTypeCrisisAlert:
-		var data websocket.CrisisAlertPayload
-		if err := json.Unmarshal(payload, &data); err != nil {
-			return websocket.NotificationOutput{}, websocket.ErrInvalidMessage
+	case websocket.AnalyticsPipelinePayload:
+		if p.Progress >= 100 {
+			return websocket.ImportanceNormal
 		}
-		output.Payload = data
+		return websocket.ImportanceLow
 
-	case websocket.MessageTypeCampaignEvent:
-		var data websocket.CampaignEventPayload
-		if err := json.Unmarshal(payload, &data); err != nil {
-			return websocket.NotificationOutput{}, websocket.ErrInvalidMessage
+	case websocket.CampaignEventPayload:
+		switch strings.ToLower(p.EventType) {
+		case "started", "finished":
+			return websocket.ImportanceNormal
 		}
-		output.Payload = data
+		return websocket.ImportanceLow
 
-	case websocket.MessageTypeSystem:
-		// System messages might be plain strings or generic maps
-		var data interface{}
-		if err := json.Unmarshal(payload, &data); err != nil {
-			return websocket.NotificationOutput{}, websocket.ErrInvalidMessage
+	case websocket.SecurityAlertPayload:
+		switch strings.ToLower(p.Severity) {
+		case "critical":
+			return websocket.ImportanceCritical
+		case "warning":
+			return websocket.ImportanceHigh
 		}
-		output.Payload = data
+		return websocket.ImportanceNormal
 
-	default:
-		return websocket.NotificationOutput{}, websocket.ErrUnknownMessageType
+	case websocket.BillingEventPayload:
+		if strings.EqualFold(p.BillingEvent, "payment_failed") {
+			return websocket.ImportanceHigh
+		}
+		return websocket.ImportanceNormal
+
+	case websocket.SystemMaintenancePayload:
+		if strings.EqualFold(p.Status, "started") {
+			return websocket.ImportanceHigh
+		}
+		return websocket.ImportanceNormal
+	}
+
+	if msgType == websocket.MessageTypeSystem {
+		return websocket.ImportanceHigh
 	}
+	return websocket.ImportanceNormal
+}
+
+// notificationKeys returns the group and collapse keys of a notification.
+// Progress updates collapse per data source, so only the latest one is shown;
+// alerts, campaign events, invoices and maintenance windows are grouped but
+// never collapsed, so the coalescer never holds priority frames.
+func notificationKeys(data any) (groupKey, collapseKey string) {
+	switch p := data.(type) {
+	case websocket.DataOnboardingPayload:
+		if p.ProjectID == "" {
+			return "", ""
+		}
+		groupKey = "project:" + p.ProjectID
+		if p.SourceID != "" {
+			collapseKey = groupKey + ":onboarding:" + p.SourceID
+		}
+
+	case websocket.AnalyticsPipelinePayload:
+		if p.ProjectID == "" {
+			return "", ""
+		}
+		groupKey = "project:" + p.ProjectID
+		collapseKey = groupKey + ":pipeline"
+		if p.SourceID != "" {
+			collapseKey += ":" + p.SourceID
+		}
+
+	case websocket.CrisisAlertPayload:
+		if p.ProjectID != "" {
+			groupKey = "project:" + p.ProjectID
+		}
 
-	return output, nil
+	case websocket.CampaignEventPayload:
+		if p.CampaignID != "" {
+			groupKey = "campaign:" + p.CampaignID
+		}
+
+	case websocket.BillingEventPayload:
+		if p.InvoiceID != "" {
+			groupKey = "invoice:" + p.InvoiceID
+		}
+
+	case websocket.SystemMaintenancePayload:
+		if p.MaintenanceID != "" {
+			groupKey = "maintenance:" + p.MaintenanceID
+		}
+	}
+	return groupKey, collapseKey
 }
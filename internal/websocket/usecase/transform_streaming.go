@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+
+	ws "notification-srv/internal/websocket"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// streamingTransformVersion is the candidate transformer decoding payloads in
+// a single streaming pass. encoding/json validates a payload in a full scan
+// before decoding it, and in strict mode decodes it a second time through a
+// json.Decoder, which first copies the payload into its own buffer. For
+// multi-MB payloads that is several times the payload size per message.
+const streamingTransformVersion = "streaming-v1"
+
+var (
+	streamingLenient = jsoniter.ConfigCompatibleWithStandardLibrary
+	streamingStrict  = jsoniter.Config{
+		EscapeHTML:             true,
+		SortMapKeys:            true,
+		ValidateJsonRawMessage: true,
+		DisallowUnknownFields:  true,
+	}.Froze()
+)
+
+// transformStreaming is transformMessage decoding with decodePayloadStreaming.
+func transformStreaming(uc *implUseCase, ctx context.Context, msgType ws.MessageType, payload []byte) (ws.NotificationOutput, error) {
+	return uc.transformWith(ctx, decodePayloadStreaming, msgType, payload)
+}
+
+// decodePayloadStreaming is decodePayload with a streaming decoder that reads
+// payload in place. Unknown fields are rejected in the same pass; only a
+// payload failing it is decoded again, to tell an unknown field from invalid
+// JSON.
+func decodePayloadStreaming(payload []byte, v any, strict bool) (violation error, err error) {
+	if !strict {
+		if err := streamingLenient.Unmarshal(payload, v); err != nil {
+			return nil, ws.ErrInvalidMessage
+		}
+		return nil, nil
+	}
+
+	violation = streamingStrict.Unmarshal(payload, v)
+	if violation == nil {
+		return nil, nil
+	}
+	rv := reflect.ValueOf(v).Elem()
+	rv.Set(reflect.Zero(rv.Type()))
+	if err := streamingLenient.Unmarshal(payload, v); err != nil {
+		return nil, ws.ErrInvalidMessage
+	}
+	return violation, nil
+}
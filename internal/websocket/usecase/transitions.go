@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"container/list"
+	"context"
+	"strings"
+
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+)
+
+// DefaultTransitions are the allowed transitions of the job statuses. A
+// failed or completed onboarding may only start over from pending.
+var DefaultTransitions = map[ws.MessageType]map[string][]string{
+	ws.MessageTypeDataOnboarding: {
+		"pending":    {"processing", "completed", "failed"},
+		"processing": {"completed", "failed"},
+		"completed":  {"pending"},
+		"failed":     {"pending"},
+	},
+	ws.MessageTypeCampaignEvent: {
+		"created":  {"started", "finished"},
+		"started":  {"paused", "finished"},
+		"paused":   {"started", "finished"},
+		"finished": {},
+	},
+}
+
+// newTransitionValidator returns nil when validation is off. A nil
+// *transitionValidator allows every transition.
+func newTransitionValidator(cfg TransitionConfig) *transitionValidator {
+	if cfg.Mode == "" || cfg.Mode == TransitionOff {
+		return nil
+	}
+
+	// Configured statuses replace the default next statuses of that status
+	// only; the rest of the type's graph stays
+	allowed := make(map[ws.MessageType]map[string]map[string]bool)
+	for _, graphs := range []map[ws.MessageType]map[string][]string{DefaultTransitions, cfg.Allowed} {
+		for msgType, graph := range graphs {
+			from := allowed[msgType]
+			if from == nil {
+				from = make(map[string]map[string]bool, len(graph))
+				allowed[msgType] = from
+			}
+			for status, next := range graph {
+				to := make(map[string]bool, len(next))
+				for _, n := range next {
+					to[strings.ToLower(n)] = true
+				}
+				from[strings.ToLower(status)] = to
+			}
+		}
+	}
+
+	return &transitionValidator{
+		mode:      cfg.Mode,
+		maxTopics: cfg.MaxTopics,
+		allowed:   allowed,
+		topics:    make(map[string]*list.Element),
+		lru:       list.New(),
+	}
+}
+
+// jobStatusOf returns the job key suffix and status of a payload, or ok false
+// for payloads without a tracked status.
+func jobStatusOf(output ws.NotificationOutput) (job, status string, ok bool) {
+	switch p := output.Payload.(type) {
+	case ws.DataOnboardingPayload:
+		return p.SourceID, p.Status, true
+	case ws.CampaignEventPayload:
+		return p.CampaignID, p.EventType, true
+	}
+	return "", "", false
+}
+
+// check reports the job's previous status and whether status may follow it.
+// The new status is remembered unless the transition is suppressed.
+func (v *transitionValidator) check(key string, msgType ws.MessageType, status string) (from string, valid bool) {
+	if v == nil {
+		return "", true
+	}
+	status = strings.ToLower(status)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	js := v.touch(key)
+	from = js.status
+	valid = v.valid(msgType, from, status)
+	if valid || v.mode != TransitionSuppress {
+		js.status = status
+	}
+	return from, valid
+}
+
+// valid reports whether a job of msgType may move from one status to another.
+func (v *transitionValidator) valid(msgType ws.MessageType, from, to string) bool {
+	if from == "" || from == to {
+		return true
+	}
+	graph := v.allowed[msgType]
+	next, ok := graph[from]
+	if !ok {
+		return true
+	}
+	if _, known := graph[to]; !known {
+		return true
+	}
+	return next[to]
+}
+
+// purge forgets every job whose key starts with prefix and returns how many
+// were forgotten.
+func (v *transitionValidator) purge(prefix string) int {
+	if v == nil {
+		return 0
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	purged := 0
+	for key, el := range v.topics {
+		if strings.HasPrefix(key, prefix) {
+			v.lru.Remove(el)
+			delete(v.topics, key)
+			purged++
+		}
+	}
+	return purged
+}
+
+// touch returns the status of key, creating it and evicting the least recently
+// updated job if needed. Must be called with mu held.
+func (v *transitionValidator) touch(key string) *jobStatus {
+	if el, ok := v.topics[key]; ok {
+		v.lru.MoveToFront(el)
+		return el.Value.(*jobStatus)
+	}
+
+	js := &jobStatus{key: key}
+	v.topics[key] = v.lru.PushFront(js)
+
+	if v.maxTopics > 0 && v.lru.Len() > v.maxTopics {
+		oldest := v.lru.Back()
+		v.lru.Remove(oldest)
+		delete(v.topics, oldest.Value.(*jobStatus).key)
+	}
+	return js
+}
+
+// checkTransition validates the status transition of a job update and reports
+// whether the message should still be delivered.
+func (uc *implUseCase) checkTransition(ctx context.Context, parsed ParsedChannel, output ws.NotificationOutput) bool {
+	if uc.transitions == nil || !sequenced(parsed) {
+		return true
+	}
+	job, status, ok := jobStatusOf(output)
+	if !ok {
+		return true
+	}
+
+	key := topicKey(topicName(parsed.ChannelType, parsed.EntityID), parsed.UserID) + ":" + job
+	from, valid := uc.transitions.check(key, output.Type, status)
+	if valid {
+		return true
+	}
+
+	action := "flagged"
+	if uc.transitions.mode == TransitionSuppress {
+		action = "suppressed"
+	}
+	metrics.InvalidTransitions.WithLabelValues(string(output.Type), from, strings.ToLower(status), action).Inc()
+	uc.logger.Warnf(ctx, "invalid status transition (%s): type=%s job=%s %s -> %s", action, output.Type, key, from, status)
+	return uc.transitions.mode != TransitionSuppress
+}
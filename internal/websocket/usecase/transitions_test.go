@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	ws "notification-srv/internal/websocket"
+	"notification-srv/pkg/notificationtest"
+)
+
+func newTransitionUseCase(cfg TransitionConfig) *implUseCase {
+	return &implUseCase{
+		logger:      notificationtest.Logger{},
+		transitions: newTransitionValidator(cfg),
+	}
+}
+
+var onboardingChannel = ParsedChannel{ChannelType: ws.ChannelTypeProject, EntityID: "p1", UserID: "u1"}
+
+func onboarding(sourceID, status string) ws.NotificationOutput {
+	return ws.NotificationOutput{
+		Type:    ws.MessageTypeDataOnboarding,
+		Payload: ws.DataOnboardingPayload{SourceID: sourceID, Status: status},
+	}
+}
+
+// onboardingKey is the job key checkTransition uses for a source.
+func onboardingKey(sourceID string) string {
+	return topicKey(topicName(onboardingChannel.ChannelType, onboardingChannel.EntityID), onboardingChannel.UserID) + ":" + sourceID
+}
+
+// deliveries runs the statuses of one source through checkTransition and
+// returns whether each was delivered.
+func deliveries(uc *implUseCase, statuses ...string) []bool {
+	delivered := make([]bool, len(statuses))
+	for i, status := range statuses {
+		delivered[i] = uc.checkTransition(context.Background(), onboardingChannel, onboarding("s1", status))
+	}
+	return delivered
+}
+
+func TestTransitionFlagDeliversAndTracks(t *testing.T) {
+	uc := newTransitionUseCase(TransitionConfig{Mode: TransitionFlag, MaxTopics: 10})
+
+	// processing -> pending is invalid but delivered, and becomes the last
+	// status: pending -> processing is then valid again
+	got := deliveries(uc, "pending", "processing", "pending", "processing")
+	for i, delivered := range got {
+		if !delivered {
+			t.Errorf("status %d not delivered in flag mode", i)
+		}
+	}
+	if from, valid := uc.transitions.check(onboardingKey("s1"), ws.MessageTypeDataOnboarding, "completed"); from != "processing" || !valid {
+		t.Errorf("check() = %q, %v; want processing, true", from, valid)
+	}
+}
+
+func TestTransitionSuppressDropsAndKeepsLastStatus(t *testing.T) {
+	uc := newTransitionUseCase(TransitionConfig{Mode: TransitionSuppress, MaxTopics: 10})
+
+	// completed -> processing is dropped and not remembered, so completed ->
+	// pending still follows completed
+	got := deliveries(uc, "pending", "completed", "processing", "pending")
+	want := []bool{true, true, false, true}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("status %d delivered = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestTransitionUnknownStatusesAndRepeats(t *testing.T) {
+	uc := newTransitionUseCase(TransitionConfig{Mode: TransitionSuppress, MaxTopics: 10})
+
+	got := deliveries(uc, "processing", "processing", "archived", "PENDING")
+	for i, delivered := range got {
+		if !delivered {
+			t.Errorf("status %d suppressed: repeats and statuses outside the graph are allowed", i)
+		}
+	}
+}
+
+func TestTransitionAllowedMergesPerStatus(t *testing.T) {
+	v := newTransitionValidator(TransitionConfig{
+		Mode:      TransitionFlag,
+		MaxTopics: 10,
+		Allowed: map[ws.MessageType]map[string][]string{
+			ws.MessageTypeDataOnboarding: {"failed": {"pending", "processing"}, "Retrying": {"processing"}},
+		},
+	})
+
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{"failed", "processing", true},     // Configured
+		{"completed", "processing", false}, // Built in, kept
+		{"pending", "processing", true},    // Built in, kept
+		{"retrying", "processing", true},   // New status
+		{"retrying", "completed", false},   // New status, only what it lists
+		{"processing", "retrying", false},  // Now a known status
+	}
+	for _, tt := range tests {
+		if got := v.valid(ws.MessageTypeDataOnboarding, tt.from, tt.to); got != tt.want {
+			t.Errorf("valid(%s -> %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	// The other type keeps its graph
+	if v.valid(ws.MessageTypeCampaignEvent, "finished", "started") {
+		t.Error("campaign finished -> started allowed, want the built-in graph")
+	}
+	// And another validator still starts from the defaults
+	if newTransitionValidator(TransitionConfig{Mode: TransitionFlag}).valid(ws.MessageTypeDataOnboarding, "failed", "processing") {
+		t.Error("failed -> processing allowed by default")
+	}
+}
+
+func TestTransitionEvictsLeastRecentlyUpdated(t *testing.T) {
+	v := newTransitionValidator(TransitionConfig{Mode: TransitionSuppress, MaxTopics: 2})
+
+	v.check("a", ws.MessageTypeDataOnboarding, "completed")
+	v.check("b", ws.MessageTypeDataOnboarding, "completed")
+	v.check("a", ws.MessageTypeDataOnboarding, "pending") // a is now the most recent
+	v.check("c", ws.MessageTypeDataOnboarding, "completed")
+
+	if v.lru.Len() != 2 || len(v.topics) != 2 {
+		t.Fatalf("%d jobs in the LRU, %d indexed; want 2", v.lru.Len(), len(v.topics))
+	}
+	// b was forgotten, a kept
+	if from, _ := v.check("a", ws.MessageTypeDataOnboarding, "pending"); from != "pending" {
+		t.Errorf("check(a) from = %q, want pending", from)
+	}
+	if from, valid := v.check("b", ws.MessageTypeDataOnboarding, "processing"); from != "" || !valid {
+		t.Errorf("check(b) = %q, %v; want a forgotten job", from, valid)
+	}
+	// Adding b back evicted c, now the least recently updated
+	if from, _ := v.check("c", ws.MessageTypeDataOnboarding, "pending"); from != "" {
+		t.Errorf("check(c) from = %q, want c forgotten", from)
+	}
+}
+
+func TestTransitionOff(t *testing.T) {
+	for _, mode := range []TransitionMode{"", TransitionOff} {
+		uc := newTransitionUseCase(TransitionConfig{Mode: mode})
+		if uc.transitions != nil {
+			t.Fatalf("mode %q: validator created", mode)
+		}
+		for i, delivered := range deliveries(uc, "completed", "processing") {
+			if !delivered {
+				t.Errorf("mode %q: status %d suppressed", mode, i)
+			}
+		}
+	}
+}
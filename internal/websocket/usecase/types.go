@@ -1,7 +1,18 @@
 package usecase
 
 import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"notification-srv/internal/sink"
 	"notification-srv/internal/websocket"
+	"notification-srv/internal/websocket/repository"
+
+	"github.com/smap-hcmut/shared-libs/go/log"
 )
 
 // ParsedChannel represents the components extracted from a Redis channel string.
@@ -11,3 +22,700 @@ type ParsedChannel struct {
 	UserID      string // Target user (empty for broadcast channels like system:*)
 	SubType     string // For alert channels: "crisis", "warning"
 }
+
+// Config holds the tunables of the WebSocket UseCase.
+type Config struct {
+	MaxConnections int
+	Heartbeat      bool // Send a heartbeat frame (server time + RTT) on every ping
+
+	// Consecutive malformed client frames tolerated before the connection is closed (0 = never)
+	MaxProtocolViolations int
+
+	// How payload validation failures are handled (strict, lenient, log-only)
+	Validation ValidationMode
+
+	// Message types dropped on arrival (nil = none)
+	DisabledTypes map[websocket.MessageType]bool
+
+	// Job status transition validation (off, flag, suppress)
+	Transitions TransitionConfig
+
+	// Suppression of repeated terminal job updates
+	TerminalDedupe TerminalDedupeConfig
+
+	// Cleaning of free-text payload fields before delivery
+	Sanitize SanitizeConfig
+
+	// Candidate transformer to shadow-run against the current one
+	Shadow ShadowConfig
+
+	// Project state snapshot pushed to project-filtered connections on connect
+	Backfill BackfillConfig
+
+	// Latest frame of each project topic kept in Redis after delivery
+	LatestState LatestStateConfig
+
+	// Per message type delivery guarantees (acks, retries, offline queue)
+	Delivery DeliveryConfig
+
+	// Limits of targeted tracing of single users
+	UserTrace UserTraceConfig
+
+	// Synthetic user whose messages are probed end to end
+	Canary CanaryConfig
+
+	// Hello frame and the features the protocol document advertises
+	Protocol ProtocolConfig
+
+	// Per-topic sequence numbers and replay buffer
+	Sequence SequenceConfig
+
+	// Outbound sinks that receive a copy of every delivered notification
+	Sinks []sink.Sink
+
+	// Presence key kept alive in Redis while the user has a connection open
+	Presence PresenceConfig
+
+	// Rolling window of the per-channel statistics
+	StatsWindow time.Duration
+
+	// Periodic samples of the Hub stats kept in memory
+	StatsHistory StatsHistoryConfig
+
+	// Delay between a project finishing and its topic cleanup (0 = never clean up)
+	TopicGCGrace time.Duration
+
+	// Frames per collapse key between full snapshots for delta-encoding connections (1 = never send deltas)
+	DeltaSnapshotEvery int
+
+	// Coalescing of collapse_key frames, adapted per connection
+	Coalesce CoalesceConfig
+
+	// Resolves broadcast segments other than "all" and "users" (nil = only those two)
+	Segments websocket.SegmentResolver
+
+	// Decides who may subscribe as another user (nil = no one)
+	Delegation websocket.DelegationAuthorizer
+
+	// Detection of users and IPs churning connections
+	Churn ChurnConfig
+
+	// Never-issued project IDs whose subscribers are reported
+	Decoy DecoyConfig
+
+	// Projects anonymous viewers may follow without a token
+	Public PublicConfig
+
+	// Signals asking publishers of channels whose frames are dropped to slow down
+	Backpressure BackpressureConfig
+
+	// Per-user connection quota
+	Quota QuotaConfig
+
+	// Client labels used as metric labels as they are; others are counted as "other"
+	ClientLabels []string
+
+	// Outbound frame queue of each connection
+	SendQueue SendQueueConfig
+}
+
+// SendQueuePolicy is what a full send queue does with a new frame.
+type SendQueuePolicy string
+
+const (
+	SendQueueDropNewest SendQueuePolicy = "drop_newest" // Refuse the new frame (counted as dropped)
+	SendQueueDropOldest SendQueuePolicy = "drop_oldest" // Evict the oldest queued frame for it
+)
+
+// SendQueueConfig sizes the outbound queue of each connection. ClassSizes
+// overrides Size per client label (lower-cased), e.g. smaller for mobile builds.
+type SendQueueConfig struct {
+	Size       int
+	Policy     SendQueuePolicy
+	ClassSizes map[string]int
+}
+
+// StatsHistoryConfig sizes the stats history: a sample every Interval, kept
+// for Retention. A zero Interval disables sampling.
+type StatsHistoryConfig struct {
+	Interval  time.Duration
+	Retention time.Duration
+}
+
+// PresenceConfig controls the presence key. TTL must exceed the ping period,
+// since the key is refreshed on every pong.
+type PresenceConfig struct {
+	Enabled bool
+	TTL     time.Duration
+}
+
+// CoalesceConfig controls frame coalescing. Each connection starts at
+// MinInterval and doubles towards MaxInterval while it is slow.
+type CoalesceConfig struct {
+	Enabled     bool
+	MinInterval time.Duration
+	MaxInterval time.Duration
+}
+
+// ChurnConfig controls connection churn detection. Counts are kept per fixed
+// Window; a user or IP is reported once per window when it crosses a threshold.
+type ChurnConfig struct {
+	Enabled         bool
+	Window          time.Duration
+	MaxUserConnects int           // Connects of one user
+	MaxIPUsers      int           // Distinct users connecting from one IP
+	BanTTL          time.Duration // Ban the offender for this long (0 = alert only)
+}
+
+// DecoyConfig lists decoy project IDs: never issued, so subscribing to one
+// means the caller is enumerating projects.
+type DecoyConfig struct {
+	Patterns []string      // path.Match patterns of decoy project IDs, e.g. "decoy-*"
+	BanTTL   time.Duration // Ban the subscriber for this long (0 = alert only)
+	BanIP    bool          // Ban the subscriber's IP as well as the user
+}
+
+// PublicConfig lists the projects anonymous viewers may follow, e.g. for a
+// status page. Project messages are published per user, so each project is
+// fed by the channel of one owner.
+type PublicConfig struct {
+	Projects       map[string]string // Project ID to the user whose project channel feeds the viewers
+	MaxConnections int               // Anonymous connections on this replica (0 = unlimited)
+	MaxPerIP       int               // Anonymous connections per IP (0 = unlimited)
+	ConnectRate    float64           // Anonymous upgrades per second and IP
+	ConnectBurst   int
+}
+
+// BackpressureConfig controls the backpressure signals sent to publishers.
+// Frames are counted per inbound channel over fixed Windows; a channel whose
+// frames were dropped for full send queues at MinDropRate or more is signalled
+// once per window.
+type BackpressureConfig struct {
+	Window      time.Duration // 0 = off
+	MinDropRate float64       // Share of the channel's frames dropped (0-1)
+	MinFrames   int           // Frames routed in the window before a channel may be signalled
+}
+
+// QuotaConfig limits the connections a user may hold on this replica.
+// Provider is asked first; DefaultPerUser applies when it has no quota for the
+// user or fails.
+type QuotaConfig struct {
+	Provider       websocket.QuotaProvider // nil = DefaultPerUser for everyone
+	DefaultPerUser int                     // 0 = unlimited
+	Policy         QuotaPolicy             // What a connection beyond the quota does (default reject)
+}
+
+// eventBus fans Hub events out to in-process subscribers. Publishing never
+// blocks: each subscriber has a queue drained by its own goroutine.
+type eventBus struct {
+	logger log.Logger
+
+	mu     sync.RWMutex
+	subs   map[int]*eventSubscriber
+	nextID int
+	count  atomic.Int32 // Number of subscribers, so publishers can skip building events
+}
+
+// eventSubscriber is one SubscribeHubEvents registration.
+type eventSubscriber struct {
+	types   map[websocket.HubEventType]bool // nil receives every type
+	queue   chan websocket.HubEvent
+	handler websocket.HubEventHandler
+	logger  log.Logger
+}
+
+// presence writes and clears users' presence keys off the caller's goroutine.
+// A nil *presence is valid and does nothing.
+type presence struct {
+	repo   repository.PresenceRepository
+	logger log.Logger
+	ttl    time.Duration
+	hub    *Hub // Source of the user's focused projects
+}
+
+// latestState writes the last frame of each project topic to Redis. Frames
+// waiting for the writer are compacted per topic, so a slow Redis costs
+// intermediate states, never the order of a topic's writes.
+type latestState struct {
+	repo    repository.LatestStateRepository
+	logger  log.Logger
+	ttl     time.Duration
+	mu      sync.Mutex
+	pending map[latestTopic][]byte
+	dropped []string // Projects whose states to delete after the pending writes
+	wake    chan struct{}
+}
+
+// ackTracker holds the at-least-once frames sent to each user and not yet
+// acked. An ack from any of the user's connections settles a frame.
+type ackTracker struct {
+	cfg      DeliveryConfig
+	policies map[websocket.MessageType]websocket.DeliveryPolicy
+	mu       sync.Mutex
+	pending  map[string]map[string]*pendingAck // User ID -> message ID -> frame
+}
+
+// pendingAck is an at-least-once frame waiting for its ack.
+type pendingAck struct {
+	projectID  string // Project of a project channel frame; empty for user-wide frames
+	msgType    websocket.MessageType
+	importance websocket.Importance
+	frame      []byte
+	deadline   time.Time
+	retries    int
+}
+
+// dueAck is a frame whose ack deadline passed.
+type dueAck struct {
+	userID string
+	id     string
+	*pendingAck
+}
+
+// offlineHead is the part of a queued offline frame needed to track it again.
+type offlineHead struct {
+	ID         string                `json:"id"`
+	Type       websocket.MessageType `json:"type"`
+	Importance websocket.Importance  `json:"importance"`
+	Topic      string                `json:"topic"`
+}
+
+// heldHead is the part of a frame the coalescer reads.
+type heldHead struct {
+	CollapseKey string `json:"collapse_key"`
+	Topic       string `json:"topic"`
+}
+
+// latestTopic is the project topic of one user.
+type latestTopic struct {
+	projectID string
+	userID    string
+}
+
+// channelStats keeps per channel pattern counters in time buckets covering
+// the stats window. Patterns are few (IDs are replaced by *), so the map stays small.
+type channelStats struct {
+	window   time.Duration
+	interval time.Duration // Width of one bucket
+
+	mu       sync.Mutex
+	channels map[string][]statsBucket // Ring of buckets, indexed by bucket number modulo its length
+	total    channelCounts            // Counts of all channels since start
+}
+
+// statsHistory is a ring of Hub stats samples. Once full, each sample
+// overwrites the oldest, so memory is bounded by Retention / Interval samples.
+type statsHistory struct {
+	interval time.Duration
+
+	mu      sync.Mutex
+	samples []websocket.StatsSample
+	next    int           // Index the next sample is written to
+	full    bool          // Every slot holds a sample
+	last    channelCounts // Totals at the previous sample
+}
+
+// statsBucket holds the counters of one bucket interval.
+type statsBucket struct {
+	slot   int64 // Bucket number (unix time / interval); stale when outside the window
+	counts channelCounts
+}
+
+// channelCounts are the counters recorded for one message or summed over a window.
+type channelCounts struct {
+	received        uint64
+	transformErrors uint64
+	delivered       uint64
+	dropped         uint64
+	noRecipients    uint64
+}
+
+// churnDetector counts connects per user and distinct users per IP in the
+// current window. All counts are dropped when a new window starts, so memory
+// is bounded by one window of traffic.
+type churnDetector struct {
+	cfg ChurnConfig
+
+	mu      sync.Mutex
+	start   time.Time                  // Start of the current window
+	users   map[string]int             // user_id -> connects
+	ips     map[string]map[string]bool // ip -> user_ids
+	flagged map[string]bool            // kind:subject already reported in this window
+}
+
+// backpressureDetector counts the frames of each inbound channel in the
+// current window. Counts are dropped when a new window starts, so memory is
+// bounded by one window of traffic.
+type backpressureDetector struct {
+	cfg BackpressureConfig
+
+	mu       sync.Mutex
+	start    time.Time               // Start of the current window
+	channels map[string]*channelLoad // Inbound channel -> counts
+}
+
+// channelLoad is what one inbound channel sent in the current window.
+type channelLoad struct {
+	pattern  string // channelPattern of the channel, the metric label
+	messages int
+	frames   int // Frames routed to connections, dropped ones included
+	dropped  int // Frames not queued because the send queue was full
+}
+
+// backpressureEvent is a channel reported by backpressureDetector.
+type backpressureEvent struct {
+	pattern string
+	signal  websocket.BackpressureSignal
+}
+
+// churnEvent is a threshold crossing reported by churnDetector.
+type churnEvent struct {
+	kind      string // websocket.BanKindUser or websocket.BanKindIP
+	subject   string // User ID or IP
+	count     int
+	threshold int
+}
+
+// topicGC holds the pending cleanups of finished projects. A new message on
+// the project's topic during the grace period cancels its cleanup.
+type topicGC struct {
+	grace time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*time.Timer // project_id -> cleanup timer
+}
+
+// userTraces are the users under targeted tracing on this replica, with the
+// time their trace expires.
+type userTraces struct {
+	mu       sync.RWMutex
+	until    map[string]time.Time
+	maxTTL   time.Duration
+	maxUsers int
+}
+
+// messageTrace records the hops of one message of a traced user.
+type messageTrace struct {
+	userID  string
+	channel string
+	start   time.Time
+	hops    []traceHop
+	outcome string
+	payload []byte
+	frame   []byte
+}
+
+// traceHop is a step of a traced message and when it ended.
+type traceHop struct {
+	name string
+	at   time.Time
+}
+
+// canary probes the delivery path as a reserved user. Its state is only
+// touched by runCanary.
+type canary struct {
+	cfg     CanaryConfig
+	source  string // source_id of this replica's probes
+	started atomic.Bool
+
+	next          int               // Sequence number of the next probe
+	pending       map[int]time.Time // Probes in flight by sequence number, with their send time
+	failures      int               // Failed probes in a row
+	alerted       bool              // The current run of failures was reported
+	lastSuccessAt time.Time
+}
+
+// canaryHead is the part of a probe frame the canary reads.
+type canaryHead struct {
+	Payload struct {
+		SourceID       string `json:"source_id"`
+		ProcessedCount int    `json:"processed_count"` // Probe sequence number
+	} `json:"payload"`
+}
+
+// maintenanceState is the maintenance mode of this replica.
+type maintenanceState struct {
+	mu      sync.RWMutex
+	enabled bool
+	reason  string
+	since   time.Time
+}
+
+// announcementState is the service announcement active on this replica.
+type announcementState struct {
+	mu      sync.RWMutex
+	current *websocket.ServiceAnnouncementPayload // nil if none
+	frame   []byte                                // current, as sent to new connections
+}
+
+// announcementControl is the payload of control:announcement. An empty title
+// and body withdraw the active announcement.
+type announcementControl struct {
+	ID        string    `json:"id"` // Optional; derived from the payload if empty
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	Severity  string    `json:"severity"`   // Defaults to info
+	ExpiresAt time.Time `json:"expires_at"` // Optional
+}
+
+// maintenanceControl is the payload of control:maintenance:{on|off}.
+type maintenanceControl struct {
+	Reason string `json:"reason"`
+}
+
+// broadcastControl is the payload of control:broadcast:{broadcast_id}. The
+// frame is built once so every replica sends the same ID.
+type broadcastControl struct {
+	All        bool            `json:"all,omitempty"`
+	UserIDs    []string        `json:"user_ids,omitempty"`
+	ProjectIDs []string        `json:"project_ids,omitempty"`
+	Frame      json.RawMessage `json:"frame"`
+}
+
+// banControl is the payload of control:ban:{user|ip}.
+type banControl struct {
+	Value string `json:"value"` // User ID or normalized CIDR range
+}
+
+// traceControl is the payload of control:trace:{user_id}.
+type traceControl struct {
+	ExpiresAt time.Time `json:"expires_at"` // Past or zero: stop tracing the user
+}
+
+// delivery is the Hub outcome of routing one message, reported to sinks.
+type delivery struct {
+	receivedAt time.Time
+	recipients int // -1 for broadcasts
+	dropped    int
+}
+
+// SequenceConfig bounds the per-topic replay buffer used for gap recovery.
+type SequenceConfig struct {
+	BufferSize int // Frames kept per topic
+	MaxTopics  int // Topics kept; the least recently used is evicted beyond this
+}
+
+// sequencer assigns per-topic sequence numbers and keeps the last frames of
+// each topic so clients can recover gaps. Topics are kept in LRU order.
+type sequencer struct {
+	epoch      string
+	bufferSize int
+	maxTopics  int
+
+	mu     sync.Mutex
+	topics map[string]*list.Element // Value is *topicLog
+	lru    *list.List               // Front = most recently used
+}
+
+// topicLog is the sequence state of one topic.
+type topicLog struct {
+	topic  string
+	seq    uint64
+	frames []seqFrame // Ring buffer of at most bufferSize frames, oldest first after rotation
+	start  int        // Index of the oldest frame in frames
+}
+
+// transitionValidator remembers the last status of each job. Jobs are kept in
+// LRU order.
+type transitionValidator struct {
+	mode      TransitionMode
+	maxTopics int
+	allowed   map[websocket.MessageType]map[string]map[string]bool
+
+	mu     sync.Mutex
+	topics map[string]*list.Element // Value is *jobStatus
+	lru    *list.List               // Front = most recently updated
+}
+
+// jobStatus is the last status of one job.
+type jobStatus struct {
+	key    string
+	status string
+}
+
+// terminalDedupe remembers the last terminal update of each job. Jobs are
+// kept in LRU order.
+type terminalDedupe struct {
+	window    time.Duration
+	maxTopics int
+
+	mu     sync.Mutex
+	topics map[string]*list.Element // Value is *terminalSeen
+	lru    *list.List               // Front = most recently updated
+}
+
+// terminalSeen is the last terminal update delivered for one job.
+type terminalSeen struct {
+	key    string
+	status string
+	at     time.Time
+}
+
+// sanitizer cleans free-text payload fields with a rule per JSON field name.
+type sanitizer struct {
+	rules map[string]SanitizeRule
+}
+
+// seqFrame is a sent frame and its sequence number.
+type seqFrame struct {
+	seq  uint64
+	data []byte
+}
+
+// BackfillConfig controls the project state snapshot sent on connect.
+type BackfillConfig struct {
+	Enabled    bool
+	UserScoped bool          // The state key carries {user_id}, so every lookup reads the user's own state
+	Timeout    time.Duration // Upper bound on the state lookup; the connection proceeds without a snapshot on timeout
+}
+
+// LatestStateConfig controls the latest state key written per project topic.
+type LatestStateConfig struct {
+	Enabled bool
+	TTL     time.Duration // Expiry of a topic's key, refreshed by every frame
+}
+
+// DeliveryConfig controls at-least-once delivery. Policies replaces entries
+// of DefaultDeliveryPolicies.
+type DeliveryConfig struct {
+	Enabled          bool
+	Policies         map[websocket.MessageType]websocket.DeliveryPolicy
+	AckTimeout       time.Duration // Resend a frame not acked within this long
+	MaxRetries       int           // Resends before an unacked frame is given up
+	OfflineMaxFrames int           // Newest frames kept in a user's offline queue
+	OfflineTTL       time.Duration // Expiry of an offline queue after its last frame
+}
+
+// ShadowConfig selects a registered candidate transformer and the share of
+// messages (0-100) it is shadow-run on. Its output is compared, never delivered.
+type ShadowConfig struct {
+	Version string
+	Percent int
+}
+
+// ValidationMode controls how the transform layer treats invalid payloads.
+type ValidationMode string
+
+const (
+	// ValidationStrict rejects payloads with unknown fields or rule violations.
+	ValidationStrict ValidationMode = "strict"
+	// ValidationLenient ignores unknown fields but rejects rule violations.
+	ValidationLenient ValidationMode = "lenient"
+	// ValidationLogOnly records violations (logs + metrics) but still delivers the message.
+	ValidationLogOnly ValidationMode = "log-only"
+)
+
+// TransitionMode controls what happens to a status update its topic's previous
+// status does not lead to.
+type TransitionMode string
+
+const (
+	// TransitionOff does not track statuses.
+	TransitionOff TransitionMode = "off"
+	// TransitionFlag records invalid transitions (logs + metrics) but still delivers the message.
+	TransitionFlag TransitionMode = "flag"
+	// TransitionSuppress drops messages with an invalid transition.
+	TransitionSuppress TransitionMode = "suppress"
+)
+
+// TransitionConfig controls status transition validation. Statuses are
+// tracked per (channel, job): a project's onboarding of one source, or a campaign.
+type TransitionConfig struct {
+	Mode      TransitionMode
+	MaxTopics int // Jobs remembered; the least recently updated is forgotten beyond this
+
+	// Allowed maps a status to the statuses that may follow it, per message
+	// type. A status listed here replaces its next statuses in
+	// DefaultTransitions; the type's other statuses keep theirs. Staying in
+	// the same status and moving from or to a status missing from the map are
+	// always allowed.
+	Allowed map[websocket.MessageType]map[string][]string
+}
+
+// UserTraceConfig bounds targeted tracing.
+type UserTraceConfig struct {
+	MaxTTL   time.Duration // Longest a user may be traced
+	MaxUsers int           // Users traced at once
+}
+
+// ProtocolConfig controls the hello frame. Compression is whether the upgrade
+// offers permessage-deflate, which the usecase does not see otherwise.
+type ProtocolConfig struct {
+	Hello       bool
+	Compression bool
+}
+
+// CanaryConfig controls the canary: probes published for a reserved user and
+// received by its loopback connection on this replica.
+type CanaryConfig struct {
+	Enabled         bool
+	UserID          string        // Reserved user the probes are addressed to
+	ProjectID       string        // Project of the probes' channel
+	InstanceID      string        // Tells this replica's probes from the others'
+	Interval        time.Duration // Between probes
+	Timeout         time.Duration // A probe not received within this is lost
+	Threshold       time.Duration // A probe received later than this is slow
+	FailuresToAlert int           // Failed probes in a row before alerting
+}
+
+// TerminalDedupeConfig controls the suppression of terminal job updates
+// (finished or failed onboardings, completed pipelines, finished campaigns)
+// that repeat the job's last one. A payload with "force": true is always
+// delivered.
+type TerminalDedupeConfig struct {
+	Window    time.Duration // A repeat within this long of the last delivered one is dropped (0 = off)
+	MaxTopics int           // Jobs remembered; the least recently updated is forgotten beyond this
+}
+
+// SanitizeConfig controls the cleaning of free-text payload fields (names,
+// messages, crisis sample mentions) before delivery. Cleaned text is valid
+// UTF-8 without control characters other than newlines and tabs.
+type SanitizeConfig struct {
+	Enabled bool
+
+	// Fields replaces the DefaultSanitizeRules of a field, by JSON field name.
+	Fields map[string]SanitizeRule
+}
+
+// SanitizeRule is how one free-text field is cut and escaped.
+type SanitizeRule struct {
+	MaxLength  int  // Characters kept, the trailing ellipsis included (0 = no limit)
+	EscapeHTML bool // Escape <, >, &, ' and " for clients that render the text as HTML
+}
+
+// QuotaPolicy decides what happens when a user at their quota connects again.
+type QuotaPolicy string
+
+const (
+	// QuotaReject refuses the new connection.
+	QuotaReject QuotaPolicy = "reject"
+	// QuotaKickOldest accepts it and closes the user's oldest connections with
+	// websocket.CloseCodeReplaced.
+	QuotaKickOldest QuotaPolicy = "kick_oldest"
+)
+
+// transformFunc is the signature shared by all transformer versions.
+type transformFunc func(uc *implUseCase, ctx context.Context, msgType websocket.MessageType, payload []byte) (websocket.NotificationOutput, error)
+
+// shadowTransformers holds candidate transformer versions that can be shadow-run
+// against transformMessage. Register a rewrite here under a version name, then
+// select it with transform.shadow.version before cutting over.
+var shadowTransformers = map[string]transformFunc{
+	streamingTransformVersion: transformStreaming,
+}
+
+// publicViewers admits anonymous viewers of public projects. Upgrades are
+// rate limited per IP with token buckets, which are dropped once refilled.
+type publicViewers struct {
+	cfg     PublicConfig
+	viewers []string // Hub identities of the public projects, for connection counts
+
+	mu      sync.Mutex
+	buckets map[string]*connectBucket // ip -> upgrades allowance
+}
+
+// connectBucket is the upgrade allowance of one IP.
+type connectBucket struct {
+	tokens float64
+	at     time.Time // Last refill
+}
@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"notification-srv/internal/metrics"
+	ws "notification-srv/internal/websocket"
+)
+
+// payloadDecoder unmarshals payload into v; see decodePayload.
+type payloadDecoder func(payload []byte, v any, strict bool) (violation error, err error)
+
+// decodeTyped decodes payload into the payload struct of msgType. A payload that
+// is not valid JSON for the type is an error; rule violations (and unknown
+// fields when strict is set) are returned separately as violation so the caller
+// decides whether they are fatal.
+func decodeTyped(msgType ws.MessageType, payload []byte, strict bool) (data any, violation error, err error) {
+	return decodeTypedWith(decodePayload, msgType, payload, strict)
+}
+
+// decodeTypedWith is decodeTyped with the given JSON decoder.
+func decodeTypedWith(decode payloadDecoder, msgType ws.MessageType, payload []byte, strict bool) (data any, violation error, err error) {
+	switch msgType {
+	case ws.MessageTypeDataOnboarding:
+		var d ws.DataOnboardingPayload
+		v, err := decode(payload, &d, strict)
+		return d, errors.Join(v, validateDataOnboarding(d)), err
+
+	case ws.MessageTypeAnalyticsPipeline:
+		var d ws.AnalyticsPipelinePayload
+		v, err := decode(payload, &d, strict)
+		return d, errors.Join(v, validateAnalyticsPipeline(d)), err
+
+	case ws.MessageTypeCrisisAlert:
+		var d ws.CrisisAlertPayload
+		v, err := decode(payload, &d, strict)
+		return d, errors.Join(v, validateCrisisAlert(d)), err
+
+	case ws.MessageTypeCampaignEvent:
+		var d ws.CampaignEventPayload
+		v, err := decode(payload, &d, strict)
+		return d, errors.Join(v, validateCampaignEvent(d)), err
+
+	case ws.MessageTypeSecurityAlert:
+		var d ws.SecurityAlertPayload
+		v, err := decode(payload, &d, strict)
+		return d, errors.Join(v, validateSecurityAlert(d)), err
+
+	case ws.MessageTypeBillingEvent:
+		var d ws.BillingEventPayload
+		v, err := decode(payload, &d, strict)
+		return d, errors.Join(v, validateBillingEvent(d)), err
+
+	case ws.MessageTypeSystemMaintenance:
+		var d ws.SystemMaintenancePayload
+		v, err := decode(payload, &d, strict)
+		return d, errors.Join(v, validateSystemMaintenance(d)), err
+
+	case ws.MessageTypeSystem:
+		// System messages might be plain strings or generic maps
+		var d interface{}
+		if _, err := decode(payload, &d, false); err != nil {
+			return nil, nil, ws.ErrInvalidMessage
+		}
+		return d, nil, nil
+
+	default:
+		return nil, nil, ws.ErrUnknownMessageType
+	}
+}
+
+// decodePayload unmarshals payload into v. When strict is set, unknown fields
+// are reported as a violation rather than an error.
+func decodePayload(payload []byte, v any, strict bool) (violation error, err error) {
+	if err := json.Unmarshal(payload, v); err != nil {
+		return nil, ws.ErrInvalidMessage
+	}
+	if !strict {
+		return nil, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(reflect.New(reflect.TypeOf(v).Elem()).Interface()); err != nil {
+		return err, nil
+	}
+	return nil, nil
+}
+
+// enforceValidation applies the configured validation mode to a violation.
+// In log-only mode the violation is recorded and the message still flows.
+func (uc *implUseCase) enforceValidation(ctx context.Context, msgType ws.MessageType, violation error) error {
+	if violation == nil {
+		return nil
+	}
+
+	metrics.ValidationFailures.WithLabelValues(string(msgType), string(uc.validation)).Inc()
+	if uc.validation == ValidationLogOnly {
+		uc.logger.Warnf(ctx, "validation failed (log-only, delivering anyway): type=%s err=%v", msgType, violation)
+		return nil
+	}
+	return fmt.Errorf("%w: %v", ws.ErrValidationFailed, violation)
+}
+
+func validateDataOnboarding(d ws.DataOnboardingPayload) error {
+	return errors.Join(
+		requireField("project_id", d.ProjectID),
+		requireField("source_id", d.SourceID),
+		oneOf("status", d.Status, "pending", "processing", "completed", "failed"),
+		inRange("progress", d.Progress, 0, 100),
+		nonNegative("record_count", d.RecordCount),
+		nonNegative("error_count", d.ErrorCount),
+	)
+}
+
+func validateAnalyticsPipeline(d ws.AnalyticsPipelinePayload) error {
+	return errors.Join(
+		requireField("project_id", d.ProjectID),
+		requireField("source_id", d.SourceID),
+		inRange("progress", d.Progress, 0, 100),
+		nonNegative("total_records", d.TotalRecords),
+		nonNegative("processed_count", d.ProcessedCount),
+		nonNegative("success_count", d.SuccessCount),
+		nonNegative("failed_count", d.FailedCount),
+	)
+}
+
+func validateCrisisAlert(d ws.CrisisAlertPayload) error {
+	return errors.Join(
+		requireField("project_id", d.ProjectID),
+		requireField("alert_type", d.AlertType),
+		oneOf("severity", d.Severity, "critical", "warning", "info"),
+	)
+}
+
+func validateCampaignEvent(d ws.CampaignEventPayload) error {
+	return errors.Join(
+		requireField("campaign_id", d.CampaignID),
+		oneOf("event_type", d.EventType, "created", "started", "paused", "finished"),
+	)
+}
+
+func validateSecurityAlert(d ws.SecurityAlertPayload) error {
+	return errors.Join(
+		requireField("alert_id", d.AlertID),
+		requireField("security_event", d.SecurityEvent),
+		oneOf("severity", d.Severity, "critical", "warning", "info"),
+	)
+}
+
+func validateBillingEvent(d ws.BillingEventPayload) error {
+	var currency error
+	if d.Amount != 0 {
+		currency = requireField("currency", d.Currency)
+	}
+	return errors.Join(
+		oneOf("billing_event", d.BillingEvent, "invoice_issued", "invoice_paid", "payment_failed",
+			"subscription_renewed", "subscription_canceled", "plan_changed", "trial_ending"),
+		currency,
+		nonNegative("amount", int(d.Amount)),
+	)
+}
+
+func validateSystemMaintenance(d ws.SystemMaintenancePayload) error {
+	var window error
+	if d.StartsAt != nil && d.EndsAt != nil && !d.EndsAt.After(*d.StartsAt) {
+		window = errors.New("ends_at must be after starts_at")
+	}
+	return errors.Join(
+		requireField("maintenance_id", d.MaintenanceID),
+		oneOf("status", d.Status, "scheduled", "started", "completed", "cancelled"),
+		window,
+	)
+}
+
+func requireField(name, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s is required", name)
+	}
+	return nil
+}
+
+func oneOf(name, value string, allowed ...string) error {
+	for _, a := range allowed {
+		if strings.EqualFold(value, a) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s %q is not one of %v", name, value, allowed)
+}
+
+func inRange(name string, value, min, max int) error {
+	if value < min || value > max {
+		return fmt.Errorf("%s %d is out of range [%d, %d]", name, value, min, max)
+	}
+	return nil
+}
+
+func nonNegative(name string, value int) error {
+	if value < 0 {
+		return fmt.Errorf("%s must not be negative", name)
+	}
+	return nil
+}
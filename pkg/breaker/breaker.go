@@ -0,0 +1,209 @@
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned for calls rejected while the breaker is open, or while
+// its half-open probes are in flight.
+var ErrOpen = errors.New("breaker: circuit open")
+
+// State is the state of a breaker.
+type State int
+
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half_open"
+	case StateOpen:
+		return "open"
+	}
+	return "unknown"
+}
+
+// Config is the configuration of a breaker.
+type Config struct {
+	// Name identifies the breaker in OnStateChange.
+	Name string
+
+	// FailureRatio of the calls within Interval opens the breaker, once at
+	// least MinRequests calls were made.
+	FailureRatio float64
+	MinRequests  int
+	Interval     time.Duration
+
+	// OpenTimeout is how long the breaker stays open before probing.
+	OpenTimeout time.Duration
+
+	// HalfOpenProbes is the number of calls let through while half-open.
+	HalfOpenProbes int
+
+	// OnStateChange, if set, is called on every transition, without the
+	// breaker's lock held.
+	OnStateChange func(name string, from, to State)
+}
+
+// Breaker is a circuit breaker. It is safe for concurrent use.
+type Breaker struct {
+	cfg Config
+	now func() time.Time
+
+	mu         sync.Mutex
+	state      State
+	generation uint64    // Bumped on every reset; results of calls from before are ignored
+	expiry     time.Time // End of the counting interval when closed, of the timeout when open
+	requests   int
+	failures   int
+	successes  int // Successful probes while half-open
+}
+
+// New creates a closed breaker.
+func New(cfg Config) *Breaker {
+	if cfg.HalfOpenProbes < 1 {
+		cfg.HalfOpenProbes = 1
+	}
+	if cfg.MinRequests < 1 {
+		cfg.MinRequests = 1
+	}
+	b := &Breaker{cfg: cfg, now: time.Now}
+	b.expiry = b.now().Add(cfg.Interval)
+	return b
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() State {
+	if b == nil {
+		return StateClosed
+	}
+
+	b.mu.Lock()
+	state, change := b.current(b.now())
+	b.mu.Unlock()
+	b.notify(change)
+	return state
+}
+
+// Allow reports whether a call may be made. If so, done must be called with
+// whether the call failed; otherwise ErrOpen is returned. A nil breaker allows
+// every call.
+func (b *Breaker) Allow() (done func(failed bool), err error) {
+	if b == nil {
+		return func(bool) {}, nil
+	}
+
+	b.mu.Lock()
+	now := b.now()
+	state, change := b.current(now)
+
+	if state == StateOpen || (state == StateHalfOpen && b.requests >= b.cfg.HalfOpenProbes) {
+		b.mu.Unlock()
+		b.notify(change)
+		return nil, ErrOpen
+	}
+	b.requests++
+	generation := b.generation
+	b.mu.Unlock()
+	b.notify(change)
+
+	return func(failed bool) { b.done(generation, failed) }, nil
+}
+
+// Do runs fn if the breaker allows it and records whether it failed. An error
+// counts as a failure unless isFailure is set and returns false for it, e.g.
+// for errors caused by the caller rather than the dependency.
+func (b *Breaker) Do(fn func() error, isFailure func(error) bool) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	done(err != nil && (isFailure == nil || isFailure(err)))
+	return err
+}
+
+func (b *Breaker) done(generation uint64, failed bool) {
+	b.mu.Lock()
+	now := b.now()
+	state, change := b.current(now)
+	if generation != b.generation {
+		b.mu.Unlock()
+		b.notify(change)
+		return
+	}
+
+	switch state {
+	case StateClosed:
+		if failed {
+			b.failures++
+		}
+		if b.requests >= b.cfg.MinRequests && float64(b.failures) >= b.cfg.FailureRatio*float64(b.requests) {
+			change = b.setState(StateOpen, now)
+		}
+	case StateHalfOpen:
+		if failed {
+			change = b.setState(StateOpen, now)
+			break
+		}
+		b.successes++
+		if b.successes >= b.cfg.HalfOpenProbes {
+			change = b.setState(StateClosed, now)
+		}
+	}
+	b.mu.Unlock()
+	b.notify(change)
+}
+
+// transition is a state change to report once the lock is released.
+type transition struct {
+	from, to State
+	ok       bool
+}
+
+// current advances the state for the passage of time: a new counting interval
+// when closed, half-open once the open timeout is over. Callers hold mu.
+func (b *Breaker) current(now time.Time) (State, transition) {
+	var change transition
+	switch b.state {
+	case StateClosed:
+		if b.cfg.Interval > 0 && !now.Before(b.expiry) {
+			b.reset(now)
+		}
+	case StateOpen:
+		if !now.Before(b.expiry) {
+			change = b.setState(StateHalfOpen, now)
+		}
+	}
+	return b.state, change
+}
+
+func (b *Breaker) setState(state State, now time.Time) transition {
+	change := transition{from: b.state, to: state, ok: true}
+	b.state = state
+	b.reset(now)
+	if state == StateOpen {
+		b.expiry = now.Add(b.cfg.OpenTimeout)
+	}
+	return change
+}
+
+func (b *Breaker) reset(now time.Time) {
+	b.generation++
+	b.requests, b.failures, b.successes = 0, 0, 0
+	b.expiry = now.Add(b.cfg.Interval)
+}
+
+func (b *Breaker) notify(change transition) {
+	if change.ok && b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(b.cfg.Name, change.from, change.to)
+	}
+}
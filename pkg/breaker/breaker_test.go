@@ -0,0 +1,205 @@
+package breaker
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// clock is a manual time source.
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time { return c.t }
+
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// newTestBreaker creates a breaker on a manual clock that records its
+// transitions.
+func newTestBreaker(cfg Config) (*Breaker, *clock, *[]string) {
+	var transitions []string
+	cfg.Name = "test"
+	cfg.OnStateChange = func(name string, from, to State) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+	}
+	c := &clock{t: time.Unix(1700000000, 0)}
+	b := New(cfg)
+	b.now = c.now
+	b.expiry = c.now().Add(cfg.Interval)
+	return b, c, &transitions
+}
+
+// call makes one call through b and reports whether it was allowed.
+func call(b *Breaker, failed bool) bool {
+	done, err := b.Allow()
+	if err != nil {
+		return false
+	}
+	done(failed)
+	return true
+}
+
+func TestBreakerOpensOnFailureRatio(t *testing.T) {
+	b, _, transitions := newTestBreaker(Config{FailureRatio: 0.5, MinRequests: 4, Interval: time.Minute, OpenTimeout: time.Second})
+
+	// Under MinRequests, even all failures keep it closed
+	for i := 0; i < 3; i++ {
+		call(b, true)
+	}
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("State() = %v after 3 calls, want closed", got)
+	}
+
+	call(b, false)
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("State() = %v after 3 of 4 calls failed, want open", got)
+	}
+	if _, err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Allow() error = %v while open, want ErrOpen", err)
+	}
+	if want := []string{"closed->open"}; !reflect.DeepEqual(*transitions, want) {
+		t.Errorf("transitions = %v, want %v", *transitions, want)
+	}
+}
+
+func TestBreakerIntervalResetsCounts(t *testing.T) {
+	b, clk, _ := newTestBreaker(Config{FailureRatio: 0.5, MinRequests: 2, Interval: time.Minute, OpenTimeout: time.Second})
+
+	call(b, true)
+	clk.advance(time.Minute)
+	call(b, true)
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("State() = %v, want closed: the first failure belongs to a past interval", got)
+	}
+	call(b, true)
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("State() = %v, want open", got)
+	}
+}
+
+func TestBreakerHalfOpenProbesClose(t *testing.T) {
+	b, clk, transitions := newTestBreaker(Config{FailureRatio: 1, MinRequests: 1, Interval: time.Minute, OpenTimeout: time.Second, HalfOpenProbes: 2})
+
+	call(b, true)
+	clk.advance(999 * time.Millisecond)
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("State() = %v before OpenTimeout, want open", got)
+	}
+	clk.advance(time.Millisecond)
+	if got := b.State(); got != StateHalfOpen {
+		t.Fatalf("State() = %v after OpenTimeout, want half_open", got)
+	}
+
+	// Only HalfOpenProbes calls are let through
+	first, err := b.Allow()
+	if err != nil {
+		t.Fatalf("first probe: %v", err)
+	}
+	second, err := b.Allow()
+	if err != nil {
+		t.Fatalf("second probe: %v", err)
+	}
+	if _, err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("third call error = %v, want ErrOpen", err)
+	}
+
+	first(false)
+	if got := b.State(); got != StateHalfOpen {
+		t.Fatalf("State() = %v after one probe, want half_open", got)
+	}
+	second(false)
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("State() = %v after both probes, want closed", got)
+	}
+	if !call(b, false) {
+		t.Error("call rejected after closing")
+	}
+
+	want := []string{"closed->open", "open->half_open", "half_open->closed"}
+	if !reflect.DeepEqual(*transitions, want) {
+		t.Errorf("transitions = %v, want %v", *transitions, want)
+	}
+}
+
+func TestBreakerHalfOpenFailureReopens(t *testing.T) {
+	b, clk, transitions := newTestBreaker(Config{FailureRatio: 1, MinRequests: 1, Interval: time.Minute, OpenTimeout: time.Second, HalfOpenProbes: 2})
+
+	call(b, true)
+	clk.advance(time.Second)
+	call(b, false)
+	call(b, true)
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("State() = %v after a failed probe, want open", got)
+	}
+
+	// The open timeout starts over
+	clk.advance(999 * time.Millisecond)
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("State() = %v, want open until the new timeout", got)
+	}
+
+	want := []string{"closed->open", "open->half_open", "half_open->open"}
+	if !reflect.DeepEqual(*transitions, want) {
+		t.Errorf("transitions = %v, want %v", *transitions, want)
+	}
+}
+
+func TestBreakerIgnoresStaleResults(t *testing.T) {
+	b, clk, _ := newTestBreaker(Config{FailureRatio: 0.5, MinRequests: 1, Interval: time.Minute, OpenTimeout: time.Second})
+
+	// A slow call from before the breaker opened finishes while half-open
+	slow, err := b.Allow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	call(b, true)
+	clk.advance(time.Second)
+	probe, err := b.Allow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slow(true)
+	if got := b.State(); got != StateHalfOpen {
+		t.Fatalf("State() = %v, want half_open: a stale failure must not reopen", got)
+	}
+	probe(false)
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("State() = %v, want closed", got)
+	}
+}
+
+func TestBreakerDo(t *testing.T) {
+	b, _, _ := newTestBreaker(Config{FailureRatio: 0.5, MinRequests: 1, Interval: time.Minute, OpenTimeout: time.Second})
+	errCaller := errors.New("bad request")
+	errDependency := errors.New("unavailable")
+	isFailure := func(err error) bool { return err != errCaller }
+
+	if err := b.Do(func() error { return errCaller }, isFailure); err != errCaller {
+		t.Fatalf("Do() = %v, want the call's error", err)
+	}
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("State() = %v, want closed: caller errors are not failures", got)
+	}
+
+	b.Do(func() error { return errDependency }, isFailure)
+	ran := false
+	err := b.Do(func() error { ran = true; return nil }, isFailure)
+	if !errors.Is(err, ErrOpen) || ran {
+		t.Fatalf("Do() = %v, ran = %v while open; want ErrOpen without running", err, ran)
+	}
+}
+
+func TestNilBreakerAllows(t *testing.T) {
+	var b *Breaker
+	if got := b.State(); got != StateClosed {
+		t.Errorf("State() = %v, want closed", got)
+	}
+	done, err := b.Allow()
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	done(true)
+}
@@ -0,0 +1,10 @@
+// Package breaker implements a circuit breaker for outbound dependencies, so
+// an outage fails calls fast instead of making every caller wait for its
+// timeout.
+//
+// A closed breaker counts calls over a fixed interval and opens once at least
+// MinRequests calls were made and the share of failures reaches
+// FailureRatio. An open breaker rejects calls with ErrOpen until OpenTimeout
+// has passed, then lets HalfOpenProbes calls through: it closes again when
+// they all succeed and reopens on the first failure.
+package breaker
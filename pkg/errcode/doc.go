@@ -0,0 +1,14 @@
+// Package errcode is the error taxonomy shared by the service's modules, so
+// clients and dashboards can reason about failures uniformly.
+//
+// A domain declares its sentinel errors with New or NewRetryable, giving each
+// a stable code such as "ws.quota_exceeded", a Category deciding the HTTP
+// status, and a public message safe to show clients. The sentinels stay comparable with errors.Is, and Error still
+// returns the internal message, so logs are unchanged.
+//
+// Respond answers a request with an application/problem+json document
+// (RFC 7807): the category's status, the public message as title, a type URI
+// stable per code, the taxonomy of the error and the request's trace ID as
+// correlation_id. Errors outside the taxonomy panic, to be turned into a 500
+// by the recovery middleware.
+package errcode
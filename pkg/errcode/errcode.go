@@ -0,0 +1,117 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Category groups failures by how a caller should react to them.
+type Category string
+
+const (
+	Invalid         Category = "invalid"         // The request or message is malformed
+	Unauthenticated Category = "unauthenticated" // Missing or invalid credentials
+	Forbidden       Category = "forbidden"       // Authenticated but not allowed
+	NotFound        Category = "not_found"
+	RateLimited     Category = "rate_limited"     // A quota or limit was reached
+	Timeout         Category = "timeout"          // A deadline expired
+	Unavailable     Category = "unavailable"      // A dependency or capacity is exhausted
+	Storage         Category = "storage"          // Redis or another store failed
+	NotImplemented  Category = "not_implemented"  // The feature is not configured
+	UpgradeRequired Category = "upgrade_required" // The client build is too old
+	Internal        Category = "internal"
+)
+
+// Status is the HTTP status answered for the category.
+func (c Category) Status() int {
+	switch c {
+	case Invalid:
+		return http.StatusBadRequest
+	case Unauthenticated:
+		return http.StatusUnauthorized
+	case Forbidden:
+		return http.StatusForbidden
+	case NotFound:
+		return http.StatusNotFound
+	case RateLimited:
+		return http.StatusTooManyRequests
+	case Timeout:
+		return http.StatusGatewayTimeout
+	case Unavailable, Storage:
+		return http.StatusServiceUnavailable
+	case NotImplemented:
+		return http.StatusNotImplemented
+	case UpgradeRequired:
+		return http.StatusUpgradeRequired
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Error is a classified error. Declare them once as package-level sentinels.
+type Error struct {
+	Code      string // Stable, e.g. "ws.quota_exceeded"
+	Category  Category
+	Retryable bool   // Retrying the same request later may succeed
+	Message   string // Internal message, returned by Error
+	Public    string // Message safe to show clients
+}
+
+// New returns a classified error with its internal and public message.
+func New(code string, category Category, message, public string) *Error {
+	return &Error{Code: code, Category: category, Message: message, Public: public}
+}
+
+// NewRetryable is New for failures that retrying the same request later may
+// get past, such as a reached limit or an unavailable dependency.
+func NewRetryable(code string, category Category, message, public string) *Error {
+	return &Error{Code: code, Category: category, Retryable: true, Message: message, Public: public}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Info is the client-facing description of an error.
+type Info struct {
+	Code      string   `json:"code"`
+	Category  Category `json:"category"`
+	Retryable bool     `json:"retryable"`
+}
+
+func (e *Error) Info() Info {
+	return Info{Code: e.Code, Category: e.Category, Retryable: e.Retryable}
+}
+
+// Of returns the first classified error in err's chain.
+func Of(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// Unclassified stands for errors outside the taxonomy in Classify.
+var Unclassified = New("internal.unclassified", Internal, "unclassified error", "Internal server error")
+
+// Classify returns the classified error in err's chain, or Unclassified.
+func Classify(err error) *Error {
+	if e, ok := Of(err); ok {
+		return e
+	}
+	return Unclassified
+}
+
+// Wrap classifies err as def unless it is classified already. The result
+// matches def with errors.Is and keeps err's text.
+func Wrap(def *Error, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := Of(err); ok {
+		return err
+	}
+	return fmt.Errorf("%w: %v", def, err)
+}
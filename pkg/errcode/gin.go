@@ -0,0 +1,65 @@
+package errcode
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/smap-hcmut/shared-libs/go/tracing"
+)
+
+var tracer = tracing.NewTraceContext()
+
+// ContentType is the media type of error responses (RFC 7807).
+const ContentType = "application/problem+json"
+
+// TypePrefix prefixes the code of an error to form its problem type URI.
+const TypePrefix = "urn:notification-srv:problem:"
+
+// Problem is the body of an error response, an RFC 7807 problem document
+// extended with the taxonomy of the error and the request's correlation ID.
+// Responses carrying more details embed it.
+type Problem struct {
+	Type          string   `json:"type"`   // TypePrefix + Code, stable per code
+	Title         string   `json:"title"`  // The public message
+	Status        int      `json:"status"` // The HTTP status
+	Detail        string   `json:"detail,omitempty"`
+	Instance      string   `json:"instance,omitempty"` // The request path
+	Code          string   `json:"code"`
+	Category      Category `json:"category"`
+	Retryable     bool     `json:"retryable"`
+	CorrelationID string   `json:"correlation_id,omitempty"` // The X-Trace-Id of the request
+}
+
+// TypeURI returns the problem type URI of an error code.
+func TypeURI(code string) string {
+	return TypePrefix + code
+}
+
+// NewProblem returns the problem document answering c with e.
+func NewProblem(c *gin.Context, e *Error) Problem {
+	return Problem{
+		Type:          TypeURI(e.Code),
+		Title:         e.Public,
+		Status:        e.Category.Status(),
+		Instance:      c.Request.URL.Path,
+		Code:          e.Code,
+		Category:      e.Category,
+		Retryable:     e.Retryable,
+		CorrelationID: tracer.GetTraceID(c.Request.Context()),
+	}
+}
+
+// Respond answers the request with the classified error in err's chain.
+// Unclassified errors panic, to be recovered as a 500.
+func Respond(c *gin.Context, err error) {
+	e, ok := Of(err)
+	if !ok {
+		panic(err)
+	}
+	WriteProblem(c, e.Category.Status(), NewProblem(c, e))
+}
+
+// WriteProblem writes body, a Problem or a struct embedding one, with the
+// problem+json content type.
+func WriteProblem(c *gin.Context, status int, body any) {
+	c.Header("Content-Type", ContentType)
+	c.JSON(status, body)
+}
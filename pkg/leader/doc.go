@@ -0,0 +1,10 @@
+// Package leader elects one replica to run a singleton task, such as a
+// scheduler or a reaper, through a lease held in Redis.
+//
+// A replica becomes leader by setting the lease key with SET NX and a TTL,
+// then renews it every RenewInterval. It steps down when another replica
+// holds the key, or when it cannot renew and the lease may expire before its
+// next attempt, so two replicas never both believe they lead. On shutdown the
+// leader deletes the key so another replica takes over without waiting out
+// the TTL.
+package leader
@@ -0,0 +1,169 @@
+package leader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// releaseTimeout bounds deleting the lease on shutdown, after ctx is done.
+const releaseTimeout = 2 * time.Second
+
+// Client is the part of a go-redis client an elector uses.
+type Client interface {
+	goredis.Scripter
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *goredis.BoolCmd
+}
+
+// Config is the configuration of an elector.
+type Config struct {
+	// Key is the Redis key of the lease, shared by all replicas.
+	Key string
+
+	// Identity identifies this replica, e.g. the hostname. A random suffix
+	// is added, so two processes on one host never share a lease.
+	Identity string
+
+	// TTL is how long the lease outlives its last renewal. It bounds how
+	// long a crashed leader blocks the others.
+	TTL time.Duration
+
+	// RenewInterval is how often the leader renews the lease and the others
+	// try to take it. Defaults to a third of TTL.
+	RenewInterval time.Duration
+
+	// OnAcquire, if set, is called when this replica becomes leader. ctx is
+	// cancelled as soon as leadership is lost; work should run until then.
+	// It must not block.
+	OnAcquire func(ctx context.Context)
+
+	// OnLose, if set, is called after the context of OnAcquire was cancelled.
+	OnLose func()
+
+	// OnError, if set, is called for every failed Redis call.
+	OnError func(err error)
+}
+
+// renewScript extends the lease if this replica still holds it.
+var renewScript = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// releaseScript deletes the lease if this replica still holds it.
+var releaseScript = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Elector campaigns for a lease. It is safe for concurrent use.
+type Elector struct {
+	client Client
+	cfg    Config
+	id     string
+	leader atomic.Bool
+}
+
+// New creates an elector. Run starts campaigning.
+func New(client Client, cfg Config) *Elector {
+	if cfg.RenewInterval <= 0 {
+		cfg.RenewInterval = cfg.TTL / 3
+	}
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return &Elector{client: client, cfg: cfg, id: cfg.Identity + "-" + hex.EncodeToString(suffix)}
+}
+
+// ID returns the value this replica stores in the lease.
+func (e *Elector) ID() string {
+	return e.id
+}
+
+// IsLeader reports whether this replica currently holds the lease.
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Run campaigns until ctx is done, then steps down and releases the lease if
+// held.
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.cfg.RenewInterval)
+	defer ticker.Stop()
+
+	var (
+		cancel context.CancelFunc // Set while leader
+		expiry time.Time          // Earliest time the lease may expire in Redis
+	)
+	stepDown := func() {
+		cancel()
+		cancel = nil
+		e.leader.Store(false)
+		if e.cfg.OnLose != nil {
+			e.cfg.OnLose()
+		}
+	}
+
+	for {
+		// Taken before the call, so the lease expires in Redis no earlier
+		start := time.Now()
+		if cancel == nil {
+			ok, err := e.client.SetNX(ctx, e.cfg.Key, e.id, e.cfg.TTL).Result()
+			if err != nil {
+				e.report(err)
+			} else if ok {
+				expiry = start.Add(e.cfg.TTL)
+				var leaderCtx context.Context
+				leaderCtx, cancel = context.WithCancel(ctx)
+				e.leader.Store(true)
+				if e.cfg.OnAcquire != nil {
+					e.cfg.OnAcquire(leaderCtx)
+				}
+			}
+		} else {
+			renewed, err := renewScript.Run(ctx, e.client, []string{e.cfg.Key}, e.id, e.cfg.TTL.Milliseconds()).Int()
+			switch {
+			case err == nil && renewed == 1:
+				expiry = start.Add(e.cfg.TTL)
+			case err == nil:
+				stepDown() // Taken over after it expired
+			default:
+				e.report(err)
+				// Step down before the lease can expire, not after
+				if time.Until(expiry) <= e.cfg.RenewInterval {
+					stepDown()
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if cancel != nil {
+				stepDown()
+				e.release()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Elector) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	if err := releaseScript.Run(ctx, e.client, []string{e.cfg.Key}, e.id).Err(); err != nil {
+		e.report(err)
+	}
+}
+
+func (e *Elector) report(err error) {
+	if e.cfg.OnError != nil && !errors.Is(err, context.Canceled) {
+		e.cfg.OnError(err)
+	}
+}
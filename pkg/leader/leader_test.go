@@ -0,0 +1,220 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// fakeClient holds one lease in memory. It runs the elector's scripts by
+// their hash and ignores the TTL; tests take a lease over with steal.
+type fakeClient struct {
+	goredis.Scripter // Unused methods
+
+	mu      sync.Mutex
+	holder  string
+	evalErr error // Returned by renewals while set
+}
+
+func (f *fakeClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *goredis.BoolCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.holder != "" {
+		return goredis.NewBoolResult(false, nil)
+	}
+	f.holder = value.(string)
+	return goredis.NewBoolResult(true, nil)
+}
+
+func (f *fakeClient) EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) *goredis.Cmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	held := f.holder == args[0].(string)
+	switch sha1 {
+	case renewScript.Hash():
+		if f.evalErr != nil {
+			return goredis.NewCmdResult(nil, f.evalErr)
+		}
+	case releaseScript.Hash():
+		if held {
+			f.holder = ""
+		}
+	}
+	if held {
+		return goredis.NewCmdResult(int64(1), nil)
+	}
+	return goredis.NewCmdResult(int64(0), nil)
+}
+
+func (f *fakeClient) steal(holder string) {
+	f.mu.Lock()
+	f.holder = holder
+	f.mu.Unlock()
+}
+
+func (f *fakeClient) setEvalErr(err error) {
+	f.mu.Lock()
+	f.evalErr = err
+	f.mu.Unlock()
+}
+
+func (f *fakeClient) lease() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.holder
+}
+
+// campaign runs an elector on client until the test ends.
+type campaign struct {
+	elector  *Elector
+	acquired chan context.Context
+	lost     chan struct{}
+	errs     chan error
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+func startCampaign(t *testing.T, client Client, ttl, renew time.Duration) *campaign {
+	t.Helper()
+	c := &campaign{
+		acquired: make(chan context.Context, 4),
+		lost:     make(chan struct{}, 4),
+		errs:     make(chan error, 64),
+		done:     make(chan struct{}),
+	}
+	c.elector = New(client, Config{
+		Key:           "leader",
+		Identity:      "test",
+		TTL:           ttl,
+		RenewInterval: renew,
+		OnAcquire:     func(ctx context.Context) { c.acquired <- ctx },
+		OnLose:        func() { c.lost <- struct{}{} },
+		OnError: func(err error) {
+			select {
+			case c.errs <- err:
+			default:
+			}
+		},
+	})
+
+	var ctx context.Context
+	ctx, c.cancel = context.WithCancel(context.Background())
+	go func() {
+		c.elector.Run(ctx)
+		close(c.done)
+	}()
+	t.Cleanup(c.stop)
+	return c
+}
+
+func (c *campaign) stop() {
+	c.cancel()
+	<-c.done
+}
+
+func (c *campaign) waitAcquired(t *testing.T) context.Context {
+	t.Helper()
+	select {
+	case ctx := <-c.acquired:
+		return ctx
+	case <-time.After(5 * time.Second):
+		t.Fatal("lease not acquired")
+		return nil
+	}
+}
+
+func (c *campaign) waitLost(t *testing.T) {
+	t.Helper()
+	select {
+	case <-c.lost:
+	case <-time.After(5 * time.Second):
+		t.Fatal("leader did not step down")
+	}
+}
+
+func TestElectorReleasesOnCancel(t *testing.T) {
+	client := &fakeClient{}
+	c := startCampaign(t, client, time.Minute, 10*time.Millisecond)
+
+	leaderCtx := c.waitAcquired(t)
+	if !c.elector.IsLeader() {
+		t.Fatal("IsLeader() = false after acquiring")
+	}
+	if got := client.lease(); got != c.elector.ID() {
+		t.Fatalf("lease held by %q, want %q", got, c.elector.ID())
+	}
+
+	c.stop()
+	c.waitLost(t)
+	if leaderCtx.Err() == nil {
+		t.Error("OnAcquire context not cancelled")
+	}
+	if c.elector.IsLeader() {
+		t.Error("IsLeader() = true after Run returned")
+	}
+	if got := client.lease(); got != "" {
+		t.Errorf("lease held by %q after shutdown, want released", got)
+	}
+}
+
+func TestElectorStepsDownOnTakeover(t *testing.T) {
+	client := &fakeClient{}
+	c := startCampaign(t, client, time.Minute, 10*time.Millisecond)
+
+	leaderCtx := c.waitAcquired(t)
+	client.steal("other")
+	c.waitLost(t)
+	if leaderCtx.Err() == nil {
+		t.Error("OnAcquire context not cancelled")
+	}
+	if c.elector.IsLeader() {
+		t.Error("IsLeader() = true after the lease was taken over")
+	}
+
+	// Nor does it take the lease back, or release the other's on shutdown
+	time.Sleep(50 * time.Millisecond)
+	c.stop()
+	if got := client.lease(); got != "other" {
+		t.Errorf("lease held by %q, want other", got)
+	}
+}
+
+func TestElectorStepsDownWhenRenewalsFail(t *testing.T) {
+	errRedis := errors.New("connection refused")
+
+	// Failures well within the TTL keep the lease
+	client := &fakeClient{}
+	c := startCampaign(t, client, time.Minute, 5*time.Millisecond)
+	c.waitAcquired(t)
+	client.setEvalErr(errRedis)
+	for i := 0; i < 3; i++ {
+		select {
+		case err := <-c.errs:
+			if !errors.Is(err, errRedis) {
+				t.Fatalf("OnError(%v), want %v", err, errRedis)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("renewal error not reported")
+		}
+	}
+	if !c.elector.IsLeader() {
+		t.Fatal("stepped down although the lease is far from expiring")
+	}
+
+	// Failures close to the TTL give it up before it can expire
+	client = &fakeClient{}
+	c = startCampaign(t, client, 30*time.Millisecond, 10*time.Millisecond)
+	leaderCtx := c.waitAcquired(t)
+	client.setEvalErr(errRedis)
+	c.waitLost(t)
+	if leaderCtx.Err() == nil {
+		t.Error("OnAcquire context not cancelled")
+	}
+	if c.elector.IsLeader() {
+		t.Error("IsLeader() = true after renewals failed")
+	}
+}
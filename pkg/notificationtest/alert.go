@@ -0,0 +1,65 @@
+package notificationtest
+
+import (
+	"context"
+	"sync"
+
+	"notification-srv/internal/alert"
+)
+
+// AlertRecorder is an alert.UseCase that records every dispatch instead of
+// sending it. Err, if set, is returned from every dispatch.
+type AlertRecorder struct {
+	Err error
+
+	mu    sync.Mutex
+	calls []any
+}
+
+var _ alert.UseCase = (*AlertRecorder)(nil)
+
+// Calls returns the dispatched inputs in order, e.g. alert.CrisisAlertInput values.
+func (r *AlertRecorder) Calls() []any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]any(nil), r.calls...)
+}
+
+func (r *AlertRecorder) record(input any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, input)
+	return r.Err
+}
+
+func (r *AlertRecorder) DispatchCrisisAlert(ctx context.Context, input alert.CrisisAlertInput) error {
+	return r.record(input)
+}
+
+func (r *AlertRecorder) DispatchDataOnboarding(ctx context.Context, input alert.DataOnboardingInput) error {
+	return r.record(input)
+}
+
+func (r *AlertRecorder) DispatchCampaignEvent(ctx context.Context, input alert.CampaignEventInput) error {
+	return r.record(input)
+}
+
+func (r *AlertRecorder) DispatchComponentRestart(ctx context.Context, input alert.ComponentRestartInput) error {
+	return r.record(input)
+}
+
+func (r *AlertRecorder) DispatchSubscriberStall(ctx context.Context, input alert.SubscriberStallInput) error {
+	return r.record(input)
+}
+
+func (r *AlertRecorder) DispatchConnectionChurn(ctx context.Context, input alert.ConnectionChurnInput) error {
+	return r.record(input)
+}
+
+func (r *AlertRecorder) DispatchDecoyProject(ctx context.Context, input alert.DecoyProjectInput) error {
+	return r.record(input)
+}
+
+func (r *AlertRecorder) DispatchCanaryFailure(ctx context.Context, input alert.CanaryFailureInput) error {
+	return r.record(input)
+}
@@ -0,0 +1,8 @@
+// Package notificationtest provides in-memory fakes of the service's
+// dependencies and domain interfaces for tests: a no-op logger, a scriptable
+// token manager, a recording alert dispatcher, a fake Hub implementing the
+// WebSocket UseCase, a map-backed segment resolver, and a Publisher that feeds messages to a UseCase the way
+// the Redis subscriber does.
+//
+// All fakes are safe for concurrent use.
+package notificationtest
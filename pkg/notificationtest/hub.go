@@ -0,0 +1,361 @@
+package notificationtest
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"notification-srv/internal/model"
+	"notification-srv/internal/websocket"
+)
+
+// Hub is an in-memory websocket.UseCase. It records registrations and
+// processed messages, and streams frames passed to Send to the matching
+// Subscribe callers. Set ProcessErr to make ProcessMessage fail. Bans match
+// user IDs and IPs exactly; ranges are not expanded.
+type Hub struct {
+	ProcessErr error
+
+	mu          sync.Mutex
+	registered  []websocket.ConnectionInput
+	processed   []websocket.ProcessMessageInput
+	streams     map[*stream]struct{}
+	handlers    map[int]hubEventSub
+	nextHandler int
+	maintenance websocket.MaintenanceStatus
+	broadcasts  []websocket.BroadcastInput
+	bans        map[string]bool // kind:value
+	traces      map[string]time.Time
+}
+
+type stream struct {
+	input  websocket.SubscribeInput
+	frames chan []byte
+}
+
+type hubEventSub struct {
+	types   map[websocket.HubEventType]bool
+	handler websocket.HubEventHandler
+}
+
+var _ websocket.UseCase = (*Hub)(nil)
+
+func NewHub() *Hub {
+	return &Hub{
+		streams:  make(map[*stream]struct{}),
+		handlers: make(map[int]hubEventSub),
+		bans:     make(map[string]bool),
+		traces:   make(map[string]time.Time),
+	}
+}
+
+// Registered returns the ConnectionInputs passed to Register, in order.
+func (h *Hub) Registered() []websocket.ConnectionInput {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]websocket.ConnectionInput(nil), h.registered...)
+}
+
+// Processed returns the messages passed to ProcessMessage, in order.
+func (h *Hub) Processed() []websocket.ProcessMessageInput {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]websocket.ProcessMessageInput(nil), h.processed...)
+}
+
+// Broadcasts returns the inputs passed to Broadcast, in order.
+func (h *Hub) Broadcasts() []websocket.BroadcastInput {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]websocket.BroadcastInput(nil), h.broadcasts...)
+}
+
+// Send queues frame to every stream of userID that has no project filter or
+// is filtered to projectID. Returns the number of streams it was queued to;
+// streams with a full buffer are skipped, as the real Hub does.
+func (h *Hub) Send(userID, projectID string, frame []byte) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sent := 0
+	for s := range h.streams {
+		if s.input.UserID != userID {
+			continue
+		}
+		if s.input.ProjectID != "" && s.input.ProjectID != projectID {
+			continue
+		}
+		select {
+		case s.frames <- frame:
+			sent++
+		default:
+		}
+	}
+	return sent
+}
+
+// Emit delivers ev to the Hub event handlers subscribed to its type.
+func (h *Hub) Emit(ev websocket.HubEvent) {
+	h.mu.Lock()
+	var handlers []websocket.HubEventHandler
+	for _, sub := range h.handlers {
+		if len(sub.types) == 0 || sub.types[ev.Type] {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(ev)
+	}
+}
+
+func (h *Hub) Run() {}
+
+func (h *Hub) SampleStats(ctx context.Context) {}
+
+// Shutdown closes every open stream.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for s := range h.streams {
+		close(s.frames)
+		delete(h.streams, s)
+	}
+	return nil
+}
+
+func (h *Hub) Register(ctx context.Context, input websocket.ConnectionInput) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.registered = append(h.registered, input)
+	return nil
+}
+
+func (h *Hub) Unregister(ctx context.Context, input websocket.ConnectionInput) error {
+	return nil
+}
+
+// Subscribe opens a stream fed by Send; it is closed when ctx ends.
+func (h *Hub) Subscribe(ctx context.Context, input websocket.SubscribeInput) (<-chan []byte, error) {
+	if input.UserID == "" {
+		return nil, websocket.ErrInvalidMessage
+	}
+
+	s := &stream{input: input, frames: make(chan []byte, 256)}
+	h.mu.Lock()
+	h.streams[s] = struct{}{}
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.streams[s]; ok {
+			close(s.frames)
+			delete(h.streams, s)
+		}
+	}()
+	return s.frames, nil
+}
+
+func (h *Hub) SubscribeHubEvents(types []websocket.HubEventType, handler websocket.HubEventHandler) func() {
+	sub := hubEventSub{types: make(map[websocket.HubEventType]bool), handler: handler}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	id := h.nextHandler
+	h.nextHandler++
+	h.handlers[id] = sub
+
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.handlers, id)
+	}
+}
+
+// GetStats counts open streams as connections.
+func (h *Hub) GetStats(ctx context.Context) (websocket.HubStats, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	users := make(map[string]bool)
+	for s := range h.streams {
+		users[s.input.UserID] = true
+	}
+	return websocket.HubStats{
+		ActiveConnections: len(h.streams),
+		TotalUniqueUsers:  len(users),
+	}, nil
+}
+
+func (h *Hub) ListConnections(ctx context.Context, input websocket.ListConnectionsInput) (websocket.ListConnectionsOutput, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var out websocket.ListConnectionsOutput
+	for s := range h.streams {
+		if input.UserID != "" && s.input.UserID != input.UserID {
+			continue
+		}
+		out.Connections = append(out.Connections, websocket.ConnectionInfo{
+			UserID:    s.input.UserID,
+			ProjectID: s.input.ProjectID,
+			UserAgent: s.input.UserAgent,
+			Types:     s.input.Types,
+		})
+	}
+	return out, nil
+}
+
+func (h *Hub) GetStatsHistory(ctx context.Context, input websocket.GetStatsHistoryInput) (websocket.GetStatsHistoryOutput, error) {
+	return websocket.GetStatsHistoryOutput{}, nil
+}
+
+func (h *Hub) ListProjectNotifications(ctx context.Context, sc model.Scope, input websocket.ListProjectNotificationsInput) (websocket.ListProjectNotificationsOutput, error) {
+	return websocket.ListProjectNotificationsOutput{}, nil
+}
+
+func (h *Hub) GetChannelStats(ctx context.Context, input websocket.GetChannelStatsInput) (websocket.GetChannelStatsOutput, error) {
+	return websocket.GetChannelStatsOutput{}, nil
+}
+
+func (h *Hub) SetMaintenance(ctx context.Context, sc model.Scope, input websocket.SetMaintenanceInput) (websocket.MaintenanceStatus, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.maintenance = websocket.MaintenanceStatus{Enabled: input.Enabled}
+	if input.Enabled {
+		h.maintenance.Reason = input.Reason
+		h.maintenance.Since = time.Now()
+	}
+	return h.maintenance, nil
+}
+
+// GetProtocol describes the protocol without optional features.
+func (h *Hub) GetProtocol(ctx context.Context) (websocket.Protocol, error) {
+	return websocket.Protocol{
+		Version:   websocket.ProtocolVersion,
+		Encodings: []string{"json"},
+		Actions:   websocket.InboundActions,
+	}, nil
+}
+
+func (h *Hub) GetMaintenance(ctx context.Context) (websocket.MaintenanceStatus, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.maintenance, nil
+}
+
+func (h *Hub) Broadcast(ctx context.Context, sc model.Scope, input websocket.BroadcastInput) (websocket.BroadcastOutput, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.broadcasts = append(h.broadcasts, input)
+	return websocket.BroadcastOutput{
+		ID:      "broadcast-" + strconv.Itoa(len(h.broadcasts)),
+		All:     input.Segment.Kind == websocket.SegmentAll,
+		UserIDs: len(input.Segment.UserIDs),
+	}, nil
+}
+
+func (h *Hub) Ban(ctx context.Context, sc model.Scope, input websocket.BanInput) (websocket.BanOutput, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.bans[input.Kind+":"+input.Value] = true
+	return websocket.BanOutput{
+		Kind:      input.Kind,
+		Value:     input.Value,
+		ExpiresAt: time.Now().Add(input.TTL),
+	}, nil
+}
+
+func (h *Hub) Unban(ctx context.Context, sc model.Scope, input websocket.UnbanInput) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.bans, input.Kind+":"+input.Value)
+	return nil
+}
+
+func (h *Hub) CheckBan(ctx context.Context, input websocket.CheckBanInput) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if (input.UserID != "" && h.bans[websocket.BanKindUser+":"+input.UserID]) ||
+		(input.RemoteIP != "" && h.bans[websocket.BanKindIP+":"+input.RemoteIP]) {
+		return websocket.ErrBanned
+	}
+	return nil
+}
+
+// TraceUser records the trace without bounding its TTL or the traced users.
+func (h *Hub) TraceUser(ctx context.Context, sc model.Scope, input websocket.TraceUserInput) (websocket.UserTrace, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	expiresAt := time.Now().Add(input.TTL)
+	h.traces[input.UserID] = expiresAt
+	return websocket.UserTrace{UserID: input.UserID, ExpiresAt: expiresAt}, nil
+}
+
+func (h *Hub) UntraceUser(ctx context.Context, sc model.Scope, input websocket.UntraceUserInput) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.traces, input.UserID)
+	return nil
+}
+
+func (h *Hub) ListUserTraces(ctx context.Context) (websocket.ListUserTracesOutput, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var out websocket.ListUserTracesOutput
+	for userID, expiresAt := range h.traces {
+		out.Traces = append(out.Traces, websocket.UserTrace{UserID: userID, ExpiresAt: expiresAt})
+	}
+	return out, nil
+}
+
+// CheckDecoy never reports a decoy.
+func (h *Hub) CheckDecoy(ctx context.Context, input websocket.CheckDecoyInput) error {
+	return nil
+}
+
+// CheckPublic reports every project as not public.
+func (h *Hub) CheckPublic(ctx context.Context, input websocket.CheckPublicInput) (websocket.CheckPublicOutput, error) {
+	return websocket.CheckPublicOutput{}, websocket.ErrNotPublic
+}
+
+// CheckDelegation lets users subscribe to their own stream only.
+func (h *Hub) CheckDelegation(ctx context.Context, input websocket.DelegationInput) error {
+	if input.ActorID != input.UserID {
+		return websocket.ErrDelegationDenied
+	}
+	return nil
+}
+
+// CheckQuota always allows the connection, without a quota.
+func (h *Hub) CheckQuota(ctx context.Context, input websocket.QuotaInput) (int, error) {
+	return 0, nil
+}
+
+func (h *Hub) ProcessMessage(ctx context.Context, input websocket.ProcessMessageInput) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.processed = append(h.processed, input)
+	return h.ProcessErr
+}
+
+func (h *Hub) CheckContract(ctx context.Context, input websocket.CheckContractInput) (websocket.CheckContractOutput, error) {
+	return websocket.CheckContractOutput{}, nil
+}
+
+func (h *Hub) OnUserConnected(ctx context.Context, userID string) error {
+	return nil
+}
+
+func (h *Hub) OnUserDisconnected(ctx context.Context, userID string, hasOtherConnections bool) error {
+	return nil
+}
@@ -0,0 +1,28 @@
+package notificationtest
+
+import (
+	"context"
+
+	"github.com/smap-hcmut/shared-libs/go/log"
+)
+
+// Logger discards everything it is given.
+type Logger struct{}
+
+var _ log.Logger = Logger{}
+
+func (l Logger) Debug(ctx context.Context, args ...any)                    {}
+func (l Logger) Debugf(ctx context.Context, template string, args ...any)  {}
+func (l Logger) Info(ctx context.Context, args ...any)                     {}
+func (l Logger) Infof(ctx context.Context, template string, args ...any)   {}
+func (l Logger) Warn(ctx context.Context, args ...any)                     {}
+func (l Logger) Warnf(ctx context.Context, template string, args ...any)   {}
+func (l Logger) Error(ctx context.Context, args ...any)                    {}
+func (l Logger) Errorf(ctx context.Context, template string, args ...any)  {}
+func (l Logger) DPanic(ctx context.Context, args ...any)                   {}
+func (l Logger) DPanicf(ctx context.Context, template string, args ...any) {}
+func (l Logger) Panic(ctx context.Context, args ...any)                    {}
+func (l Logger) Panicf(ctx context.Context, template string, args ...any)  {}
+func (l Logger) Fatal(ctx context.Context, args ...any)                    {}
+func (l Logger) Fatalf(ctx context.Context, template string, args ...any)  {}
+func (l Logger) WithTrace(ctx context.Context) log.Logger                  { return l }
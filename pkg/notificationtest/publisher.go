@@ -0,0 +1,37 @@
+package notificationtest
+
+import (
+	"context"
+	"encoding/json"
+
+	"notification-srv/internal/websocket"
+)
+
+// Publisher feeds messages to a WebSocket UseCase the way the Redis subscriber
+// does, without Redis.
+type Publisher struct {
+	UC     websocket.UseCase
+	Region string // Optional upstream region stamped on every message
+}
+
+// Publish delivers payload on channel, e.g. project:{project_id}:user:{user_id}.
+// Payloads other than []byte and json.RawMessage are marshalled to JSON first.
+func (p Publisher) Publish(ctx context.Context, channel string, payload any) error {
+	var data []byte
+	switch v := payload.(type) {
+	case []byte:
+		data = v
+	case json.RawMessage:
+		data = v
+	default:
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return err
+		}
+	}
+	return p.UC.ProcessMessage(ctx, websocket.ProcessMessageInput{
+		Channel: channel,
+		Payload: data,
+		Region:  p.Region,
+	})
+}
@@ -0,0 +1,43 @@
+package notificationtest
+
+import (
+	"context"
+	"sync"
+
+	"notification-srv/internal/websocket"
+)
+
+// SegmentResolver is a websocket.SegmentResolver backed by a map of
+// kind and value to target. Unknown segments return websocket.ErrUnknownSegment.
+type SegmentResolver struct {
+	mu      sync.Mutex
+	targets map[segmentKey]websocket.SegmentTarget
+}
+
+type segmentKey struct {
+	kind, value string
+}
+
+var _ websocket.SegmentResolver = (*SegmentResolver)(nil)
+
+func NewSegmentResolver() *SegmentResolver {
+	return &SegmentResolver{targets: make(map[segmentKey]websocket.SegmentTarget)}
+}
+
+// AddSegment makes ResolveSegment return target for the kind and value.
+func (r *SegmentResolver) AddSegment(kind, value string, target websocket.SegmentTarget) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.targets[segmentKey{kind, value}] = target
+}
+
+func (r *SegmentResolver) ResolveSegment(ctx context.Context, segment websocket.Segment) (websocket.SegmentTarget, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	target, ok := r.targets[segmentKey{segment.Kind, segment.Value}]
+	if !ok {
+		return websocket.SegmentTarget{}, websocket.ErrUnknownSegment
+	}
+	return target, nil
+}
@@ -0,0 +1,93 @@
+package notificationtest
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/smap-hcmut/shared-libs/go/auth"
+)
+
+// ErrUnknownToken is returned for tokens that were not added to a TokenManager.
+var ErrUnknownToken = errors.New("notificationtest: unknown token")
+
+// TokenManager is an auth.Manager that accepts only the tokens added to it.
+type TokenManager struct {
+	mu       sync.Mutex
+	tokens   map[string]auth.Payload
+	scopes   map[string]auth.Scope
+	verified map[string]int
+}
+
+var _ auth.Manager = (*TokenManager)(nil)
+
+func NewTokenManager() *TokenManager {
+	return &TokenManager{
+		tokens:   make(map[string]auth.Payload),
+		scopes:   make(map[string]auth.Scope),
+		verified: make(map[string]int),
+	}
+}
+
+// AddToken makes Verify accept token for userID.
+func (m *TokenManager) AddToken(token, userID string) {
+	m.AddPayload(token, auth.Payload{UserID: userID})
+}
+
+// AddPayload makes Verify accept token and return payload.
+func (m *TokenManager) AddPayload(token string, payload auth.Payload) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens[token] = payload
+}
+
+// AddScope makes VerifyScope accept the scope header.
+func (m *TokenManager) AddScope(header string, scope auth.Scope) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scopes[header] = scope
+}
+
+// Verified returns how many times token was passed to Verify, accepted or not.
+func (m *TokenManager) Verified(token string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.verified[token]
+}
+
+func (m *TokenManager) Verify(token string) (auth.Payload, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.verified[token]++
+	payload, ok := m.tokens[token]
+	if !ok {
+		return auth.Payload{}, ErrUnknownToken
+	}
+	return payload, nil
+}
+
+func (m *TokenManager) VerifyWithTrace(ctx context.Context, token string) (auth.Payload, context.Context, error) {
+	payload, err := m.Verify(token)
+	return payload, ctx, err
+}
+
+// CreateToken returns the payload's user ID as the token and accepts it from then on.
+func (m *TokenManager) CreateToken(payload auth.Payload) (string, error) {
+	m.AddPayload(payload.UserID, payload)
+	return payload.UserID, nil
+}
+
+func (m *TokenManager) CreateTokenWithTrace(ctx context.Context, payload auth.Payload) (string, context.Context, error) {
+	token, err := m.CreateToken(payload)
+	return token, ctx, err
+}
+
+func (m *TokenManager) VerifyScope(scopeHeader string) (auth.Scope, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	scope, ok := m.scopes[scopeHeader]
+	if !ok {
+		return auth.Scope{}, ErrUnknownToken
+	}
+	return scope, nil
+}
@@ -0,0 +1,7 @@
+// Package redisclient connects to Redis servers that need more than a password:
+// ACL users (AUTH username password) and TLS with a custom CA bundle or a client
+// certificate. Plain connections are left to the shared redis package.
+//
+// ConnectWithRetry waits for a server that is not up yet, with exponential
+// backoff bounded by a total wait.
+package redisclient
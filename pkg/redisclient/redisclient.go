@@ -0,0 +1,145 @@
+package redisclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/smap-hcmut/shared-libs/go/redis"
+)
+
+// Config is the connection configuration of one Redis server.
+type Config struct {
+	Host     string
+	Port     int
+	Username string // ACL user; empty authenticates as the default user
+	Password string
+	DB       int
+	TLS      TLSConfig
+}
+
+// TLSConfig enables TLS. CAFile replaces the system roots; CertFile and
+// KeyFile present a client certificate.
+type TLSConfig struct {
+	Enabled            bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	ServerName         string // Defaults to Host
+	InsecureSkipVerify bool
+}
+
+// New connects to Redis and pings it. Without a username or TLS it is
+// redis.New.
+func New(cfg Config) (redis.IRedis, error) {
+	if cfg.Username == "" && !cfg.TLS.Enabled {
+		return redis.New(redis.RedisConfig{Host: cfg.Host, Port: cfg.Port, Password: cfg.Password, DB: cfg.DB})
+	}
+	if cfg.Host == "" {
+		return nil, redis.ErrHostRequired
+	}
+	if cfg.Port <= 0 || cfg.Port > 65535 {
+		return nil, redis.ErrInvalidPort
+	}
+
+	opts := &goredis.Options{
+		Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Username: cfg.Username,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	}
+	if cfg.TLS.Enabled {
+		tlsConfig, err := newTLSConfig(cfg.Host, cfg.TLS)
+		if err != nil {
+			return nil, configError{err}
+		}
+		opts.TLSConfig = tlsConfig
+	}
+
+	client := goredis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), redis.DefaultConnectTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	return &redisImpl{client: client}, nil
+}
+
+func newTLSConfig(host string, cfg TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = host
+	}
+
+	if cfg.CAFile != "" {
+		caPEM, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read redis CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("redis CA file contains no certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load redis client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// redisImpl implements redis.IRedis over a go-redis client.
+type redisImpl struct {
+	client *goredis.Client
+}
+
+func (c *redisImpl) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (c *redisImpl) Get(ctx context.Context, key string) (string, error) {
+	return c.client.Get(ctx, key).Result()
+}
+
+func (c *redisImpl) Delete(ctx context.Context, keys ...string) error {
+	return c.client.Del(ctx, keys...).Err()
+}
+
+func (c *redisImpl) Exists(ctx context.Context, key string) (bool, error) {
+	n, err := c.client.Exists(ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (c *redisImpl) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return c.client.TTL(ctx, key).Result()
+}
+
+func (c *redisImpl) Close() error {
+	return c.client.Close()
+}
+
+func (c *redisImpl) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
+func (c *redisImpl) GetClient() *goredis.Client {
+	return c.client
+}
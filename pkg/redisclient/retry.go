@@ -0,0 +1,84 @@
+package redisclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/smap-hcmut/shared-libs/go/redis"
+)
+
+// Retry is the policy of ConnectWithRetry.
+type Retry struct {
+	// MaxWait bounds the total time spent connecting. Zero makes a single
+	// attempt.
+	MaxWait time.Duration
+
+	// InitialBackoff is the wait after the first failed attempt. It doubles
+	// after every further attempt, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// OnRetry, if set, is called after every failed attempt that will be
+	// retried, with the wait before the next one.
+	OnRetry func(attempt int, wait time.Duration, err error)
+}
+
+// configError marks an error of the configuration itself, which retrying
+// cannot fix.
+type configError struct{ error }
+
+func (e configError) Unwrap() error { return e.error }
+
+// ConnectWithRetry is New, retried with exponential backoff while the server
+// is unreachable, until it answers, retry.MaxWait has passed or ctx is done.
+// Configuration errors fail at once. The error after the last attempt names
+// the server, the attempts made and the time spent.
+func ConnectWithRetry(ctx context.Context, cfg Config, retry Retry) (redis.IRedis, error) {
+	start := time.Now()
+	deadline := start.Add(retry.MaxWait)
+	backoff := retry.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		client, err := New(cfg)
+		if err == nil {
+			return client, nil
+		}
+		if !retryable(err) {
+			return nil, err
+		}
+
+		wait := backoff
+		if remaining := time.Until(deadline); wait > remaining {
+			wait = remaining
+		}
+		if wait <= 0 {
+			return nil, fmt.Errorf("redis %s:%d unreachable after %d attempts in %s: %w",
+				cfg.Host, cfg.Port, attempt, time.Since(start).Round(time.Millisecond), err)
+		}
+		if retry.OnRetry != nil {
+			retry.OnRetry(attempt, wait, err)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("redis %s:%d: gave up waiting after %d attempts: %w (last error: %v)",
+				cfg.Host, cfg.Port, attempt, ctx.Err(), err)
+		case <-timer.C:
+		}
+
+		if backoff *= 2; backoff > retry.MaxBackoff {
+			backoff = retry.MaxBackoff
+		}
+	}
+}
+
+func retryable(err error) bool {
+	var cfgErr configError
+	return !errors.Is(err, redis.ErrHostRequired) &&
+		!errors.Is(err, redis.ErrInvalidPort) &&
+		!errors.As(err, &cfgErr)
+}
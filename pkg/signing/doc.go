@@ -0,0 +1,22 @@
+// Package signing implements HMAC-SHA256 request signing for the service's
+// internal endpoints. Each caller holds one or more keys identified by a key
+// ID; a key is rotated by adding a new ID for the caller and removing the old
+// one once the caller has switched, and revoked by removing it.
+//
+// A signed request carries three headers:
+//
+//	X-Signature-Key-Id:    key ID
+//	X-Signature-Timestamp: unix seconds when the request was signed
+//	X-Signature:           hex HMAC-SHA256 of the string to sign
+//
+// The string to sign is the method, the path with its raw query, the
+// timestamp and the hex SHA-256 of the body, joined by newlines. The path is
+// the one the service receives, so callers going through a proxy that strips
+// a prefix must reach the service directly.
+//
+// There is no nonce or replay cache: a captured request verifies again, as
+// many times as it is sent, until its timestamp falls outside the allowed
+// skew. A cache would have to be shared by every replica to close that
+// window, so callers are expected to send over TLS and keep internal requests
+// idempotent instead.
+package signing
@@ -0,0 +1,145 @@
+package signing_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"notification-srv/pkg/signing"
+)
+
+var (
+	collectorKey = signing.Key{ID: "collector-2", Caller: "collector", Secret: []byte("collector-secret")}
+	retiredKey   = signing.Key{ID: "collector-1", Caller: "collector", Secret: []byte("retired-secret")}
+	signedAt     = time.Unix(1_700_000_000, 0)
+)
+
+func TestVerify(t *testing.T) {
+	verifier := signing.NewVerifier([]signing.Key{collectorKey}, time.Minute)
+	body := []byte(`{"project_id":"p1"}`)
+
+	tests := []struct {
+		name   string
+		key    signing.Key
+		tamper func(req *httptestRequest) // Applied after signing
+		body   []byte                     // Verified body; nil = the signed one
+		now    time.Time
+		want   error
+	}{
+		{name: "valid", key: collectorKey, now: signedAt},
+		{name: "skew within the limit ahead", key: collectorKey, now: signedAt.Add(time.Minute)},
+		{name: "skew within the limit behind", key: collectorKey, now: signedAt.Add(-time.Minute)},
+		{name: "uppercase hex signature", key: collectorKey, now: signedAt, tamper: func(r *httptestRequest) {
+			r.set(signing.HeaderSignature, strings.ToUpper(r.get(signing.HeaderSignature)))
+		}},
+
+		{name: "timestamp too old", key: collectorKey, now: signedAt.Add(time.Minute + time.Second), want: signing.ErrExpired},
+		{name: "timestamp in the future", key: collectorKey, now: signedAt.Add(-time.Minute - time.Second), want: signing.ErrExpired},
+		{name: "timestamp not a number", key: collectorKey, now: signedAt, want: signing.ErrExpired, tamper: func(r *httptestRequest) {
+			r.set(signing.HeaderTimestamp, "yesterday")
+		}},
+		{name: "timestamp changed", key: collectorKey, now: signedAt, want: signing.ErrBadSignature, tamper: func(r *httptestRequest) {
+			r.set(signing.HeaderTimestamp, "1700000001")
+		}},
+
+		{name: "unknown key id", key: retiredKey, now: signedAt, want: signing.ErrUnknownKey},
+		{name: "signed with another secret", key: signing.Key{ID: collectorKey.ID, Secret: []byte("guess")}, now: signedAt, want: signing.ErrBadSignature},
+		{name: "signature altered", key: collectorKey, now: signedAt, want: signing.ErrBadSignature, tamper: func(r *httptestRequest) {
+			sig := r.get(signing.HeaderSignature)
+			r.set(signing.HeaderSignature, "0"+sig[1:])
+		}},
+
+		{name: "body tampered", key: collectorKey, now: signedAt, body: []byte(`{"project_id":"p2"}`), want: signing.ErrBadSignature},
+		{name: "body dropped", key: collectorKey, now: signedAt, body: []byte{}, want: signing.ErrBadSignature},
+		{name: "query tampered", key: collectorKey, now: signedAt, want: signing.ErrBadSignature, tamper: func(r *httptestRequest) {
+			r.req.URL.RawQuery = "dry_run=false"
+		}},
+		{name: "path tampered", key: collectorKey, now: signedAt, want: signing.ErrBadSignature, tamper: func(r *httptestRequest) {
+			r.req.URL.Path = "/internal/ban"
+		}},
+		{name: "method tampered", key: collectorKey, now: signedAt, want: signing.ErrBadSignature, tamper: func(r *httptestRequest) {
+			r.req.Method = "PUT"
+		}},
+
+		{name: "missing key id", key: collectorKey, now: signedAt, want: signing.ErrMissingSignature, tamper: func(r *httptestRequest) {
+			r.set(signing.HeaderKeyID, "")
+		}},
+		{name: "missing timestamp", key: collectorKey, now: signedAt, want: signing.ErrMissingSignature, tamper: func(r *httptestRequest) {
+			r.set(signing.HeaderTimestamp, "")
+		}},
+		{name: "missing signature", key: collectorKey, now: signedAt, want: signing.ErrMissingSignature, tamper: func(r *httptestRequest) {
+			r.set(signing.HeaderSignature, "")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &httptestRequest{req: httptest.NewRequest("POST", "/internal/publish?dry_run=true", nil)}
+			signing.Sign(r.req, tt.key, body, signedAt)
+			if tt.tamper != nil {
+				tt.tamper(r)
+			}
+			verified := body
+			if tt.body != nil {
+				verified = tt.body
+			}
+
+			key, err := verifier.Verify(r.req, verified, tt.now)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && key.Caller != collectorKey.Caller {
+				t.Fatalf("Verify() caller = %q, want %q", key.Caller, collectorKey.Caller)
+			}
+		})
+	}
+}
+
+func TestVerifyDuringRotation(t *testing.T) {
+	verifier := signing.NewVerifier([]signing.Key{retiredKey, collectorKey}, 0)
+
+	for _, key := range []signing.Key{retiredKey, collectorKey} {
+		req := httptest.NewRequest("GET", "/internal/stats", nil)
+		signing.Sign(req, key, nil, signedAt)
+		// 0 falls back to DefaultMaxSkew
+		got, err := verifier.Verify(req, nil, signedAt.Add(signing.DefaultMaxSkew))
+		if err != nil {
+			t.Fatalf("key %s: %v", key.ID, err)
+		}
+		if got.ID != key.ID {
+			t.Fatalf("verified with %s, want %s", got.ID, key.ID)
+		}
+	}
+}
+
+func TestSigned(t *testing.T) {
+	req := httptest.NewRequest("GET", "/internal/stats", nil)
+	if signing.Signed(req) {
+		t.Fatal("unsigned request reported as signed")
+	}
+	signing.Sign(req, collectorKey, nil, signedAt)
+	if !signing.Signed(req) {
+		t.Fatal("signed request reported as unsigned")
+	}
+}
+
+// httptestRequest edits a signed request.
+type httptestRequest struct {
+	req *http.Request
+}
+
+func (r *httptestRequest) get(header string) string {
+	return r.req.Header.Get(header)
+}
+
+// set replaces header, or removes it when value is empty.
+func (r *httptestRequest) set(header, value string) {
+	if value == "" {
+		r.req.Header.Del(header)
+		return
+	}
+	r.req.Header.Set(header, value)
+}
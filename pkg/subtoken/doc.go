@@ -0,0 +1,15 @@
+// Package subtoken verifies project subscription tokens: short-lived JWTs
+// that let a read-only widget, e.g. one embedded in an external page, follow
+// one project without the user's session cookie.
+//
+// A subscription token is signed with the same key as user tokens and carries:
+//
+//	sub:        user who minted it; the connection counts against their quota
+//	aud:        the subscription audience, which user tokens never carry
+//	project_id: the only project the connection receives messages of
+//	iat, exp:   required; exp - iat may not exceed the configured lifetime
+//
+// Because the signing key is shared, the user-token path must refuse the
+// subscription audience, or a leaked widget token would act as the user. Guard
+// wraps the JWT manager to do so.
+package subtoken
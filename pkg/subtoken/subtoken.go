@@ -0,0 +1,130 @@
+package subtoken
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/smap-hcmut/shared-libs/go/auth"
+)
+
+var (
+	ErrAudience  = errors.New("subtoken: token is not a subscription token")
+	ErrLifetime  = errors.New("subtoken: token lifetime missing or too long")
+	ErrNoProject = errors.New("subtoken: token has no project_id")
+
+	// ErrSubscriptionToken is returned by a guarded manager for subscription
+	// tokens presented as user tokens.
+	ErrSubscriptionToken = fmt.Errorf("%w: subscription token used as a user token", auth.ErrInvalidToken)
+)
+
+// Claims are the verified claims of a subscription token.
+type Claims struct {
+	UserID    string
+	ProjectID string
+	ExpiresAt time.Time
+}
+
+// Verifier verifies subscription tokens with the signature checks of a JWT
+// manager.
+type Verifier struct {
+	mgr      auth.Manager
+	audience string
+	maxTTL   time.Duration
+}
+
+// NewVerifier returns a Verifier of tokens for audience that live at most
+// maxTTL. mgr must not be guarded, or every token is refused.
+func NewVerifier(mgr auth.Manager, audience string, maxTTL time.Duration) *Verifier {
+	return &Verifier{mgr: mgr, audience: audience, maxTTL: maxTTL}
+}
+
+// Verify checks the signature, expiry, audience and lifetime of token and
+// returns its claims.
+func (v *Verifier) Verify(token string) (Claims, error) {
+	payload, err := v.mgr.Verify(token)
+	if err != nil {
+		return Claims{}, err
+	}
+	claims := decode(token)
+	if !hasAudience(claims, v.audience) {
+		return Claims{}, ErrAudience
+	}
+	if payload.IssuedAt == 0 || payload.ExpiresAt == 0 ||
+		time.Duration(payload.ExpiresAt-payload.IssuedAt)*time.Second > v.maxTTL {
+		return Claims{}, ErrLifetime
+	}
+	projectID, _ := claims["project_id"].(string)
+	if projectID == "" {
+		return Claims{}, ErrNoProject
+	}
+	return Claims{
+		UserID:    payload.UserID,
+		ProjectID: projectID,
+		ExpiresAt: time.Unix(payload.ExpiresAt, 0),
+	}, nil
+}
+
+// Guard returns mgr refusing tokens for audience, so subscription tokens are
+// never accepted where a user token is expected.
+func Guard(mgr auth.Manager, audience string) auth.Manager {
+	return guard{Manager: mgr, audience: audience}
+}
+
+type guard struct {
+	auth.Manager
+	audience string
+}
+
+func (g guard) Verify(token string) (auth.Payload, error) {
+	payload, err := g.Manager.Verify(token)
+	if err == nil && hasAudience(decode(token), g.audience) {
+		return auth.Payload{}, ErrSubscriptionToken
+	}
+	return payload, err
+}
+
+func (g guard) VerifyWithTrace(ctx context.Context, token string) (auth.Payload, context.Context, error) {
+	payload, ctx, err := g.Manager.VerifyWithTrace(ctx, token)
+	if err == nil && hasAudience(decode(token), g.audience) {
+		return auth.Payload{}, ctx, ErrSubscriptionToken
+	}
+	return payload, ctx, err
+}
+
+// decode returns the claims of a token whose signature was already verified,
+// or nil if they cannot be decoded.
+func decode(token string) map[string]any {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return nil
+	}
+	return claims
+}
+
+// hasAudience reports whether the aud claim, a string or a list, holds audience.
+func hasAudience(claims map[string]any, audience string) bool {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return aud == audience
+	case []any:
+		for _, a := range aud {
+			if s, ok := a.(string); ok && s == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package subtoken_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"notification-srv/pkg/subtoken"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/smap-hcmut/shared-libs/go/auth"
+)
+
+const (
+	secret   = "test-secret"
+	audience = "notification-subscription"
+	maxTTL   = time.Hour
+)
+
+// mint signs claims with the key the manager under test verifies with.
+func mint(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// subscription returns valid subscription token claims; tests edit them.
+func subscription() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"sub":        "user_123",
+		"aud":        audience,
+		"project_id": "p1",
+		"iat":        now.Unix(),
+		"exp":        now.Add(maxTTL).Unix(),
+	}
+}
+
+func TestVerify(t *testing.T) {
+	verifier := subtoken.NewVerifier(auth.NewManager(secret), audience, maxTTL)
+
+	tests := []struct {
+		name string
+		edit func(jwt.MapClaims)
+		want error
+	}{
+		{name: "valid"},
+		{name: "audience in a list", edit: func(c jwt.MapClaims) {
+			c["aud"] = []string{"other", audience}
+		}},
+		{name: "user token", want: subtoken.ErrAudience, edit: func(c jwt.MapClaims) {
+			delete(c, "aud")
+		}},
+		{name: "other audience", want: subtoken.ErrAudience, edit: func(c jwt.MapClaims) {
+			c["aud"] = []string{"other"}
+		}},
+		{name: "missing iat", want: subtoken.ErrLifetime, edit: func(c jwt.MapClaims) {
+			delete(c, "iat")
+		}},
+		{name: "missing exp", want: subtoken.ErrLifetime, edit: func(c jwt.MapClaims) {
+			delete(c, "exp")
+		}},
+		{name: "lifetime over the limit", want: subtoken.ErrLifetime, edit: func(c jwt.MapClaims) {
+			c["exp"] = time.Now().Add(maxTTL + time.Second).Unix()
+		}},
+		{name: "missing project_id", want: subtoken.ErrNoProject, edit: func(c jwt.MapClaims) {
+			delete(c, "project_id")
+		}},
+		{name: "expired", want: auth.ErrInvalidToken, edit: func(c jwt.MapClaims) {
+			c["iat"] = time.Now().Add(-2 * time.Minute).Unix()
+			c["exp"] = time.Now().Add(-time.Minute).Unix()
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := subscription()
+			if tt.edit != nil {
+				tt.edit(claims)
+			}
+
+			got, err := verifier.Verify(mint(t, claims))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && (got.UserID != "user_123" || got.ProjectID != "p1") {
+				t.Fatalf("Verify() = %+v, want user_123 on p1", got)
+			}
+		})
+	}
+}
+
+func TestVerifyBadSignature(t *testing.T) {
+	verifier := subtoken.NewVerifier(auth.NewManager("other-secret"), audience, maxTTL)
+
+	if _, err := verifier.Verify(mint(t, subscription())); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Fatalf("Verify() error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestGuard(t *testing.T) {
+	mgr := subtoken.Guard(auth.NewManager(secret), audience)
+
+	listed := subscription()
+	listed["aud"] = []string{"other", audience}
+	user := subscription()
+	delete(user, "aud")
+	delete(user, "project_id")
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{name: "subscription token", token: mint(t, subscription()), want: subtoken.ErrSubscriptionToken},
+		{name: "audience in a list", token: mint(t, listed), want: subtoken.ErrSubscriptionToken},
+		{name: "user token", token: mint(t, user)},
+		{name: "invalid token", token: "not-a-token", want: auth.ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := mgr.Verify(tt.token)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && payload.UserID != "user_123" {
+				t.Fatalf("Verify() user = %q, want user_123", payload.UserID)
+			}
+
+			payload, _, err = mgr.VerifyWithTrace(context.Background(), tt.token)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("VerifyWithTrace() error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && payload.UserID != "user_123" {
+				t.Fatalf("VerifyWithTrace() user = %q, want user_123", payload.UserID)
+			}
+		})
+	}
+
+	// ErrSubscriptionToken is an ErrInvalidToken, so callers answer it with 401
+	if !errors.Is(subtoken.ErrSubscriptionToken, auth.ErrInvalidToken) {
+		t.Fatal("ErrSubscriptionToken does not wrap auth.ErrInvalidToken")
+	}
+}
@@ -0,0 +1,21 @@
+-- Delivery events exported by notification-srv (sinks.clickhouse).
+-- One row per notification routed to the WebSocket Hub.
+CREATE TABLE IF NOT EXISTS notification.delivery_events
+(
+    event_time   DateTime64(3, 'UTC'),
+    message_id   String,
+    message_type LowCardinality(String),
+    channel_type LowCardinality(String),
+    topic        String,
+    user_id      String,
+    region       LowCardinality(String),
+    frame_bytes  UInt32,
+    recipients   Int32,  -- connections the frame was queued to; -1 for broadcasts
+    dropped      UInt32, -- connections skipped because their send buffer was full
+    status       LowCardinality(String), -- delivered | dropped | no_recipient | broadcast
+    latency_ms   Int64   -- Redis receipt to Hub queueing
+)
+ENGINE = MergeTree
+PARTITION BY toYYYYMM(event_time)
+ORDER BY (message_type, event_time)
+TTL toDateTime(event_time) + INTERVAL 180 DAY;